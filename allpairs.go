@@ -0,0 +1,193 @@
+package bmssp
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// ErrMatrixTooLarge is returned when an all-pairs result would exceed the
+// configured memory limit.
+var ErrMatrixTooLarge = errors.New("bmssp: all-pairs result exceeds memory limit")
+
+const (
+	// DefaultAllPairsMaxBytes is the default memory limit for AllPairs results.
+	DefaultAllPairsMaxBytes = 1 << 30
+
+	// denseAllPairsLimit is the largest node count stored as a dense matrix.
+	denseAllPairsLimit = 4096
+
+	// sparseEntryBytes approximates the cost of one map entry in a sparse row,
+	// including key, value and hash table overhead.
+	sparseEntryBytes = 40
+)
+
+// AllPairsOptions configures AllPairsWithOptions.
+type AllPairsOptions struct {
+	Workers  int   // number of worker goroutines (default: GOMAXPROCS)
+	MaxBytes int64 // result memory limit (default: DefaultAllPairsMaxBytes, negative: unlimited)
+	Bound    Dist  // per-source distance bound; farther nodes are INF (default: INF)
+
+	// Progress, if set, is called after each source finishes with the number
+	// of sources done and the total. Calls are serialized.
+	Progress func(done, total int)
+}
+
+// DistanceTable holds all-pairs shortest distances. Graphs with up to a few
+// thousand nodes are stored as a dense n×n matrix; larger graphs keep one map
+// of finite distances per source.
+type DistanceTable struct {
+	nodes  []NodeID
+	index  map[NodeID]int
+	dense  []Dist            // row-major n×n matrix, nil for sparse tables
+	sparse []map[NodeID]Dist // finite distances per source, nil for dense tables
+}
+
+// Nodes returns the nodes of the table in ascending order.
+func (t *DistanceTable) Nodes() []NodeID {
+	return t.nodes
+}
+
+// Dist returns the shortest distance from u to v, or INF if v is unreachable
+// or either node is unknown.
+func (t *DistanceTable) Dist(u, v NodeID) Dist {
+	i, ok := t.index[u]
+	if !ok {
+		return INF
+	}
+	if t.sparse != nil {
+		if d, ok := t.sparse[i][v]; ok {
+			return d
+		}
+		return INF
+	}
+	j, ok := t.index[v]
+	if !ok {
+		return INF
+	}
+	return t.dense[i*len(t.nodes)+j]
+}
+
+// Dense reports whether the table is stored as a dense matrix.
+func (t *DistanceTable) Dense() bool {
+	return t.dense != nil
+}
+
+// EstimateAllPairsBytes returns the worst-case number of bytes needed to store
+// all-pairs distances for a graph with n nodes.
+func EstimateAllPairsBytes(n int) int64 {
+	cells := int64(n) * int64(n)
+	if n <= denseAllPairsLimit {
+		return cells * 8
+	}
+	return cells * sparseEntryBytes
+}
+
+// AllPairs computes shortest distances between every pair of nodes using the
+// default options. See AllPairsWithOptions.
+func AllPairs(g *Graph) (*DistanceTable, error) {
+	return AllPairsWithOptions(g, AllPairsOptions{})
+}
+
+// AllPairsWithOptions runs BMSSP from every node of g across a pool of worker
+// goroutines sharing the read-only graph. The graph must not be mutated while
+// the computation runs.
+//
+// Returns:
+//   - the distance table
+//   - ErrMatrixTooLarge if the estimated result size exceeds opts.MaxBytes
+func AllPairsWithOptions(g *Graph, opts AllPairsOptions) (*DistanceTable, error) {
+	nodes := make([]NodeID, 0, len(g.adj))
+	for u := range g.adj {
+		nodes = append(nodes, u)
+	}
+	slices.Sort(nodes)
+	n := len(nodes)
+
+	maxBytes := opts.MaxBytes
+	if maxBytes == 0 {
+		maxBytes = DefaultAllPairsMaxBytes
+	}
+	if est := EstimateAllPairsBytes(n); maxBytes > 0 && est > maxBytes {
+		return nil, fmt.Errorf("%w: %d nodes need ~%d bytes, limit is %d", ErrMatrixTooLarge, n, est, maxBytes)
+	}
+
+	bound := opts.Bound
+	if bound == 0 {
+		bound = INF
+	}
+
+	t := &DistanceTable{nodes: nodes, index: make(map[NodeID]int, n)}
+	for i, u := range nodes {
+		t.index[u] = i
+	}
+	if n <= denseAllPairsLimit {
+		t.dense = make([]Dist, n*n)
+	} else {
+		t.sparse = make([]map[NodeID]Dist, n)
+	}
+
+	var mu sync.Mutex
+	done := 0
+	parallelFor(n, opts.Workers, func(i int) {
+		t.fillRow(i, BMSSPSingleSource(g, nodes[i], bound), bound)
+		if opts.Progress != nil {
+			mu.Lock()
+			done++
+			opts.Progress(done, n)
+			mu.Unlock()
+		}
+	})
+
+	return t, nil
+}
+
+// parallelFor calls fn(i) for every i in [0, n) on a pool of worker
+// goroutines (GOMAXPROCS workers if workers <= 0) and waits for completion.
+func parallelFor(n, workers int, fn func(i int)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// fillRow stores the distances from nodes[i], with INF for those above
+// bound: the search leaves tentative distances there that are not final.
+// Each row is written by exactly one worker, so no locking is needed.
+func (t *DistanceTable) fillRow(i int, dhat map[NodeID]Dist, bound Dist) {
+	if t.sparse != nil {
+		row := make(map[NodeID]Dist)
+		for v, d := range dhat {
+			if d < INF && d <= bound {
+				row[v] = d
+			}
+		}
+		t.sparse[i] = row
+		return
+	}
+	row := t.dense[i*len(t.nodes) : (i+1)*len(t.nodes)]
+	for j, v := range t.nodes {
+		if d := dhat[v]; d <= bound {
+			row[j] = d
+		} else {
+			row[j] = INF
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package bmssp
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAllPairs(t *testing.T) {
+	g := generateRandomGraph(60, 240, 10.0, 7)
+
+	table, err := AllPairsWithOptions(g, AllPairsOptions{Workers: 4})
+	if err != nil {
+		t.Fatalf("AllPairs: %v", err)
+	}
+	if !table.Dense() {
+		t.Error("expected a dense table for a small graph")
+	}
+
+	for _, u := range table.Nodes() {
+		want := Dijkstra(g, u)
+		for _, v := range table.Nodes() {
+			if got := table.Dist(u, v); got != want[v] && math.Abs(float64(got-want[v])) > 1e-9 {
+				t.Fatalf("Dist(%d, %d): expected %v, got %v", u, v, want[v], got)
+			}
+		}
+	}
+	if d := table.Dist(0, 1000); d != INF {
+		t.Errorf("unknown node: expected INF, got %v", d)
+	}
+}
+
+func TestAllPairs_Bound(t *testing.T) {
+	g := generateRandomGraph(60, 240, 10.0, 7)
+	const bound = 8
+
+	table, err := AllPairsWithOptions(g, AllPairsOptions{Bound: bound})
+	if err != nil {
+		t.Fatalf("AllPairs: %v", err)
+	}
+	for _, u := range table.Nodes() {
+		want := Dijkstra(g, u)
+		for _, v := range table.Nodes() {
+			exp := want[v]
+			if exp > bound {
+				exp = INF
+			}
+			if got := table.Dist(u, v); got != exp && math.Abs(float64(got-exp)) > 1e-9 {
+				t.Fatalf("Dist(%d, %d) with bound %d: expected %v, got %v", u, v, bound, exp, got)
+			}
+		}
+	}
+}
+
+func TestAllPairs_MemoryGuard(t *testing.T) {
+	g := generateRandomGraph(100, 300, 10.0, 7)
+
+	_, err := AllPairsWithOptions(g, AllPairsOptions{MaxBytes: 1024})
+	if !errors.Is(err, ErrMatrixTooLarge) {
+		t.Errorf("expected ErrMatrixTooLarge, got %v", err)
+	}
+	if _, err := AllPairsWithOptions(g, AllPairsOptions{MaxBytes: -1}); err != nil {
+		t.Errorf("unlimited: unexpected error %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package bmssp
+
+import (
+	"iter"
+	"math"
+	"slices"
+)
+
+// shortestPathDAG holds the edges lying on shortest paths from a source: the
+// tight edges u->v with dist[u]+w equal to dist[v] within DefaultEpsilon.
+type shortestPathDAG struct {
+	dist  map[NodeID]Dist
+	order []NodeID            // topological order of the nodes on the DAG
+	sigma map[NodeID]float64  // number of shortest paths to each ordered node
+	preds map[NodeID][]NodeID // tight predecessors, one entry per parallel edge
+}
+
+// newShortestPathDAG computes distances from source and orders the tight
+// edges topologically with Kahn's algorithm, counting paths on the way.
+// Nodes on or behind a cycle of zero-weight edges are left out of order.
+func newShortestPathDAG(g *Graph, source NodeID) *shortestPathDAG {
+	dag := &shortestPathDAG{
+		dist:  BMSSPSingleSource(g, source, INF),
+		sigma: make(map[NodeID]float64),
+		preds: make(map[NodeID][]NodeID),
+	}
+	if _, ok := g.adj[source]; !ok {
+		return dag
+	}
+	for u, edges := range g.adj {
+		if dag.dist[u] == INF {
+			continue
+		}
+		for _, e := range edges {
+			if e.Weight < INF && approxEqual(dag.dist[u]+e.Weight, dag.dist[e.To], DefaultEpsilon) {
+				dag.preds[e.To] = append(dag.preds[e.To], u)
+			}
+		}
+	}
+	indeg := make(map[NodeID]int, len(dag.preds))
+	for v, ps := range dag.preds {
+		indeg[v] = len(ps)
+		slices.Sort(ps)
+	}
+	if indeg[source] > 0 {
+		return dag // the source lies on a zero-weight cycle
+	}
+
+	dag.sigma[source] = 1
+	dag.order = []NodeID{source}
+	for i := 0; i < len(dag.order); i++ {
+		u := dag.order[i]
+		for _, e := range g.adj[u] {
+			if e.Weight == INF || !approxEqual(dag.dist[u]+e.Weight, dag.dist[e.To], DefaultEpsilon) {
+				continue
+			}
+			dag.sigma[e.To] += dag.sigma[u]
+			if indeg[e.To]--; indeg[e.To] == 0 {
+				dag.order = append(dag.order, e.To)
+			}
+		}
+	}
+	return dag
+}
+
+// CountShortestPaths returns the number of distinct shortest paths from s to
+// every node; parallel edges make distinct paths. Counts are float64, as
+// they grow exponentially on grid-like graphs, and are exact up to 2^53.
+// Lengths are compared within DefaultEpsilon.
+//
+// Parameters:
+//   - g: input graph
+//   - s: source node
+//
+// Returns:
+//   - path counts: 1 at s, 0 for unreachable nodes, +Inf for nodes reached
+//     through a cycle of zero-weight edges
+func CountShortestPaths(g *Graph, s NodeID) map[NodeID]float64 {
+	dag := newShortestPathDAG(g, s)
+	counts := make(map[NodeID]float64, len(g.adj))
+	for v, d := range dag.dist {
+		if d < INF {
+			counts[v] = math.Inf(1)
+		} else {
+			counts[v] = 0
+		}
+	}
+	for _, v := range dag.order {
+		counts[v] = dag.sigma[v]
+	}
+	return counts
+}
+
+// AllShortestPaths lazily enumerates every shortest path from s to t, each
+// as a fresh slice from s to t, in lexicographic order of the reversed
+// paths. Paths through cycles of zero-weight edges are skipped, as there are
+// infinitely many. See CountShortestPaths for the number of paths, which can
+// be far too large to enumerate fully.
+//
+// Parameters:
+//   - g: input graph
+//   - s: source node
+//   - t: target node
+//
+// Returns:
+//   - a sequence of the shortest paths, empty if t is unreachable
+func AllShortestPaths(g *Graph, s, t NodeID) iter.Seq[[]NodeID] {
+	return func(yield func([]NodeID) bool) {
+		dag := newShortestPathDAG(g, s)
+		onDAG := NewNodeSet()
+		for _, v := range dag.order {
+			onDAG.Add(v)
+		}
+		if !onDAG.Has(t) {
+			return
+		}
+
+		// Walk the predecessors back from t; rev holds the partial path
+		rev := []NodeID{t}
+		var walk func(v NodeID) bool
+		walk = func(v NodeID) bool {
+			if v == s {
+				path := slices.Clone(rev)
+				slices.Reverse(path)
+				return yield(path)
+			}
+			for _, u := range dag.preds[v] {
+				if !onDAG.Has(u) {
+					continue
+				}
+				rev = append(rev, u)
+				ok := walk(u)
+				rev = rev[:len(rev)-1]
+				if !ok {
+					return false
+				}
+			}
+			return true
+		}
+		walk(t)
+	}
+}
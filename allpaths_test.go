@@ -0,0 +1,66 @@
+package bmssp
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestCountShortestPaths(t *testing.T) {
+	// On a grid every monotone staircase is shortest: C(w+h, w) paths
+	g := generateGridGraph(6, 5)
+	counts := CountShortestPaths(g, 0)
+	if counts[0] != 1 || counts[29] != 126 || counts[5] != 1 || counts[7] != 2 {
+		t.Errorf("unexpected grid counts: 29=%v 5=%v 7=%v", counts[29], counts[5], counts[7])
+	}
+
+	g = NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(0, 1, 1) // parallel edges are distinct paths
+	g.AddEdge(1, 2, 1)
+	g.AddEdge(0, 2, 3)
+	g.AddEdge(2, 3, 0)
+	g.AddEdge(3, 2, 0) // zero-weight cycle
+	g.AddEdge(4, 0, 1)
+	counts = CountShortestPaths(g, 0)
+	if counts[1] != 2 || counts[4] != 0 || !math.IsInf(counts[2], 1) || !math.IsInf(counts[3], 1) {
+		t.Errorf("unexpected counts %v", counts)
+	}
+}
+
+func TestAllShortestPaths(t *testing.T) {
+	g := generateGridGraph(4, 3)
+	var paths [][]NodeID
+	for p := range AllShortestPaths(g, 0, 11) {
+		paths = append(paths, p)
+	}
+	if want := CountShortestPaths(g, 0)[11]; float64(len(paths)) != want || want != 10 {
+		t.Fatalf("expected %v paths, got %d", want, len(paths))
+	}
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		if p[0] != 0 || p[len(p)-1] != 11 || len(p) != 6 {
+			t.Errorf("not a shortest path: %v", p)
+		}
+		key := fmt.Sprint(p)
+		if seen[key] {
+			t.Errorf("duplicate path %v", p)
+		}
+		seen[key] = true
+	}
+
+	// Stopping early and unreachable targets
+	n := 0
+	for range AllShortestPaths(g, 0, 11) {
+		if n++; n == 3 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Errorf("expected to stop after 3 paths, got %d", n)
+	}
+	g.AddEdge(12, 0, 1)
+	for p := range AllShortestPaths(g, 0, 12) {
+		t.Errorf("expected no path to a node with no in-edges, got %v", p)
+	}
+}
@@ -0,0 +1,108 @@
+package bmssp
+
+import (
+	"cmp"
+	"slices"
+)
+
+// AlternativeOptions configures AlternativeRoutesWithOptions. Zero fields
+// take their defaults.
+type AlternativeOptions struct {
+	Penalty    float64 // factor applied to the edges of every route found (default 1.5)
+	MaxStretch float64 // longest accepted route relative to the shortest (default 1.4)
+	MaxOverlap float64 // largest share of a route's length on an earlier route (default 0.6)
+	MaxRounds  int     // number of searches before giving up (default 4k)
+}
+
+// AlternativeRoutes returns up to k meaningfully different routes from s to
+// t using the default options. See AlternativeRoutesWithOptions.
+func AlternativeRoutes(g *Graph, s, t NodeID, k int) []Route {
+	return AlternativeRoutesWithOptions(g, s, t, k, AlternativeOptions{})
+}
+
+// AlternativeRoutesWithOptions computes alternatives to the shortest route
+// from s to t by iterative penalization: after every search the edges of
+// the route found are made more expensive, steering the next search to
+// other roads. A candidate is kept only if it is not much longer than the
+// shortest route and shares little of its length with the routes already
+// kept, so unlike the k shortest paths the results are not near-duplicates
+// differing in a single detour, e.g. the "3 sensible options" of a
+// navigation app.
+//
+// Parameters:
+//   - g: input graph (not modified)
+//   - s, t: endpoints of the routes
+//   - k: number of routes wanted, the shortest included
+//   - opts: penalty and acceptance criteria
+//
+// Returns:
+//   - the routes by increasing cost, the shortest first; fewer than k if
+//     no further acceptable route was found, nil if t is unreachable
+func AlternativeRoutesWithOptions(g *Graph, s, t NodeID, k int, opts AlternativeOptions) []Route {
+	if opts.Penalty <= 1 {
+		opts.Penalty = 1.5
+	}
+	if opts.MaxStretch < 1 {
+		opts.MaxStretch = 1.4
+	}
+	if opts.MaxOverlap <= 0 {
+		opts.MaxOverlap = 0.6
+	}
+	if opts.MaxRounds <= 0 {
+		opts.MaxRounds = 4 * k
+	}
+
+	var routes []Route
+	penalties := make(penaltyOverlay)
+	for round := 0; round < opts.MaxRounds && len(routes) < k; round++ {
+		path, _ := ShortestPath(g, s, t, WithOverlay(penalties))
+		if path == nil {
+			break
+		}
+		cost, _ := PathCost(g, path)
+		if acceptableAlternative(g, path, cost, routes, opts) {
+			routes = append(routes, Route{Path: path, Cost: cost})
+		} else if cost > Dist(opts.MaxStretch)*routes[0].Cost {
+			// Penalties only grow, so every later route is longer still
+			break
+		}
+		for _, id := range PathEdgeIDs(path) {
+			if f, ok := penalties[id]; ok {
+				penalties[id] = f * opts.Penalty
+			} else {
+				penalties[id] = opts.Penalty
+			}
+		}
+	}
+	slices.SortStableFunc(routes, func(a, b Route) int { return cmp.Compare(a.Cost, b.Cost) })
+	return routes
+}
+
+// acceptableAlternative reports whether path, of the given cost, is short
+// enough and different enough from routes to be kept. The first route is
+// always kept.
+func acceptableAlternative(g *Graph, path []NodeID, cost Dist, routes []Route, opts AlternativeOptions) bool {
+	if len(routes) == 0 {
+		return true
+	}
+	if cost > Dist(opts.MaxStretch)*routes[0].Cost {
+		return false
+	}
+	for _, r := range routes {
+		used := make(map[EdgeID]bool, len(r.Path))
+		for _, id := range PathEdgeIDs(r.Path) {
+			used[id] = true
+		}
+		var shared Dist
+		for _, id := range PathEdgeIDs(path) {
+			if used[id] {
+				w, _ := PathCost(g, []NodeID{id.From, id.To})
+				shared += w
+			}
+		}
+		if cost == 0 || shared > Dist(opts.MaxOverlap)*cost {
+			return false
+		}
+	}
+	return true
+}
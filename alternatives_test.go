@@ -0,0 +1,48 @@
+package bmssp
+
+import "testing"
+
+func TestAlternativeRoutes(t *testing.T) {
+	g := generateGridGraph(8, 8)
+	routes := AlternativeRoutes(g, 0, 63, 3)
+	if len(routes) != 3 {
+		t.Fatalf("expected 3 routes, got %d", len(routes))
+	}
+	if routes[0].Cost != 14 {
+		t.Errorf("expected the shortest route first, got cost %v", routes[0].Cost)
+	}
+	for i, r := range routes {
+		if r.Path[0] != 0 || r.Path[len(r.Path)-1] != 63 {
+			t.Errorf("route %d has wrong endpoints: %v", i, r.Path)
+		}
+		if cost, ok := PathCost(g, r.Path); !ok || cost != r.Cost || cost > 1.4*14 {
+			t.Errorf("route %d has cost %v, reported %v", i, cost, r.Cost)
+		}
+		for j := range i {
+			shared := 0
+			used := make(map[EdgeID]bool)
+			for _, id := range PathEdgeIDs(routes[j].Path) {
+				used[id] = true
+			}
+			for _, id := range PathEdgeIDs(r.Path) {
+				if used[id] {
+					shared++
+				}
+			}
+			if float64(shared) > 0.6*float64(r.Cost) {
+				t.Errorf("routes %d and %d share %d edges", j, i, shared)
+			}
+		}
+	}
+
+	// A single corridor has no alternative
+	line := NewGraph()
+	line.AddEdge(0, 1, 1)
+	line.AddEdge(1, 2, 1)
+	if got := AlternativeRoutes(line, 0, 2, 3); len(got) != 1 {
+		t.Errorf("expected only the shortest route, got %v", got)
+	}
+	if got := AlternativeRoutes(line, 2, 0, 3); got != nil {
+		t.Errorf("expected no route, got %v", got)
+	}
+}
@@ -0,0 +1,43 @@
+package bmssp
+
+import "iter"
+
+// Progressive runs a query in phases of doubling bound, yielding after each
+// phase the bound reached and the result within it, e.g. for a UI to draw
+// an isochrone expanding while the search continues. Each phase resumes the
+// previous one with ResumeBMSSP, so distances within earlier bounds are
+// never recomputed. The sequence ends once B is reached, nothing lies
+// beyond the current bound, or a phase stops early; breaking out of the
+// loop stops the search.
+//
+// Parameters:
+//   - g: input graph
+//   - sources: set of source nodes, all at distance 0
+//   - first: bound of the first phase; if not positive, the largest edge
+//     weight of g
+//   - B: final distance bound
+//   - opts: optional query settings, applied to every phase
+func Progressive(g *Graph, sources NodeSet, first, B Dist, opts ...Option) iter.Seq2[Dist, *Result] {
+	return func(yield func(Dist, *Result) bool) {
+		if !(first > 0) {
+			first = max(g.maxWeight, 1)
+		}
+		bound := min(first, B)
+		res := Solve(g, sources, bound, opts...)
+		for {
+			if !yield(bound, res) {
+				return
+			}
+			if bound >= B || !res.Truncated || res.Stopped != StopComplete && res.Stopped != StopMaxHops {
+				return
+			}
+			next := min(2*bound, B)
+			r, err := ResumeBMSSP(res, next-bound)
+			if err != nil {
+				// Hop limits cannot be resumed: run the phase from scratch
+				r = Solve(g, sources, next, opts...)
+			}
+			bound, res = next, r
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package bmssp
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestProgressive(t *testing.T) {
+	g := generateGridGraph(30, 30)
+	want := Dijkstra(g, 0)
+
+	var bounds []Dist
+	settled := 0
+	for bound, res := range Progressive(g, sources(0), 4, INF) {
+		bounds = append(bounds, bound)
+		for v, d := range want {
+			if d <= bound && res.Dist[v] != d {
+				t.Fatalf("bound %v: node %d: expected %v, got %v", bound, v, d, res.Dist[v])
+			}
+		}
+		settled += res.Stats.NodesSettled
+	}
+	// The farthest node is 58 away: phases 4, 8, 16, 32, 64
+	if len(bounds) != 5 || bounds[4] != 64 {
+		t.Errorf("unexpected phases %v", bounds)
+	}
+	if settled > 2*len(want) {
+		t.Errorf("expected earlier phases to be reused, settled %d nodes in total", settled)
+	}
+
+	// Breaking out and a final bound between phases
+	bounds = nil
+	for bound := range Progressive(g, sources(0), 4, 20) {
+		bounds = append(bounds, bound)
+	}
+	if len(bounds) != 4 || bounds[3] != 20 {
+		t.Errorf("expected phases up to 20, got %v", bounds)
+	}
+	for range Progressive(g, sources(0), 4, INF) {
+		break
+	}
+
+	// Hop limits are honoured by running every phase afresh; nothing lies
+	// beyond 3 within 3 hops
+	bounds = nil
+	for bound, res := range Progressive(g, sources(0), 2, 8, WithMaxHops(3)) {
+		bounds = append(bounds, bound)
+		if res.Dist[4] != INF || bound >= 4 && res.Dist[3] != 3 {
+			t.Errorf("bound %v: hop limit ignored", bound)
+		}
+	}
+	if len(bounds) != 2 {
+		t.Errorf("expected phases up to 4, got %v", bounds)
+	}
+}
+
+func TestProgressive_LongFirstEdge(t *testing.T) {
+	// The first edge is far longer than the first phase, so early phases
+	// settle nothing new but must not end the sequence
+	g := NewGraph()
+	g.AddEdge(0, 1, 4)
+	g.AddEdge(1, 2, 3)
+	var last *Result
+	for _, res := range Progressive(g, sources(0), 1, INF) {
+		last = res
+	}
+	if last.Dist[2] != 7 || last.Truncated {
+		t.Errorf("expected node 2 at 7 in a complete result, got %v (truncated=%v)", last.Dist[2], last.Truncated)
+	}
+
+	r := rand.New(rand.NewSource(31))
+	for i := 0; i < 30; i++ {
+		g := generateRandomGraph(200, 600, 50, int64(i))
+		g.AddEdge(200, NodeID(r.Intn(200)), 100)
+		want := Dijkstra(g, 200)
+		for _, res := range Progressive(g, sources(200), Dist(r.Float64()*5+0.5), INF) {
+			last = res
+		}
+		for v, d := range want {
+			if !approxEqual(last.Dist[v], d, DefaultEpsilon) {
+				t.Fatalf("graph %d: node %d: expected %v, got %v", i, v, d, last.Dist[v])
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package bmssp
+
+// ArcFlags is a goal-directed pruning index: the nodes are partitioned into
+// regions and every edge carries one flag per region, set if the edge lies
+// on a shortest path into that region. A query towards a target only needs
+// the edges flagged for the target's region, so it skips most of the graph
+// heading away from the target.
+//
+// The index reflects the graph when it was built; rebuild it after the
+// graph changes. An ArcFlags is safe for concurrent use.
+type ArcFlags struct {
+	region  map[NodeID]int // dense region index of every assigned node
+	regions int
+	flags   map[EdgeID][]uint64 // one bit per region
+}
+
+// BuildArcFlags computes the arc flags of g for the given partition. The
+// flags of a region are set on the edges inside it and on the edges of the
+// shortest-path trees towards each of its boundary nodes, those entered from
+// outside, taking one backward search per boundary node. Small regions with
+// few boundary nodes make preprocessing cheap; more regions make queries
+// faster.
+//
+// Parameters:
+//   - g: input graph (not modified)
+//   - partitions: region of every node, e.g. from Partition; unassigned
+//     nodes belong to no region and targets among them are searched
+//     without pruning
+//
+// Returns:
+//   - the arc-flags index of g
+func BuildArcFlags(g *Graph, partitions map[NodeID]int) *ArcFlags {
+	af := &ArcFlags{region: make(map[NodeID]int, len(partitions)), flags: make(map[EdgeID][]uint64)}
+	ids := make(map[int]int)
+	for v, r := range partitions {
+		if _, ok := g.adj[v]; !ok {
+			continue
+		}
+		if _, ok := ids[r]; !ok {
+			ids[r] = len(ids)
+		}
+		af.region[v] = ids[r]
+	}
+	af.regions = len(ids)
+	words := (len(ids) + 63) / 64
+
+	// Boundary nodes of every region
+	boundary := make([][]NodeID, len(ids))
+	for v, r := range af.region {
+		for _, e := range g.InEdges(v) {
+			if af.regionOf(e.To) != r {
+				boundary[r] = append(boundary[r], v)
+				break
+			}
+		}
+	}
+
+	rev := g.transposed()
+	flagged := make([][]EdgeID, len(ids))
+	parallelFor(len(ids), 0, func(r int) {
+		seen := make(map[EdgeID]bool)
+		mark := func(id EdgeID) {
+			if !seen[id] {
+				seen[id] = true
+				flagged[r] = append(flagged[r], id)
+			}
+		}
+		for u, edges := range g.adj {
+			for _, e := range edges {
+				if af.regionOf(u) == r && af.regionOf(e.To) == r {
+					mark(EdgeID{u, e.To})
+				}
+			}
+		}
+		for _, b := range boundary[r] {
+			dist := Dijkstra(rev, b)
+			for u, edges := range g.adj {
+				du, ok := dist[u]
+				if !ok || du == INF {
+					continue
+				}
+				for _, e := range edges {
+					if dv, ok := dist[e.To]; ok && dv < INF && dv+e.Weight == du {
+						mark(EdgeID{u, e.To})
+					}
+				}
+			}
+		}
+	})
+	for r, ids := range flagged {
+		for _, id := range ids {
+			f := af.flags[id]
+			if f == nil {
+				f = make([]uint64, words)
+				af.flags[id] = f
+			}
+			f[r/64] |= 1 << (r % 64)
+		}
+	}
+	return af
+}
+
+// regionOf returns the region index of v, -1 if v is unassigned.
+func (af *ArcFlags) regionOf(v NodeID) int {
+	if r, ok := af.region[v]; ok {
+		return r
+	}
+	return -1
+}
+
+// allows reports whether the edge from u to v is flagged for region r.
+func (af *ArcFlags) allows(u, v NodeID, r int) bool {
+	f := af.flags[EdgeID{u, v}]
+	return f != nil && f[r/64]&(1<<(r%64)) != 0
+}
+
+// Regions returns the number of regions of the index.
+func (af *ArcFlags) Regions() int {
+	return af.regions
+}
+
+// WithArcFlags prunes the query to the edges flagged for the region of
+// target. Distances are exact for the nodes of that region, target
+// included, and may be overestimated elsewhere; combine it with WithTargets
+// to stop once the target is settled. It composes with WithEdgeFilter given
+// before it.
+func WithArcFlags(af *ArcFlags, target NodeID) Option {
+	return func(c *config) {
+		r := af.regionOf(target)
+		if r < 0 {
+			return
+		}
+		keep := c.edgeFilter
+		c.edgeFilter = func(u NodeID, e Edge) bool {
+			return af.allows(u, e.To, r) && (keep == nil || keep(u, e))
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package bmssp
+
+import "testing"
+
+// quadrants partitions a w×h grid laid out like generateGridGraph into
+// four regions.
+func quadrants(w, h int) map[NodeID]int {
+	part := make(map[NodeID]int, w*h)
+	for y := range h {
+		for x := range w {
+			part[NodeID(y*w+x)] = 2*(2*y/h) + 2*x/w
+		}
+	}
+	return part
+}
+
+func TestArcFlags(t *testing.T) {
+	g := generateGridGraph(30, 30)
+	// Make horizontal moves cheaper so shortest paths are unique
+	for y := range 30 {
+		for x := range 29 {
+			v := NodeID(30*y + x)
+			g.UpdateEdgeWeight(v, v+1, 0.5)
+			g.UpdateEdgeWeight(v+1, v, 0.5)
+		}
+	}
+	af := BuildArcFlags(g, quadrants(30, 30))
+	if af.Regions() != 4 {
+		t.Fatalf("expected 4 regions, got %d", af.Regions())
+	}
+
+	for _, pair := range [][2]NodeID{{0, 899}, {899, 0}, {29, 870}, {0, 1}, {465, 14}} {
+		s, target := pair[0], pair[1]
+		want := Solve(g, sources(s), INF, WithTargets(sources(target)))
+		got := Solve(g, sources(s), INF, WithTargets(sources(target)), WithArcFlags(af, target))
+		if got.Dist[target] != want.Dist[target] {
+			t.Errorf("%d -> %d: expected %v, got %v", s, target, want.Dist[target], got.Dist[target])
+		}
+		if got.Stats.NodesSettled > want.Stats.NodesSettled {
+			t.Errorf("%d -> %d: pruning settled more nodes: %d > %d", s, target, got.Stats.NodesSettled, want.Stats.NodesSettled)
+		}
+	}
+
+	// From the top left corner to the top right quadrant, the lower half
+	// of the grid is pruned
+	full := Solve(g, sources(0), INF)
+	pruned := Solve(g, sources(0), INF, WithArcFlags(af, 29))
+	if pruned.Dist[29] != full.Dist[29] || pruned.Stats.NodesSettled >= full.Stats.NodesSettled*3/4 {
+		t.Errorf("expected exact pruned search, got %v after %d of %d nodes",
+			pruned.Dist[29], pruned.Stats.NodesSettled, full.Stats.NodesSettled)
+	}
+
+	// Unassigned targets are searched without pruning
+	if res := Solve(g, sources(0), INF, WithArcFlags(BuildArcFlags(g, nil), 899)); res.Dist[899] != full.Dist[899] {
+		t.Errorf("expected an unpruned search, got %v", res.Dist[899])
+	}
+}
@@ -0,0 +1,67 @@
+package bmssp
+
+// transposed returns a read-only view of g with every edge reversed. The
+// view shares g's reverse index and must not be mutated.
+func (g *Graph) transposed() *Graph {
+	t := &Graph{
+		adj:       make(map[NodeID][]Edge, len(g.adj)),
+		numEdges:  g.numEdges,
+		maxWeight: g.maxWeight,
+		shape:     g.shape,
+		uniform:   g.uniform,
+	}
+	for v := range g.adj {
+		t.adj[v] = g.InEdges(v)
+	}
+	return t
+}
+
+// reversedOverlay presents an overlay of g to searches over g.transposed():
+// the reversed edge from v to u is weighted like the original edge u->v.
+type reversedOverlay struct {
+	WeightOverlay
+}
+
+func (r reversedOverlay) Weight(from NodeID, e Edge) Dist {
+	return r.WeightOverlay.Weight(e.To, Edge{To: from, Weight: e.Weight})
+}
+
+// BMSSPSingleTarget computes the shortest distance from every node to target
+// within bound B by running BMSSP over the transposed graph, e.g. for every
+// vehicle routing to one depot. It uses the graph's reverse index (see
+// InEdges), building it on first use.
+//
+// Parameters:
+//   - G: input graph
+//   - target: target node
+//   - B: distance bound
+//   - opts: optional query settings; weight overlays, weight functions and
+//     edge filters see the original edge directions, edges added by overlays are not
+//     considered
+//
+// Returns:
+//   - map of shortest distances from every node to target
+func BMSSPSingleTarget(G *Graph, target NodeID, B Dist, opts ...Option) map[NodeID]Dist {
+	s := newSolver(G.transposed(), opts)
+	if s.cfg.overlay != nil {
+		s.cfg.overlay = reversedOverlay{s.cfg.overlay}
+		s.cfg.extra = nil
+	}
+	if fn := s.cfg.weightFunc; fn != nil {
+		s.cfg.weightFunc = func(from NodeID, e Edge) Dist {
+			return fn(e.To, Edge{To: from, Weight: e.Weight})
+		}
+	}
+	if keep := s.cfg.edgeFilter; keep != nil {
+		s.cfg.edgeFilter = func(from NodeID, e Edge) bool {
+			return keep(e.To, Edge{To: from, Weight: e.Weight})
+		}
+	}
+	s.dhat[target] = 0
+
+	S := NewNodeSet()
+	S.Add(target)
+	s.run(B, S)
+
+	return s.dhat
+}
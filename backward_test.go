@@ -0,0 +1,30 @@
+package bmssp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBMSSPSingleTarget(t *testing.T) {
+	g := generateRandomGraph(200, 1000, 10, 23)
+	got := BMSSPSingleTarget(g, 7, INF)
+
+	for u := range g.adj {
+		want := Dijkstra(g, u)[7]
+		if math.Abs(float64(got[u]-want)) > 1e-9 && got[u] != want {
+			t.Errorf("node %d: expected distance %v to target, got %v", u, want, got[u])
+		}
+	}
+}
+
+func TestBMSSPSingleTarget_Overlay(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 2, 1)
+	g.AddEdge(0, 2, 5)
+
+	got := BMSSPSingleTarget(g, 2, INF, WithOverlay(closedEdge{From: 1, To: 2}))
+	if got[0] != 5 || got[1] != INF {
+		t.Errorf("expected 0 to detour at cost 5 and 1 to be cut off, got %v", got)
+	}
+}
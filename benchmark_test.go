@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"runtime"
 	"testing"
 )
 
@@ -146,6 +147,16 @@ func BenchmarkBMSSPGrid20x20(b *testing.B) {
 	g := generateGridGraph(20, 20)
 	source := NodeID(0)
 
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = BMSSPSingleSource(g, source, 1000, WithoutFastPaths())
+	}
+}
+
+func BenchmarkBFSGrid20x20(b *testing.B) {
+	g := generateGridGraph(20, 20)
+	source := NodeID(0)
+
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		_ = BMSSPSingleSource(g, source, 1000)
@@ -169,7 +180,21 @@ func BenchmarkBMSSPGrid50x50(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = BMSSPSingleSource(g, source, 1000)
+		_ = BMSSPSingleSource(g, source, 1000, WithoutFastPaths())
+	}
+}
+
+// Benchmark sequential against parallel bucket scans on a graph with wide buckets
+func BenchmarkBMSSPRandom100k(b *testing.B) { benchmarkParallelism(b, 1) }
+
+func BenchmarkBMSSPRandom100kParallel(b *testing.B) { benchmarkParallelism(b, runtime.GOMAXPROCS(0)) }
+
+func benchmarkParallelism(b *testing.B, workers int) {
+	g := generateRandomGraph(100000, 1000000, 10.0, 42)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = BMSSPSingleSource(g, 0, INF, WithParallelism(workers))
 	}
 }
 
@@ -220,4 +245,77 @@ func TestAlgorithmEquivalence(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func benchmarkQueue(b *testing.B, newQueue func() PriorityQueue) {
+	g := generateGridGraph(100, 100)
+	S := NewNodeSet()
+	S.Add(0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SolveDijkstra(g, S, WithQueue(newQueue))
+	}
+}
+
+func BenchmarkBinaryHeapGrid100x100(b *testing.B) { benchmarkQueue(b, BinaryHeap) }
+
+func BenchmarkRadixHeapGrid100x100(b *testing.B) { benchmarkQueue(b, RadixHeap) }
+
+// matrixNodes picks every 25th node of a 50×50 grid, 100 in all.
+func matrixNodes() []NodeID {
+	nodes := make([]NodeID, 100)
+	for i := range nodes {
+		nodes[i] = NodeID(25 * i)
+	}
+	return nodes
+}
+
+func BenchmarkDistanceMatrixGrid50x50(b *testing.B) {
+	g := generateGridGraph(50, 50)
+	nodes := matrixNodes()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = DistanceMatrix(g, nodes, nodes)
+	}
+}
+
+func BenchmarkHierarchyMatrixGrid50x50(b *testing.B) {
+	h := NewHierarchy(generateGridGraph(50, 50))
+	nodes := matrixNodes()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = h.Matrix(nodes, nodes)
+	}
+}
+
+// bulkEdges returns random edges between 100k nodes.
+func bulkEdges() []EdgeTriple {
+	r := rand.New(rand.NewSource(1))
+	edges := make([]EdgeTriple, 1_000_000)
+	for i := range edges {
+		edges[i] = EdgeTriple{From: NodeID(r.Intn(100_000)), To: NodeID(r.Intn(100_000)), Weight: Dist(r.Float64())}
+	}
+	return edges
+}
+
+func BenchmarkAddEdge1M(b *testing.B) {
+	edges := bulkEdges()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g := NewGraph()
+		for _, e := range edges {
+			g.AddEdge(e.From, e.To, e.Weight)
+		}
+	}
+}
+
+func BenchmarkNewGraphFromEdges1M(b *testing.B) {
+	edges := bulkEdges()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewGraphFromEdges(100_000, edges)
+	}
+}
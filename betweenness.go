@@ -0,0 +1,137 @@
+package bmssp
+
+import (
+	"slices"
+	"sync"
+)
+
+// BetweennessOptions configures BetweennessWithOptions.
+type BetweennessOptions struct {
+	Workers int      // number of worker goroutines (default: GOMAXPROCS)
+	Sources []NodeID // sources to accumulate over (default: every node), e.g. a sample
+
+	// Normalize divides every score by (n-1)(n-2), the number of ordered
+	// pairs of other nodes, giving values between 0 and 1.
+	Normalize bool
+}
+
+// Betweenness computes the betweenness centrality of every node of g using
+// the default options. See BetweennessWithOptions.
+func Betweenness(g *Graph) map[NodeID]float64 {
+	return BetweennessWithOptions(g, BetweennessOptions{})
+}
+
+// BetweennessWithOptions computes betweenness centrality with Brandes'
+// algorithm: for every ordered pair of nodes s != t, each node v other than
+// s and t earns the fraction of shortest s-t paths passing through it.
+//
+// Distances from each source come from BMSSP; the shortest-path DAG is
+// formed by the tight edges (du+w equals dv within DefaultEpsilon) and
+// traversed in topological order to count paths and accumulate
+// dependencies. Sources run in parallel on a pool of workers sharing the
+// read-only graph, which must not be mutated meanwhile. Nodes on cycles of
+// zero-weight edges have infinitely many shortest paths and are skipped.
+//
+// Parameters:
+//   - g: input graph; undirected graphs stored as edge pairs score every
+//     pair in both directions
+//   - opts: workers, sources and normalization
+//
+// Returns:
+//   - the centrality of every node of g
+func BetweennessWithOptions(g *Graph, opts BetweennessOptions) map[NodeID]float64 {
+	nodes := make([]NodeID, 0, len(g.adj))
+	for u := range g.adj {
+		nodes = append(nodes, u)
+	}
+	slices.Sort(nodes)
+	index := make(map[NodeID]int, len(nodes))
+	for i, u := range nodes {
+		index[u] = i
+	}
+	srcs := opts.Sources
+	if srcs == nil {
+		srcs = nodes
+	}
+
+	bc := make([]float64, len(nodes))
+	var mu sync.Mutex
+	parallelFor(len(srcs), opts.Workers, func(i int) {
+		if _, ok := index[srcs[i]]; !ok {
+			return
+		}
+		delta := brandesDependencies(g, nodes, index, srcs[i])
+		mu.Lock()
+		for v, d := range delta {
+			bc[v] += d
+		}
+		mu.Unlock()
+	})
+
+	scale := 1.0
+	if n := float64(len(nodes)); opts.Normalize && n > 2 {
+		scale = 1 / ((n - 1) * (n - 2))
+	}
+	out := make(map[NodeID]float64, len(nodes))
+	for i, u := range nodes {
+		out[u] = bc[i] * scale
+	}
+	return out
+}
+
+// brandesDependencies returns the dependency of source on every node, indexed
+// like nodes, with the source's own entry zeroed.
+func brandesDependencies(g *Graph, nodes []NodeID, index map[NodeID]int, source NodeID) []float64 {
+	dist := BMSSPSingleSource(g, source, INF)
+	tight := func(u NodeID, e Edge) bool {
+		return e.Weight < INF && approxEqual(dist[u]+e.Weight, dist[e.To], DefaultEpsilon)
+	}
+
+	// Count the tight edges into every node, then order the shortest-path
+	// DAG topologically with Kahn's algorithm, counting paths on the way.
+	indeg := make([]int, len(nodes))
+	for _, u := range nodes {
+		if dist[u] == INF {
+			continue
+		}
+		for _, e := range g.adj[u] {
+			if tight(u, e) {
+				indeg[index[e.To]]++
+			}
+		}
+	}
+	sigma := make([]float64, len(nodes))
+	sigma[index[source]] = 1
+	order := []NodeID{source}
+	if indeg[index[source]] > 0 {
+		// The source lies on a zero-weight cycle
+		return make([]float64, len(nodes))
+	}
+	for i := 0; i < len(order); i++ {
+		u := order[i]
+		for _, e := range g.adj[u] {
+			if !tight(u, e) {
+				continue
+			}
+			v := index[e.To]
+			sigma[v] += sigma[index[u]]
+			if indeg[v]--; indeg[v] == 0 {
+				order = append(order, e.To)
+			}
+		}
+	}
+
+	// Accumulate dependencies in reverse topological order
+	delta := make([]float64, len(nodes))
+	for i := len(order) - 1; i >= 0; i-- {
+		u := order[i]
+		iu := index[u]
+		for _, e := range g.adj[u] {
+			if v := index[e.To]; tight(u, e) && indeg[v] == 0 {
+				delta[iu] += sigma[iu] / sigma[v] * (1 + delta[v])
+			}
+		}
+	}
+	delta[index[source]] = 0
+	return delta
+}
@@ -0,0 +1,107 @@
+package bmssp
+
+import (
+	"cmp"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestBetweenness_Small(t *testing.T) {
+	// Undirected path 0-1-2-3
+	path := NewGraph()
+	for i := range 3 {
+		path.AddEdge(NodeID(i), NodeID(i+1), 1)
+		path.AddEdge(NodeID(i+1), NodeID(i), 1)
+	}
+	bc := Betweenness(path)
+	if bc[0] != 0 || bc[1] != 4 || bc[2] != 4 || bc[3] != 0 {
+		t.Errorf("path: unexpected centrality %v", bc)
+	}
+	if nb := BetweennessWithOptions(path, BetweennessOptions{Normalize: true}); nb[1] != 4.0/6 {
+		t.Errorf("path: expected normalized 2/3, got %v", nb[1])
+	}
+
+	// Two equal routes split the credit; zero weights count as shortest too
+	diamond := NewGraph()
+	diamond.AddEdge(0, 1, 1)
+	diamond.AddEdge(0, 2, 0.5)
+	diamond.AddEdge(2, 4, 0)
+	diamond.AddEdge(4, 3, 0.5)
+	diamond.AddEdge(1, 3, 0)
+	bc = Betweenness(diamond)
+	if bc[1] != 0.5 || bc[2] != 1.5 || bc[4] != 1.5 || bc[0] != 0 || bc[3] != 0 {
+		t.Errorf("diamond: unexpected centrality %v", bc)
+	}
+}
+
+// bruteBetweenness sums sigma(s,v)·sigma(v,t)/sigma(s,t) over all pairs,
+// counting shortest paths by dynamic programming in distance order.
+func bruteBetweenness(g *Graph) map[NodeID]float64 {
+	nodes := make([]NodeID, 0, len(g.adj))
+	for u := range g.adj {
+		nodes = append(nodes, u)
+	}
+	dist := make(map[NodeID]map[NodeID]Dist)
+	sigma := make(map[NodeID]map[NodeID]float64)
+	for _, s := range nodes {
+		d := Dijkstra(g, s)
+		order := slices.Clone(nodes)
+		slices.SortFunc(order, func(a, b NodeID) int { return cmp.Compare(d[a], d[b]) })
+		sg := map[NodeID]float64{s: 1}
+		for _, u := range order {
+			for _, e := range g.adj[u] {
+				if d[u] < INF && d[u]+e.Weight == d[e.To] {
+					sg[e.To] += sg[u]
+				}
+			}
+		}
+		dist[s], sigma[s] = d, sg
+	}
+	bc := make(map[NodeID]float64)
+	for _, s := range nodes {
+		for _, t := range nodes {
+			if s == t || dist[s][t] == INF {
+				continue
+			}
+			for _, v := range nodes {
+				if v != s && v != t && dist[s][v]+dist[v][t] == dist[s][t] {
+					bc[v] += sigma[s][v] * sigma[v][t] / sigma[s][t]
+				}
+			}
+		}
+	}
+	return bc
+}
+
+func TestBetweenness_MatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	g := NewGraph()
+	for range 250 {
+		// Small integer weights produce many ties
+		g.AddEdge(NodeID(rng.Intn(40)), NodeID(rng.Intn(40)), Dist(1+rng.Intn(3)))
+	}
+
+	want := bruteBetweenness(g)
+	got := BetweennessWithOptions(g, BetweennessOptions{Workers: 4})
+	for v := range g.adj {
+		if math.Abs(got[v]-want[v]) > 1e-9*max(1, want[v]) {
+			t.Errorf("node %d: expected %v, got %v", v, want[v], got[v])
+		}
+	}
+
+	// Restricting the sources sums only their dependencies
+	some := BetweennessWithOptions(g, BetweennessOptions{Sources: []NodeID{3, 99}})
+	if total := sum(some); total <= 0 || total >= sum(got) {
+		t.Errorf("expected a partial sum below %v, got %v", sum(got), total)
+	}
+}
+
+func sum(m map[NodeID]float64) float64 {
+	total := 0.0
+	for _, c := range m {
+		total += c
+	}
+	return total
+}
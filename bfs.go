@@ -0,0 +1,187 @@
+package bmssp
+
+// weightShape classifies the edge weights of a graph, so that queries over
+// graphs whose weights are all equal or all 0 or 1 can run as a BFS.
+type weightShape uint8
+
+const (
+	shapeEmpty   weightShape = iota // no edge weights noted yet
+	shapeUniform                    // every edge has the same positive weight
+	shapeZeroOne                    // every edge weighs 0 or 1
+	shapeGeneral                    // anything else
+)
+
+// note returns the shape, and the common weight of a uniform shape, after an
+// edge of weight w is added to a graph of shape sh with common weight u.
+func (sh weightShape) note(u, w Dist) (weightShape, Dist) {
+	zeroOne := w == 0 || w == 1
+	switch sh {
+	case shapeEmpty:
+		switch {
+		case w == 0:
+			return shapeZeroOne, 0
+		case w > 0 && w < INF:
+			return shapeUniform, w
+		}
+	case shapeUniform:
+		switch {
+		case w == u:
+			return shapeUniform, u
+		case zeroOne && u == 1:
+			return shapeZeroOne, 0
+		}
+	case shapeZeroOne:
+		if zeroOne {
+			return shapeZeroOne, 0
+		}
+	}
+	return shapeGeneral, 0
+}
+
+// measureShape computes the exact weight shape of g's current edges.
+func (g *Graph) measureShape() (weightShape, Dist) {
+	shape, uniform := shapeEmpty, Dist(0)
+	for _, edges := range g.adj {
+		for _, e := range edges {
+			if shape, uniform = shape.note(uniform, e.Weight); shape == shapeGeneral {
+				return shape, uniform
+			}
+		}
+	}
+	return shape, uniform
+}
+
+// WithUnitWeights evaluates the query as if every edge weighed 1, so that
+// distances are hop counts. Such queries run as a plain BFS.
+func WithUnitWeights() Option {
+	return func(c *config) { c.unitWeights = true }
+}
+
+// WithoutFastPaths runs the general algorithm even on graphs that qualify
+// for the BFS or DAG fast paths, e.g. to benchmark it on unit-weight graphs.
+func WithoutFastPaths() Option {
+	return func(c *config) { c.noFastPaths = true }
+}
+
+// useBFS reports whether the query from S can run as a BFS: every edge it
+// sees weighs 0 or 1, or all weigh the same, the sources share one distance
+// and no hop limit applies. Graphs are classified as edges are added;
+// removals and weight updates only widen the class until Freeze measures it
+// again.
+func (s *solver) useBFS(S NodeSet) bool {
+	if s.cfg.noFastPaths || s.cfg.maxHops > 0 {
+		return false
+	}
+	d := Dist(-1)
+	for v := range S {
+		if d >= 0 && s.dhat[v] != d {
+			return false
+		}
+		d = s.dhat[v]
+	}
+	if s.cfg.unitWeights {
+		return true
+	}
+	if s.g == nil || s.cfg.overlay != nil || s.cfg.weightFunc != nil {
+		return false
+	}
+	return s.g.shape == shapeUniform || s.g.shape == shapeZeroOne
+}
+
+// bfs settles the nodes reachable from S within bound B in distance order,
+// like dijkstra, using a deque instead of a heap: nodes reached over a
+// zero-weight edge go to the front and all others to the back. With a single
+// positive weight this is a plain BFS, otherwise a 0-1 BFS; either way every
+// operation takes constant time.
+func (s *solver) bfs(S NodeSet, B Dist) {
+	s.bound = B
+	if s.cfg.tracer != nil {
+		end := s.beginSpan("bmssp.bfs", Attr{"sources", len(S)}, Attr{"bound", float64(B)})
+		defer func() {
+			end(Attr{"nodes_settled", s.stats.NodesSettled}, Attr{"stopped", s.stopReason().String()})
+		}()
+	}
+
+	var dq deque
+	for v := range S {
+		dq.pushBack(v)
+		s.stats.QueueOps++
+	}
+	settled := NewNodeSet()
+	pending := len(s.cfg.targets)
+	if s.cfg.targets != nil && pending == 0 {
+		s.stopped = StopTargets
+		return
+	}
+
+	for dq.len() > 0 {
+		u := dq.popFront()
+		s.stats.QueueOps++
+		if settled.Has(u) {
+			continue
+		}
+		settled.Add(u)
+		if s.shouldStop() {
+			return
+		}
+		if s.emit != nil && !s.emit(u, s.dhat[u]) {
+			s.stopped = StopCanceled
+			return
+		}
+		if s.cfg.targets.Has(u) {
+			if pending--; pending == 0 {
+				s.stopped = StopTargets
+				return
+			}
+		}
+		s.settle(u)
+
+		for _, e := range s.outEdges(u) {
+			s.stats.EdgesScanned++
+			w := s.weight(u, e)
+			d := s.dhat[u] + w
+			if !s.improves(u, e.To, d) {
+				continue
+			}
+			if d > B {
+				s.cut = true
+				continue
+			}
+			s.relax(u, e.To, d)
+			if w == 0 {
+				dq.pushFront(e.To)
+			} else {
+				dq.pushBack(e.To)
+			}
+			s.stats.QueueOps++
+		}
+	}
+}
+
+// deque is a double-ended queue of nodes: a stack for the front and a
+// queue for the back.
+type deque struct {
+	front []NodeID // popped from the end
+	back  []NodeID // popped from head
+	head  int
+}
+
+func (q *deque) len() int { return len(q.front) + len(q.back) - q.head }
+
+func (q *deque) pushFront(v NodeID) { q.front = append(q.front, v) }
+
+func (q *deque) pushBack(v NodeID) { q.back = append(q.back, v) }
+
+func (q *deque) popFront() NodeID {
+	if n := len(q.front); n > 0 {
+		v := q.front[n-1]
+		q.front = q.front[:n-1]
+		return v
+	}
+	v := q.back[q.head]
+	q.head++
+	if q.head == len(q.back) {
+		q.back, q.head = q.back[:0], 0
+	}
+	return v
+}
@@ -0,0 +1,114 @@
+package bmssp
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestWeightShape(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights []Dist
+		want    weightShape
+	}{
+		{"empty", nil, shapeEmpty},
+		{"uniform", []Dist{2.5, 2.5, 2.5}, shapeUniform},
+		{"unit", []Dist{1, 1}, shapeUniform},
+		{"zero-one", []Dist{1, 0, 1}, shapeZeroOne},
+		{"zero first", []Dist{0, 1}, shapeZeroOne},
+		{"mixed", []Dist{2, 1}, shapeGeneral},
+		{"zero and two", []Dist{2, 0}, shapeGeneral},
+		{"closed edge", []Dist{1, INF}, shapeGeneral},
+	}
+	for _, tt := range tests {
+		g := NewGraph()
+		for i, w := range tt.weights {
+			g.AddEdge(NodeID(i), NodeID(i+1), w)
+		}
+		if g.shape != tt.want {
+			t.Errorf("%s: shape %d, want %d", tt.name, g.shape, tt.want)
+		}
+	}
+}
+
+func TestBFS_ZeroOne(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	g := NewGraph()
+	for range 3000 {
+		g.AddEdge(NodeID(rng.Intn(500)), NodeID(rng.Intn(500)), Dist(rng.Intn(2)))
+	}
+	if g.shape != shapeZeroOne {
+		t.Fatalf("expected a 0-1 graph, got shape %d", g.shape)
+	}
+
+	res := Solve(g, sources(0, 7), INF)
+	ref := SolveDijkstra(g, sources(0, 7))
+	for v, want := range ref.Dist {
+		if res.Dist[v] != want {
+			t.Fatalf("node %d: expected %v, got %v", v, want, res.Dist[v])
+		}
+	}
+	if res.Stats.Delta != 0 || res.Stats.RecursionDepth != 0 {
+		t.Errorf("expected the BFS path, got stats %+v", res.Stats)
+	}
+	for v, d := range res.Dist {
+		if p, ok := res.Pred[v]; ok && d != res.Dist[p] && d != res.Dist[p]+1 {
+			t.Errorf("node %d at %v has predecessor %d at %v", v, d, p, res.Dist[p])
+		}
+	}
+}
+
+func TestBFS_Uniform(t *testing.T) {
+	g := generateGridGraph(15, 15)
+	for u, edges := range g.adj {
+		for i := range edges {
+			g.adj[u][i].Weight = 2.5
+		}
+	}
+	g.shape, g.uniform = g.measureShape()
+
+	got := BMSSPSingleSource(g, 0, 20)
+	want := BMSSPSingleSource(g, 0, 20, WithoutFastPaths())
+	for v, d := range want {
+		if d <= 20 && got[v] != d {
+			t.Errorf("node %d: expected %v, got %v", v, d, got[v])
+		}
+		if d > 20 && got[v] <= 20 {
+			t.Errorf("node %d beyond the bound reported at %v", v, got[v])
+		}
+	}
+}
+
+func TestWithUnitWeights(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 10)
+	g.AddEdge(1, 2, 10)
+	g.AddEdge(0, 3, 1)
+	g.AddEdge(3, 4, 1)
+	g.AddEdge(4, 2, 1)
+
+	res := Solve(g, sources(0), INF, WithUnitWeights())
+	if res.Dist[2] != 2 || res.Dist[4] != 2 {
+		t.Errorf("expected hop counts, got %v", res.Dist)
+	}
+	if path := res.PathTo(2); len(path) != 3 || path[1] != 1 {
+		t.Errorf("expected the two-hop path through 1, got %v", path)
+	}
+	if d := Solve(g, sources(0), INF).Dist[2]; d != 3 {
+		t.Errorf("without unit weights expected 3, got %v", d)
+	}
+}
+
+func TestFreeze_MeasuresShape(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 2, 5)
+	g.UpdateEdgeWeight(1, 2, 1)
+	if g.shape != shapeGeneral {
+		t.Fatalf("updates should not narrow the shape, got %d", g.shape)
+	}
+	g.Freeze()
+	if g.shape != shapeUniform || g.uniform != 1 {
+		t.Errorf("expected Freeze to find unit weights, got shape %d weight %v", g.shape, g.uniform)
+	}
+}
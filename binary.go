@@ -0,0 +1,169 @@
+package bmssp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// ErrCorruptGraph is returned by ReadGraph for input that is not a graph in
+// the binary format, or is truncated.
+var ErrCorruptGraph = errors.New("bmssp: corrupt binary graph")
+
+// Binary format: the magic "BMSG", a format version byte, the node count,
+// then for each node in ascending ID order the gap to the previous node ID,
+// the out-degree, and each edge as the zigzag varint offset of its head from
+// the tail followed by its float64 weight in little-endian byte order. All
+// counts and gaps are unsigned varints. Edge data is not stored.
+const (
+	binaryMagic   = "BMSG"
+	binaryVersion = 1
+
+	// maxPrealloc caps allocations sized from untrusted counts
+	maxPrealloc = 1 << 16
+)
+
+// countingWriter tracks the bytes written for io.WriterTo.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (c *countingWriter) write(p []byte) {
+	n, _ := c.w.Write(p) // errors are sticky and reported by Flush
+	c.n += int64(n)
+}
+
+func (c *countingWriter) uvarint(x uint64) { c.write(binary.AppendUvarint(c.buf[:0], x)) }
+func (c *countingWriter) varint(x int64)   { c.write(binary.AppendVarint(c.buf[:0], x)) }
+
+// WriteTo writes g to w in the compact binary format read by ReadGraph.
+// Node IDs are delta- and varint-encoded, so graphs with dense, local IDs
+// such as road networks take little more than their weights.
+//
+// Returns:
+//   - the number of bytes written
+//   - the first write error
+func (g *Graph) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	cw.write([]byte(binaryMagic))
+	cw.write([]byte{binaryVersion})
+
+	nodes := make([]NodeID, 0, len(g.adj))
+	for u := range g.adj {
+		nodes = append(nodes, u)
+	}
+	slices.Sort(nodes)
+
+	cw.uvarint(uint64(len(nodes)))
+	prev := int64(0)
+	for i, u := range nodes {
+		if i == 0 {
+			cw.varint(int64(u))
+		} else {
+			cw.uvarint(uint64(int64(u) - prev))
+		}
+		prev = int64(u)
+
+		edges := g.adj[u]
+		cw.uvarint(uint64(len(edges)))
+		for _, e := range edges {
+			cw.varint(int64(e.To) - int64(u))
+			cw.write(binary.LittleEndian.AppendUint64(cw.buf[:0], math.Float64bits(float64(e.Weight))))
+		}
+	}
+	return cw.n, cw.w.Flush()
+}
+
+// ReadGraph reads a graph written by Graph.WriteTo.
+//
+// Returns:
+//   - the graph
+//   - ErrCorruptGraph for malformed or truncated input or an unsupported
+//     format version, or the reader's error
+func ReadGraph(r io.Reader) (*Graph, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		b := bufio.NewReader(r)
+		br, r = b, b
+	}
+	corrupt := func(err error) error {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("%w: truncated", ErrCorruptGraph)
+		}
+		if errors.Is(err, ErrCorruptGraph) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrCorruptGraph, err)
+	}
+
+	var header [len(binaryMagic) + 1]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, corrupt(err)
+	}
+	if string(header[:len(binaryMagic)]) != binaryMagic {
+		return nil, fmt.Errorf("%w: bad magic %q", ErrCorruptGraph, header[:len(binaryMagic)])
+	}
+	if v := header[len(binaryMagic)]; v != binaryVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrCorruptGraph, v)
+	}
+
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, corrupt(err)
+	}
+	g := &Graph{adj: make(map[NodeID][]Edge, min(n, maxPrealloc))}
+
+	var u int64
+	var weight [8]byte
+	for i := uint64(0); i < n; i++ {
+		if i == 0 {
+			u, err = binary.ReadVarint(br)
+		} else {
+			var gap uint64
+			gap, err = binary.ReadUvarint(br)
+			u += int64(gap)
+		}
+		if err != nil {
+			return nil, corrupt(err)
+		}
+		degree, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, corrupt(err)
+		}
+
+		edges := make([]Edge, 0, min(degree, maxPrealloc))
+		for range degree {
+			offset, err := binary.ReadVarint(br)
+			if err != nil {
+				return nil, corrupt(err)
+			}
+			if _, err := io.ReadFull(r, weight[:]); err != nil {
+				return nil, corrupt(err)
+			}
+			w := Dist(math.Float64frombits(binary.LittleEndian.Uint64(weight[:])))
+			edges = append(edges, Edge{To: NodeID(u + offset), Weight: w})
+			g.noteWeight(w)
+		}
+		if len(edges) == 0 {
+			edges = nil
+		}
+		g.adj[NodeID(u)] = edges
+		g.numEdges += len(edges)
+	}
+
+	// Every edge head must be a node of the graph
+	for _, edges := range g.adj {
+		for _, e := range edges {
+			if _, ok := g.adj[e.To]; !ok {
+				return nil, fmt.Errorf("%w: edge to unknown node %d", ErrCorruptGraph, e.To)
+			}
+		}
+	}
+	return g, nil
+}
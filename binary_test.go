@@ -0,0 +1,61 @@
+package bmssp
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestGraph_WriteToReadGraph(t *testing.T) {
+	g := generateRandomGraph(300, 2000, 50, 11)
+	g.AddEdge(-5, 7, 0.25)
+	g.AddEdge(1000, 1000, 3)
+
+	var buf bytes.Buffer
+	n, err := g.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("expected %d bytes reported, got %d", buf.Len(), n)
+	}
+
+	h, err := ReadGraph(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.adj) != len(g.adj) || h.numEdges != g.numEdges || h.maxWeight != g.maxWeight {
+		t.Fatalf("expected %d nodes and %d edges, got %d and %d", len(g.adj), g.numEdges, len(h.adj), h.numEdges)
+	}
+	for u, edges := range g.adj {
+		got := h.adj[u]
+		if len(got) != len(edges) {
+			t.Fatalf("node %d: expected %d edges, got %d", u, len(edges), len(got))
+		}
+		for i := range edges {
+			if got[i] != edges[i] {
+				t.Errorf("node %d: edge %d: expected %+v, got %+v", u, i, edges[i], got[i])
+			}
+		}
+	}
+}
+
+func TestReadGraph_Corrupt(t *testing.T) {
+	g := generateGridGraph(5, 5)
+	var buf bytes.Buffer
+	if _, err := g.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	for name, input := range map[string][]byte{
+		"empty":     nil,
+		"magic":     append([]byte("XXXX"), data[4:]...),
+		"version":   append([]byte("BMSG\x09"), data[5:]...),
+		"truncated": data[:len(data)-3],
+	} {
+		if _, err := ReadGraph(bytes.NewReader(input)); !errors.Is(err, ErrCorruptGraph) {
+			t.Errorf("%s: expected ErrCorruptGraph, got %v", name, err)
+		}
+	}
+}
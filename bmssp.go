@@ -9,8 +9,13 @@
 package bmssp
 
 import (
+	"context"
+	"errors"
+	"maps"
 	"math"
+	"slices"
 	"sort"
+	"time"
 )
 
 // NodeID represents a unique identifier for a graph node.
@@ -25,8 +30,32 @@ type Dist float64
 var INF = Dist(math.Inf(1)) //nolint:gochecknoglobals
 
 // Graph represents a directed weighted graph using adjacency lists.
+//
+// Queries only read the graph, so any number of them may run concurrently as
+// long as nothing mutates it meanwhile. Freeze makes that guarantee explicit:
+// a frozen graph rejects mutations and has no lazily built state left.
 type Graph struct {
-	adj map[NodeID][]Edge
+	adj       map[NodeID][]Edge
+	numEdges  int  // number of edges, parallel edges counted separately
+	maxWeight Dist // upper bound on the finite edge weights, for sizing queues
+	shape     weightShape
+	uniform   Dist // the weight of every edge when shape is shapeUniform
+
+	// radj is the reverse index: the edges into each node, with Edge.To
+	// holding the tail. It is nil until first needed and then kept in sync.
+	radj map[NodeID][]Edge
+
+	data map[EdgeID]any // user data attached to edges; nil until first set
+
+	version uint64   // incremented by every change to nodes, edges or weights
+	frozen  bool     // set by Freeze; mutations panic
+	topo    []NodeID // topological order, set by Freeze if g is acyclic
+
+	// Copy-on-write state of Snapshot: shared marks node maps shared with a
+	// snapshot; owned and ownedIn, once set, record the edge lists of adj
+	// and radj copied since, which g may write in place.
+	shared         bool
+	owned, ownedIn map[NodeID]bool
 }
 
 // Edge represents a directed edge in the graph.
@@ -35,14 +64,235 @@ type Edge struct {
 	Weight Dist   // edge weight
 }
 
+// EdgeID identifies the directed edge(s) between two nodes.
+type EdgeID struct {
+	From NodeID
+	To   NodeID
+}
+
 // NewGraph creates and returns a new empty graph.
 func NewGraph() *Graph {
 	return &Graph{adj: make(map[NodeID][]Edge)}
 }
 
 // AddEdge adds a directed edge from 'from' to 'to' with the given weight.
+// Both endpoints become nodes of the graph.
 func (g *Graph) AddEdge(from, to NodeID, weight Dist) {
-	g.adj[from] = append(g.adj[from], Edge{To: to, Weight: weight})
+	g.mustBeMutable()
+	g.adj[from] = append(g.ownOut(from), Edge{To: to, Weight: weight})
+	g.numEdges++
+	g.version++
+	g.noteWeight(weight)
+	if _, ok := g.adj[to]; !ok {
+		g.adj[to] = nil
+	}
+	if g.radj != nil {
+		g.radj[to] = append(g.ownIn(to), Edge{To: from, Weight: weight})
+	}
+}
+
+// AddNode adds v to the graph without any edges. It does nothing if v is
+// already a node.
+func (g *Graph) AddNode(v NodeID) {
+	g.mustBeMutable()
+	if _, ok := g.adj[v]; ok {
+		return
+	}
+	g.unshare()
+	g.adj[v] = nil
+	g.version++
+}
+
+// removeEdgesTo removes the edges to v from edges in place and returns the
+// remaining edges and the number removed.
+func removeEdgesTo(edges []Edge, v NodeID) ([]Edge, int) {
+	kept := edges[:0]
+	for _, e := range edges {
+		if e.To != v {
+			kept = append(kept, e)
+		}
+	}
+	// Clear the tail so removed edges don't linger in the backing array
+	clear(edges[len(kept):])
+	return kept, len(edges) - len(kept)
+}
+
+// RemoveEdge removes every directed edge from 'from' to 'to'.
+// Both endpoints remain in the graph. It reports whether any edge was removed.
+func (g *Graph) RemoveEdge(from, to NodeID) bool {
+	g.mustBeMutable()
+	if !slices.ContainsFunc(g.adj[from], func(e Edge) bool { return e.To == to }) {
+		return false
+	}
+	kept, removed := removeEdgesTo(g.ownOut(from), to)
+	g.adj[from] = kept
+	g.numEdges -= removed
+	g.version++
+	delete(g.data, EdgeID{From: from, To: to})
+	if g.radj != nil {
+		g.radj[to], _ = removeEdgesTo(g.ownIn(to), from)
+	}
+	return true
+}
+
+// RemoveNode removes node v together with all of its incoming and outgoing edges.
+// It reports whether v was present in the graph.
+func (g *Graph) RemoveNode(v NodeID) bool {
+	g.mustBeMutable()
+	if _, ok := g.adj[v]; !ok {
+		return false
+	}
+	g.unshare()
+	out := g.adj[v]
+	g.numEdges -= len(out)
+	g.version++
+	delete(g.adj, v)
+	for _, e := range out {
+		delete(g.data, EdgeID{From: v, To: e.To})
+	}
+
+	if g.radj == nil {
+		for u := range g.adj {
+			g.RemoveEdge(u, v)
+		}
+		return true
+	}
+
+	// With the reverse index only the neighbors of v need updating
+	for _, e := range out {
+		g.radj[e.To], _ = removeEdgesTo(g.ownIn(e.To), v)
+	}
+	for _, in := range slices.Clone(g.radj[v]) {
+		g.RemoveEdge(in.To, v)
+	}
+	delete(g.radj, v)
+	return true
+}
+
+// UpdateEdgeWeight sets the weight of every directed edge from 'from' to 'to'.
+// It reports whether such an edge exists.
+func (g *Graph) UpdateEdgeWeight(from, to NodeID, weight Dist) bool {
+	g.mustBeMutable()
+	if !slices.ContainsFunc(g.adj[from], func(e Edge) bool { return e.To == to }) {
+		return false
+	}
+	out := g.ownOut(from)
+	for i := range out {
+		if out[i].To == to {
+			out[i].Weight = weight
+		}
+	}
+	g.version++
+	g.noteWeight(weight)
+	if g.radj != nil {
+		in := g.ownIn(to)
+		for i := range in {
+			if in[i].To == from {
+				in[i].Weight = weight
+			}
+		}
+	}
+	return true
+}
+
+// autoDelta picks the Δ-stepping bucket width with the standard heuristic
+// Δ ≈ maxWeight/avgDegree: wide enough that a bucket holds a useful amount of
+// parallel work, narrow enough that few nodes are re-expanded. It falls back
+// to 1 for graphs without weighted edges.
+func (g *Graph) autoDelta() Dist {
+	if g.numEdges == 0 || !(g.maxWeight > 0) {
+		return 1
+	}
+	avgDegree := Dist(g.numEdges) / Dist(len(g.adj))
+	return g.maxWeight / avgDegree
+}
+
+// noteWeight raises the recorded maximum edge weight if w exceeds it and
+// updates the weight shape. Removals never lower the maximum or narrow the
+// shape, so both stay conservative.
+func (g *Graph) noteWeight(w Dist) {
+	if w > g.maxWeight && w < INF {
+		g.maxWeight = w
+	}
+	g.shape, g.uniform = g.shape.note(g.uniform, w)
+}
+
+// Clone returns a deep copy of g.
+func (g *Graph) Clone() *Graph {
+	c := &Graph{
+		adj:       make(map[NodeID][]Edge, len(g.adj)),
+		numEdges:  g.numEdges,
+		maxWeight: g.maxWeight,
+		shape:     g.shape,
+		uniform:   g.uniform,
+	}
+	for u, edges := range g.adj {
+		c.adj[u] = slices.Clone(edges)
+	}
+	if g.data != nil {
+		c.data = maps.Clone(g.data)
+	}
+	return c
+}
+
+// Reverse returns a new graph with every edge of g reversed.
+func (g *Graph) Reverse() *Graph {
+	r := &Graph{
+		adj:       make(map[NodeID][]Edge, len(g.adj)),
+		numEdges:  g.numEdges,
+		maxWeight: g.maxWeight,
+		shape:     g.shape,
+		uniform:   g.uniform,
+	}
+	for u, edges := range g.adj {
+		if _, ok := r.adj[u]; !ok {
+			r.adj[u] = nil
+		}
+		for _, e := range edges {
+			r.adj[e.To] = append(r.adj[e.To], Edge{To: u, Weight: e.Weight})
+		}
+	}
+	return r
+}
+
+// Subgraph returns a new graph induced by nodes: the nodes of g that are in
+// the set and the edges of g between them.
+func (g *Graph) Subgraph(nodes NodeSet) *Graph {
+	sub := NewGraph()
+	for u := range nodes {
+		edges, ok := g.adj[u]
+		if !ok {
+			continue
+		}
+		sub.adj[u] = nil
+		for _, e := range edges {
+			if nodes.Has(e.To) {
+				sub.AddEdge(u, e.To, e.Weight)
+			}
+		}
+	}
+	return sub
+}
+
+// InEdges returns all incoming edges of node v; Edge.To holds the tail of
+// each edge. The first call builds a reverse index, which later mutations
+// keep up to date. Call InEdges once before sharing the graph between
+// goroutines, as building the index writes to the graph.
+func (g *Graph) InEdges(v NodeID) []Edge {
+	if g.radj == nil {
+		g.buildReverseIndex()
+	}
+	return g.radj[v]
+}
+
+// buildReverseIndex builds radj from adj.
+func (g *Graph) buildReverseIndex() {
+	g.radj = make(map[NodeID][]Edge, len(g.adj))
+	for u, edges := range g.adj {
+		for _, e := range edges {
+			g.radj[e.To] = append(g.radj[e.To], Edge{To: u, Weight: e.Weight})
+		}
+	}
 }
 
 // OutEdges returns all outgoing edges from node u.
@@ -50,6 +300,28 @@ func (g *Graph) OutEdges(u NodeID) []Edge {
 	return g.adj[u]
 }
 
+// NumNodes returns the number of nodes of g.
+func (g *Graph) NumNodes() int {
+	return len(g.adj)
+}
+
+// HasNode reports whether v is a node of g.
+func (g *Graph) HasNode(v NodeID) bool {
+	_, ok := g.adj[v]
+	return ok
+}
+
+// NumEdges returns the number of edges of g, counting parallel edges
+// separately.
+func (g *Graph) NumEdges() int {
+	return g.numEdges
+}
+
+// OutDegree returns the number of edges leaving v, 0 for an unknown node.
+func (g *Graph) OutDegree(v NodeID) int {
+	return len(g.adj[v])
+}
+
 // NodeSet represents a set of graph nodes.
 // Implemented as a map for O(1) membership testing.
 type NodeSet map[NodeID]struct{}
@@ -112,109 +384,761 @@ func medianOfThreePivot(S NodeSet, dhat map[NodeID]Dist) NodeID {
 	return candidates[1] // median of the three
 }
 
+// maxBucketIndex caps the bucket index so that huge distances (or a tiny Δ)
+// cannot make the queue allocate an unbounded number of buckets. Distances
+// beyond the cap share the last bucket, which only costs ordering precision:
+// the Δ-stepping passes are label-correcting and stay exact.
+const maxBucketIndex = 1 << 20
+
+// defaultRingBuckets is the initial ring size when the maximum edge weight
+// is unknown.
+const defaultRingBuckets = 64
+
+// maxRingBuckets caps the ring size. Nodes whose bucket lies further ahead of
+// the current one, e.g. because of a tiny Δ or a few enormous weights, wait
+// in an overflow list and enter the ring once it reaches them, so memory
+// stays proportional to the number of queued nodes rather than distance/Δ.
+const maxRingBuckets = 1 << 16
+
+// overflowBucket marks the position of a node held in the overflow list.
+const overflowBucket = -1
+
 // bucketQueue implements Δ-stepping bucket queue for efficient shortest path computation.
 // This is a key optimization that makes BMSSP faster than standard Dijkstra.
+//
+// Buckets live in a circular array: bucket i is stored in slot i mod
+// len(ring). With non-negative weights of at most W, all queued distances lie
+// within W of the current minimum, so ceil(W/Δ)+1 slots suffice; the ring
+// grows if a key lands further ahead, up to maxRingBuckets. Every entry
+// records its position, so removal swaps it with the last entry of its bucket
+// in O(1), and emptied bucket slices keep their capacity for reuse when the
+// ring wraps around.
+//
+// The queue is monotone: a node is never placed behind the bucket currently
+// being extracted, so extraction order is non-decreasing up to one bucket
+// width Δ, even in the presence of floating point rounding.
 type bucketQueue struct {
-	buckets [][]NodeID      // buckets organized by distance ranges
-	delta   Dist            // bucket width parameter
-	minIdx  int             // index of minimum non-empty bucket
-	pos     map[NodeID]int  // position tracking for decrease-key operations
+	ring   [][]NodeID            // circular array of buckets, length a power of two
+	delta  Dist                  // bucket width parameter
+	minIdx int                   // absolute index of the current bucket
+	maxIdx int                   // highest absolute bucket index used
+	n      int                   // number of queued nodes, including overflow
+	pos    map[NodeID]bucketSlot // position of every queued node
+
+	overflow    []overflowEntry // nodes beyond the ring's reach
+	overflowMin int             // lower bound on the buckets in overflow
+}
+
+// bucketSlot locates a queued node: absolute bucket index and offset within
+// the bucket, or overflowBucket and the offset in the overflow list.
+type bucketSlot struct {
+	bucket int
+	offset int
 }
 
-// newBucketQueue creates a new Δ-stepping bucket queue.
-func newBucketQueue(delta Dist) *bucketQueue {
+// overflowEntry is a node waiting for the ring to reach its bucket.
+type overflowEntry struct {
+	node   NodeID
+	bucket int
+}
+
+// newBucketQueue creates a new Δ-stepping bucket queue sized for edge weights
+// up to maxWeight (0 if unknown). Non-positive, non-finite or subnormal widths
+// fall back to 1.
+func newBucketQueue(delta, maxWeight Dist) *bucketQueue {
+	const minNormal = 0x1p-1022 // smallest normal float64
+	if !(delta >= minNormal) || math.IsInf(float64(delta), 1) {
+		delta = 1
+	}
+	size := defaultRingBuckets
+	if span := float64(maxWeight / delta); span >= 1 && span < maxRingBuckets-1 {
+		size = int(span) + 2
+	}
 	return &bucketQueue{
-		buckets: make([][]NodeID, 0),
-		delta:   delta,
-		minIdx:  0,
-		pos:     make(map[NodeID]int),
+		ring:  make([][]NodeID, ringSize(size)),
+		delta: delta,
+		pos:   make(map[NodeID]bucketSlot),
+	}
+}
+
+// ringSize rounds n up to a power of two.
+func ringSize(n int) int {
+	size := 1
+	for size < n {
+		size <<= 1
+	}
+	return size
+}
+
+// bucketIndex maps a distance to its bucket. Distances that round to a bucket
+// already passed (e.g. 0.1*3 landing just below the boundary at 0.3), NaN and
+// negative values are clamped to the current bucket; huge values are clamped
+// to maxBucketIndex.
+func (q *bucketQueue) bucketIndex(dist Dist) int {
+	f := math.Floor(float64(dist / q.delta))
+	switch {
+	case math.IsNaN(f) || f < float64(q.minIdx):
+		return q.minIdx
+	case f > maxBucketIndex:
+		return maxBucketIndex
+	}
+	return int(f)
+}
+
+// slot returns the ring position of absolute bucket idx.
+func (q *bucketQueue) slot(idx int) int {
+	return idx & (len(q.ring) - 1)
+}
+
+// grow enlarges the ring so that bucket idx fits ahead of minIdx.
+func (q *bucketQueue) grow(idx int) {
+	ring := make([][]NodeID, ringSize(idx-q.minIdx+1))
+	for i := q.minIdx; i < q.minIdx+len(q.ring); i++ {
+		ring[i&(len(ring)-1)] = q.ring[q.slot(i)]
 	}
+	q.ring = ring
 }
 
 // insert adds a node to the appropriate bucket based on its distance.
 func (q *bucketQueue) insert(v NodeID, dist Dist) {
-	idx := int(dist / q.delta)
-	
-	// Expand buckets if necessary
-	for idx >= len(q.buckets) {
-		q.buckets = append(q.buckets, nil)
+	idx := q.bucketIndex(dist)
+	q.maxIdx = max(q.maxIdx, idx)
+	q.n++
+	q.place(v, idx)
+}
+
+// place stores v in bucket idx, or in the overflow list if the bucket is out
+// of the ring's reach.
+func (q *bucketQueue) place(v NodeID, idx int) {
+	if idx-q.minIdx >= len(q.ring) {
+		if idx-q.minIdx >= maxRingBuckets {
+			if len(q.overflow) == 0 || idx < q.overflowMin {
+				q.overflowMin = idx
+			}
+			q.pos[v] = bucketSlot{bucket: overflowBucket, offset: len(q.overflow)}
+			q.overflow = append(q.overflow, overflowEntry{node: v, bucket: idx})
+			return
+		}
+		q.grow(idx)
 	}
-	
-	q.buckets[idx] = append(q.buckets[idx], v)
-	q.pos[v] = idx
+
+	i := q.slot(idx)
+	q.pos[v] = bucketSlot{bucket: idx, offset: len(q.ring[i])}
+	q.ring[i] = append(q.ring[i], v)
 }
 
-// extractMin removes and returns the node with minimum distance.
-func (q *bucketQueue) extractMin() (NodeID, bool) {
-	// Find next non-empty bucket
-	for q.minIdx < len(q.buckets) && len(q.buckets[q.minIdx]) == 0 {
-		q.minIdx++
+// refill moves the overflow nodes that the ring now reaches into their
+// buckets and recomputes overflowMin.
+func (q *bucketQueue) refill() {
+	waiting := q.overflow
+	q.overflow = nil
+	for _, e := range waiting {
+		q.place(e.node, e.bucket)
 	}
-	
-	if q.minIdx >= len(q.buckets) {
+}
+
+// remove deletes v from its bucket by moving the bucket's last entry into
+// its place.
+func (q *bucketQueue) remove(v NodeID, at bucketSlot) {
+	q.n--
+	delete(q.pos, v)
+	if at.bucket == overflowBucket {
+		last := q.overflow[len(q.overflow)-1]
+		q.overflow[at.offset] = last
+		q.overflow = q.overflow[:len(q.overflow)-1]
+		if last.node != v {
+			q.pos[last.node] = at
+		}
+		return
+	}
+
+	i := q.slot(at.bucket)
+	bucket := q.ring[i]
+	last := bucket[len(bucket)-1]
+	bucket[at.offset] = last
+	if last != v {
+		q.pos[last] = at
+	}
+	q.ring[i] = bucket[:len(bucket)-1]
+}
+
+// extractMin removes and returns a node of the minimum non-empty bucket.
+func (q *bucketQueue) extractMin() (NodeID, bool) {
+	if q.n == 0 {
 		return 0, false
 	}
-	
-	// Extract node from bucket
-	v := q.buckets[q.minIdx][0]
-	q.buckets[q.minIdx] = q.buckets[q.minIdx][1:]
-	delete(q.pos, v)
-	
+	q.advance()
+
+	bucket := q.ring[q.slot(q.minIdx)]
+	v := bucket[len(bucket)-1]
+	q.remove(v, q.pos[v])
 	return v, true
 }
 
-// decreaseKey updates a node's distance and moves it to the appropriate bucket.
+// extractBucket removes every node of the minimum non-empty bucket and
+// appends them to dst.
+func (q *bucketQueue) extractBucket(dst []NodeID) []NodeID {
+	if q.n == 0 {
+		return dst
+	}
+	q.advance()
+
+	i := q.slot(q.minIdx)
+	for _, v := range q.ring[i] {
+		delete(q.pos, v)
+	}
+	dst = append(dst, q.ring[i]...)
+	q.n -= len(q.ring[i])
+	q.ring[i] = q.ring[i][:0]
+	return dst
+}
+
+// advance moves minIdx to the next non-empty bucket, pulling in overflow
+// nodes as the ring reaches them and jumping ahead when only overflow nodes
+// are left. The queue must not be empty.
+func (q *bucketQueue) advance() {
+	for {
+		if len(q.overflow) > 0 && q.minIdx >= q.overflowMin {
+			q.refill()
+		}
+		if len(q.ring[q.slot(q.minIdx)]) > 0 {
+			return
+		}
+		if q.n == len(q.overflow) {
+			q.minIdx = q.overflowMin
+			continue
+		}
+		q.minIdx++
+	}
+}
+
+// decreaseKey updates a node's distance and moves it to the appropriate
+// bucket, inserting it if it is not queued.
 func (q *bucketQueue) decreaseKey(v NodeID, newDist Dist) {
-	// Remove from old bucket if exists
-	if oldIdx, ok := q.pos[v]; ok {
-		bucket := q.buckets[oldIdx]
-		for i := range bucket {
-			if bucket[i] == v {
-				q.buckets[oldIdx] = append(bucket[:i], bucket[i+1:]...)
-				break
-			}
+	if at, ok := q.pos[v]; ok {
+		if at.bucket == q.bucketIndex(newDist) {
+			return
 		}
+		q.remove(v, at)
 	}
-	
 	q.insert(v, newDist)
 }
 
-// dijkstraDeltaStepping implements the Δ-stepping algorithm for bounded shortest paths.
+// solver holds the state shared by the recursive BMSSP calls of a single query.
+type solver struct {
+	g    *Graph
+	src  GraphReader // external graph searched instead of g; nil for g
+	nb   Neighbors   // implicit graph searched instead of g; nil for g
+	buf  []Edge      // edges of the last node read from src
+	dhat map[NodeID]Dist
+	pred map[NodeID]NodeID // shortest-path predecessors; nil when not tracked
+
+	// children inverts pred for DynamicSSSP, which needs the subtree below
+	// a node; nil when not maintained.
+	children map[NodeID]NodeSet
+	cfg      config
+
+	scanned [][]candidate // per-worker results of parallel relaxEdges
+
+	// parent is the solver that forked this one as a branch of its
+	// recursion; its distances back those missing from dhat. nil outside a
+	// fork.
+	parent *solver
+
+	stats Stats // work counters for the query
+	depth int   // current recursion depth of run
+	delta Dist  // Δ-stepping bucket width; 0 until chosen by bucketWidth
+
+	stopped   StopReason     // why the search ended early; StopComplete while running
+	hops      map[NodeID]int // edges on the tentative path; nil without a hop limit
+	truncated bool           // an edge was skipped because of the hop limit
+	limit     Dist           // bound of the whole query
+	cut       bool           // a node was reached only beyond limit
+
+	emit func(v NodeID, d Dist) bool // receives final distances from dijkstra; false stops
+
+	bound Dist          // bound of the running search pass
+	trace *explainTrace // per-node history for Explain; nil unless WithExplain
+	start time.Time     // when the query started, for latency metrics
+	span  Span          // innermost open tracing span; nil without a tracer
+	total int           // nodes in the graph, for progress reports
+}
+
+// weight returns the weight of edge e leaving u as seen by this query.
+func (s *solver) weight(u NodeID, e Edge) Dist {
+	if !s.allowed(u, e) {
+		return INF
+	}
+	if s.cfg.unitWeights {
+		return 1
+	}
+	w := e.Weight
+	if s.cfg.overlay != nil {
+		w = s.cfg.overlay.Weight(u, e)
+	}
+	if s.cfg.weightFunc != nil && w < INF {
+		w = s.cfg.weightFunc(u, Edge{To: e.To, Weight: w})
+	}
+	return w
+}
+
+// outEdges returns the edges leaving u as seen by this query.
+func (s *solver) outEdges(u NodeID) []Edge {
+	var edges []Edge
+	if s.src != nil {
+		s.buf = slices.AppendSeq(s.buf[:0], s.src.OutEdges(u))
+		edges = s.buf
+	} else if s.nb != nil {
+		edges = s.nb.Neighbors(u)
+	} else {
+		edges = s.g.adj[u]
+	}
+	if s.cfg.extra != nil {
+		if more := s.cfg.extra.ExtraEdges(u); len(more) > 0 {
+			// Never append into the graph's backing array
+			edges = append(edges[:len(edges):len(edges)], more...)
+		}
+	}
+	return edges
+}
+
+// dist returns the tentative distance of v; nodes missing from the distance
+// map (e.g. added by an overlay) have not been reached yet. A branch of a
+// fork looks up the nodes it has not improved in its parent.
+func (s *solver) dist(v NodeID) Dist {
+	if d, ok := s.dhat[v]; ok {
+		return d
+	}
+	if s.parent != nil {
+		return s.parent.dist(v)
+	}
+	return INF
+}
+
+// targetsBelow reports whether every configured target has a distance below
+// limit, which is final once no queued node is closer than limit.
+func (s *solver) targetsBelow(limit Dist) bool {
+	if s.cfg.targets == nil {
+		return false
+	}
+	for t := range s.cfg.targets {
+		if !(s.dist(t) < limit) {
+			return false
+		}
+	}
+	return true
+}
+
+// deadlineCheckInterval is the number of settled nodes between clock reads.
+const deadlineCheckInterval = 256
+
+// shouldStop reports whether the node budget is used up, the deadline has
+// passed or the context is done, recording why the search stops.
+func (s *solver) shouldStop() bool {
+	if s.cfg.nodeBudget > 0 && s.stats.NodesSettled >= s.cfg.nodeBudget {
+		s.stopped = StopNodeBudget
+		return true
+	}
+	if s.stats.NodesSettled%deadlineCheckInterval != 0 {
+		return false
+	}
+	if !s.cfg.deadline.IsZero() && !time.Now().Before(s.cfg.deadline) {
+		s.stopped = StopDeadline
+		return true
+	}
+	if s.cfg.ctx != nil {
+		switch err := s.cfg.ctx.Err(); {
+		case errors.Is(err, context.DeadlineExceeded):
+			s.stopped = StopDeadline
+			return true
+		case err != nil:
+			s.stopped = StopCanceled
+			return true
+		}
+	}
+	return false
+}
+
+// canExtend reports whether paths through u may take another edge under the
+// hop limit.
+func (s *solver) canExtend(u NodeID) bool {
+	if s.cfg.maxHops == 0 || s.hops[u] < s.cfg.maxHops {
+		return true
+	}
+	if len(s.outEdges(u)) > 0 {
+		s.truncated = true
+	}
+	return false
+}
+
+// settle records that u's outgoing edges are about to be scanned.
+func (s *solver) settle(u NodeID) {
+	s.stats.NodesSettled++
+	if s.cfg.progress != nil && s.stats.NodesSettled%progressInterval == 0 {
+		s.cfg.progress(min(s.stats.NodesSettled, s.total), s.total)
+	}
+	if s.cfg.visitor != nil {
+		s.cfg.visitor.OnSettle(u, s.dhat[u])
+	}
+	if s.trace != nil {
+		s.trace.settle(u, s.depth, s.bound)
+	}
+}
+
+// relax records d as the tentative distance of v, reached through u.
+func (s *solver) relax(u, v NodeID, d Dist) {
+	s.stats.Relaxations++
+	if s.cfg.visitor != nil {
+		s.cfg.visitor.OnRelax(u, v, s.dist(v), d)
+	}
+	if s.trace != nil {
+		s.trace.node(v).relaxations++
+	}
+	s.dhat[v] = d
+	if s.children != nil {
+		reparent(s.pred, s.children, v, u)
+	} else if s.pred != nil {
+		s.pred[v] = u
+	}
+	if s.hops != nil {
+		s.hops[v] = s.hops[u] + 1
+	}
+}
+
+// deltaStepping implements the Δ-stepping algorithm for bounded shortest paths.
 // This is the core subroutine that makes BMSSP efficient.
-func dijkstraDeltaStepping(S NodeSet, B Dist, G *Graph, dhat map[NodeID]Dist, delta Dist) {
-	pq := newBucketQueue(delta)
-	
+//
+// Every node reached within B is settled. Nodes reached beyond B are left
+// unexpanded and returned as the frontier for a later pass.
+func (s *solver) deltaStepping(S NodeSet, B Dist, delta Dist) NodeSet {
+	pq := newBucketQueue(delta, s.maxWeight())
+	s.bound = B
+	if s.cfg.tracer != nil {
+		settled := s.stats.NodesSettled
+		end := s.beginSpan("bmssp.delta_stepping", Attr{"sources", len(S)}, Attr{"bound", float64(B)}, Attr{"delta", float64(delta)})
+		defer func() { end(Attr{"nodes_settled", s.stats.NodesSettled - settled}) }()
+	}
+
 	// Initialize queue with source nodes
 	for v := range S {
-		pq.insert(v, dhat[v])
+		pq.insert(v, s.dhat[v])
+		s.stats.QueueOps++
 	}
-	
-	visited := make(map[NodeID]bool)
-	
+
+	// Nodes are expanded every time they are extracted: a node improved after
+	// its expansion (possible within a bucket when weights are below Δ) is
+	// re-queued and expanded again, which keeps each pass exact.
+	expanded := NewNodeSet()
+	frontier := NewNodeSet()
+	checked := -1 // last bucket checked for settled targets
+
+	// Sequential queries take one node at a time; with WithParallelism the
+	// whole bucket is taken so its edges can be scanned together
+	var batch, scan []NodeID
 	for {
-		u, ok := pq.extractMin()
-		if !ok {
+		batch = batch[:0]
+		if s.parallel() {
+			batch = pq.extractBucket(batch)
+		} else if u, ok := pq.extractMin(); ok {
+			batch = append(batch, u)
+		}
+		if len(batch) == 0 {
 			break
 		}
-		
-		if visited[u] {
-			continue
+		s.stats.QueueOps += len(batch)
+
+		// Every queued node is at least minIdx*Δ away, so targets below that
+		// can no longer improve. Nodes beyond B are deferred unexpanded
+		// rather than queued, so targets beyond B may still improve.
+		if pq.minIdx != checked {
+			checked = pq.minIdx
+			if s.targetsBelow(min(Dist(pq.minIdx)*pq.delta, B)) {
+				s.stopped = StopTargets
+				return nil
+			}
 		}
-		
-		visited[u] = true
-		
-		// Stop if beyond bound
-		if dhat[u] > B {
-			continue
+
+		scan = scan[:0]
+		for _, u := range batch {
+			// Stop if beyond bound
+			if s.dhat[u] > B {
+				frontier.Add(u)
+				continue
+			}
+
+			if s.shouldStop() {
+				return nil
+			}
+			expanded.Add(u)
+			s.settle(u)
+			if s.canExtend(u) {
+				scan = append(scan, u)
+			}
+		}
+		s.relaxEdges(scan, pq)
+	}
+
+	s.stats.MaxBucket = max(s.stats.MaxBucket, pq.maxIdx)
+
+	// Nodes may have been pulled back within the bound after being deferred
+	for v := range frontier {
+		if expanded.Has(v) {
+			delete(frontier, v)
+		}
+	}
+	return frontier
+}
+
+// minParallelScan is the smallest number of nodes whose edges are scanned
+// in parallel; smaller batches do not pay for the goroutines.
+const minParallelScan = 64
+
+// parallel reports whether buckets are scanned by several goroutines. A
+// GraphReader reads edges into a shared buffer and is always scanned
+// sequentially.
+func (s *solver) parallel() bool {
+	return s.cfg.workers > 1 && s.src == nil
+}
+
+// candidate is an edge scanned by a parallel relaxEdges worker: from was at
+// distance du when the edge offered d to reach to.
+type candidate struct {
+	from, to NodeID
+	du, d    Dist
+}
+
+// relaxEdges relaxes the outgoing edges of the nodes in scan, queueing every
+// node whose distance improves. Large batches of a parallel query are
+// scanned by the workers into separate candidate lists, which are applied
+// in order afterwards, so the workers only read the solver's state.
+func (s *solver) relaxEdges(scan []NodeID, pq *bucketQueue) {
+	if !s.parallel() || len(scan) < minParallelScan {
+		for _, u := range scan {
+			for _, e := range s.outEdges(u) {
+				s.stats.EdgesScanned++
+				s.offer(u, e.To, s.dhat[u]+s.weight(u, e), pq)
+			}
+		}
+		return
+	}
+
+	workers := min(s.cfg.workers, len(scan))
+	if len(s.scanned) < workers {
+		s.scanned = make([][]candidate, workers)
+	}
+	parallelFor(workers, workers, func(w int) {
+		out := s.scanned[w][:0]
+		for _, u := range scan[w*len(scan)/workers : (w+1)*len(scan)/workers] {
+			du := s.dhat[u]
+			for _, e := range s.outEdges(u) {
+				out = append(out, candidate{from: u, to: e.To, du: du, d: du + s.weight(u, e)})
+			}
+		}
+		s.scanned[w] = out
+	})
+	for _, out := range s.scanned[:workers] {
+		s.stats.EdgesScanned += len(out)
+		for _, c := range out {
+			// A node improved by an earlier candidate is queued again and
+			// rescanned from its new distance
+			if s.dhat[c.from] == c.du {
+				s.offer(c.from, c.to, c.d, pq)
+			}
+		}
+	}
+}
+
+// offer relaxes the edge from u to v if reaching v at distance d improves it.
+func (s *solver) offer(u, v NodeID, d Dist, pq *bucketQueue) {
+	if s.improves(u, v, d) {
+		s.cut = s.cut || d > s.limit
+		s.relax(u, v, d)
+		pq.decreaseKey(v, d)
+		s.stats.QueueOps++
+	}
+}
+
+// bucketWidth returns the Δ of the query: the WithDelta override, or the
+// graph's automatic choice (1 for a GraphReader, whose weights are unknown).
+func (s *solver) bucketWidth() Dist {
+	if s.delta == 0 {
+		s.delta = s.cfg.delta
+		if !(s.delta > 0) && s.g != nil {
+			s.delta = s.g.autoDelta()
+		}
+		if !(s.delta > 0) {
+			s.delta = 1
+		}
+		s.stats.Delta = s.delta
+	}
+	return s.delta
+}
+
+// effectiveBound returns INF for bounds no path from S can reach: unless
+// weights are transformed, no shortest path is longer than maxWeight times
+// the number of nodes, plus the largest initial distance of a source.
+// Queries with bounds like 1e18 then run as plain unbounded searches instead
+// of partitioning around a bound that never binds.
+func (s *solver) effectiveBound(B Dist, S NodeSet) Dist {
+	if s.g == nil || s.cfg.overlay != nil || s.cfg.weightFunc != nil || s.cfg.unitWeights {
+		return B
+	}
+	var seed Dist
+	for v := range S {
+		seed = max(seed, s.dhat[v])
+	}
+	if B >= seed+s.g.maxWeight*Dist(len(s.g.adj)) {
+		return INF
+	}
+	return B
+}
+
+// run executes the recursive BMSSP procedure; see BMSSP.
+func (s *solver) run(B Dist, S NodeSet) {
+	if len(S) == 0 || s.stopped != StopComplete {
+		return
+	}
+	if s.depth == 0 && s.cfg.shadow != nil && s.cfg.shadow.sample() {
+		defer s.cfg.shadow.verify(s, seedsOf(s.dhat, S), B)
+	}
+	if s.depth == 0 {
+		defer s.report("bmssp")
+		B = s.effectiveBound(B, S)
+		s.limit = B
+		if s.trace != nil {
+			s.trace.bound = B
+		}
+		if s.cfg.tracer != nil {
+			end := s.beginSpan("bmssp.query", Attr{"sources", len(S)}, Attr{"bound", float64(B)})
+			defer func() {
+				end(Attr{"nodes_settled", s.stats.NodesSettled}, Attr{"stopped", s.stopReason().String()})
+			}()
+		}
+		if s.useBFS(S) {
+			s.bfs(S, B)
+			return
+		}
+		if order := s.dagOrder(S); order != nil {
+			s.dag(order, B)
+			return
+		}
+	}
+	delta := s.bucketWidth()
+	if s.cfg.tracer != nil {
+		settled := s.stats.NodesSettled
+		end := s.beginSpan("bmssp.level", Attr{"depth", s.depth + 1}, Attr{"sources", len(S)}, Attr{"bound", float64(B)})
+		defer func() { end(Attr{"nodes_settled", s.stats.NodesSettled - settled}) }()
+	}
+
+	s.depth++
+	defer func() { s.depth-- }()
+	s.stats.RecursionDepth = max(s.stats.RecursionDepth, s.depth)
+
+	// Base case: if only one source or small bound, just run Dijkstra
+	if len(S) == 1 || B <= delta {
+		s.deltaStepping(S, B, delta)
+		return
+	}
+
+	// Select pivot using median-of-three strategy
+	pivot := medianOfThreePivot(S, s.dhat)
+	bound := math.Min(float64(B), float64(s.dhat[pivot]))
+
+	// If bound is same as B, no point in partitioning
+	if approxEqual(Dist(bound), B, s.cfg.epsilon) {
+		s.deltaStepping(S, B, delta)
+		return
+	}
+
+	// Run bounded Dijkstra with Δ-stepping: everything up to the pivot's
+	// distance is settled, the rest of the frontier is handled recursively.
+	// The frontier lies strictly beyond the pivot, so every call settles at
+	// least the pivot and the recursion terminates.
+	right := s.deltaStepping(S, Dist(bound), delta)
+	for v := range right {
+		if s.dhat[v] > B {
+			delete(right, v)
+		}
+	}
+	if k := s.forkBranches(len(right)); k > 1 {
+		s.fork(B, right, k)
+		return
+	}
+	s.run(B, right)
+}
+
+// minBranchSources is the smallest share of the frontier a branch of a
+// parallel recursion starts from.
+const minBranchSources = 32
+
+// forkBranches returns into how many parallel branches the recursion on a
+// frontier of n nodes is split: 1 unless WithParallelRecursion is set and
+// the query needs no global settlement order.
+func (s *solver) forkBranches(n int) int {
+	if s.cfg.branches < 2 || s.src != nil || s.hops != nil || s.children != nil || s.trace != nil ||
+		s.cfg.tieBreak != TieBreakNone || s.cfg.targets != nil || s.cfg.nodeBudget > 0 ||
+		s.cfg.visitor != nil || s.cfg.progress != nil || s.cfg.tracer != nil {
+		return 1
+	}
+	return min(s.cfg.branches, n/minBranchSources)
+}
+
+// fork runs the recursion on the frontier S as k branches in parallel. The
+// frontier is split into k ranges of node IDs, which are often close
+// together in the graph, and each branch searches from its range with a
+// distance map of its own, a fragment over s.dhat that holds only the
+// distances it improves. Distances up to the last pivot are final, so the
+// branches are independent multi-source searches within B; s.dhat is only
+// read while they run, and their fragments are merged into it afterwards,
+// the smallest distance winning. Branches may explore overlapping regions,
+// so the fork pays off when the ranges lead to different parts of the
+// graph.
+func (s *solver) fork(B Dist, S NodeSet, k int) {
+	ids := S.ToSlice()
+	slices.Sort(ids)
+	branches := make([]*solver, k)
+	parallelFor(k, k, func(i int) {
+		b := &solver{g: s.g, nb: s.nb, dhat: make(map[NodeID]Dist), cfg: s.cfg, parent: s,
+			depth: s.depth, delta: s.delta, limit: s.limit}
+		// The branches share the workers and forks of the query
+		b.cfg.workers, b.cfg.branches = s.cfg.workers/k, s.cfg.branches/k
+		if s.pred != nil {
+			b.pred = make(map[NodeID]NodeID)
 		}
-		
-		// Relax outgoing edges
-		for _, e := range G.adj[u] {
-			if dhat[u]+e.Weight < dhat[e.To] {
-				dhat[e.To] = dhat[u] + e.Weight
-				pq.decreaseKey(e.To, dhat[e.To])
+		part := NewNodeSet()
+		for _, v := range ids[i*len(ids)/k : (i+1)*len(ids)/k] {
+			part.Add(v)
+			b.dhat[v] = s.dhat[v]
+		}
+		b.run(B, part)
+		branches[i] = b
+	})
+
+	for _, b := range branches {
+		for v, d := range b.dhat {
+			if d < s.dist(v) {
+				s.dhat[v] = d
+				if p, ok := b.pred[v]; ok {
+					s.pred[v] = p
+				}
 			}
 		}
+		s.stats.NodesSettled += b.stats.NodesSettled
+		s.stats.EdgesScanned += b.stats.EdgesScanned
+		s.stats.Relaxations += b.stats.Relaxations
+		s.stats.QueueOps += b.stats.QueueOps
+		s.stats.MaxBucket = max(s.stats.MaxBucket, b.stats.MaxBucket)
+		s.stats.RecursionDepth = max(s.stats.RecursionDepth, b.stats.RecursionDepth)
+		if s.stopped == StopComplete {
+			s.stopped = b.stopped
+		}
+	}
+	// A branch may reach a node only beyond the limit that another one
+	// reaches within it
+	for _, b := range branches {
+		for v := range b.dhat {
+			s.cut = s.cut || s.dist(v) > s.limit
+		}
 	}
 }
 
@@ -230,65 +1154,62 @@ func dijkstraDeltaStepping(S NodeSet, B Dist, G *Graph, dhat map[NodeID]Dist, de
 // The algorithm uses a divide-and-conquer approach with pivot-based partitioning
 // and Δ-stepping for efficient bounded shortest path computation.
 func BMSSP(B Dist, S NodeSet, G *Graph, dhat map[NodeID]Dist) {
-	if len(S) == 0 {
-		return
+	s := &solver{g: G, dhat: dhat}
+	s.run(B, S)
+}
+
+// newDistanceMap returns a distance map with every node of g set to infinity.
+func newDistanceMap(g *Graph) map[NodeID]Dist {
+	dhat := make(map[NodeID]Dist, len(g.adj))
+	for u := range g.adj {
+		dhat[u] = INF
 	}
-	
-	// Base case: if only one source or small bound, just run Dijkstra
-	if len(S) == 1 || B <= 1.0 {
-		dijkstraDeltaStepping(S, B, G, dhat, 1.0)
-		return
+	return dhat
+}
+
+// newSolver prepares a query over g with every node at distance INF.
+func newSolver(g *Graph, opts []Option) *solver {
+	s := &solver{g: g, dhat: newDistanceMap(g), cfg: newConfig(opts)}
+	s.prepare()
+	return s
+}
+
+// prepare allocates the per-query state required by the configuration.
+func (s *solver) prepare() {
+	if s.cfg.metrics != nil {
+		s.start = time.Now()
 	}
-	
-	// Select pivot using median-of-three strategy
-	pivot := medianOfThreePivot(S, dhat)
-	bound := math.Min(float64(B), float64(dhat[pivot]))
-	
-	// If bound is same as B, no point in partitioning
-	if math.Abs(bound-float64(B)) < 1e-9 {
-		dijkstraDeltaStepping(S, B, G, dhat, 1.0)
-		return
+	s.total = len(s.dhat)
+	if s.cfg.maxHops > 0 || s.cfg.tieBreak == TieBreakFewestHops {
+		s.hops = make(map[NodeID]int)
 	}
-	
-	// Run bounded Dijkstra with Δ-stepping
-	dijkstraDeltaStepping(S, Dist(bound), G, dhat, 1.0)
-	
-	// Partition nodes for recursive calls - only include nodes updated by dijkstra
-	left := NewNodeSet()
-	right := NewNodeSet()
-	
-	// Only partition nodes that are reachable and have finite distance
-	for v := range G.adj {
-		if dhat[v] < INF {
-			if dhat[v] <= Dist(bound) {
-				left.Add(v)
-			} else if dhat[v] < B {
-				right.Add(v)
-			}
-		}
+	if s.cfg.tieBreak == TieBreakLexicographic && s.pred == nil {
+		s.pred = make(map[NodeID]NodeID)
 	}
-	
-	// Also check destination nodes from edges
-	for _, edges := range G.adj {
-		for _, edge := range edges {
-			v := edge.To
-			if dhat[v] < INF {
-				if dhat[v] <= Dist(bound) {
-					left.Add(v)
-				} else if dhat[v] < B {
-					right.Add(v)
-				}
-			}
-		}
+	if s.cfg.explain {
+		s.trace = newExplainTrace()
+	}
+}
+
+// maxWeight returns an upper bound on the edge weights, or 0 if unknown.
+func (s *solver) maxWeight() Dist {
+	if s.g == nil {
+		return 0
 	}
-	
-	// Recursive calls on partitioned sets - only if they have meaningful size
-	if len(left) > 0 && len(left) < len(S) {
-		BMSSP(Dist(bound), left, G, dhat)
+	return s.g.maxWeight
+}
+
+// initialDistances returns a distance map with every node of the searched
+// graph at INF, or an empty one for implicit graphs, whose nodes are only
+// known once reached.
+func (s *solver) initialDistances() map[NodeID]Dist {
+	if s.src != nil {
+		return newReaderDistanceMap(s.src)
 	}
-	if len(right) > 0 && len(right) < len(S) {
-		BMSSP(B, right, G, dhat)
+	if s.nb != nil {
+		return make(map[NodeID]Dist)
 	}
+	return newDistanceMap(s.g)
 }
 
 // BMSSPSingleSource is a convenience function for single-source shortest paths.
@@ -297,35 +1218,48 @@ func BMSSP(B Dist, S NodeSet, G *Graph, dhat map[NodeID]Dist) {
 // Parameters:
 //   - G: input graph
 //   - source: source node
-//   - B: distance bound (use large value like 1000 for full exploration)
+//   - B: distance bound; nodes farther away are left unreached (INF or a
+//     tentative distance above B), see BMSSPSingleSourceFull
+//   - opts: optional query settings
 //
 // Returns:
 //   - map of shortest distances from source to all reachable nodes
-func BMSSPSingleSource(G *Graph, source NodeID, B Dist) map[NodeID]Dist {
-	dhat := make(map[NodeID]Dist)
-	
-	// Initialize all nodes to infinity
-	for u := range G.adj {
-		dhat[u] = INF
-	}
-	
-	// Also initialize destination nodes
-	for _, edges := range G.adj {
-		for _, edge := range edges {
-			if _, exists := dhat[edge.To]; !exists {
-				dhat[edge.To] = INF
-			}
-		}
-	}
-	
+func BMSSPSingleSource(G *Graph, source NodeID, B Dist, opts ...Option) map[NodeID]Dist {
+	s := newSolver(G, opts)
+
 	// Set source distance to 0
-	dhat[source] = 0
-	
+	s.dhat[source] = 0
+
 	// Create source set and run BMSSP
 	S := NewNodeSet()
 	S.Add(source)
-	
-	BMSSP(B, S, G, dhat)
-	
-	return dhat
-}
\ No newline at end of file
+
+	s.run(B, S)
+
+	return s.dhat
+}
+
+// BMSSPSingleSourceFull computes shortest distances from source to every
+// reachable node, without a bound. Unlike a guessed bound such as 1000, it
+// never truncates the result on graphs with long distances.
+//
+// Parameters:
+//   - G: input graph
+//   - source: source node
+//   - opts: optional query settings
+//
+// Returns:
+//   - map of shortest distances from source, INF for unreachable nodes
+func BMSSPSingleSourceFull(G *Graph, source NodeID, opts ...Option) map[NodeID]Dist {
+	return BMSSPSingleSource(G, source, INF, opts...)
+}
+
+// AutoBound returns a bound that no shortest path in g exceeds: the largest
+// edge weight times the number of edges on a longest simple path. Queries
+// with this bound explore everything reachable, like queries with INF.
+func AutoBound(g *Graph) Dist {
+	if len(g.adj) < 2 {
+		return 0
+	}
+	return g.maxWeight * Dist(len(g.adj)-1)
+}
@@ -1,7 +1,10 @@
 package bmssp
 
 import (
+	"cmp"
 	"math"
+	"math/rand"
+	"slices"
 	"testing"
 )
 
@@ -30,3 +33,391 @@ func TestBMSSP_PaperExample(t *testing.T) {
 		}
 	}
 }
+
+func TestGraph_Mutation(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 2, 1)
+	g.AddEdge(0, 2, 5)
+
+	if !g.UpdateEdgeWeight(0, 2, 1) {
+		t.Fatal("UpdateEdgeWeight: expected edge 0->2 to exist")
+	}
+	if g.UpdateEdgeWeight(2, 0, 1) {
+		t.Error("UpdateEdgeWeight: edge 2->0 should not exist")
+	}
+	if d := BMSSPSingleSource(g, 0, 1000)[2]; d != 1 {
+		t.Errorf("after update: expected dist 1 to node 2, got %v", d)
+	}
+
+	if !g.RemoveEdge(0, 2) {
+		t.Fatal("RemoveEdge: expected edge 0->2 to be removed")
+	}
+	if d := BMSSPSingleSource(g, 0, 1000)[2]; d != 2 {
+		t.Errorf("after edge removal: expected dist 2 to node 2, got %v", d)
+	}
+
+	if !g.RemoveNode(1) {
+		t.Fatal("RemoveNode: expected node 1 to be removed")
+	}
+	dhat := BMSSPSingleSource(g, 0, 1000)
+	if _, ok := dhat[1]; ok {
+		t.Error("removed node 1 still present in result")
+	}
+	if dhat[2] != INF {
+		t.Errorf("after node removal: expected node 2 unreachable, got %v", dhat[2])
+	}
+	if len(g.OutEdges(0)) != 0 {
+		t.Errorf("expected incoming edges of removed node to be dropped, got %v", g.OutEdges(0))
+	}
+}
+
+func TestBMSSP_MultiSourceRandom(t *testing.T) {
+	// Compare against Dijkstra from a virtual node joined to every source by
+	// a zero-weight edge, with and without a bound
+	for i := 0; i < 20; i++ {
+		g := generateRandomGraph(300, 1200, 10.0, int64(i))
+		ref := generateRandomGraph(300, 1200, 10.0, int64(i))
+		S := NewNodeSet()
+		for k := 0; k <= i%5+1; k++ {
+			v := NodeID((i*37 + k*101) % 300)
+			S.Add(v)
+			ref.AddEdge(-1, v, 0)
+		}
+		want := initializeDistanceMap(ref, -1)
+		DijkstraSingleSource(ref, -1, want)
+
+		B := INF
+		if i%2 == 1 {
+			B = Dist(5 * (i%4 + 1))
+		}
+		dhat := newDistanceMap(g)
+		for v := range S {
+			dhat[v] = 0
+		}
+		BMSSP(B, S, g, dhat)
+		for v, d := range want {
+			if v == -1 || d > B {
+				continue
+			}
+			if math.Abs(float64(dhat[v]-d)) > 1e-9 {
+				t.Fatalf("query %d: node %d: expected %v, got %v", i, v, d, dhat[v])
+			}
+		}
+	}
+}
+
+func TestBMSSP_MultiSource(t *testing.T) {
+	// Two disjoint chains, each with its own source
+	g := NewGraph()
+	for i := 0; i < 10; i++ {
+		g.AddEdge(NodeID(i), NodeID(i+1), 1)
+		g.AddEdge(NodeID(20+i), NodeID(21+i), 2)
+	}
+
+	dhat := newDistanceMap(g)
+	dhat[0], dhat[20] = 0, 0
+	S := NewNodeSet()
+	S.Add(0)
+	S.Add(20)
+	BMSSP(1000, S, g, dhat)
+
+	if dhat[10] != 10 || dhat[30] != 20 {
+		t.Errorf("expected chain ends at 10 and 20, got %v and %v", dhat[10], dhat[30])
+	}
+}
+
+func TestBucketQueue_MonotoneExtraction(t *testing.T) {
+	deltas := []Dist{1, 0.1, 0.3, 7, 1e-310, 0, Dist(math.NaN())}
+
+	for _, delta := range deltas {
+		r := rand.New(rand.NewSource(42))
+		q := newBucketQueue(delta, 3)
+		dist := make(map[NodeID]Dist)
+		last := Dist(0)
+
+		// Interleave inserts, decrease-keys and extractions the way a
+		// shortest-path search does: new keys are never below the last
+		// extracted key.
+		for i := 0; i < 5000; i++ {
+			switch op := r.Intn(3); {
+			case op == 0 || len(dist) == 0:
+				v := NodeID(r.Intn(1000))
+				if _, queued := q.pos[v]; queued {
+					continue
+				}
+				dist[v] = last + Dist(r.Float64()*3)
+				q.insert(v, dist[v])
+			case op == 1:
+				for v := range q.pos {
+					// Land exactly on or just below a bucket boundary
+					d := max(last, dist[v]*Dist(0.5+r.Float64()/2))
+					if r.Intn(2) == 0 {
+						d = Dist(math.Nextafter(float64(d), 0))
+					}
+					dist[v] = max(d, last)
+					q.decreaseKey(v, dist[v])
+					break
+				}
+			default:
+				v, ok := q.extractMin()
+				if !ok {
+					continue
+				}
+				if dist[v] < last-q.delta {
+					t.Fatalf("delta %v: extracted %v after %v", delta, dist[v], last)
+				}
+				last = max(last, dist[v])
+				delete(dist, v)
+			}
+		}
+	}
+}
+
+func TestBMSSP_WeightsBelowDelta(t *testing.T) {
+	// Weights far below the bucket width force re-improvements within a bucket
+	r := rand.New(rand.NewSource(1))
+	g := NewGraph()
+	for i := 0; i < 2000; i++ {
+		g.AddEdge(NodeID(r.Intn(300)), NodeID(r.Intn(300)), Dist(r.Float64()*0.5+0.01))
+	}
+
+	got := BMSSPSingleSource(g, 0, 1000)
+	for v, want := range Dijkstra(g, 0) {
+		if got[v] != want && math.Abs(float64(got[v]-want)) > 1e-9 {
+			t.Fatalf("node %d: expected %v, got %v", v, want, got[v])
+		}
+	}
+}
+
+func TestBucketQueue_RingGrowth(t *testing.T) {
+	q := newBucketQueue(1, 0)
+	for i, d := range []Dist{0, 1000, 5, 70, 5.5} {
+		q.insert(NodeID(i), d)
+	}
+	q.decreaseKey(1, 65)
+
+	var order []NodeID
+	for {
+		v, ok := q.extractMin()
+		if !ok {
+			break
+		}
+		order = append(order, v)
+	}
+	// Nodes 2 and 4 share bucket 5 and may come out in either order
+	if len(order) != 5 || order[0] != 0 || order[3] != 1 || order[4] != 3 {
+		t.Errorf("expected order 0, {2, 4}, 1, 3, got %v", order)
+	}
+	if len(q.pos) != 0 || q.n != 0 {
+		t.Errorf("expected an empty queue, %d entries left", q.n)
+	}
+}
+
+func TestGraph_InEdges(t *testing.T) {
+	g := generateRandomGraph(50, 300, 10, 9)
+	g.InEdges(0) // build the index before mutating
+
+	r := rand.New(rand.NewSource(9))
+	for i := 0; i < 200; i++ {
+		u, v := NodeID(r.Intn(50)), NodeID(r.Intn(50))
+		switch r.Intn(4) {
+		case 0:
+			g.AddEdge(u, v, Dist(r.Intn(10)))
+		case 1:
+			g.RemoveEdge(u, v)
+		case 2:
+			g.UpdateEdgeWeight(u, v, Dist(r.Intn(10)))
+		default:
+			if r.Intn(10) == 0 {
+				g.RemoveNode(u)
+			}
+		}
+	}
+
+	// Compare against the index rebuilt from scratch
+	want := g.Clone()
+	for v := range g.adj {
+		got, exp := g.InEdges(v), want.InEdges(v)
+		sortEdges := func(a, b Edge) int {
+			if c := cmp.Compare(a.To, b.To); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.Weight, b.Weight)
+		}
+		got, exp = slices.Clone(got), slices.Clone(exp)
+		slices.SortFunc(got, sortEdges)
+		slices.SortFunc(exp, sortEdges)
+		if !slices.Equal(got, exp) {
+			t.Errorf("node %d: expected in-edges %v, got %v", v, exp, got)
+		}
+	}
+	for v := range g.radj {
+		if _, ok := g.adj[v]; !ok && len(g.radj[v]) > 0 {
+			t.Errorf("removed node %d still has in-edges %v", v, g.radj[v])
+		}
+	}
+}
+
+func TestGraph_Counts(t *testing.T) {
+	g := generateGridGraph(3, 3)
+	g.AddEdge(0, 1, 5) // parallel edge
+	g.AddEdge(9, 9, 1) // self-loop on a new node
+	if g.NumNodes() != 10 {
+		t.Errorf("NumNodes = %d, want 10", g.NumNodes())
+	}
+	if g.NumEdges() != 26 {
+		t.Errorf("NumEdges = %d, want 26", g.NumEdges())
+	}
+	if g.OutDegree(0) != 3 || g.OutDegree(4) != 4 || g.OutDegree(42) != 0 {
+		t.Errorf("OutDegree(0, 4, 42) = %d, %d, %d, want 3, 4, 0", g.OutDegree(0), g.OutDegree(4), g.OutDegree(42))
+	}
+
+	seen := NewNodeSet()
+	for v := range g.Nodes() {
+		seen.Add(v)
+	}
+	if len(seen) != g.NumNodes() || !seen.Has(9) {
+		t.Errorf("Nodes yielded %d nodes, want all %d", len(seen), g.NumNodes())
+	}
+
+	g.RemoveNode(4)
+	if g.NumNodes() != 9 || g.NumEdges() != 18 {
+		t.Errorf("after RemoveNode: %d nodes and %d edges, want 9 and 18", g.NumNodes(), g.NumEdges())
+	}
+	if g.HasNode(4) || !g.HasNode(9) {
+		t.Errorf("HasNode(4, 9) = %v, %v, want false, true", g.HasNode(4), g.HasNode(9))
+	}
+	g.AddNode(4)
+	g.AddNode(0) // already a node
+	if g.NumNodes() != 10 || g.OutDegree(0) != 3 || g.InEdges(4) != nil {
+		t.Errorf("after AddNode: %d nodes, OutDegree(0) = %d, want 10 and 3", g.NumNodes(), g.OutDegree(0))
+	}
+}
+
+func TestGraph_ReverseAndSubgraph(t *testing.T) {
+	g := generateRandomGraph(60, 300, 10, 13)
+
+	r := g.Reverse()
+	for u := range g.adj {
+		want := Dijkstra(g, u)
+		for v, d := range BMSSPSingleTarget(r, u, INF) {
+			if math.Abs(float64(d-want[v])) > 1e-9 && d != want[v] {
+				t.Fatalf("reverse: distance %d->%d: expected %v, got %v", u, v, want[v], d)
+			}
+		}
+	}
+
+	keep := NewNodeSet()
+	for v := NodeID(0); v < 30; v++ {
+		keep.Add(v)
+	}
+	keep.Add(1000) // not in g
+	sub := g.Subgraph(keep)
+	if len(sub.adj) != 30 {
+		t.Errorf("expected 30 nodes in the subgraph, got %d", len(sub.adj))
+	}
+	edges := 0
+	for u, out := range g.adj {
+		for _, e := range out {
+			if keep.Has(u) && keep.Has(e.To) {
+				edges++
+			}
+		}
+	}
+	if sub.numEdges != edges {
+		t.Errorf("expected %d induced edges, got %d", edges, sub.numEdges)
+	}
+}
+
+func TestBucketQueue_Overflow(t *testing.T) {
+	// Keys spanning far more buckets than the ring may hold
+	q := newBucketQueue(1e-3, 0)
+	r := rand.New(rand.NewSource(5))
+	keys := make(map[NodeID]Dist)
+	for i := 0; i < 2000; i++ {
+		keys[NodeID(i)] = Dist(r.Float64() * 1e3)
+		q.insert(NodeID(i), keys[NodeID(i)])
+	}
+	for v := NodeID(0); v < 2000; v += 7 {
+		keys[v] /= 2
+		q.decreaseKey(v, keys[v])
+	}
+	if len(q.ring) > maxRingBuckets {
+		t.Fatalf("expected the ring to stay within %d buckets, got %d", maxRingBuckets, len(q.ring))
+	}
+
+	last := Dist(0)
+	for range keys {
+		v, ok := q.extractMin()
+		if !ok {
+			t.Fatal("queue ran empty early")
+		}
+		if keys[v] < last-q.delta {
+			t.Fatalf("extracted %v after %v", keys[v], last)
+		}
+		last = max(last, keys[v])
+	}
+	if _, ok := q.extractMin(); ok || len(q.pos) != 0 {
+		t.Errorf("expected an empty queue, %d entries left", len(q.pos))
+	}
+}
+
+func TestBMSSP_HugeBounds(t *testing.T) {
+	g := generateGridGraph(30, 30)
+	g.AddEdge(0, 899, 1e15)
+	want := Dijkstra(g, 0)
+
+	for _, B := range []Dist{1e18, 1e300, math.MaxFloat64, INF} {
+		res := Solve(g, sources(0), B, WithDelta(1e-3))
+		for v, d := range want {
+			if res.Dist[v] != d {
+				t.Fatalf("bound %v: node %d: expected %v, got %v", B, v, d, res.Dist[v])
+			}
+		}
+	}
+
+	// Finite bounds below the longest possible path still bind
+	if d := BMSSPSingleSource(g, 0, 10)[899]; d <= 10 && d != INF {
+		t.Errorf("expected node 899 beyond bound 10, got %v", d)
+	}
+}
+
+func TestBMSSPSingleSourceFull(t *testing.T) {
+	// A chain whose far end lies beyond the customary bound of 1000
+	g := NewGraph()
+	for i := range 30 {
+		g.AddEdge(NodeID(i), NodeID(i+1), 50)
+	}
+
+	if d := BMSSPSingleSource(g, 0, 1000)[30]; d <= 1000 {
+		t.Fatalf("expected node 30 beyond the bound, got %v", d)
+	}
+	full := BMSSPSingleSourceFull(g, 0)
+	if full[30] != 1500 {
+		t.Errorf("expected 1500, got %v", full[30])
+	}
+	if B := AutoBound(g); B < 1500 || BMSSPSingleSource(g, 0, B)[30] != 1500 {
+		t.Errorf("AutoBound %v does not cover the graph", B)
+	}
+
+	for name, opts := range map[string][]Option{
+		"bmssp":   {WithoutFastPaths()},
+		"uniform": nil,
+		"dag":     {WithDAG()},
+	} {
+		if res := Solve(g, sources(0), 1000, opts...); !res.Truncated || res.Dist[20] != 1000 {
+			t.Errorf("%s: expected a truncated result, got %v", name, res.Truncated)
+		}
+		if res := Solve(g, sources(0), INF, opts...); res.Truncated {
+			t.Errorf("%s: unbounded query reported truncation", name)
+		}
+		if res := Solve(g, sources(10), 1000, opts...); res.Truncated {
+			t.Errorf("%s: bound covering everything reported truncation", name)
+		}
+	}
+	g.AddEdge(31, 32, 1)
+	if res := SolveDijkstra(g, sources(0)); res.Truncated || !res.Unreachable.Has(32) {
+		t.Errorf("unreachable nodes are not truncation: %v", res.Truncated)
+	}
+}
@@ -0,0 +1,103 @@
+package bmssp
+
+import "slices"
+
+// EdgeTriple is a directed edge for bulk loading with AddEdges and
+// NewGraphFromEdges.
+type EdgeTriple struct {
+	From, To NodeID
+	Weight   Dist
+}
+
+// AddEdges adds the edges in order, as calls to AddEdge would, but grows
+// every adjacency list once instead of edge by edge.
+func (g *Graph) AddEdges(edges []EdgeTriple) {
+	g.mustBeMutable()
+	if len(edges) == 0 {
+		return
+	}
+	degree := make(map[NodeID]int)
+	for _, e := range edges {
+		degree[e.From]++
+	}
+	if len(g.adj) == 0 {
+		g.adj = make(map[NodeID][]Edge, len(degree))
+	}
+	for u, d := range degree {
+		g.adj[u] = slices.Grow(g.ownOut(u), d)
+	}
+
+	for _, e := range edges {
+		g.adj[e.From] = append(g.adj[e.From], Edge{To: e.To, Weight: e.Weight})
+		g.noteWeight(e.Weight)
+		if _, ok := g.adj[e.To]; !ok {
+			g.adj[e.To] = nil
+		}
+		if g.radj != nil {
+			g.radj[e.To] = append(g.ownIn(e.To), Edge{To: e.From, Weight: e.Weight})
+		}
+	}
+	g.numEdges += len(edges)
+	g.version++
+}
+
+// NewGraphFromEdges builds a graph from a list of edges in linear time, for
+// loading large graphs. The edges are bucketed by tail with one counting
+// sort into a single array, which the adjacency lists then slice, so the
+// graph costs two allocations plus the node map however many edges it has.
+//
+// Parameters:
+//   - n: the graph has nodes 0 to n-1, isolated or not; endpoints outside
+//     that range are added as by AddEdge, more slowly
+//   - edges: the edges; parallel edges are kept, and every node's edges stay
+//     in input order
+//
+// Returns:
+//   - the graph, equal to one built by calling AddEdge for every edge
+func NewGraphFromEdges(n int, edges []EdgeTriple) *Graph {
+	n = max(n, 0)
+	g := &Graph{adj: make(map[NodeID][]Edge, n)}
+	inRange := func(v NodeID) bool { return v >= 0 && int(v) < n }
+
+	start := make([]int, n+1)
+	for _, e := range edges {
+		if inRange(e.From) {
+			start[e.From+1]++
+		}
+	}
+	for v := range n {
+		start[v+1] += start[v]
+	}
+	arcs := make([]Edge, start[n])
+	next := slices.Clone(start[:n])
+	var rest []EdgeTriple
+	for _, e := range edges {
+		if !inRange(e.From) {
+			rest = append(rest, e)
+			continue
+		}
+		arcs[next[e.From]] = Edge{To: e.To, Weight: e.Weight}
+		next[e.From]++
+		g.noteWeight(e.Weight)
+	}
+
+	for v := range n {
+		if lo, hi := start[v], start[v+1]; lo < hi {
+			// Cap each list so appending to it reallocates instead of
+			// overwriting the next node's edges
+			g.adj[NodeID(v)] = arcs[lo:hi:hi]
+		} else {
+			g.adj[NodeID(v)] = nil
+		}
+	}
+	g.numEdges = len(arcs)
+	for _, a := range arcs {
+		if !inRange(a.To) {
+			if _, ok := g.adj[a.To]; !ok {
+				g.adj[a.To] = nil
+			}
+		}
+	}
+	g.AddEdges(rest)
+	return g
+}
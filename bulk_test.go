@@ -0,0 +1,74 @@
+package bmssp
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// sameGraph reports whether a and b have the same nodes, edge lists and
+// weight bookkeeping.
+func sameGraph(a, b *Graph) bool {
+	if len(a.adj) != len(b.adj) || a.numEdges != b.numEdges || a.maxWeight != b.maxWeight ||
+		a.shape != b.shape || a.uniform != b.uniform {
+		return false
+	}
+	for u, edges := range a.adj {
+		other, ok := b.adj[u]
+		if !ok || !slices.Equal(edges, other) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNewGraphFromEdges(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var edges []EdgeTriple
+	want := NewGraph()
+	for v := range 50 {
+		want.adj[NodeID(v)] = nil
+	}
+	for range 400 {
+		e := EdgeTriple{From: NodeID(r.Intn(50)), To: NodeID(r.Intn(50)), Weight: Dist(r.Intn(10))}
+		edges = append(edges, e)
+		want.AddEdge(e.From, e.To, e.Weight)
+	}
+	// Endpoints outside 0..n-1
+	edges = append(edges, EdgeTriple{From: 3, To: 70, Weight: 2}, EdgeTriple{From: -1, To: 4, Weight: 1})
+	want.AddEdge(3, 70, 2)
+	want.AddEdge(-1, 4, 1)
+
+	g := NewGraphFromEdges(50, edges)
+	if !sameGraph(g, want) {
+		t.Fatal("NewGraphFromEdges differs from the graph built with AddEdge")
+	}
+
+	// Appending to one node's edges must not overwrite the next node's
+	next := slices.Clone(g.adj[4])
+	g.AddEdge(3, 5, 1)
+	if !slices.Equal(g.adj[4], next) {
+		t.Error("AddEdge overwrote the edges of the next node")
+	}
+
+	if got := NewGraphFromEdges(3, nil); len(got.adj) != 3 || got.numEdges != 0 {
+		t.Errorf("NewGraphFromEdges(3, nil) has %d nodes and %d edges, want 3 and 0", len(got.adj), got.numEdges)
+	}
+}
+
+func TestGraph_AddEdges(t *testing.T) {
+	g := generateGridGraph(4, 4)
+	g.InEdges(0)
+	want := g.Clone()
+	edges := []EdgeTriple{{From: 0, To: 15, Weight: 3}, {From: 20, To: 0, Weight: 1}, {From: 0, To: 15, Weight: 2}}
+	for _, e := range edges {
+		want.AddEdge(e.From, e.To, e.Weight)
+	}
+	g.AddEdges(edges)
+	if !sameGraph(g, want) {
+		t.Fatal("AddEdges differs from calling AddEdge per edge")
+	}
+	if in := g.InEdges(15); len(in) != 4 {
+		t.Errorf("node 15 has %d incoming edges, want 4", len(in))
+	}
+}
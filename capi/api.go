@@ -0,0 +1,119 @@
+package main
+
+import (
+	"math"
+	"runtime/cgo"
+	"slices"
+
+	"github.com/mfreeman451/bmssp-go"
+)
+
+// Status codes of the C API, returned instead of results on failure. They
+// match the BMSSP_* macros of the header.
+const (
+	statusOK              = 0
+	statusBadHandle       = -1 // handle unknown or already freed
+	statusInvalidWeight   = -2 // negative or NaN weight or bound
+	statusInvalidArgument = -3 // NULL buffer or negative capacity
+	statusInternal        = -4 // unexpected failure inside the library
+)
+
+// The functions below implement the exported C functions on Go types, so
+// that they can be tested without cgo. Each recovers from panics: a Go panic
+// crossing into C would abort the host process.
+
+// recoverStatus sets *status to statusInternal if the caller panics.
+func recoverStatus(status *int64) {
+	if recover() != nil {
+		*status = statusInternal
+	}
+}
+
+// graphOf resolves a graph handle. It reports false for the zero handle
+// and for freed or foreign ones.
+func graphOf(h uintptr) (g *bmssp.Graph, ok bool) {
+	if h == 0 {
+		return nil, false
+	}
+	defer func() {
+		if recover() != nil {
+			g, ok = nil, false
+		}
+	}()
+	g, ok = cgo.Handle(h).Value().(*bmssp.Graph)
+	return g, ok
+}
+
+// validWeight reports whether w is non-negative and not NaN; +Inf marks a
+// closed edge, as in the Go API.
+func validWeight(w float64) bool {
+	return w >= 0 && !math.IsNaN(w)
+}
+
+func newGraph() uintptr {
+	return uintptr(cgo.NewHandle(bmssp.NewGraph()))
+}
+
+func freeGraph(h uintptr) (status int64) {
+	defer recoverStatus(&status)
+	if _, ok := graphOf(h); !ok {
+		return statusBadHandle
+	}
+	cgo.Handle(h).Delete()
+	return statusOK
+}
+
+func addEdge(h uintptr, from, to int64, weight float64) (status int64) {
+	defer recoverStatus(&status)
+	g, ok := graphOf(h)
+	if !ok {
+		return statusBadHandle
+	}
+	if !validWeight(weight) {
+		return statusInvalidWeight
+	}
+	g.AddEdge(bmssp.NodeID(from), bmssp.NodeID(to), bmssp.Dist(weight))
+	return statusOK
+}
+
+// singleSource fills nodes and dists with the nodes reachable from source
+// within bound, ordered by node, and returns their number.
+func singleSource(h uintptr, source int64, bound float64, nodes []int64, dists []float64) (n int64) {
+	defer recoverStatus(&n)
+	g, ok := graphOf(h)
+	if !ok {
+		return statusBadHandle
+	}
+	if !validWeight(bound) {
+		return statusInvalidWeight
+	}
+	dist := bmssp.BMSSPSingleSource(g, bmssp.NodeID(source), bmssp.Dist(bound))
+
+	reached := make([]bmssp.NodeID, 0, len(dist))
+	for v, d := range dist {
+		if d <= bmssp.Dist(bound) && d < bmssp.INF {
+			reached = append(reached, v)
+		}
+	}
+	slices.Sort(reached)
+	for i, v := range reached[:min(len(reached), len(nodes))] {
+		nodes[i] = int64(v)
+		dists[i] = float64(dist[v])
+	}
+	return int64(len(reached))
+}
+
+// shortestPath fills path with a shortest path from source to target and
+// returns its number of nodes, 0 if target is unreachable, and its cost.
+func shortestPath(h uintptr, source, target int64, path []int64) (n int64, cost float64) {
+	defer recoverStatus(&n)
+	g, ok := graphOf(h)
+	if !ok {
+		return statusBadHandle, 0
+	}
+	p, d := bmssp.ShortestPath(g, bmssp.NodeID(source), bmssp.NodeID(target))
+	for i, v := range p[:min(len(p), len(path))] {
+		path[i] = int64(v)
+	}
+	return int64(len(p)), float64(d)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestGraphLifecycle(t *testing.T) {
+	h := newGraph()
+	for _, e := range [][3]float64{{0, 1, 2}, {1, 2, 3}, {0, 2, 10}} {
+		if s := addEdge(h, int64(e[0]), int64(e[1]), e[2]); s != statusOK {
+			t.Fatalf("addEdge(%v): status %d", e, s)
+		}
+	}
+
+	nodes, dists := make([]int64, 2), make([]float64, 2)
+	if n := singleSource(h, 0, math.Inf(1), nodes, dists); n != 3 {
+		t.Errorf("singleSource: expected 3 reachable nodes, got %d", n)
+	}
+	if !slices.Equal(nodes, []int64{0, 1}) || !slices.Equal(dists, []float64{0, 2}) {
+		t.Errorf("singleSource: expected nodes [0 1] at [0 2], got %v at %v", nodes, dists)
+	}
+
+	path := make([]int64, 8)
+	n, cost := shortestPath(h, 0, 2, path)
+	if n != 3 || cost != 5 || !slices.Equal(path[:n], []int64{0, 1, 2}) {
+		t.Errorf("shortestPath: expected [0 1 2] of cost 5, got %v of cost %v", path[:max(n, 0)], cost)
+	}
+
+	if s := freeGraph(h); s != statusOK {
+		t.Errorf("freeGraph: status %d", s)
+	}
+}
+
+func TestInvalidInput(t *testing.T) {
+	h := newGraph()
+	defer freeGraph(h)
+	for _, w := range []float64{-1, math.NaN()} {
+		if s := addEdge(h, 0, 1, w); s != statusInvalidWeight {
+			t.Errorf("weight %v: expected statusInvalidWeight, got %d", w, s)
+		}
+	}
+	if s := addEdge(h, 0, 1, math.Inf(1)); s != statusOK {
+		t.Errorf("weight +Inf: expected statusOK, got %d", s)
+	}
+	if n := singleSource(h, 0, -1, nil, nil); n != statusInvalidWeight {
+		t.Errorf("negative bound: expected statusInvalidWeight, got %d", n)
+	}
+
+	freed := newGraph()
+	freeGraph(freed)
+	for _, bad := range []uintptr{0, freed, freed + 1000} {
+		if s := addEdge(bad, 0, 1, 1); s != statusBadHandle {
+			t.Errorf("handle %d: addEdge expected statusBadHandle, got %d", bad, s)
+		}
+		if n := singleSource(bad, 0, 1, nil, nil); n != statusBadHandle {
+			t.Errorf("handle %d: singleSource expected statusBadHandle, got %d", bad, n)
+		}
+		if n, _ := shortestPath(bad, 0, 1, nil); n != statusBadHandle {
+			t.Errorf("handle %d: shortestPath expected statusBadHandle, got %d", bad, n)
+		}
+		if s := freeGraph(bad); s != statusBadHandle {
+			t.Errorf("handle %d: freeGraph expected statusBadHandle, got %d", bad, s)
+		}
+	}
+}
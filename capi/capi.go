@@ -0,0 +1,98 @@
+// Command capi builds a C shared library exposing BMSSP to other languages:
+//
+//	go build -buildmode=c-shared -o libbmssp.so ./capi
+//
+// This writes libbmssp.so and the header libbmssp.h. Graphs are referenced
+// by opaque handles that must be released with bmssp_graph_free. A handle
+// must not be used by several threads while it is being modified.
+//
+// Functions report failures with the negative BMSSP_ERR_* status codes
+// instead of aborting the host process, including for invalid handles.
+package main
+
+/*
+#include <stdint.h>
+
+#define BMSSP_OK 0
+#define BMSSP_ERR_HANDLE -1
+#define BMSSP_ERR_WEIGHT -2
+#define BMSSP_ERR_ARGUMENT -3
+#define BMSSP_ERR_INTERNAL -4
+*/
+import "C"
+
+import "unsafe"
+
+func main() {}
+
+// int64Buffer returns the C buffer p of length capacity as a slice, or
+// false for a NULL buffer with a positive capacity or a negative capacity.
+func int64Buffer(p *C.int64_t, capacity C.int64_t) ([]int64, bool) {
+	if capacity < 0 || p == nil && capacity > 0 {
+		return nil, false
+	}
+	if capacity == 0 {
+		return nil, true
+	}
+	return unsafe.Slice((*int64)(unsafe.Pointer(p)), int(capacity)), true
+}
+
+// bmssp_graph_new creates an empty graph and returns its handle.
+//
+//export bmssp_graph_new
+func bmssp_graph_new() C.uintptr_t {
+	return C.uintptr_t(newGraph())
+}
+
+// bmssp_graph_free releases a graph handle. It returns BMSSP_OK, or
+// BMSSP_ERR_HANDLE for a handle that is unknown or already freed.
+//
+//export bmssp_graph_free
+func bmssp_graph_free(h C.uintptr_t) C.int {
+	return C.int(freeGraph(uintptr(h)))
+}
+
+// bmssp_graph_add_edge adds a directed edge. It returns BMSSP_OK, or
+// BMSSP_ERR_WEIGHT for a negative or NaN weight.
+//
+//export bmssp_graph_add_edge
+func bmssp_graph_add_edge(h C.uintptr_t, from, to C.int64_t, weight C.double) C.int {
+	return C.int(addEdge(uintptr(h), int64(from), int64(to), float64(weight)))
+}
+
+// bmssp_single_source computes the distances from source to every node
+// reachable within bound. It writes up to capacity (node, distance) pairs,
+// ordered by node, to nodes and dists and returns the number of reachable
+// nodes; if that exceeds capacity, call again with larger buffers. It
+// returns a negative status code on failure.
+//
+//export bmssp_single_source
+func bmssp_single_source(h C.uintptr_t, source C.int64_t, bound C.double,
+	nodes *C.int64_t, dists *C.double, capacity C.int64_t) C.int64_t {
+	outNodes, ok1 := int64Buffer(nodes, capacity)
+	if !ok1 || dists == nil && capacity > 0 {
+		return statusInvalidArgument
+	}
+	var outDists []float64
+	if capacity > 0 {
+		outDists = unsafe.Slice((*float64)(unsafe.Pointer(dists)), int(capacity))
+	}
+	return C.int64_t(singleSource(uintptr(h), int64(source), float64(bound), outNodes, outDists))
+}
+
+// bmssp_shortest_path computes a shortest path from source to target. It
+// writes the path length to *cost (infinity if unreachable) and up to
+// capacity nodes of the path to path, and returns the number of nodes on the
+// path (0 if unreachable), or a negative status code on failure.
+//
+//export bmssp_shortest_path
+func bmssp_shortest_path(h C.uintptr_t, source, target C.int64_t,
+	path *C.int64_t, capacity C.int64_t, cost *C.double) C.int64_t {
+	out, ok := int64Buffer(path, capacity)
+	if !ok || cost == nil {
+		return statusInvalidArgument
+	}
+	n, d := shortestPath(uintptr(h), int64(source), int64(target), out)
+	*cost = C.double(d)
+	return C.int64_t(n)
+}
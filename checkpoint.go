@@ -0,0 +1,106 @@
+package bmssp
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// Checkpoint is the state of a query, e.g. one stopped by WithDeadline or
+// WithNodeBudget, in a form that can be saved and restored in another
+// process or on another machine. Distances are lengths of actual paths, so
+// Restore only needs them and the bound to finish the query.
+type Checkpoint struct {
+	Bound Dist              // bound of the query
+	Dist  map[NodeID]Dist   // finite distances found so far
+	Pred  map[NodeID]NodeID // predecessors on the paths found so far
+}
+
+// Checkpoint captures the state of r. The query of a Result not produced
+// by Solve or SolveFrom is taken to be unbounded.
+func (r *Result) Checkpoint() *Checkpoint {
+	cp := &Checkpoint{Bound: INF, Dist: make(map[NodeID]Dist, len(r.Dist)), Pred: maps.Clone(r.Pred)}
+	if r.g != nil {
+		cp.Bound = r.bound
+	}
+	for v, d := range r.Dist {
+		if d < INF {
+			cp.Dist[v] = d
+		}
+	}
+	return cp
+}
+
+// Restore finishes the query captured by cp on g, which must be the graph
+// it ran on. Nodes whose edges were already relaxed are not searched again:
+// every edge is scanned once to find where the search left off, and the
+// search continues from there. Settings such as overlays cannot be saved;
+// pass the options of the original query again.
+//
+// Parameters:
+//   - g: input graph
+//   - cp: saved query state
+//   - opts: optional query settings, those of the original query
+//
+// Returns:
+//   - the result of the query, resumable by ResumeBMSSP
+//   - ErrNodeNotFound if cp refers to a node not in g
+//   - ErrNotResumable if opts set a hop limit or hop tie-break, as hop
+//     counts are not saved
+func Restore(g *Graph, cp *Checkpoint, opts ...Option) (*Result, error) {
+	start := time.Now()
+
+	s := newSolver(g, opts)
+	if s.cfg.maxHops > 0 || s.cfg.tieBreak == TieBreakFewestHops {
+		return nil, fmt.Errorf("%w: hop counts are not kept", ErrNotResumable)
+	}
+	s.pred = maps.Clone(cp.Pred)
+	if s.pred == nil {
+		s.pred = make(map[NodeID]NodeID)
+	}
+	for v, d := range cp.Dist {
+		if _, ok := g.adj[v]; !ok {
+			return nil, fmt.Errorf("%w: %d", ErrNodeNotFound, v)
+		}
+		s.dhat[v] = d
+	}
+	s.limit = cp.Bound
+	s.run(cp.Bound, s.reopen(cp.Bound))
+
+	return s.resumable(s.result(start)), nil
+}
+
+// checkpointJSON is the JSON form of a Checkpoint; an unbounded query has
+// no bound.
+type checkpointJSON struct {
+	Bound *float64          `json:"bound,omitempty"`
+	Dist  map[NodeID]Dist   `json:"dist"`
+	Pred  map[NodeID]NodeID `json:"pred,omitempty"`
+}
+
+// MarshalJSON encodes cp.
+func (cp *Checkpoint) MarshalJSON() ([]byte, error) {
+	out := checkpointJSON{Dist: cp.Dist, Pred: cp.Pred}
+	if cp.Bound < INF {
+		b := float64(cp.Bound)
+		out.Bound = &b
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a Checkpoint written by MarshalJSON.
+func (cp *Checkpoint) UnmarshalJSON(data []byte) error {
+	var in checkpointJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*cp = Checkpoint{Bound: INF, Dist: in.Dist, Pred: in.Pred}
+	if in.Bound != nil {
+		cp.Bound = Dist(*in.Bound)
+	}
+	if cp.Dist == nil {
+		cp.Dist = make(map[NodeID]Dist)
+	}
+	return nil
+}
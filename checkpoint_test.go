@@ -0,0 +1,55 @@
+package bmssp
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestCheckpointRestore(t *testing.T) {
+	g := generateRandomGraph(1000, 6000, 10, 5)
+	for _, B := range []Dist{INF, 25} {
+		want := Solve(g, sources(0), B)
+		partial := Solve(g, sources(0), B, WithNodeBudget(300), WithoutFastPaths())
+		if partial.Stopped != StopNodeBudget {
+			t.Fatalf("expected the budget to stop the query, got %v", partial.Stopped)
+		}
+
+		// Save and load, as across a restart
+		data, err := json.Marshal(partial.Checkpoint())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cp Checkpoint
+		if err := json.Unmarshal(data, &cp); err != nil {
+			t.Fatal(err)
+		}
+		if cp.Bound != B {
+			t.Errorf("expected bound %v, got %v", B, cp.Bound)
+		}
+
+		res, err := Restore(g, &cp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for v, d := range want.Dist {
+			if d <= B && !approxEqual(res.Dist[v], d, DefaultEpsilon) {
+				t.Fatalf("bound %v: node %d: expected %v, got %v", B, v, d, res.Dist[v])
+			}
+		}
+		if path := res.PathTo(999); want.Dist[999] <= B && len(path) == 0 {
+			t.Errorf("bound %v: expected a path to 999", B)
+		}
+		if res.Stopped != StopComplete || res.Stats.NodesSettled >= want.Stats.NodesSettled {
+			t.Errorf("bound %v: expected restored work below a fresh run, settled %d vs %d",
+				B, res.Stats.NodesSettled, want.Stats.NodesSettled)
+		}
+	}
+
+	if _, err := Restore(generateGridGraph(3, 3), Solve(g, sources(0), INF).Checkpoint()); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("expected ErrNodeNotFound on another graph, got %v", err)
+	}
+	if _, err := Restore(g, &Checkpoint{Bound: INF}, WithMaxHops(2)); !errors.Is(err, ErrNotResumable) {
+		t.Errorf("expected ErrNotResumable with a hop limit, got %v", err)
+	}
+}
@@ -0,0 +1,306 @@
+// Command bmssp runs shortest-path queries on a graph file.
+//
+// Usage:
+//
+//	bmssp <command> -graph <file> [flags]
+//
+// Commands:
+//
+//	sssp       distances from a source to every node within a bound
+//	p2p        shortest path between two nodes
+//	isochrone  nodes within a bound of a source and where the region is left
+//	bench      compare BMSSP with Dijkstra on repeated queries
+//
+// Graphs are read as DIMACS (.gr), CSV edge lists (.csv, "from,to,weight")
+// or the package's binary format (.bin), chosen by extension or -format.
+// Results are printed as text, or as JSON with -json.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mfreeman451/bmssp-go"
+)
+
+const usage = `usage: bmssp <command> -graph <file> [flags]
+
+commands:
+  sssp       distances from -source to every node within -bound
+  p2p        shortest path from -from to -to
+  isochrone  nodes within -bound of -source and the edges leaving the region
+  bench      time -runs queries from -source with BMSSP and Dijkstra
+
+run "bmssp <command> -h" for the flags of a command
+`
+
+var errUsage = errors.New("usage")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "bmssp:", err)
+		os.Exit(1)
+	}
+}
+
+// options holds the flags shared by all commands.
+type options struct {
+	graph   string
+	format  string
+	jsonOut bool
+}
+
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.graph, "graph", "", "graph file (required)")
+	fs.StringVar(&o.format, "format", "", "graph format: dimacs, csv or bin (default: by extension)")
+	fs.BoolVar(&o.jsonOut, "json", false, "print results as JSON")
+}
+
+// run executes the command in args and writes its output to w.
+func run(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	cmd, args := args[0], args[1:]
+
+	var o options
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	o.register(fs)
+	source := fs.Int("source", 0, "source node")
+	bound := fs.Float64("bound", float64(bmssp.INF), "distance bound")
+	from := fs.Int("from", 0, "origin node (p2p)")
+	to := fs.Int("to", 0, "destination node (p2p)")
+	runs := fs.Int("runs", 10, "queries per algorithm (bench)")
+
+	switch cmd {
+	case "sssp", "p2p", "isochrone", "bench":
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(w, usage)
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, cmd)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if o.graph == "" {
+		return fmt.Errorf("%s: -graph is required", cmd)
+	}
+	g, err := load(o.graph, o.format)
+	if err != nil {
+		return err
+	}
+
+	switch cmd {
+	case "sssp":
+		return sssp(w, g, bmssp.NodeID(*source), bmssp.Dist(*bound), o.jsonOut)
+	case "p2p":
+		return p2p(w, g, bmssp.NodeID(*from), bmssp.NodeID(*to), o.jsonOut)
+	case "isochrone":
+		return isochrone(w, g, bmssp.NodeID(*source), bmssp.Dist(*bound), o.jsonOut)
+	default:
+		return bench(w, g, bmssp.NodeID(*source), *runs, o.jsonOut)
+	}
+}
+
+// load reads a graph file in the given format, or the one implied by its
+// extension.
+func load(path, format string) (*bmssp.Graph, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".gr", ".dimacs":
+			format = "dimacs"
+		case ".csv", ".tsv":
+			format = "csv"
+		case ".bin", ".bmsg":
+			format = "bin"
+		default:
+			return nil, fmt.Errorf("cannot tell the format of %s; use -format", path)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch format {
+	case "dimacs":
+		return bmssp.ReadDIMACS(f)
+	case "csv":
+		opts := bmssp.CSVOptions{}
+		if strings.EqualFold(filepath.Ext(path), ".tsv") {
+			opts.Comma = '\t'
+		}
+		return bmssp.ReadEdgeListCSV(f, opts)
+	case "bin":
+		return bmssp.ReadGraph(f)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// writeJSON prints v as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// check validates g and the sources. A checked query with a zero bound
+// settles only the sources, so it costs little beyond the validation.
+func check(g *bmssp.Graph, sources bmssp.NodeSet) error {
+	_, err := bmssp.SolveChecked(g, sources, 0)
+	return err
+}
+
+func sssp(w io.Writer, g *bmssp.Graph, source bmssp.NodeID, bound bmssp.Dist, jsonOut bool) error {
+	S := bmssp.NewNodeSet()
+	S.Add(source)
+	res, err := bmssp.SolveChecked(g, S, bound)
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		return writeJSON(w, res)
+	}
+
+	nodes := make([]bmssp.NodeID, 0, len(res.Dist))
+	for v, d := range res.Dist {
+		if d <= bound {
+			nodes = append(nodes, v)
+		}
+	}
+	slices.Sort(nodes)
+	for _, v := range nodes {
+		fmt.Fprintf(w, "%d\t%v\n", v, res.Dist[v])
+	}
+	return nil
+}
+
+func p2p(w io.Writer, g *bmssp.Graph, from, to bmssp.NodeID, jsonOut bool) error {
+	path, cost, err := bmssp.ShortestPathChecked(g, from, to)
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		out := struct {
+			Path []bmssp.NodeID `json:"path"`
+			Cost *bmssp.Dist    `json:"cost"` // null if unreachable
+		}{Path: path}
+		if path != nil {
+			out.Cost = &cost
+		}
+		return writeJSON(w, out)
+	}
+
+	if path == nil {
+		fmt.Fprintf(w, "%d is unreachable from %d\n", to, from)
+		return nil
+	}
+	steps := make([]string, len(path))
+	for i, v := range path {
+		steps[i] = fmt.Sprint(v)
+	}
+	fmt.Fprintf(w, "cost %v: %s\n", cost, strings.Join(steps, " -> "))
+	return nil
+}
+
+func isochrone(w io.Writer, g *bmssp.Graph, source bmssp.NodeID, bound bmssp.Dist, jsonOut bool) error {
+	S := bmssp.NewNodeSet()
+	S.Add(source)
+	if err := check(g, S); err != nil {
+		return err
+	}
+	res := bmssp.Isochrone(g, S, bound)
+
+	nodes := make([]bmssp.NodeID, 0, len(res.Dist))
+	for v := range res.Dist {
+		nodes = append(nodes, v)
+	}
+	slices.Sort(nodes)
+
+	if jsonOut {
+		type node struct {
+			ID   bmssp.NodeID `json:"id"`
+			Dist bmssp.Dist   `json:"dist"`
+		}
+		type edge struct {
+			From   bmssp.NodeID `json:"from"`
+			To     bmssp.NodeID `json:"to"`
+			Weight bmssp.Dist   `json:"weight"`
+			Reach  bmssp.Dist   `json:"reach"`
+		}
+		out := struct {
+			Nodes    []node `json:"nodes"`
+			Frontier []edge `json:"frontier"`
+		}{Nodes: []node{}, Frontier: []edge{}}
+		for _, v := range nodes {
+			out.Nodes = append(out.Nodes, node{ID: v, Dist: res.Dist[v]})
+		}
+		for _, e := range res.Frontier {
+			out.Frontier = append(out.Frontier, edge{From: e.From, To: e.To, Weight: e.Weight, Reach: e.Reach})
+		}
+		return writeJSON(w, out)
+	}
+
+	for _, v := range nodes {
+		fmt.Fprintf(w, "%d\t%v\n", v, res.Dist[v])
+	}
+	for _, e := range res.Frontier {
+		fmt.Fprintf(w, "frontier %d -> %d\treach %v of %v\n", e.From, e.To, e.Reach, e.Weight)
+	}
+	return nil
+}
+
+func bench(w io.Writer, g *bmssp.Graph, source bmssp.NodeID, runs int, jsonOut bool) error {
+	if runs < 1 {
+		return fmt.Errorf("bench: -runs must be positive")
+	}
+	S := bmssp.NewNodeSet()
+	S.Add(source)
+	if err := check(g, S); err != nil {
+		return err
+	}
+
+	measure := func(solve func() *bmssp.Result) (time.Duration, bmssp.Stats) {
+		var best time.Duration
+		var stats bmssp.Stats
+		for i := range runs {
+			res := solve()
+			if i == 0 || res.Stats.Duration < best {
+				best, stats = res.Stats.Duration, res.Stats
+			}
+		}
+		return best, stats
+	}
+	bmsspTime, bmsspStats := measure(func() *bmssp.Result { return bmssp.Solve(g, S, bmssp.INF) })
+	dijkstraTime, dijkstraStats := measure(func() *bmssp.Result { return bmssp.SolveDijkstra(g, S) })
+
+	if jsonOut {
+		return writeJSON(w, map[string]any{
+			"runs":     runs,
+			"bmssp":    map[string]any{"best_ns": bmsspTime.Nanoseconds(), "nodes_settled": bmsspStats.NodesSettled, "edges_scanned": bmsspStats.EdgesScanned},
+			"dijkstra": map[string]any{"best_ns": dijkstraTime.Nanoseconds(), "nodes_settled": dijkstraStats.NodesSettled, "edges_scanned": dijkstraStats.EdgesScanned},
+		})
+	}
+	fmt.Fprintf(w, "best of %d runs\n", runs)
+	fmt.Fprintf(w, "bmssp     %v\t%d nodes settled, %d edges scanned\n", bmsspTime, bmsspStats.NodesSettled, bmsspStats.EdgesScanned)
+	fmt.Fprintf(w, "dijkstra  %v\t%d nodes settled, %d edges scanned\n", dijkstraTime, dijkstraStats.NodesSettled, dijkstraStats.EdgesScanned)
+	if bmsspTime > 0 {
+		fmt.Fprintf(w, "speedup   %.2fx\n", float64(dijkstraTime)/float64(bmsspTime))
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mfreeman451/bmssp-go"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	gr := filepath.Join(dir, "g.gr")
+	if err := os.WriteFile(gr, []byte("p sp 4 4\na 1 2 3\na 2 3 4\na 1 3 10\na 3 4 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	csv := filepath.Join(dir, "g.csv")
+	if err := os.WriteFile(csv, []byte("1,2,3\n2,3,4\n1,3,10\n3,4,1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	bin := filepath.Join(dir, "g.bin")
+	f, err := os.Create(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, _ := bmssp.ReadDIMACS(strings.NewReader("p sp 4 4\na 1 2 3\na 2 3 4\na 1 3 10\na 3 4 1\n"))
+	if _, err := g.WriteTo(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"sssp", "-graph", gr, "-source", "1"}, "1\t0\n2\t3\n3\t7\n4\t8\n"},
+		{[]string{"sssp", "-graph", csv, "-source", "1", "-bound", "5"}, "1\t0\n2\t3\n"},
+		{[]string{"p2p", "-graph", bin, "-from", "1", "-to", "4"}, "cost 8: 1 -> 2 -> 3 -> 4\n"},
+		{[]string{"p2p", "-graph", gr, "-from", "4", "-to", "1"}, "1 is unreachable from 4\n"},
+		{[]string{"isochrone", "-graph", gr, "-source", "1", "-bound", "5"}, "1\t0\n2\t3\nfrontier 1 -> 3\treach 5 of 10\nfrontier 2 -> 3\treach 2 of 4\n"},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		if err := run(tt.args, &out); err != nil {
+			t.Errorf("%v: %v", tt.args, err)
+			continue
+		}
+		if out.String() != tt.want {
+			t.Errorf("%v: expected %q, got %q", tt.args, tt.want, out.String())
+		}
+	}
+
+	var out strings.Builder
+	if err := run([]string{"p2p", "-graph", gr, "-from", "1", "-to", "4", "-json"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	var route struct {
+		Path []int   `json:"path"`
+		Cost float64 `json:"cost"`
+	}
+	if err := json.Unmarshal([]byte(out.String()), &route); err != nil || route.Cost != 8 || len(route.Path) != 4 {
+		t.Errorf("expected a JSON route of cost 8, got %s (%v)", out.String(), err)
+	}
+
+	out.Reset()
+	if err := run([]string{"bench", "-graph", gr, "-source", "1", "-runs", "2"}, &out); err != nil || !strings.Contains(out.String(), "best of 2 runs") {
+		t.Errorf("expected a benchmark report, got %q (%v)", out.String(), err)
+	}
+	if err := run([]string{"sssp", "-graph", gr, "-source", "9"}, &out); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+	if err := run([]string{"frobnicate"}, &out); err == nil {
+		t.Error("expected an error for an unknown command")
+	}
+}
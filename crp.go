@@ -0,0 +1,238 @@
+package bmssp
+
+import (
+	"slices"
+	"sync"
+)
+
+// defaultCellSizes are the maximum cell sizes of the overlay levels used
+// when NewOverlayGraph is given none, finest first.
+var defaultCellSizes = []int{64, 1024, 16384}
+
+// OverlayGraph is a customizable route planning (CRP) index. The nodes are
+// partitioned into nested cells on several levels; every cell keeps a
+// clique of shortcuts from the nodes where it is entered to those where it
+// is left. A query only descends into the cells of its endpoints and
+// crosses every other cell along its clique, on the coarsest level that
+// separates the cell from both endpoints.
+//
+// The partition depends only on the graph's topology, so a new metric,
+// e.g. live traffic, is applied by Customize, which recomputes the cliques
+// in a fraction of the time of rebuilding the index. An OverlayGraph is
+// safe for concurrent use, including Customize while queries run; the
+// graph must not be mutated meanwhile.
+type OverlayGraph struct {
+	g     *Graph
+	sizes []int            // maximum cell size of every level, finest first
+	cell  map[NodeID][]int // cell of every node on every level
+	entry []NodeSet        // nodes entered from another cell, per level
+	exit  []NodeSet        // nodes left to another cell, per level
+
+	mu     sync.RWMutex
+	metric *overlayMetric
+}
+
+// overlayMetric holds the customized weights of an OverlayGraph.
+type overlayMetric struct {
+	weight func(u NodeID, e Edge) Dist // nil for the graph's weights
+	clique []map[NodeID][]Edge         // shortcuts from every entry node, per level
+}
+
+// NewOverlayGraph partitions g into nested cells and customizes the
+// overlay with the graph's own weights.
+//
+// Parameters:
+//   - g: input graph (not modified)
+//   - cellSizes: maximum number of nodes per cell on every level, finest
+//     first and increasing; nil for 64, 1024 and 16384. Levels whose cells
+//     would hold the whole graph are dropped.
+//
+// Returns:
+//   - the customized overlay graph
+func NewOverlayGraph(g *Graph, cellSizes []int) *OverlayGraph {
+	if cellSizes == nil {
+		cellSizes = defaultCellSizes
+	}
+	o := &OverlayGraph{g: g, cell: make(map[NodeID][]int, len(g.adj))}
+	for _, size := range cellSizes {
+		if size > 0 && size < len(g.adj) {
+			o.sizes = append(o.sizes, size)
+		}
+	}
+	levels := len(o.sizes)
+	nodes := make([]NodeID, 0, len(g.adj))
+	for v := range g.adj {
+		nodes = append(nodes, v)
+		o.cell[v] = make([]int, levels)
+	}
+	slices.Sort(nodes)
+	o.carve(nodes, levels, make([]int, levels))
+
+	o.entry, o.exit = make([]NodeSet, levels), make([]NodeSet, levels)
+	for l := range levels {
+		o.entry[l], o.exit[l] = NewNodeSet(), NewNodeSet()
+	}
+	for u, edges := range g.adj {
+		for _, e := range edges {
+			for l := range levels {
+				if o.cell[u][l] != o.cell[e.To][l] {
+					o.exit[l].Add(u)
+					o.entry[l].Add(e.To)
+				}
+			}
+		}
+	}
+	o.Customize(nil)
+	return o
+}
+
+// carve assigns the cells of the given level and the finer ones below to
+// nodes by recursive bisection; next holds the next free cell ID per level.
+func (o *OverlayGraph) carve(nodes []NodeID, level int, next []int) {
+	if level == 0 {
+		return
+	}
+	for _, part := range splitUntil(o.g, nodes, o.sizes[level-1]) {
+		id := next[level-1]
+		next[level-1]++
+		for _, v := range part {
+			o.cell[v][level-1] = id
+		}
+		o.carve(part, level-1, next)
+	}
+}
+
+// Levels returns the number of overlay levels.
+func (o *OverlayGraph) Levels() int {
+	return len(o.sizes)
+}
+
+// Customize recomputes the cliques for a new metric, level by level and
+// the cells of a level in parallel. Queries running meanwhile complete on
+// the previous metric.
+//
+// Parameters:
+//   - weight: the weight of every edge e leaving u, INF to close it; nil
+//     for the graph's weights
+func (o *OverlayGraph) Customize(weight func(u NodeID, e Edge) Dist) {
+	m := &overlayMetric{weight: weight, clique: make([]map[NodeID][]Edge, len(o.sizes))}
+	for l := range o.sizes {
+		m.clique[l] = make(map[NodeID][]Edge, len(o.entry[l]))
+		exits := make(map[int][]NodeID)
+		for v := range o.exit[l] {
+			exits[o.cell[v][l]] = append(exits[o.cell[v][l]], v)
+		}
+		entries := make([]NodeID, 0, len(o.entry[l]))
+		for v := range o.entry[l] {
+			entries = append(entries, v)
+			m.clique[l][v] = make([]Edge, len(exits[o.cell[v][l]]))
+		}
+		// Every entry writes only its own preallocated clique
+		parallelFor(len(entries), 0, func(i int) {
+			v := entries[i]
+			c := o.cell[v][l]
+			// Cells on level l are crossed along the cliques of level l-1,
+			// the finest ones along the original edges
+			dist := o.search(m, v,
+				func(NodeID) int { return l },
+				func(u NodeID) bool { return o.cell[u][l] == c },
+				nil)
+			for j, x := range exits[c] {
+				m.clique[l][v][j] = Edge{To: x, Weight: o.distOf(dist, x)}
+			}
+		})
+	}
+
+	o.mu.Lock()
+	o.metric = m
+	o.mu.Unlock()
+}
+
+// distOf returns dist[v], INF if v was not reached.
+func (o *OverlayGraph) distOf(dist map[NodeID]Dist, v NodeID) Dist {
+	if d, ok := dist[v]; ok {
+		return d
+	}
+	return INF
+}
+
+// search runs Dijkstra's algorithm from s over the overlay. Every node u
+// is scanned on level(u): level 0 scans the original edges, level l > 0
+// the clique of u on level l and the original edges leaving u's cell on
+// that level. Nodes for which inside returns false are not entered, unless
+// inside is nil. The search stops once target is settled, if target is not
+// nil.
+func (o *OverlayGraph) search(m *overlayMetric, s NodeID, level func(u NodeID) int, inside func(v NodeID) bool, target *NodeID) map[NodeID]Dist {
+	dist := map[NodeID]Dist{s: 0}
+	pq := BinaryHeap()
+	pq.Push(s, 0)
+	for pq.Len() > 0 {
+		u, d, _ := pq.Pop()
+		if d > dist[u] {
+			continue
+		}
+		if target != nil && u == *target {
+			break
+		}
+		relax := func(v NodeID, w Dist) {
+			if w == INF || inside != nil && !inside(v) {
+				return
+			}
+			if old, ok := dist[v]; !ok || d+w < old {
+				dist[v] = d + w
+				pq.Push(v, d+w)
+			}
+		}
+		l := level(u)
+		if l > 0 {
+			for _, e := range m.clique[l-1][u] {
+				relax(e.To, e.Weight)
+			}
+		}
+		for _, e := range o.g.adj[u] {
+			if l > 0 && o.cell[u][l-1] == o.cell[e.To][l-1] {
+				continue
+			}
+			relax(e.To, m.edgeWeight(u, e))
+		}
+	}
+	return dist
+}
+
+// edgeWeight returns the weight of e leaving u under the metric.
+func (m *overlayMetric) edgeWeight(u NodeID, e Edge) Dist {
+	if m.weight != nil {
+		return m.weight(u, e)
+	}
+	return e.Weight
+}
+
+// Distance returns the shortest distance from s to t under the current
+// metric.
+//
+// Returns:
+//   - the distance; INF if t is unreachable or either node is not in the
+//     graph
+func (o *OverlayGraph) Distance(s, t NodeID) Dist {
+	if _, ok := o.cell[s]; !ok {
+		return INF
+	}
+	if _, ok := o.cell[t]; !ok {
+		return INF
+	}
+	o.mu.RLock()
+	m := o.metric
+	o.mu.RUnlock()
+
+	dist := o.search(m, s, func(u NodeID) int {
+		// The coarsest level separating u's cell from both endpoints
+		for l := len(o.sizes); l > 0; l-- {
+			c := o.cell[u][l-1]
+			if c != o.cell[s][l-1] && c != o.cell[t][l-1] {
+				return l
+			}
+		}
+		return 0
+	}, nil, &t)
+	return o.distOf(dist, t)
+}
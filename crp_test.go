@@ -0,0 +1,47 @@
+package bmssp
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestOverlayGraph(t *testing.T) {
+	for name, g := range map[string]*Graph{
+		"grid":   generateGridGraph(30, 30),
+		"random": generateRandomGraph(400, 1600, 10, 5),
+	} {
+		o := NewOverlayGraph(g, []int{16, 128})
+		if o.Levels() != 2 {
+			t.Fatalf("%s: expected 2 levels, got %d", name, o.Levels())
+		}
+		check := func(metric string, opts ...Option) {
+			rng := rand.New(rand.NewSource(1))
+			for range 40 {
+				s, target := NodeID(rng.Intn(len(g.adj))), NodeID(rng.Intn(len(g.adj)))
+				want := SolveDijkstra(g, sources(s), opts...).Dist[target]
+				if got := o.Distance(s, target); got != want && !approxEqual(got, want, 1e-9) {
+					t.Errorf("%s, %s metric: %d -> %d: expected %v, got %v", name, metric, s, target, want, got)
+				}
+			}
+		}
+		check("initial")
+
+		// Slow down and close some edges, as live traffic would
+		traffic := func(u NodeID, e Edge) Dist {
+			switch {
+			case (u+e.To)%7 == 0:
+				return INF
+			case u%3 == 0:
+				return 4 * e.Weight
+			}
+			return e.Weight
+		}
+		o.Customize(traffic)
+		check("traffic", WithWeightFunc(traffic))
+	}
+
+	o := NewOverlayGraph(generateGridGraph(5, 5), nil)
+	if o.Levels() != 0 || o.Distance(0, 24) != 8 || o.Distance(0, 99) != INF {
+		t.Errorf("expected a single-level search on a small graph, got %d levels", o.Levels())
+	}
+}
@@ -0,0 +1,179 @@
+package bmssp
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrBadCSV is returned for edge-list files that cannot be interpreted.
+var ErrBadCSV = errors.New("bmssp: malformed edge list")
+
+// CSVOptions configures ReadEdgeListCSV. The zero value reads
+// "from,to,weight" rows without a header.
+type CSVOptions struct {
+	Comma   rune // field delimiter (default ',')
+	Comment rune // lines starting with this rune are ignored (default: none)
+
+	// Header makes the first row a header; columns are then selected by the
+	// names in FromColumn, ToColumn and WeightColumn (default "from", "to"
+	// and "weight"). Without a weight column every edge has weight 1.
+	Header       bool
+	FromColumn   string
+	ToColumn     string
+	WeightColumn string
+
+	// Columns selects the from, to and weight columns by 0-based index when
+	// there is no header (default {0, 1, 2}). With two entries every edge has
+	// weight 1.
+	Columns []int
+
+	// IDs interns node keys such as OSM IDs or hostnames, numbering nodes
+	// densely from 0 in order of first appearance; the mapper translates
+	// results back to the original keys. Without it node columns must hold
+	// integers, which become node IDs as they are.
+	IDs *IDMapper[string]
+
+	Undirected bool // add every edge in both directions
+}
+
+// ReadEdgeListCSV reads a graph from a delimited edge list, one edge per row.
+// Rows are streamed, so only the graph itself is held in memory.
+//
+// Returns:
+//   - the graph
+//   - ErrBadCSV for missing columns or unparsable node IDs, ErrInvalidWeight
+//     for negative or NaN weights, or the reader's error
+func ReadEdgeListCSV(r io.Reader, opts CSVOptions) (*Graph, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = ','
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	cr.Comment = opts.Comment
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	cols := opts.Columns
+	if cols == nil {
+		cols = []int{0, 1, 2}
+	}
+	if opts.Header {
+		header, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("%w: reading header: %v", ErrBadCSV, err)
+		}
+		var err2 error
+		if cols, err2 = headerColumns(header, opts); err2 != nil {
+			return nil, err2
+		}
+	}
+	if len(cols) < 2 {
+		return nil, fmt.Errorf("%w: need from and to columns", ErrBadCSV)
+	}
+
+	g := NewGraph()
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return g, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		from, err := parseNode(rec, cols[0], opts.IDs)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		to, err := parseNode(rec, cols[1], opts.IDs)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		w := Dist(1)
+		if len(cols) > 2 && cols[2] >= 0 {
+			if w, err = parseWeight(rec, cols[2]); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+
+		g.AddEdge(from, to, w)
+		if opts.Undirected && from != to {
+			g.AddEdge(to, from, w)
+		}
+	}
+}
+
+// headerColumns maps the configured column names to indices.
+func headerColumns(header []string, opts CSVOptions) ([]int, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+	pick := func(name, def string) (int, bool) {
+		if name == "" {
+			name = def
+		}
+		i, ok := index[name]
+		return i, ok
+	}
+
+	from, ok := pick(opts.FromColumn, "from")
+	if !ok {
+		return nil, fmt.Errorf("%w: no from column in header %q", ErrBadCSV, header)
+	}
+	to, ok := pick(opts.ToColumn, "to")
+	if !ok {
+		return nil, fmt.Errorf("%w: no to column in header %q", ErrBadCSV, header)
+	}
+	weight, ok := pick(opts.WeightColumn, "weight")
+	if !ok {
+		if opts.WeightColumn != "" {
+			return nil, fmt.Errorf("%w: no %s column in header %q", ErrBadCSV, opts.WeightColumn, header)
+		}
+		weight = -1
+	}
+	return []int{from, to, weight}, nil
+}
+
+// field returns column i of rec.
+func field(rec []string, i int) (string, error) {
+	if i < 0 || i >= len(rec) {
+		return "", fmt.Errorf("%w: row has %d columns, need column %d", ErrBadCSV, len(rec), i+1)
+	}
+	return strings.TrimSpace(rec[i]), nil
+}
+
+func parseNode(rec []string, i int, ids *IDMapper[string]) (NodeID, error) {
+	s, err := field(rec, i)
+	if err != nil {
+		return 0, err
+	}
+	if ids != nil {
+		return ids.Intern(s), nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: node %q is not an integer", ErrBadCSV, s)
+	}
+	return NodeID(v), nil
+}
+
+func parseWeight(rec []string, i int) (Dist, error) {
+	s, err := field(rec, i)
+	if err != nil {
+		return 0, err
+	}
+	w, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: weight %q is not a number", ErrBadCSV, s)
+	}
+	if !validWeight(Dist(w)) {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidWeight, w)
+	}
+	return Dist(w), nil
+}
@@ -0,0 +1,69 @@
+package bmssp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReadEdgeListCSV(t *testing.T) {
+	g, err := ReadEdgeListCSV(strings.NewReader("0,1,2\n1,2,3.5\n0,2,9\n"), CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, d := ShortestPath(g, 0, 2); d != 5.5 {
+		t.Errorf("expected distance 5.5, got %v", d)
+	}
+}
+
+func TestReadEdgeListCSV_Header(t *testing.T) {
+	data := "# road network\ncost;dst;src\n4;b;a\n1;c;b\n"
+	ids := NewIDMapper[string]()
+	g, err := ReadEdgeListCSV(strings.NewReader(data), CSVOptions{
+		Comma:        ';',
+		Comment:      '#',
+		Header:       true,
+		FromColumn:   "src",
+		ToColumn:     "dst",
+		WeightColumn: "cost",
+		IDs:          ids,
+		Undirected:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := ids.ID("c")
+	a, _ := ids.ID("a")
+	if _, d := ShortestPath(g, c, a); d != 5 {
+		t.Errorf("expected undirected distance 5 from c to a, got %v", d)
+	}
+}
+
+func TestReadEdgeListCSV_Columns(t *testing.T) {
+	// Unweighted edges in columns 1 and 2
+	g, err := ReadEdgeListCSV(strings.NewReader("x,0,1\ny,1,2\n"), CSVOptions{Columns: []int{1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, d := ShortestPath(g, 0, 2); d != 2 {
+		t.Errorf("expected 2 unit edges, got %v", d)
+	}
+}
+
+func TestReadEdgeListCSV_Errors(t *testing.T) {
+	tests := []struct {
+		data string
+		opts CSVOptions
+		want error
+	}{
+		{"0,1,-2\n", CSVOptions{}, ErrInvalidWeight},
+		{"0,x,2\n", CSVOptions{}, ErrBadCSV},
+		{"0,1\n", CSVOptions{}, ErrBadCSV},
+		{"a,b\n0,1\n", CSVOptions{Header: true}, ErrBadCSV},
+	}
+	for _, tt := range tests {
+		if _, err := ReadEdgeListCSV(strings.NewReader(tt.data), tt.opts); !errors.Is(err, tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.data, tt.want, err)
+		}
+	}
+}
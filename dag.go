@@ -0,0 +1,168 @@
+package bmssp
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrCycle is returned when an operation requiring an acyclic graph finds a
+// cycle.
+var ErrCycle = errors.New("bmssp: graph has a cycle")
+
+// TopologicalOrder returns the nodes of g ordered so that every edge leads
+// from an earlier node to a later one. Ties are broken by ascending node ID,
+// so the order is deterministic.
+//
+// Parameters:
+//   - g: input graph
+//
+// Returns:
+//   - the nodes in topological order
+//   - ErrCycle if g is not acyclic
+func TopologicalOrder(g *Graph) ([]NodeID, error) {
+	indeg := make(map[NodeID]int, len(g.adj))
+	for _, edges := range g.adj {
+		for _, e := range edges {
+			indeg[e.To]++
+		}
+	}
+	order := make([]NodeID, 0, len(g.adj))
+	for u := range g.adj {
+		if indeg[u] == 0 {
+			order = append(order, u)
+		}
+	}
+	slices.Sort(order)
+
+	// order doubles as the FIFO queue of Kahn's algorithm
+	for i := 0; i < len(order); i++ {
+		for _, e := range g.adj[order[i]] {
+			if indeg[e.To]--; indeg[e.To] == 0 {
+				order = append(order, e.To)
+			}
+		}
+	}
+	if len(order) < len(g.adj) {
+		return nil, fmt.Errorf("%w: %d of %d nodes lie on or behind a cycle", ErrCycle, len(g.adj)-len(order), len(g.adj))
+	}
+	return order, nil
+}
+
+// WithDAG hints that the part of the graph reachable from the sources is
+// acyclic, e.g. a dependency or scheduling graph. The query then relaxes the
+// edges once in topological order, in linear time, instead of running the
+// general algorithm; if a cycle is found it falls back to the latter. Frozen
+// acyclic graphs take this path without the hint.
+//
+// Nodes are settled in topological rather than distance order, which
+// Visitors observe.
+func WithDAG() Option {
+	return func(c *config) { c.dag = true }
+}
+
+// dagOrder returns a topological order covering every node the query from S
+// can reach, or nil if the query cannot use the DAG fast path.
+func (s *solver) dagOrder(S NodeSet) []NodeID {
+	if s.cfg.noFastPaths || s.cfg.maxHops > 0 || s.emit != nil {
+		return nil
+	}
+	if s.g != nil && s.g.topo != nil && s.cfg.extra == nil {
+		return s.g.topo
+	}
+	if !s.cfg.dag || s.nb != nil {
+		return nil
+	}
+	return s.reachableOrder(S)
+}
+
+// reachableOrder orders the nodes reachable from S topologically by a
+// depth-first search, returning nil if it finds a cycle.
+func (s *solver) reachableOrder(S NodeSet) []NodeID {
+	const (
+		open = iota + 1 // on the DFS stack
+		done
+	)
+	type frame struct {
+		u     NodeID
+		edges []Edge
+	}
+	state := make(map[NodeID]int)
+	var post []NodeID
+	var stack []frame
+	for src := range S {
+		if state[src] != 0 {
+			continue
+		}
+		state[src] = open
+		// Edge slices are cloned: outEdges may reuse its buffer
+		stack = append(stack, frame{src, slices.Clone(s.outEdges(src))})
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if len(top.edges) == 0 {
+				state[top.u] = done
+				post = append(post, top.u)
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			v := top.edges[0].To
+			top.edges = top.edges[1:]
+			switch state[v] {
+			case open:
+				return nil
+			case 0:
+				state[v] = open
+				stack = append(stack, frame{v, slices.Clone(s.outEdges(v))})
+			}
+		}
+	}
+	slices.Reverse(post)
+	return post
+}
+
+// dag settles the nodes of order, a topological order, within bound B. Each
+// node's distance is final once its predecessors are done, so every edge is
+// relaxed exactly once.
+func (s *solver) dag(order []NodeID, B Dist) {
+	s.bound = B
+	if s.cfg.tracer != nil {
+		end := s.beginSpan("bmssp.dag", Attr{"nodes", len(order)}, Attr{"bound", float64(B)})
+		defer func() {
+			end(Attr{"nodes_settled", s.stats.NodesSettled}, Attr{"stopped", s.stopReason().String()})
+		}()
+	}
+	pending := len(s.cfg.targets)
+	if s.cfg.targets != nil && pending == 0 {
+		s.stopped = StopTargets
+		return
+	}
+
+	for _, u := range order {
+		if d := s.dist(u); d == INF || d > B {
+			continue
+		}
+		if s.shouldStop() {
+			return
+		}
+		if s.cfg.targets.Has(u) {
+			if pending--; pending == 0 {
+				s.stopped = StopTargets
+				return
+			}
+		}
+		s.settle(u)
+
+		for _, e := range s.outEdges(u) {
+			s.stats.EdgesScanned++
+			d := s.dhat[u] + s.weight(u, e)
+			if !s.improves(u, e.To, d) {
+				continue
+			}
+			if d > B {
+				s.cut = true
+				continue
+			}
+			s.relax(u, e.To, d)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package bmssp
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// generateDAG creates a random DAG whose edges lead from lower to higher IDs.
+func generateDAG(n, m int, seed int64) *Graph {
+	rng := rand.New(rand.NewSource(seed))
+	g := NewGraph()
+	for range m {
+		u, v := rng.Intn(n), rng.Intn(n)
+		if u == v {
+			continue
+		}
+		g.AddEdge(NodeID(min(u, v)), NodeID(max(u, v)), Dist(rng.Float64()*10))
+	}
+	return g
+}
+
+func TestTopologicalOrder(t *testing.T) {
+	g := generateDAG(200, 800, 1)
+	order, err := TopologicalOrder(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(order) != len(g.adj) {
+		t.Fatalf("expected %d nodes, got %d", len(g.adj), len(order))
+	}
+	pos := make(map[NodeID]int, len(order))
+	for i, u := range order {
+		pos[u] = i
+	}
+	for u, edges := range g.adj {
+		for _, e := range edges {
+			if pos[u] >= pos[e.To] {
+				t.Fatalf("edge %d->%d goes backwards", u, e.To)
+			}
+		}
+	}
+
+	g.AddEdge(150, 3, 1)
+	if _, err := TopologicalOrder(g); !errors.Is(err, ErrCycle) {
+		t.Errorf("expected ErrCycle, got %v", err)
+	}
+}
+
+func TestWithDAG(t *testing.T) {
+	g := generateDAG(300, 1500, 2)
+	ref := SolveDijkstra(g, sources(0, 40))
+
+	for _, B := range []Dist{INF, 15} {
+		res := Solve(g, sources(0, 40), B, WithDAG())
+		if res.Stats.Delta != 0 || res.Stats.EdgesScanned > g.numEdges {
+			t.Errorf("bound %v: expected one linear pass, got stats %+v", B, res.Stats)
+		}
+		for v, want := range ref.Dist {
+			if want > B {
+				if res.Dist[v] <= B {
+					t.Errorf("bound %v: node %d reported at %v beyond the bound", B, v, res.Dist[v])
+				}
+				continue
+			}
+			if res.Dist[v] != want {
+				t.Fatalf("bound %v: node %d: expected %v, got %v", B, v, want, res.Dist[v])
+			}
+		}
+	}
+
+	// A cycle falls back to the general algorithm
+	g.AddEdge(299, 0, 1)
+	want := SolveDijkstra(g, sources(0)).Dist
+	got := Solve(g, sources(0), INF, WithDAG())
+	if got.Stats.Delta == 0 {
+		t.Error("expected the general algorithm on a cyclic graph")
+	}
+	for v, d := range want {
+		if got.Dist[v] != d {
+			t.Fatalf("node %d: expected %v, got %v", v, d, got.Dist[v])
+		}
+	}
+}
+
+func TestFreeze_DAG(t *testing.T) {
+	g := generateDAG(100, 400, 3)
+	g.Freeze()
+	if g.topo == nil {
+		t.Fatal("expected Freeze to order an acyclic graph")
+	}
+	res := Solve(g, sources(0), INF, WithTargets(sources(99)))
+	ref := SolveDijkstra(g, sources(0))
+	if res.Dist[99] != ref.Dist[99] || res.Stopped != StopTargets && ref.Dist[99] < INF {
+		t.Errorf("expected %v at target 99, got %v (%v)", ref.Dist[99], res.Dist[99], res.Stopped)
+	}
+	if path := res.PathTo(99); ref.Dist[99] < INF && (len(path) == 0 || path[0] != 0 || path[len(path)-1] != 99) {
+		t.Errorf("unexpected path %v", path)
+	}
+
+	cyclic := NewGraph()
+	cyclic.AddEdge(0, 1, 1)
+	cyclic.AddEdge(1, 0, 1)
+	cyclic.Freeze()
+	if cyclic.topo != nil {
+		t.Error("cyclic graphs have no topological order")
+	}
+}
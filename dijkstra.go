@@ -162,3 +162,76 @@ func DijkstraSingleSource(g *Graph, source NodeID, dhat map[NodeID]Dist) {
 		}
 	}
 }
+
+// dijkstra runs a heap-based Dijkstra from the nodes of S within bound B,
+// honoring the solver's query settings and collecting its statistics.
+// Nodes are settled in distance order with final distances, which are passed
+// to s.emit if set.
+func (s *solver) dijkstra(S NodeSet, B Dist) {
+	newQueue := s.cfg.newQueue
+	if newQueue == nil {
+		newQueue = BinaryHeap
+	}
+	pq := newQueue()
+	s.bound = B
+	defer s.report("dijkstra")
+	if s.cfg.tracer != nil {
+		end := s.beginSpan("bmssp.dijkstra", Attr{"sources", len(S)}, Attr{"bound", float64(B)})
+		defer func() {
+			end(Attr{"nodes_settled", s.stats.NodesSettled}, Attr{"stopped", s.stopReason().String()})
+		}()
+	}
+	for v := range S {
+		pq.Push(v, s.dhat[v])
+		s.stats.QueueOps++
+	}
+	settled := NewNodeSet()
+	pending := len(s.cfg.targets)
+	if s.cfg.targets != nil && pending == 0 {
+		s.stopped = StopTargets
+		return
+	}
+
+	for pq.Len() > 0 {
+		u, du, _ := pq.Pop()
+		s.stats.QueueOps++
+
+		// Skip stale entries left behind by later improvements
+		if settled.Has(u) || du > s.dhat[u] {
+			continue
+		}
+		settled.Add(u)
+		if s.shouldStop() {
+			return
+		}
+		if s.emit != nil && !s.emit(u, s.dhat[u]) {
+			s.stopped = StopCanceled
+			return
+		}
+		if s.cfg.targets.Has(u) {
+			if pending--; pending == 0 {
+				s.stopped = StopTargets
+				return
+			}
+		}
+		s.settle(u)
+		if !s.canExtend(u) {
+			continue
+		}
+
+		for _, e := range s.outEdges(u) {
+			s.stats.EdgesScanned++
+			d := s.dhat[u] + s.weight(u, e)
+			if !s.improves(u, e.To, d) {
+				continue
+			}
+			if d > B {
+				s.cut = true
+				continue
+			}
+			s.relax(u, e.To, d)
+			pq.Push(e.To, d)
+			s.stats.QueueOps++
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package bmssp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrBadDIMACS is returned for input that is not a DIMACS shortest-path graph.
+var ErrBadDIMACS = errors.New("bmssp: malformed DIMACS graph")
+
+// ReadDIMACS reads a graph in the DIMACS shortest-path challenge format
+// (.gr): comment lines starting with "c", a problem line "p sp <n> <m>" and
+// arc lines "a <u> <v> <w>". Node IDs are kept as written, so nodes are
+// numbered from 1, and all n nodes become part of the graph even without
+// arcs.
+//
+// Returns:
+//   - the graph
+//   - ErrBadDIMACS for malformed lines or a missing problem line,
+//     ErrInvalidWeight for negative or NaN weights, or the reader's error
+func ReadDIMACS(r io.Reader) (*Graph, error) {
+	g := NewGraph()
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	seenProblem := false
+	for line := 1; sc.Scan(); line++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		switch fields[0] {
+		case "p":
+			if len(fields) != 4 || fields[1] != "sp" {
+				return nil, fmt.Errorf("%w: line %d: expected \"p sp <n> <m>\"", ErrBadDIMACS, line)
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%w: line %d: bad node count %q", ErrBadDIMACS, line, fields[2])
+			}
+			for v := 1; v <= n; v++ {
+				if _, ok := g.adj[NodeID(v)]; !ok {
+					g.adj[NodeID(v)] = nil
+				}
+			}
+			seenProblem = true
+		case "a":
+			if !seenProblem {
+				return nil, fmt.Errorf("%w: line %d: arc before problem line", ErrBadDIMACS, line)
+			}
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("%w: line %d: expected \"a <u> <v> <w>\"", ErrBadDIMACS, line)
+			}
+			u, err1 := strconv.Atoi(fields[1])
+			v, err2 := strconv.Atoi(fields[2])
+			w, err3 := strconv.ParseFloat(fields[3], 64)
+			if err1 != nil || err2 != nil || err3 != nil {
+				return nil, fmt.Errorf("%w: line %d: bad arc %q", ErrBadDIMACS, line, sc.Text())
+			}
+			if !validWeight(Dist(w)) {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidWeight, line, w)
+			}
+			g.AddEdge(NodeID(u), NodeID(v), Dist(w))
+		default:
+			return nil, fmt.Errorf("%w: line %d: unknown line type %q", ErrBadDIMACS, line, fields[0])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if !seenProblem {
+		return nil, fmt.Errorf("%w: no problem line", ErrBadDIMACS)
+	}
+	return g, nil
+}
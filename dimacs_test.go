@@ -0,0 +1,41 @@
+package bmssp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReadDIMACS(t *testing.T) {
+	data := `c 9th DIMACS challenge sample
+p sp 4 4
+a 1 2 3
+a 2 3 4
+a 1 3 10
+c trailing comment
+a 3 1 1
+`
+	g, err := ReadDIMACS(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.adj) != 4 || g.numEdges != 4 {
+		t.Errorf("expected 4 nodes and 4 arcs, got %d and %d", len(g.adj), g.numEdges)
+	}
+	if _, d := ShortestPath(g, 1, 3); d != 7 {
+		t.Errorf("expected distance 7, got %v", d)
+	}
+
+	for name, data := range map[string]string{
+		"no problem line": "a 1 2 3\n",
+		"bad arc":         "p sp 2 1\na 1 x 3\n",
+		"unknown line":    "p sp 2 1\nn 1 2\n",
+	} {
+		if _, err := ReadDIMACS(strings.NewReader(data)); !errors.Is(err, ErrBadDIMACS) {
+			t.Errorf("%s: expected ErrBadDIMACS, got %v", name, err)
+		}
+	}
+	if _, err := ReadDIMACS(strings.NewReader("p sp 2 1\na 1 2 -1\n")); !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("expected ErrInvalidWeight, got %v", err)
+	}
+}
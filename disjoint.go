@@ -0,0 +1,220 @@
+package bmssp
+
+import (
+	"cmp"
+	"slices"
+)
+
+// DisjointPaths computes up to k edge-disjoint paths from s to t of minimum
+// total length, e.g. a working route and its backup for network resilience
+// planning. No edge is used by two paths; parallel edges count as distinct
+// edges. For k = 2 this is Suurballe's algorithm.
+//
+// The paths form a minimum-cost flow of k units over edges of capacity 1,
+// found by successive shortest paths: each round runs Dijkstra on the
+// residual graph, whose reverse arcs let a later path reroute an earlier
+// one, with Johnson potentials keeping the reduced costs non-negative.
+// Edges with weight INF are closed and never used.
+//
+// Parameters:
+//   - g: input graph (not modified)
+//   - s, t: endpoints of the paths
+//   - k: number of paths wanted
+//
+// Returns:
+//   - the paths by increasing length; fewer than k if s and t are not
+//     k-edge-connected, nil if t is unreachable
+func DisjointPaths(g *Graph, s, t NodeID, k int) [][]NodeID {
+	return disjointPaths(g, s, t, k, false)
+}
+
+// NodeDisjointPaths is DisjointPaths with no node other than s and t shared
+// between two paths, e.g. routes that survive the failure of any one
+// router. Every node is split into an entry and an exit joined by an arc of
+// capacity 1.
+//
+// Returns:
+//   - the paths by increasing length; fewer than k if s and t are not
+//     k-node-connected, nil if t is unreachable
+func NodeDisjointPaths(g *Graph, s, t NodeID, k int) [][]NodeID {
+	return disjointPaths(g, s, t, k, true)
+}
+
+// flowArc is an arc of the residual graph. Its reverse arc is rev in the
+// arc list of to.
+type flowArc struct {
+	to, rev  int
+	cap      int // residual capacity
+	capacity int // capacity of an original arc, 0 for a reverse arc
+	cost     Dist
+}
+
+// flowNetwork is the residual graph of a unit-capacity flow problem over
+// dense node indices.
+type flowNetwork struct {
+	arcs [][]flowArc
+}
+
+func (f *flowNetwork) addArc(u, v, capacity int, cost Dist) {
+	f.arcs[u] = append(f.arcs[u], flowArc{to: v, rev: len(f.arcs[v]), cap: capacity, capacity: capacity, cost: cost})
+	f.arcs[v] = append(f.arcs[v], flowArc{to: u, rev: len(f.arcs[u]) - 1, cost: -cost})
+}
+
+// augment pushes one unit of flow along a shortest residual path from src to
+// dst, reporting false if there is none. Costs are reduced by the
+// potentials pot, which are updated so that they stay non-negative.
+func (f *flowNetwork) augment(src, dst int, pot []Dist) bool {
+	n := len(f.arcs)
+	dist := make([]Dist, n)
+	for i := range dist {
+		dist[i] = INF
+	}
+	type step struct{ node, arc int }
+	prev := make([]step, n)
+	done := make([]bool, n)
+	dist[src] = 0
+	pq := BinaryHeap()
+	pq.Push(NodeID(src), 0)
+	for pq.Len() > 0 {
+		v, d, _ := pq.Pop()
+		u := int(v)
+		if done[u] || d > dist[u] {
+			continue
+		}
+		done[u] = true
+		for i, a := range f.arcs[u] {
+			if a.cap == 0 || done[a.to] {
+				continue
+			}
+			// Reduced costs are non-negative up to rounding
+			nd := dist[u] + max(a.cost+pot[u]-pot[a.to], 0)
+			if nd < dist[a.to] {
+				dist[a.to] = nd
+				prev[a.to] = step{u, i}
+				pq.Push(NodeID(a.to), nd)
+			}
+		}
+	}
+	if dist[dst] == INF {
+		return false
+	}
+	// Nodes not reached now stay unreachable: new residual arcs only join
+	// reached nodes.
+	for v, d := range dist {
+		if d < INF {
+			pot[v] += d
+		}
+	}
+	for v := dst; v != src; v = prev[v].node {
+		a := &f.arcs[prev[v].node][prev[v].arc]
+		a.cap--
+		f.arcs[a.to][a.rev].cap++
+	}
+	return true
+}
+
+// disjointPaths builds the flow network of g and augments up to k paths.
+// With splitNodes, node i of g becomes an entry 2i and an exit 2i+1.
+func disjointPaths(g *Graph, s, t NodeID, k int, splitNodes bool) [][]NodeID {
+	_, okS := g.adj[s]
+	_, okT := g.adj[t]
+	if !okS || !okT || s == t || k <= 0 {
+		return nil
+	}
+	nodes := make([]NodeID, 0, len(g.adj))
+	for u := range g.adj {
+		nodes = append(nodes, u)
+	}
+	slices.Sort(nodes)
+	index := make(map[NodeID]int, len(nodes))
+	for i, u := range nodes {
+		index[u] = i
+	}
+
+	// in and out give the flow nodes that edges enter and leave, and node
+	// the graph node a flow node stands for
+	in, out := func(i int) int { return i }, func(i int) int { return i }
+	node := func(v int) NodeID { return nodes[v] }
+	f := &flowNetwork{arcs: make([][]flowArc, len(nodes))}
+	if splitNodes {
+		in, out = func(i int) int { return 2 * i }, func(i int) int { return 2*i + 1 }
+		node = func(v int) NodeID { return nodes[v/2] }
+		f.arcs = make([][]flowArc, 2*len(nodes))
+		for i, u := range nodes {
+			capacity := 1
+			if u == s || u == t {
+				capacity = k
+			}
+			f.addArc(in(i), out(i), capacity, 0)
+		}
+	}
+	for _, u := range nodes {
+		for _, e := range g.adj[u] {
+			if e.Weight == INF || e.To == u {
+				continue
+			}
+			f.addArc(out(index[u]), in(index[e.To]), 1, e.Weight)
+		}
+	}
+
+	src, dst := out(index[s]), in(index[t])
+	flows := 0
+	pot := make([]Dist, len(f.arcs))
+	for flows < k && f.augment(src, dst, pot) {
+		flows++
+	}
+
+	// Decompose the flow into paths, consuming a unit of flow per arc taken
+	paths := make([][]NodeID, 0, flows)
+	lengths := make([]Dist, flows)
+	for p := range flows {
+		path := []NodeID{s}
+		for v := src; v != dst; {
+			for i := range f.arcs[v] {
+				a := &f.arcs[v][i]
+				if a.capacity-a.cap > 0 {
+					a.cap++
+					lengths[p] += a.cost
+					if v = a.to; node(v) != path[len(path)-1] {
+						path = append(path, node(v))
+					}
+					break
+				}
+			}
+		}
+		paths = append(paths, withoutCycles(path))
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	order := make([]int, len(paths))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(lengths[a], lengths[b]) })
+	sorted := make([][]NodeID, len(paths))
+	for i, p := range order {
+		sorted[i] = paths[p]
+	}
+	return sorted
+}
+
+// withoutCycles cuts the cycles out of a walk. A minimum-cost flow can only
+// hold cycles of zero length, so the walk keeps its length.
+func withoutCycles(walk []NodeID) []NodeID {
+	at := make(map[NodeID]int, len(walk))
+	path := walk[:0]
+	for _, v := range walk {
+		if i, ok := at[v]; ok {
+			for _, u := range path[i+1:] {
+				delete(at, u)
+			}
+			path = path[:i+1]
+			continue
+		}
+		at[v] = len(path)
+		path = append(path, v)
+	}
+	return path
+}
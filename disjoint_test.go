@@ -0,0 +1,79 @@
+package bmssp
+
+import "testing"
+
+func TestDisjointPaths(t *testing.T) {
+	// The shortest path 0-1-2-3 blocks every second path: the optimal pair
+	// has to reroute around it.
+	g := NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 2, 1)
+	g.AddEdge(2, 3, 1)
+	g.AddEdge(0, 2, 3)
+	g.AddEdge(1, 3, 3)
+	for _, find := range []func(*Graph, NodeID, NodeID, int) [][]NodeID{DisjointPaths, NodeDisjointPaths} {
+		paths := find(g, 0, 3, 2)
+		if len(paths) != 2 {
+			t.Fatalf("expected 2 paths, got %v", paths)
+		}
+		var total Dist
+		for _, p := range paths {
+			d, ok := PathCost(g, p)
+			if !ok || p[0] != 0 || p[len(p)-1] != 3 {
+				t.Fatalf("invalid path %v", p)
+			}
+			total += d
+		}
+		if total != 8 {
+			t.Errorf("expected total length 8, got %v for %v", total, paths)
+		}
+		if got := find(g, 0, 3, 1); len(got) != 1 || len(got[0]) != 4 {
+			t.Errorf("expected the shortest path for k=1, got %v", got)
+		}
+	}
+
+	// Two routes through one bottleneck node share no edge but a node
+	g = NewGraph()
+	for _, e := range [][2]NodeID{{0, 1}, {0, 2}, {1, 3}, {2, 3}, {3, 4}, {3, 5}, {4, 6}, {5, 6}} {
+		g.AddEdge(e[0], e[1], 1)
+	}
+	if got := DisjointPaths(g, 0, 6, 3); len(got) != 2 {
+		t.Errorf("expected 2 edge-disjoint paths, got %v", got)
+	}
+	if got := NodeDisjointPaths(g, 0, 6, 3); len(got) != 1 {
+		t.Errorf("expected 1 node-disjoint path, got %v", got)
+	}
+	if got := DisjointPaths(g, 6, 0, 2); got != nil {
+		t.Errorf("expected no paths back, got %v", got)
+	}
+}
+
+func TestDisjointPaths_Random(t *testing.T) {
+	g := generateRandomGraph(200, 1200, 10.0, 7)
+	paths := NodeDisjointPaths(g, 0, 199, 4)
+	if len(paths) == 0 {
+		t.Skip("target unreachable")
+	}
+	used := make(map[NodeID]bool)
+	prev := Dist(0)
+	for _, p := range paths {
+		d, ok := PathCost(g, p)
+		if !ok || p[0] != 0 || p[len(p)-1] != 199 {
+			t.Fatalf("invalid path %v", p)
+		}
+		if d < prev {
+			t.Errorf("paths not sorted by length")
+		}
+		prev = d
+		for _, v := range p[1 : len(p)-1] {
+			if used[v] {
+				t.Errorf("node %d on two paths", v)
+			}
+			used[v] = true
+		}
+	}
+	_, want := ShortestPath(g, 0, 199)
+	if got, _ := PathCost(g, NodeDisjointPaths(g, 0, 199, 1)[0]); !approxEqual(got, want, DefaultEpsilon) {
+		t.Errorf("single path has length %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package bmssp
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// DOTOptions configures WriteDOT.
+type DOTOptions struct {
+	Name string // graph name (default "G")
+
+	// Tree highlights the shortest-path tree of a result and labels each
+	// reached node with its distance.
+	Tree *Result
+	// Path highlights the edges of a path, e.g. from ShortestPath.
+	Path []NodeID
+
+	Color string              // color of highlighted edges (default "red")
+	Label func(NodeID) string // node labels, e.g. IDMapper keys (default: the ID)
+}
+
+// WriteDOT writes g in the Graphviz DOT language, for debugging small graphs
+// and for illustrations. Nodes and edges are written in ascending order, so
+// the output is stable across runs.
+//
+// Parameters:
+//   - w: destination
+//   - g: graph to write
+//   - opts: name, highlighting and labels
+//
+// Returns:
+//   - the first write error
+func WriteDOT(w io.Writer, g *Graph, opts DOTOptions) error {
+	name := opts.Name
+	if name == "" {
+		name = "G"
+	}
+	color := opts.Color
+	if color == "" {
+		color = "red"
+	}
+
+	highlight := make(map[EdgeID]bool)
+	if opts.Tree != nil {
+		for v, u := range opts.Tree.Pred {
+			highlight[EdgeID{From: u, To: v}] = true
+		}
+	}
+	for i := 1; i < len(opts.Path); i++ {
+		highlight[EdgeID{From: opts.Path[i-1], To: opts.Path[i]}] = true
+	}
+
+	nodes := make([]NodeID, 0, len(g.adj))
+	for u := range g.adj {
+		nodes = append(nodes, u)
+	}
+	slices.Sort(nodes)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph %s {\n", strconv.Quote(name))
+	for _, u := range nodes {
+		label := strconv.Itoa(int(u))
+		if opts.Label != nil {
+			label = opts.Label(u)
+		}
+		if opts.Tree != nil {
+			if d, ok := opts.Tree.Dist[u]; ok && d < INF {
+				label += fmt.Sprintf("\n%g", float64(d))
+			}
+		}
+		fmt.Fprintf(bw, "  %d [label=%s];\n", u, strconv.Quote(label))
+	}
+
+	for _, u := range nodes {
+		edges := slices.Clone(g.adj[u])
+		slices.SortStableFunc(edges, func(a, b Edge) int { return cmp.Compare(a.To, b.To) })
+		for _, e := range edges {
+			attrs := fmt.Sprintf("label=%s", strconv.Quote(strconv.FormatFloat(float64(e.Weight), 'g', -1, 64)))
+			if highlight[EdgeID{From: u, To: e.To}] {
+				attrs += fmt.Sprintf(", color=%s, penwidth=2", strconv.Quote(color))
+			}
+			fmt.Fprintf(bw, "  %d -> %d [%s];\n", u, e.To, attrs)
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
@@ -0,0 +1,55 @@
+package bmssp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteDOT(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 2, 1.5)
+	g.AddEdge(0, 2, 4)
+
+	var b strings.Builder
+	if err := WriteDOT(&b, g, DOTOptions{Path: []NodeID{0, 1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph "G" {
+  0 [label="0"];
+  1 [label="1"];
+  2 [label="2"];
+  0 -> 1 [label="1", color="red", penwidth=2];
+  0 -> 2 [label="4"];
+  1 -> 2 [label="1.5", color="red", penwidth=2];
+}
+`
+	if b.String() != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, b.String())
+	}
+}
+
+func TestWriteDOT_Tree(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 2, 1.5)
+	g.AddEdge(0, 2, 4)
+	names := []string{"a", "b", "c"}
+
+	var b strings.Builder
+	err := WriteDOT(&b, g, DOTOptions{
+		Name:  "tree",
+		Tree:  Solve(g, sources(0), INF),
+		Color: "blue",
+		Label: func(v NodeID) string { return names[v] },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, s := range []string{`digraph "tree"`, `2 [label="c\n2.5"]`, `1 -> 2 [label="1.5", color="blue"`, `0 -> 2 [label="4"];`} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected output to contain %s, got:\n%s", s, out)
+		}
+	}
+}
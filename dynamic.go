@@ -0,0 +1,222 @@
+package bmssp
+
+// DynamicSSSP maintains a bounded single-source shortest-path tree over a
+// graph that changes between queries. After a change is applied to the graph
+// the matching Repair method re-settles only the affected part of the tree
+// instead of recomputing every distance.
+type DynamicSSSP struct {
+	g        *Graph
+	source   NodeID
+	bound    Dist
+	dist     map[NodeID]Dist
+	pred     map[NodeID]NodeID
+	children map[NodeID]NodeSet // pred inverted, kept in sync by the solver
+}
+
+// NewDynamicSSSP computes the initial shortest-path tree from source within
+// bound B.
+func NewDynamicSSSP(g *Graph, source NodeID, B Dist) *DynamicSSSP {
+	d := &DynamicSSSP{
+		g:        g,
+		source:   source,
+		bound:    B,
+		dist:     newDistanceMap(g),
+		pred:     make(map[NodeID]NodeID),
+		children: make(map[NodeID]NodeSet),
+	}
+	d.dist[source] = 0
+	d.settle(source)
+	return d
+}
+
+// settle runs a bounded BMSSP pass from the given nodes, keeping the tree's
+// distances and predecessors up to date.
+func (d *DynamicSSSP) settle(from ...NodeID) {
+	S := NewNodeSet()
+	for _, v := range from {
+		S.Add(v)
+	}
+	s := &solver{g: d.g, dhat: d.dist, pred: d.pred, children: d.children}
+	s.run(d.bound, S)
+}
+
+// Dist returns the current shortest distance from the source to v
+// (INF if v is unreachable or unknown).
+func (d *DynamicSSSP) Dist(v NodeID) Dist {
+	if dv, ok := d.dist[v]; ok {
+		return dv
+	}
+	return INF
+}
+
+// Distances returns the maintained distance map. It is updated in place by
+// the repair methods and must not be modified by the caller.
+func (d *DynamicSSSP) Distances() map[NodeID]Dist {
+	return d.dist
+}
+
+// PathTo returns the current shortest path from the source to v, or nil if v
+// is unreachable.
+func (d *DynamicSSSP) PathTo(v NodeID) []NodeID {
+	if d.Dist(v) == INF {
+		return nil
+	}
+	return pathTo(d.pred, v)
+}
+
+// AddEdge adds an edge to the underlying graph and repairs the tree.
+func (d *DynamicSSSP) AddEdge(from, to NodeID, weight Dist) {
+	d.g.AddEdge(from, to, weight)
+	d.RepairAfterInsert(from, Edge{To: to, Weight: weight})
+}
+
+// RepairAfterInsert updates the tree after edge e leaving 'from' was added to
+// the graph (or had its weight decreased). Only nodes whose distance improves
+// through the new edge are re-relaxed.
+func (d *DynamicSSSP) RepairAfterInsert(from NodeID, e Edge) {
+	for _, v := range []NodeID{from, e.To} {
+		if _, ok := d.dist[v]; !ok {
+			d.dist[v] = INF
+		}
+	}
+
+	du := d.dist[from]
+	if du > d.bound || du+e.Weight >= d.dist[e.To] {
+		return
+	}
+	d.dist[e.To] = du + e.Weight
+	reparent(d.pred, d.children, e.To, from)
+	d.settle(e.To)
+}
+
+// RemoveEdge removes every edge from 'from' to 'to' from the underlying graph
+// and repairs the tree.
+func (d *DynamicSSSP) RemoveEdge(from, to NodeID) {
+	if d.g.RemoveEdge(from, to) {
+		d.repairTreeEdge(from, to)
+	}
+}
+
+// RepairAfterIncrease sets the weight of the edges from 'from' to 'to' to
+// newWeight and repairs the tree. If the edge is not part of the tree nothing
+// needs to be recomputed; otherwise only the descendants of 'to' are
+// invalidated and re-settled with a bounded BMSSP pass. A weight decrease is
+// repaired like an insertion.
+func (d *DynamicSSSP) RepairAfterIncrease(from, to NodeID, newWeight Dist) {
+	if d.g.UpdateEdgeWeight(from, to, newWeight) {
+		d.RepairAfterInsert(from, Edge{To: to, Weight: newWeight})
+		d.repairTreeEdge(from, to)
+	}
+}
+
+// repairTreeEdge restores the tree after the edges from 'from' to 'to' became
+// more expensive or disappeared. The work is bounded by the subtree below
+// 'to' and the edges into it.
+func (d *DynamicSSSP) repairTreeEdge(from, to NodeID) {
+	if p, ok := d.pred[to]; !ok || p != from {
+		return
+	}
+	// A parallel edge may still support the old distance
+	for _, e := range d.g.adj[from] {
+		if e.To == to && d.dist[from]+e.Weight <= d.dist[to] {
+			return
+		}
+	}
+
+	s := &solver{g: d.g, dhat: d.dist, pred: d.pred, children: d.children}
+	s.resettle(subtreeOf(d.children, to), d.bound)
+}
+
+// childrenOf inverts a predecessor map into child sets.
+func childrenOf(pred map[NodeID]NodeID) map[NodeID]NodeSet {
+	children := make(map[NodeID]NodeSet)
+	for v, p := range pred {
+		if children[p] == nil {
+			children[p] = NewNodeSet()
+		}
+		children[p].Add(v)
+	}
+	return children
+}
+
+// reparent makes u the predecessor of v, updating the inverted index.
+func reparent(pred map[NodeID]NodeID, children map[NodeID]NodeSet, v, u NodeID) {
+	unparent(pred, children, v)
+	pred[v] = u
+	if children[u] == nil {
+		children[u] = NewNodeSet()
+	}
+	children[u].Add(v)
+}
+
+// unparent removes the predecessor of v, updating the inverted index.
+func unparent(pred map[NodeID]NodeID, children map[NodeID]NodeSet, v NodeID) {
+	p, ok := pred[v]
+	if !ok {
+		return
+	}
+	delete(pred, v)
+	delete(children[p], v)
+	if len(children[p]) == 0 {
+		delete(children, p)
+	}
+}
+
+// subtreeOf returns root and all of its descendants.
+func subtreeOf(children map[NodeID]NodeSet, root NodeID) NodeSet {
+	subtree := NewNodeSet()
+	stack := []NodeID{root}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		subtree.Add(v)
+		for c := range children[v] {
+			stack = append(stack, c)
+		}
+	}
+	return subtree
+}
+
+// resettle discards the distances of a subtree whose connection to the rest
+// of the tree became more expensive, reconnects it through the cheapest edges
+// from settled nodes outside of it, and lets BMSSP propagate from there.
+// Only the edges into the subtree are scanned, through the reverse index;
+// overlays that add edges fall back to scanning every node.
+func (s *solver) resettle(subtree NodeSet, B Dist) {
+	for v := range subtree {
+		s.dhat[v] = INF
+		if s.children != nil {
+			unparent(s.pred, s.children, v)
+		} else {
+			delete(s.pred, v)
+		}
+	}
+
+	frontier := NewNodeSet()
+	reconnect := func(u, v NodeID, e Edge) {
+		du := s.dist(u)
+		if subtree.Has(u) || du > B {
+			return
+		}
+		if d := du + s.weight(u, e); d < s.dhat[v] {
+			s.relax(u, v, d)
+			frontier.Add(v)
+		}
+	}
+	if s.g != nil && s.cfg.extra == nil {
+		for v := range subtree {
+			for _, in := range s.g.InEdges(v) {
+				reconnect(in.To, v, Edge{To: v, Weight: in.Weight})
+			}
+		}
+	} else {
+		for u := range s.dhat {
+			for _, e := range s.outEdges(u) {
+				if subtree.Has(e.To) {
+					reconnect(u, e.To, e)
+				}
+			}
+		}
+	}
+	s.run(B, frontier)
+}
@@ -0,0 +1,97 @@
+package bmssp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// assertDistances compares a distance map against Dijkstra from source.
+func assertDistances(t *testing.T, g *Graph, source NodeID, got map[NodeID]Dist) {
+	t.Helper()
+	for v, want := range Dijkstra(g, source) {
+		if got[v] != want && math.Abs(float64(got[v]-want)) > 1e-9 {
+			t.Fatalf("node %d: expected %v, got %v", v, want, got[v])
+		}
+	}
+}
+
+func TestDynamicSSSP_RepairAfterInsert(t *testing.T) {
+	g := generateRandomGraph(200, 600, 10.0, 3)
+	d := NewDynamicSSSP(g, 0, 1e6)
+	assertDistances(t, g, 0, d.Distances())
+
+	r := rand.New(rand.NewSource(11))
+	for i := 0; i < 50; i++ {
+		u, v := NodeID(r.Intn(200)), NodeID(r.Intn(200))
+		d.AddEdge(u, v, Dist(r.Float64()*5+1))
+		assertDistances(t, g, 0, d.Distances())
+	}
+
+	// A brand-new node becomes reachable through an inserted edge
+	d.AddEdge(0, 500, 1)
+	if d.Dist(500) != 1 {
+		t.Errorf("new node: expected dist 1, got %v", d.Dist(500))
+	}
+	if path := d.PathTo(500); len(path) != 2 || path[0] != 0 || path[1] != 500 {
+		t.Errorf("new node: expected path [0 500], got %v", path)
+	}
+}
+
+func TestDynamicSSSP_RepairAfterIncrease(t *testing.T) {
+	g := generateRandomGraph(200, 800, 10.0, 5)
+	d := NewDynamicSSSP(g, 0, 1e6)
+
+	r := rand.New(rand.NewSource(13))
+	for i := 0; i < 100; i++ {
+		// Pick a tree edge most of the time so the repair path is exercised
+		v := NodeID(r.Intn(200))
+		u, ok := d.pred[v]
+		if !ok {
+			continue
+		}
+		if i%4 == 0 {
+			d.RemoveEdge(u, v)
+		} else {
+			d.RepairAfterIncrease(u, v, Dist(r.Float64()*20+1))
+		}
+		assertDistances(t, g, 0, d.Distances())
+	}
+}
+
+func TestDynamicSSSP_ChildrenIndex(t *testing.T) {
+	g := generateRandomGraph(150, 600, 10.0, 7)
+	d := NewDynamicSSSP(g, 0, 1e6)
+
+	r := rand.New(rand.NewSource(17))
+	for i := 0; i < 150; i++ {
+		u, v := NodeID(r.Intn(150)), NodeID(r.Intn(150))
+		switch i % 3 {
+		case 0:
+			d.AddEdge(u, v, Dist(r.Float64()*5+1))
+		case 1:
+			if p, ok := d.pred[v]; ok {
+				d.RemoveEdge(p, v)
+			}
+		default:
+			if p, ok := d.pred[v]; ok {
+				d.RepairAfterIncrease(p, v, Dist(r.Float64()*20+1))
+			}
+		}
+		want := childrenOf(d.pred)
+		if len(d.children) != len(want) {
+			t.Fatalf("step %d: expected %d parents in the children index, got %d", i, len(want), len(d.children))
+		}
+		for p, cs := range want {
+			if len(d.children[p]) != len(cs) {
+				t.Fatalf("step %d: node %d: expected children %v, got %v", i, p, cs, d.children[p])
+			}
+			for c := range cs {
+				if !d.children[p].Has(c) {
+					t.Fatalf("step %d: node %d: expected child %d in the index", i, p, c)
+				}
+			}
+		}
+	}
+	assertDistances(t, g, 0, d.Distances())
+}
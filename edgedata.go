@@ -0,0 +1,42 @@
+package bmssp
+
+// AddEdgeData adds a directed edge like AddEdge and attaches data to it, e.g.
+// a road name or lane information. Data is stored per EdgeID, so parallel
+// edges between the same nodes share it.
+func (g *Graph) AddEdgeData(from, to NodeID, weight Dist, data any) {
+	g.AddEdge(from, to, weight)
+	g.SetEdgeData(EdgeID{From: from, To: to}, data)
+}
+
+// SetEdgeData attaches data to the edges with the given ID, replacing any
+// previous data. Setting nil removes it.
+func (g *Graph) SetEdgeData(id EdgeID, data any) {
+	g.mustBeMutable()
+	g.unshare()
+	if data == nil {
+		delete(g.data, id)
+		return
+	}
+	if g.data == nil {
+		g.data = make(map[EdgeID]any)
+	}
+	g.data[id] = data
+}
+
+// EdgeData returns the data attached to the edges with the given ID,
+// reporting false if there is none.
+func (g *Graph) EdgeData(id EdgeID) (any, bool) {
+	data, ok := g.data[id]
+	return data, ok
+}
+
+// PathData returns the data of every edge along path, with nil for edges
+// that have none.
+func (g *Graph) PathData(path []NodeID) []any {
+	ids := PathEdgeIDs(path)
+	out := make([]any, len(ids))
+	for i, id := range ids {
+		out[i] = g.data[id]
+	}
+	return out
+}
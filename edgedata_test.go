@@ -0,0 +1,38 @@
+package bmssp
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEdgeData(t *testing.T) {
+	g := NewGraph()
+	g.AddEdgeData(0, 1, 2, "Main St")
+	g.AddEdgeData(1, 2, 3, "Elm St")
+	g.AddEdge(0, 2, 10)
+
+	path, _ := ShortestPath(g, 0, 2)
+	ids := PathEdgeIDs(path)
+	if want := []EdgeID{{From: 0, To: 1}, {From: 1, To: 2}}; !slices.Equal(ids, want) {
+		t.Fatalf("expected edge IDs %v, got %v", want, ids)
+	}
+	if names := g.PathData(path); names[0] != "Main St" || names[1] != "Elm St" {
+		t.Errorf("expected street names along the route, got %v", names)
+	}
+	if _, ok := g.EdgeData(EdgeID{From: 0, To: 2}); ok {
+		t.Error("expected no data on edge 0->2")
+	}
+
+	c := g.Clone()
+	g.RemoveEdge(0, 1)
+	if _, ok := g.EdgeData(EdgeID{From: 0, To: 1}); ok {
+		t.Error("expected data of a removed edge to be dropped")
+	}
+	if data, _ := c.EdgeData(EdgeID{From: 0, To: 1}); data != "Main St" {
+		t.Errorf("expected the clone to keep its data, got %v", data)
+	}
+	g.RemoveNode(1)
+	if _, ok := g.EdgeData(EdgeID{From: 1, To: 2}); ok {
+		t.Error("expected data of a removed node's edges to be dropped")
+	}
+}
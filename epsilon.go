@@ -0,0 +1,90 @@
+package bmssp
+
+import "math"
+
+// DefaultEpsilon is the relative tolerance used for distance comparisons
+// unless WithEpsilon sets another one.
+const DefaultEpsilon Dist = 1e-9
+
+// MaxExactDist is the largest distance up to which every integer is
+// represented exactly by Dist. Integer weights, e.g. milliseconds or
+// centimeters, produce exact integer distances as long as path lengths stay
+// below it, so comparisons need no tolerance; use WithEpsilon(0) for them.
+const MaxExactDist Dist = 1 << 53
+
+// Number is the set of types whose values can be used as edge weights and
+// distances through FromNumber and DistAs.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// WithEpsilon sets the relative tolerance eps used where the query compares
+// distances that may differ by rounding, such as shadow verification: a and
+// b are equal when they differ by at most eps*max(1, |b|). Zero makes the
+// comparisons exact, which suits integer weights.
+func WithEpsilon(eps Dist) Option {
+	return func(c *config) {
+		c.epsilon = max(eps, 0)
+	}
+}
+
+// approxEqual reports whether a and b differ by at most eps relative to b.
+// An infinite distance only equals itself.
+func approxEqual(a, b, eps Dist) bool {
+	if a == b {
+		return true
+	}
+	if a == INF || b == INF {
+		return false
+	}
+	return math.Abs(float64(a-b)) <= float64(eps)*math.Max(1, math.Abs(float64(b)))
+}
+
+// FromNumber converts a weight of any numeric type to a Dist.
+func FromNumber[T Number](w T) Dist {
+	return Dist(w)
+}
+
+// DistAs converts d to type T, reporting false if d is infinite, out of the
+// range of T, or not a whole number when T is an integer type.
+//
+// Parameters:
+//   - d: distance to convert
+//
+// Returns:
+//   - d as a T
+//   - whether the conversion is exact
+func DistAs[T Number](d Dist) (T, bool) {
+	f := float64(d)
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, false
+	}
+	v := T(f)
+	return v, float64(v) == f
+}
+
+// DistsAs converts the finite distances of a result map to type T, dropping
+// unreachable nodes. It reports false if any distance is not exactly
+// representable in T.
+//
+// Parameters:
+//   - dist: distance map, e.g. from BMSSPSingleSource
+//
+// Returns:
+//   - the finite distances as T
+//   - whether every conversion was exact
+func DistsAs[T Number](dist map[NodeID]Dist) (map[NodeID]T, bool) {
+	out := make(map[NodeID]T, len(dist))
+	exact := true
+	for v, d := range dist {
+		if d == INF {
+			continue
+		}
+		x, ok := DistAs[T](d)
+		exact = exact && ok
+		out[v] = x
+	}
+	return out, exact
+}
@@ -0,0 +1,99 @@
+package bmssp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApproxEqual(t *testing.T) {
+	tests := []struct {
+		a, b, eps Dist
+		want      bool
+	}{
+		{1, 1, 0, true},
+		{1, 1 + 1e-12, DefaultEpsilon, true},
+		{1, 1 + 1e-12, 0, false},
+		{1e6, 1e6 + 1e-4, DefaultEpsilon, true},
+		{1e6, 1e6 + 1e-2, DefaultEpsilon, false},
+		{INF, INF, 0, true},
+		{5, INF, DefaultEpsilon, false},
+		{INF, 5, DefaultEpsilon, false},
+	}
+	for _, tt := range tests {
+		if got := approxEqual(tt.a, tt.b, tt.eps); got != tt.want {
+			t.Errorf("approxEqual(%v, %v, %v) = %v, want %v", tt.a, tt.b, tt.eps, got, tt.want)
+		}
+	}
+}
+
+func TestWithEpsilon_Shadow(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 1)
+
+	// Perturb the answer slightly after the query; only an exact shadow
+	// comparison notices.
+	for _, tt := range []struct {
+		eps  Dist
+		want int
+	}{{DefaultEpsilon, 0}, {0, 1}} {
+		sh := NewShadow(1, nil)
+		s := newSolver(g, []Option{WithShadow(sh), WithEpsilon(tt.eps)})
+		s.dhat[0] = 0
+		S := NewNodeSet()
+		S.Add(0)
+		seeds := seedsOf(s.dhat, S)
+		s.run(INF, S)
+		s.dhat[1] += 1e-12
+		sh.verify(s, seeds, INF)
+		if sh.Mismatched() != tt.want {
+			t.Errorf("eps %v: %d mismatches, want %d", tt.eps, sh.Mismatched(), tt.want)
+		}
+	}
+}
+
+func TestDistAs(t *testing.T) {
+	if v, ok := DistAs[int64](42); !ok || v != 42 {
+		t.Errorf("DistAs[int64](42) = %v, %v", v, ok)
+	}
+	if _, ok := DistAs[int](2.5); ok {
+		t.Error("DistAs[int](2.5) should not be exact")
+	}
+	if _, ok := DistAs[int32](INF); ok {
+		t.Error("DistAs[int32](INF) should not be exact")
+	}
+	if _, ok := DistAs[uint8](300); ok {
+		t.Error("DistAs[uint8](300) should not be exact")
+	}
+	if v, ok := DistAs[float64](2.5); !ok || v != 2.5 {
+		t.Errorf("DistAs[float64](2.5) = %v, %v", v, ok)
+	}
+}
+
+func TestIntegerWeights(t *testing.T) {
+	// Millisecond weights large enough that float32 would round, but far
+	// below MaxExactDist.
+	g := NewGraph()
+	weights := []int64{86_400_000, 3_600_001, 7, 123_456_789}
+	for i, w := range weights {
+		g.AddEdge(NodeID(i), NodeID(i+1), FromNumber(w))
+	}
+	g.AddEdge(5, 6, 1)
+
+	got, exact := DistsAs[int64](BMSSPSingleSource(g, 0, INF, WithEpsilon(0)))
+	if !exact {
+		t.Fatal("distances should be exact integers")
+	}
+	var want int64
+	for i, w := range weights {
+		want += w
+		if got[NodeID(i+1)] != want {
+			t.Errorf("dist[%d] = %d, want %d", i+1, got[NodeID(i+1)], want)
+		}
+	}
+	if _, ok := got[6]; ok {
+		t.Error("unreachable node should be dropped")
+	}
+	if float64(MaxExactDist) != math.Pow(2, 53) {
+		t.Errorf("MaxExactDist = %v", MaxExactDist)
+	}
+}
@@ -0,0 +1,119 @@
+package bmssp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrNodeNotFound is returned when a query refers to a node that is not
+	// in the graph.
+	ErrNodeNotFound = errors.New("bmssp: node not found")
+
+	// ErrInvalidWeight is returned for negative or NaN edge weights and
+	// bounds. +Inf weights are allowed and mark closed edges.
+	ErrInvalidWeight = errors.New("bmssp: invalid weight")
+
+	// ErrEmptyGraph is returned when a query runs on a graph without nodes.
+	ErrEmptyGraph = errors.New("bmssp: empty graph")
+)
+
+// Validate checks that g is non-empty and that every edge weight is valid.
+//
+// Returns:
+//   - ErrEmptyGraph if g has no nodes
+//   - ErrInvalidWeight for the first negative or NaN weight found
+func (g *Graph) Validate() error {
+	if len(g.adj) == 0 {
+		return ErrEmptyGraph
+	}
+	for u, edges := range g.adj {
+		for _, e := range edges {
+			if !validWeight(e.Weight) {
+				return fmt.Errorf("%w: edge %d->%d has weight %v", ErrInvalidWeight, u, e.To, e.Weight)
+			}
+		}
+	}
+	return nil
+}
+
+// validWeight reports whether w is a usable weight or bound.
+func validWeight(w Dist) bool {
+	return w >= 0 && !math.IsNaN(float64(w))
+}
+
+// validateQuery checks the graph, the bound and that every node in nodes
+// exists.
+func validateQuery(g *Graph, B Dist, nodes ...NodeID) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+	if !validWeight(B) {
+		return fmt.Errorf("%w: bound %v", ErrInvalidWeight, B)
+	}
+	for _, v := range nodes {
+		if _, ok := g.adj[v]; !ok {
+			return fmt.Errorf("%w: %d", ErrNodeNotFound, v)
+		}
+	}
+	return nil
+}
+
+// BMSSPSingleSourceChecked is BMSSPSingleSource with input validation. The
+// graph is scanned once per call to check the weights.
+//
+// Returns:
+//   - map of shortest distances from source to all reachable nodes
+//   - ErrEmptyGraph, ErrNodeNotFound or ErrInvalidWeight for bad input
+func BMSSPSingleSourceChecked(G *Graph, source NodeID, B Dist, opts ...Option) (map[NodeID]Dist, error) {
+	if err := validateQuery(G, B, source); err != nil {
+		return nil, err
+	}
+	return BMSSPSingleSource(G, source, B, opts...), nil
+}
+
+// SolveChecked is Solve with input validation.
+//
+// Returns:
+//   - the query result
+//   - ErrEmptyGraph, ErrNodeNotFound or ErrInvalidWeight for bad input
+func SolveChecked(g *Graph, sources NodeSet, B Dist, opts ...Option) (*Result, error) {
+	if err := validateQuery(g, B, sources.ToSlice()...); err != nil {
+		return nil, err
+	}
+	return Solve(g, sources, B, opts...), nil
+}
+
+// SolveFromChecked is SolveFrom with input validation.
+//
+// Returns:
+//   - the query result
+//   - ErrEmptyGraph or ErrNodeNotFound for bad input, ErrInvalidWeight for
+//     a bad bound or a negative or NaN seed
+func SolveFromChecked(g *Graph, seeds map[NodeID]Dist, B Dist, opts ...Option) (*Result, error) {
+	nodes := make([]NodeID, 0, len(seeds))
+	for v, d := range seeds {
+		if !validWeight(d) {
+			return nil, fmt.Errorf("%w: seed %v at node %d", ErrInvalidWeight, d, v)
+		}
+		nodes = append(nodes, v)
+	}
+	if err := validateQuery(g, B, nodes...); err != nil {
+		return nil, err
+	}
+	return SolveFrom(g, seeds, B, opts...), nil
+}
+
+// ShortestPathChecked is ShortestPath with input validation.
+//
+// Returns:
+//   - the path and its length (nil and INF if target is unreachable)
+//   - ErrEmptyGraph, ErrNodeNotFound or ErrInvalidWeight for bad input
+func ShortestPathChecked(g *Graph, source, target NodeID, opts ...Option) ([]NodeID, Dist, error) {
+	if err := validateQuery(g, INF, source, target); err != nil {
+		return nil, INF, err
+	}
+	path, d := ShortestPath(g, source, target, opts...)
+	return path, d, nil
+}
@@ -0,0 +1,52 @@
+package bmssp
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCheckedVariants(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 2)
+	g.AddEdge(1, 2, INF) // closed edges are valid
+
+	if _, err := BMSSPSingleSourceChecked(NewGraph(), 0, INF); !errors.Is(err, ErrEmptyGraph) {
+		t.Errorf("empty graph: expected ErrEmptyGraph, got %v", err)
+	}
+	if _, err := SolveChecked(g, sources(0, 7), INF); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("missing source: expected ErrNodeNotFound, got %v", err)
+	}
+	if _, _, err := ShortestPathChecked(g, 0, 9); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("missing target: expected ErrNodeNotFound, got %v", err)
+	}
+	if _, err := BMSSPSingleSourceChecked(g, 0, Dist(math.NaN())); !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("NaN bound: expected ErrInvalidWeight, got %v", err)
+	}
+
+	for _, seed := range []Dist{-1, Dist(math.NaN())} {
+		if _, err := SolveFromChecked(g, map[NodeID]Dist{0: 0, 1: seed}, INF); !errors.Is(err, ErrInvalidWeight) {
+			t.Errorf("seed %v: expected ErrInvalidWeight, got %v", seed, err)
+		}
+	}
+	if _, err := SolveFromChecked(g, map[NodeID]Dist{7: 1}, INF); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("missing seeded source: expected ErrNodeNotFound, got %v", err)
+	}
+	if res, err := SolveFromChecked(g, map[NodeID]Dist{0: 3}, INF); err != nil || res.Dist[1] != 5 {
+		t.Errorf("valid seeds: expected node 1 at 5, got %v", err)
+	}
+
+	path, d, err := ShortestPathChecked(g, 0, 1)
+	if err != nil || d != 2 || len(path) != 2 {
+		t.Errorf("valid query: expected path of cost 2, got %v %v %v", path, d, err)
+	}
+
+	g.AddEdge(2, 0, Dist(math.NaN()))
+	if _, err := SolveChecked(g, sources(0), INF); !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("NaN weight: expected ErrInvalidWeight, got %v", err)
+	}
+	g.UpdateEdgeWeight(2, 0, -1)
+	if err := g.Validate(); !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("negative weight: expected ErrInvalidWeight, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package bmssp
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+)
+
+// defaultEstimateSamples is the number of sampled sources if none is given.
+const defaultEstimateSamples = 100
+
+// SamplingOptions configures the sampling estimators.
+type SamplingOptions struct {
+	Samples    int                  // number of sampled sources (default 100)
+	Weight     func(NodeID) float64 // source weights, e.g. population (default: uniform)
+	Confidence float64              // confidence level of the interval (default 0.95)
+	Seed       int64                // random seed, for reproducible estimates
+	Workers    int                  // number of worker goroutines (default: GOMAXPROCS)
+}
+
+// Estimate is a sampled estimate of a graph statistic.
+type Estimate struct {
+	Value   float64 // point estimate
+	StdErr  float64 // standard error of Value
+	Low     float64 // lower end of the confidence interval
+	High    float64 // upper end of the confidence interval
+	Samples int     // number of sampled sources
+}
+
+// EstimateAveragePathLength estimates the average shortest-path distance over
+// all pairs (s, t) with t reachable from s, where sources are weighted by
+// opts.Weight. Each sample is one single-source query from a source drawn
+// with probability proportional to its weight; the pairs found are combined
+// with a ratio estimator.
+func EstimateAveragePathLength(g *Graph, opts SamplingOptions) Estimate {
+	sum, count := sampleSources(g, opts, INF, func(_ NodeID, dist map[NodeID]Dist) (float64, float64) {
+		var s, c float64
+		for _, d := range dist {
+			if d > 0 && d < INF {
+				s += float64(d)
+				c++
+			}
+		}
+		return s, c
+	})
+	return ratioEstimate(sum, count, opts.Confidence)
+}
+
+// EstimateReachability estimates the fraction of other nodes reachable within
+// bound B from a source drawn with probability proportional to opts.Weight.
+func EstimateReachability(g *Graph, B Dist, opts SamplingOptions) Estimate {
+	n := float64(len(g.adj) - 1)
+	frac, ones := sampleSources(g, opts, B, func(s NodeID, dist map[NodeID]Dist) (float64, float64) {
+		if n == 0 {
+			return 0, 1
+		}
+		reached := 0
+		for v, d := range dist {
+			if v != s && d <= B && d < INF {
+				reached++
+			}
+		}
+		return float64(reached) / n, 1
+	})
+	return ratioEstimate(frac, ones, opts.Confidence)
+}
+
+// sampleSources draws opts.Samples sources with probability proportional to
+// their weights, runs a query bounded by B from each in parallel and records
+// the pair of values returned by measure for each sample.
+func sampleSources(g *Graph, opts SamplingOptions, B Dist,
+	measure func(s NodeID, dist map[NodeID]Dist) (float64, float64)) (xs, ys []float64) {
+	nodes := make([]NodeID, 0, len(g.adj))
+	for u := range g.adj {
+		nodes = append(nodes, u)
+	}
+	slices.Sort(nodes)
+
+	// Cumulative weights for inverse transform sampling
+	cum := make([]float64, 0, len(nodes))
+	var total float64
+	for _, u := range nodes {
+		w := 1.0
+		if opts.Weight != nil {
+			w = max(opts.Weight(u), 0)
+		}
+		total += w
+		cum = append(cum, total)
+	}
+	if !(total > 0) {
+		return nil, nil
+	}
+
+	samples := opts.Samples
+	if samples <= 0 {
+		samples = defaultEstimateSamples
+	}
+	r := rand.New(rand.NewSource(opts.Seed))
+	picked := make([]NodeID, samples)
+	for i := range picked {
+		x := r.Float64() * total
+		picked[i] = nodes[min(sort.SearchFloat64s(cum, x), len(nodes)-1)]
+	}
+
+	xs = make([]float64, samples)
+	ys = make([]float64, samples)
+	parallelFor(samples, opts.Workers, func(i int) {
+		xs[i], ys[i] = measure(picked[i], BMSSPSingleSource(g, picked[i], B))
+	})
+	return xs, ys
+}
+
+// ratioEstimate estimates Σx/Σy from paired samples, with a normal
+// confidence interval from the linearized variance of the ratio.
+func ratioEstimate(xs, ys []float64, confidence float64) Estimate {
+	n := float64(len(xs))
+	est := Estimate{Samples: len(xs)}
+	var sx, sy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+	}
+	if sy == 0 {
+		return est
+	}
+	est.Value = sx / sy
+	est.Low, est.High = est.Value, est.Value
+	if n < 2 {
+		return est
+	}
+
+	ybar := sy / n
+	var ss float64
+	for i := range xs {
+		r := xs[i] - est.Value*ys[i]
+		ss += r * r
+	}
+	est.StdErr = math.Sqrt(ss/(n-1)/n) / ybar
+
+	if !(confidence > 0 && confidence < 1) {
+		confidence = 0.95
+	}
+	z := math.Sqrt2 * math.Erfinv(confidence)
+	est.Low = est.Value - z*est.StdErr
+	est.High = est.Value + z*est.StdErr
+	return est
+}
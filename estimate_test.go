@@ -0,0 +1,57 @@
+package bmssp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEstimateAveragePathLength(t *testing.T) {
+	g := generateRandomGraph(200, 1000, 10, 17)
+
+	var sum, count float64
+	for u := range g.adj {
+		for _, d := range Dijkstra(g, u) {
+			if d > 0 && d < INF {
+				sum += float64(d)
+				count++
+			}
+		}
+	}
+	exact := sum / count
+
+	est := EstimateAveragePathLength(g, SamplingOptions{Samples: 60, Seed: 1})
+	if est.Samples != 60 || est.StdErr <= 0 {
+		t.Fatalf("unexpected estimate: %+v", est)
+	}
+	if exact < est.Low || exact > est.High {
+		t.Errorf("exact average %v outside the interval [%v, %v]", exact, est.Low, est.High)
+	}
+}
+
+func TestEstimateReachability(t *testing.T) {
+	// Two components: nodes 0-9 reach each other, nodes 10-19 form a chain
+	g := NewGraph()
+	for i := 0; i < 10; i++ {
+		g.AddEdge(NodeID(i), NodeID((i+1)%10), 1)
+	}
+	for i := 10; i < 19; i++ {
+		g.AddEdge(NodeID(i), NodeID(i+1), 100)
+	}
+
+	// Sampling only the cycle: every source reaches the 9 other cycle nodes
+	onCycle := func(v NodeID) float64 {
+		if v < 10 {
+			return 1
+		}
+		return 0
+	}
+	est := EstimateReachability(g, INF, SamplingOptions{Samples: 20, Weight: onCycle})
+	if want := 9.0 / 19; math.Abs(est.Value-want) > 1e-12 || est.StdErr > 1e-12 {
+		t.Errorf("expected exactly %v, got %+v", want, est)
+	}
+
+	// Within bound 3 a cycle source reaches 3 nodes
+	if est := EstimateReachability(g, 3, SamplingOptions{Samples: 20, Weight: onCycle}); math.Abs(est.Value-3.0/19) > 1e-12 {
+		t.Errorf("expected %v within the bound, got %+v", 3.0/19, est)
+	}
+}
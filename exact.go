@@ -0,0 +1,164 @@
+package bmssp
+
+import (
+	"container/heap"
+	"math"
+	"math/big"
+)
+
+// RatEdge is an edge with an exact rational weight.
+type RatEdge struct {
+	To     NodeID
+	Weight *big.Rat
+}
+
+// RatGraph is a directed graph with exact rational edge weights. It backs the
+// exact arithmetic mode used to adjudicate disagreements between algorithms
+// where float64 rounding could be the cause.
+type RatGraph struct {
+	adj map[NodeID][]RatEdge
+}
+
+// NewRatGraph creates an empty rational-weight graph.
+func NewRatGraph() *RatGraph {
+	return &RatGraph{adj: make(map[NodeID][]RatEdge)}
+}
+
+// AddEdge adds a directed edge with weight w. The weight is copied.
+func (g *RatGraph) AddEdge(from, to NodeID, w *big.Rat) {
+	g.adj[from] = append(g.adj[from], RatEdge{To: to, Weight: new(big.Rat).Set(w)})
+	if _, ok := g.adj[to]; !ok {
+		g.adj[to] = nil
+	}
+}
+
+// OutEdges returns all outgoing edges from node u.
+func (g *RatGraph) OutEdges(u NodeID) []RatEdge {
+	return g.adj[u]
+}
+
+// ExactGraph converts g to a RatGraph. Every finite float64 is a dyadic
+// rational, so the conversion is exact; edges with infinite or NaN weights
+// are dropped.
+func ExactGraph(g *Graph) *RatGraph {
+	r := NewRatGraph()
+	for u, edges := range g.adj {
+		if _, ok := r.adj[u]; !ok {
+			r.adj[u] = nil
+		}
+		for _, e := range edges {
+			w := float64(e.Weight)
+			if math.IsInf(w, 0) || math.IsNaN(w) {
+				continue
+			}
+			r.adj[u] = append(r.adj[u], RatEdge{To: e.To, Weight: new(big.Rat).SetFloat64(w)})
+			if _, ok := r.adj[e.To]; !ok {
+				r.adj[e.To] = nil
+			}
+		}
+	}
+	return r
+}
+
+// ratItem is a heap entry; stale entries are skipped when popped.
+type ratItem struct {
+	node NodeID
+	dist *big.Rat
+}
+
+type ratHeap []ratItem
+
+func (h ratHeap) Len() int           { return len(h) }
+func (h ratHeap) Less(i, j int) bool { return h[i].dist.Cmp(h[j].dist) < 0 }
+func (h ratHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *ratHeap) Push(x any)        { *h = append(*h, x.(ratItem)) }
+func (h *ratHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// ExactDistances computes shortest distances from sources with exact rational
+// arithmetic. It is much slower than BMSSP and meant for verification runs.
+//
+// Parameters:
+//   - g: rational-weight graph (weights must be non-negative)
+//   - sources: set of source nodes, all at distance 0
+//
+// Returns:
+//   - exact distances of the reachable nodes; unreachable nodes are absent
+func ExactDistances(g *RatGraph, sources NodeSet) map[NodeID]*big.Rat {
+	dist := make(map[NodeID]*big.Rat)
+	var pq ratHeap
+	for v := range sources {
+		dist[v] = new(big.Rat)
+		heap.Push(&pq, ratItem{node: v, dist: dist[v]})
+	}
+
+	settled := NewNodeSet()
+	for pq.Len() > 0 {
+		item := heap.Pop(&pq).(ratItem)
+		u := item.node
+		if settled.Has(u) || item.dist.Cmp(dist[u]) > 0 {
+			continue
+		}
+		settled.Add(u)
+
+		for _, e := range g.adj[u] {
+			d := new(big.Rat).Add(dist[u], e.Weight)
+			if old, ok := dist[e.To]; ok && d.Cmp(old) >= 0 {
+				continue
+			}
+			dist[e.To] = d
+			heap.Push(&pq, ratItem{node: e.To, dist: d})
+		}
+	}
+	return dist
+}
+
+// Disagreement is a node whose float64 distance differs from the exact one.
+type Disagreement struct {
+	Node  NodeID
+	Got   Dist     // float64 distance under test
+	Exact *big.Rat // exact distance, nil if the node is unreachable
+}
+
+// CheckExact compares float64 distances from source against exact rational
+// arithmetic on the same graph. A distance agrees if it is the float64 value
+// nearest to the exact one, or within tolerance of it.
+//
+// Parameters:
+//   - g: input graph
+//   - source: source node the distances were computed from
+//   - got: distances under test, e.g. from BMSSPSingleSource
+//   - tolerance: accepted absolute difference (0 for round-to-nearest only)
+//
+// Returns:
+//   - the disagreeing nodes
+func CheckExact(g *Graph, source NodeID, got map[NodeID]Dist, tolerance Dist) []Disagreement {
+	S := NewNodeSet()
+	S.Add(source)
+	exact := ExactDistances(ExactGraph(g), S)
+
+	var out []Disagreement
+	for v := range g.adj {
+		d, ok := got[v]
+		if !ok {
+			d = INF
+		}
+		x, reachable := exact[v]
+		if !reachable {
+			if d != INF {
+				out = append(out, Disagreement{Node: v, Got: d})
+			}
+			continue
+		}
+		nearest, _ := x.Float64()
+		if float64(d) == nearest || math.Abs(float64(d)-nearest) <= float64(tolerance) {
+			continue
+		}
+		out = append(out, Disagreement{Node: v, Got: d, Exact: x})
+	}
+	return out
+}
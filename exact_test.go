@@ -0,0 +1,37 @@
+package bmssp
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestExactDistances(t *testing.T) {
+	// 0.1 + 0.2 differs from 0.3 in float64 but not in exact arithmetic
+	g := NewRatGraph()
+	g.AddEdge(0, 1, big.NewRat(1, 10))
+	g.AddEdge(1, 2, big.NewRat(2, 10))
+	g.AddEdge(0, 2, big.NewRat(3, 10))
+	g.AddEdge(3, 0, big.NewRat(1, 1))
+
+	dist := ExactDistances(g, sources(0))
+	if dist[2].Cmp(big.NewRat(3, 10)) != 0 {
+		t.Errorf("expected exact distance 3/10 to node 2, got %v", dist[2])
+	}
+	if _, ok := dist[3]; ok {
+		t.Errorf("expected node 3 to be unreachable, got %v", dist[3])
+	}
+}
+
+func TestCheckExact(t *testing.T) {
+	g := generateRandomGraph(200, 1000, 10, 3)
+	if bad := CheckExact(g, 0, BMSSPSingleSource(g, 0, INF), 1e-9); len(bad) != 0 {
+		t.Errorf("expected BMSSP to agree with exact arithmetic, got %d disagreements, e.g. %+v", len(bad), bad[0])
+	}
+
+	wrong := BMSSPSingleSource(g, 0, INF)
+	wrong[5] += 1
+	bad := CheckExact(g, 0, wrong, 1e-9)
+	if len(bad) != 1 || bad[0].Node != 5 || bad[0].Exact == nil {
+		t.Errorf("expected a single disagreement at node 5, got %+v", bad)
+	}
+}
@@ -3,6 +3,8 @@ package bmssp_test
 import (
 	"fmt"
 	"math"
+	"sync/atomic"
+	"time"
 
 	"github.com/mfreeman451/bmssp-go"
 )
@@ -180,3 +182,33 @@ func Example_parameterTuning() {
 	// Structured graph parameters:
 	//   l=1, k=200, t=1
 }
+
+// queryMetrics adapts bmssp.Metrics to a metrics backend. With Prometheus,
+// each method would call e.g. relaxations.WithLabelValues(alg).Add(float64(n))
+// on a CounterVec, or latency.WithLabelValues(alg).Observe(d.Seconds()) on a
+// HistogramVec; plain atomic counters stand in for them here.
+type queryMetrics struct {
+	bmssp.NopMetrics // ignore the observations not exported
+	queries          atomic.Int64
+	settled          atomic.Int64
+}
+
+func (m *queryMetrics) AddNodesSettled(alg string, n int) { m.settled.Add(int64(n)) }
+
+func (m *queryMetrics) ObserveLatency(alg string, d time.Duration) { m.queries.Add(1) }
+
+// Example_metrics shows how to export query statistics to dashboards.
+func Example_metrics() {
+	g := bmssp.NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 2, 1)
+
+	m := &queryMetrics{}
+	S := bmssp.NewNodeSet()
+	S.Add(0)
+	for range 3 {
+		bmssp.Solve(g, S, bmssp.INF, bmssp.WithMetrics(m))
+	}
+	fmt.Println("queries:", m.queries.Load(), "nodes settled:", m.settled.Load())
+	// Output: queries: 3 nodes settled: 9
+}
@@ -0,0 +1,138 @@
+package bmssp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithExplain records, for every node, which search pass settled it and how
+// often its distance improved, so that Explain can report it. It costs one
+// map entry per reached node.
+func WithExplain() Option {
+	return func(c *config) { c.explain = true }
+}
+
+// nodeTrace is the recorded history of one node.
+type nodeTrace struct {
+	relaxations int  // improvements of the tentative distance
+	settles     int  // scans of the outgoing edges
+	depth       int  // recursion level of the last settle
+	bound       Dist // bound of the pass of the last settle
+}
+
+// explainTrace is the per-query history recorded with WithExplain.
+type explainTrace struct {
+	bound Dist // bound of the whole query
+	nodes map[NodeID]*nodeTrace
+}
+
+func newExplainTrace() *explainTrace {
+	return &explainTrace{bound: INF, nodes: make(map[NodeID]*nodeTrace)}
+}
+
+// node returns the trace of v, creating it if needed.
+func (t *explainTrace) node(v NodeID) *nodeTrace {
+	n, ok := t.nodes[v]
+	if !ok {
+		n = &nodeTrace{}
+		t.nodes[v] = n
+	}
+	return n
+}
+
+func (t *explainTrace) settle(v NodeID, depth int, bound Dist) {
+	n := t.node(v)
+	n.settles++
+	n.depth = depth
+	n.bound = bound
+}
+
+// PathStep is a node on the predecessor chain of an explained target.
+type PathStep struct {
+	Node NodeID
+	Dist Dist
+}
+
+// Explanation describes how a query arrived at the distance of one node.
+type Explanation struct {
+	Target NodeID
+	Dist   Dist
+	Chain  []PathStep // predecessor chain from a source to the target
+
+	// Traced is set when the query ran with WithExplain; the fields below
+	// are only filled in then.
+	Traced      bool
+	Relaxations int  // times the target's tentative distance improved
+	Settles     int  // times the target's outgoing edges were scanned
+	Depth       int  // recursion level that last settled the target (0 for Dijkstra)
+	Bound       Dist // bound of the search pass that last settled the target
+
+	// Truncated is set when the distance may not be shortest because the
+	// search stopped early (see Result.Stopped) or the target was only
+	// reached beyond the query bound.
+	Truncated bool
+}
+
+// Explain reports why target got its distance in r: the predecessor chain
+// and, if the query ran with WithExplain, which recursion level and bound
+// settled it, how often it was relaxed, and whether the distance was cut
+// short by the bound or an early stop.
+//
+// Parameters:
+//   - r: query result
+//   - target: node to explain
+//
+// Returns:
+//   - the explanation
+func Explain(r *Result, target NodeID) Explanation {
+	e := Explanation{Target: target, Dist: INF}
+	if d, ok := r.Dist[target]; ok {
+		e.Dist = d
+	}
+	if e.Dist < INF {
+		for _, v := range pathTo(r.Pred, target) {
+			e.Chain = append(e.Chain, PathStep{Node: v, Dist: r.Dist[v]})
+		}
+	}
+
+	if r.trace == nil {
+		e.Truncated = r.Approximate()
+		return e
+	}
+	e.Traced = true
+	if n, ok := r.trace.nodes[target]; ok {
+		e.Relaxations = n.relaxations
+		e.Settles = n.settles
+		e.Depth = n.depth
+		e.Bound = n.bound
+	}
+	// A hop limit can lengthen any distance; budgets and deadlines only
+	// those of nodes not settled yet
+	settled := e.Settles > 0 || e.Dist == 0
+	e.Truncated = e.Dist < INF && e.Dist > r.trace.bound ||
+		r.Approximate() && (!settled || r.Stopped == StopMaxHops)
+	return e
+}
+
+// String formats the explanation for logs and debugging sessions.
+func (e Explanation) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "node %d: distance %v", e.Target, e.Dist)
+	if len(e.Chain) > 0 {
+		steps := make([]string, len(e.Chain))
+		for i, s := range e.Chain {
+			steps[i] = fmt.Sprintf("%d (%v)", s.Node, s.Dist)
+		}
+		fmt.Fprintf(&b, " via %s", strings.Join(steps, " -> "))
+	}
+	if e.Traced {
+		fmt.Fprintf(&b, "; relaxed %d times, settled %d times", e.Relaxations, e.Settles)
+		if e.Settles > 0 {
+			fmt.Fprintf(&b, ", last at depth %d with bound %v", e.Depth, e.Bound)
+		}
+	}
+	if e.Truncated {
+		b.WriteString("; truncated")
+	}
+	return b.String()
+}
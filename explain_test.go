@@ -0,0 +1,47 @@
+package bmssp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 5)
+	g.AddEdge(0, 2, 1)
+	g.AddEdge(2, 1, 1)
+	g.AddEdge(1, 3, 10)
+
+	for name, res := range map[string]*Result{
+		"bmssp":    Solve(g, sources(0), 100, WithExplain()),
+		"dijkstra": SolveDijkstra(g, sources(0), WithExplain()),
+	} {
+		e := Explain(res, 1)
+		if !e.Traced || e.Dist != 2 || e.Relaxations != 2 || e.Settles == 0 || e.Truncated {
+			t.Errorf("%s: unexpected explanation %+v", name, e)
+		}
+		if len(e.Chain) != 3 || e.Chain[1] != (PathStep{Node: 2, Dist: 1}) {
+			t.Errorf("%s: expected chain 0, 2, 1, got %v", name, e.Chain)
+		}
+		if s := e.String(); !strings.Contains(s, "via 0 (0) -> 2 (1) -> 1 (2)") {
+			t.Errorf("%s: unexpected string %q", name, s)
+		}
+	}
+
+	// Node 3 is reached at 12, beyond the bound of 10
+	e := Explain(Solve(g, sources(0), 10, WithExplain()), 3)
+	if !e.Truncated || e.Settles != 0 || e.Relaxations != 1 {
+		t.Errorf("expected node 3 to be truncated by the bound, got %+v", e)
+	}
+	if e := Explain(Solve(g, sources(0), 30, WithExplain()), 3); e.Truncated || e.Depth < 1 || e.Bound != 30 {
+		t.Errorf("expected node 3 settled at depth >= 1 within bound 30, got %+v", e)
+	}
+
+	// Without WithExplain only the distance and chain are known
+	if e := Explain(Solve(g, sources(0), 100), 1); e.Traced || len(e.Chain) != 3 {
+		t.Errorf("expected an untraced explanation with a chain, got %+v", e)
+	}
+	if e := Explain(Solve(g, sources(0), 100, WithNodeBudget(1), WithExplain()), 1); !e.Truncated {
+		t.Errorf("expected node 1 truncated by the node budget, got %+v", e)
+	}
+}
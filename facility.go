@@ -0,0 +1,178 @@
+package bmssp
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// FacilityAssignment assigns every node to its closest facility.
+type FacilityAssignment struct {
+	Facility map[NodeID]NodeID  // closest facility of every reachable node
+	Dist     map[NodeID]Dist    // distance to that facility, INF if none is reachable
+	Regions  map[NodeID]NodeSet // nodes served by each facility, itself included
+}
+
+// NearestFacility partitions the graph among facilities, e.g. stores,
+// depots or hospitals: every node is assigned the facility it is closest to,
+// forming the graph's Voronoi regions. It is a single multi-source query,
+// with each node's facility found at the root of its shortest-path tree.
+// Distances run from the facilities to the nodes; query the Reverse graph
+// to measure from the nodes to the facilities. Nodes equally close to
+// several facilities go to any of them, unless a tie-break is passed.
+//
+// Parameters:
+//   - g: input graph
+//   - facilities: set of facility nodes; those not in g are ignored
+//   - opts: optional query settings, e.g. WithTieBreak(TieBreakLexicographic)
+//     to prefer the facility with the smallest ID
+//
+// Returns:
+//   - the facility of every reachable node, its distance and the regions
+func NearestFacility(g *Graph, facilities NodeSet, opts ...Option) *FacilityAssignment {
+	S := NewNodeSet()
+	for f := range facilities {
+		if _, ok := g.adj[f]; ok {
+			S.Add(f)
+		}
+	}
+	res := Solve(g, S, INF, opts...)
+	a := &FacilityAssignment{
+		Facility: make(map[NodeID]NodeID, len(res.Dist)),
+		Dist:     res.Dist,
+		Regions:  make(map[NodeID]NodeSet, len(S)),
+	}
+	for f := range S {
+		a.Regions[f] = NewNodeSet()
+	}
+
+	// Walk each node up its tree until a node with a known facility,
+	// assigning the facility to the whole walk
+	var walk []NodeID
+	for v, d := range res.Dist {
+		if d == INF {
+			continue
+		}
+		walk = walk[:0]
+		u := v
+		for {
+			if f, ok := a.Facility[u]; ok {
+				u = f
+				break
+			}
+			walk = append(walk, u)
+			p, ok := res.Pred[u]
+			if !ok {
+				break // u is a facility
+			}
+			u = p
+		}
+		for _, w := range walk {
+			a.Facility[w] = u
+			a.Regions[u].Add(w)
+		}
+	}
+	return a
+}
+
+// MedianOptions configures WeightedMedianWithOptions.
+type MedianOptions struct {
+	Candidates NodeSet // nodes eligible as the median (default: every node)
+	Workers    int     // number of worker goroutines (default: GOMAXPROCS)
+
+	// Samples, if positive and smaller than the number of demand nodes,
+	// estimates the costs from that many demand nodes drawn with
+	// probability proportional to their demand, instead of searching from
+	// every demand node.
+	Samples int
+	Seed    int64 // random seed of the sample, for reproducible results
+}
+
+// WeightedMedian returns the 1-median of demand using the default options.
+// See WeightedMedianWithOptions.
+func WeightedMedian(g *Graph, demand map[NodeID]float64) (NodeID, float64) {
+	return WeightedMedianWithOptions(g, demand, MedianOptions{})
+}
+
+// WeightedMedianWithOptions solves the 1-median problem: it finds the node
+// minimizing the total demand-weighted distance from it to the demand
+// nodes, e.g. the best site for a single warehouse serving stores with
+// known volumes. One backward search per demand node gives the distance
+// from every node to it; the searches run in parallel on a pool of workers
+// sharing the read-only graph.
+//
+// Parameters:
+//   - g: input graph
+//   - demand: demand of each node; nodes without positive demand are ignored
+//   - opts: candidates, workers and sampling
+//
+// Returns:
+//   - the median, the candidate with the smallest ID among equally good ones
+//   - its total weighted distance, +Inf if no candidate reaches every demand
+//     node; estimated when sampling
+func WeightedMedianWithOptions(g *Graph, demand map[NodeID]float64, opts MedianOptions) (NodeID, float64) {
+	nodes := make([]NodeID, 0, len(g.adj))
+	for u := range g.adj {
+		if opts.Candidates == nil || opts.Candidates.Has(u) {
+			nodes = append(nodes, u)
+		}
+	}
+	slices.Sort(nodes)
+	if len(nodes) == 0 {
+		return 0, math.Inf(1)
+	}
+
+	var targets []NodeID
+	var weights []float64
+	var total float64
+	for v, w := range demand {
+		if _, ok := g.adj[v]; ok && w > 0 {
+			targets = append(targets, v)
+		}
+	}
+	slices.Sort(targets)
+	for _, v := range targets {
+		total += demand[v]
+		weights = append(weights, demand[v])
+	}
+	if n := opts.Samples; n > 0 && n < len(targets) {
+		// Draw with probability proportional to demand; each draw then
+		// stands for an equal share of the total
+		cum := make([]float64, len(weights))
+		var acc float64
+		for i, w := range weights {
+			acc += w
+			cum[i] = acc
+		}
+		r := rand.New(rand.NewSource(opts.Seed))
+		picked := make([]NodeID, n)
+		for i := range picked {
+			picked[i] = targets[min(sort.SearchFloat64s(cum, r.Float64()*total), len(targets)-1)]
+		}
+		targets, weights = picked, make([]float64, n)
+		for i := range weights {
+			weights[i] = total / float64(n)
+		}
+	}
+
+	cost := make([]float64, len(nodes))
+	var mu sync.Mutex
+	parallelFor(len(targets), opts.Workers, func(i int) {
+		dist := BMSSPSingleTarget(g, targets[i], INF)
+		mu.Lock()
+		for j, u := range nodes {
+			cost[j] += weights[i] * float64(dist[u])
+		}
+		mu.Unlock()
+	})
+
+	best := 0
+	for j := range nodes {
+		if cost[j] < cost[best] {
+			best = j
+		}
+	}
+	return nodes[best], cost[best]
+}
@@ -0,0 +1,101 @@
+package bmssp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNearestFacility(t *testing.T) {
+	g := generateGridGraph(10, 10)
+	facilities := sources(0, 99, 9)
+	a := NearestFacility(g, facilities, WithTieBreak(TieBreakLexicographic))
+
+	dists := make(map[NodeID]map[NodeID]Dist)
+	for f := range facilities {
+		dists[f] = Dijkstra(g, f)
+	}
+	total := 0
+	for f, region := range a.Regions {
+		total += len(region)
+		if !region.Has(f) {
+			t.Errorf("facility %d not in its own region", f)
+		}
+		for v := range region {
+			if a.Facility[v] != f {
+				t.Errorf("node %d: in region %d but assigned %d", v, f, a.Facility[v])
+			}
+			if a.Dist[v] != dists[f][v] {
+				t.Errorf("node %d: distance %v, want %v", v, a.Dist[v], dists[f][v])
+			}
+			for other := range facilities {
+				if d := dists[other][v]; d < a.Dist[v] || d == a.Dist[v] && other < f {
+					t.Errorf("node %d: facility %d is closer than %d", v, other, f)
+				}
+			}
+		}
+	}
+	if total != 100 {
+		t.Errorf("expected the regions to cover 100 nodes, got %d", total)
+	}
+
+	g.AddEdge(100, 0, 1)
+	if a := NearestFacility(g, sources(0)); a.Dist[100] != INF || len(a.Regions[0]) != 100 {
+		t.Errorf("expected node 100 unassigned, got %v", a.Facility[100])
+	}
+	if _, ok := NearestFacility(g, sources(0, 500)).Facility[100]; ok {
+		t.Errorf("unreachable node assigned a facility")
+	}
+}
+
+func TestWeightedMedian(t *testing.T) {
+	g := generateGridGraph(5, 5)
+	uniform := make(map[NodeID]float64)
+	for v := range NodeID(25) {
+		uniform[v] = 1
+	}
+	// Sum of Manhattan distances from the center of a 5x5 grid
+	if m, cost := WeightedMedian(g, uniform); m != 12 || cost != 60 {
+		t.Errorf("expected the center with cost 60, got %d with %v", m, cost)
+	}
+	if m, cost := WeightedMedian(g, map[NodeID]float64{0: 10, 24: 1, 3: 0}); m != 0 || cost != 8 {
+		t.Errorf("expected the heavy corner with cost 8, got %d with %v", m, cost)
+	}
+	corners := sources(0, 4, 20, 24)
+	if m, cost := WeightedMedianWithOptions(g, uniform, MedianOptions{Candidates: corners}); m != 0 || cost != 100 {
+		t.Errorf("expected corner 0 with cost 100, got %d with %v", m, cost)
+	}
+
+	// Against brute force on a random graph
+	r := generateRandomGraph(60, 400, 10, 11)
+	demand := map[NodeID]float64{3: 2, 17: 1, 42: 5, 59: 0.5}
+	best, bestCost := NodeID(-1), math.Inf(1)
+	for u := range NodeID(60) {
+		dist := Dijkstra(r, u)
+		var c float64
+		for v, w := range demand {
+			c += w * float64(dist[v])
+		}
+		if c < bestCost {
+			best, bestCost = u, c
+		}
+	}
+	if m, cost := WeightedMedian(r, demand); m != best || math.Abs(cost-bestCost) > 1e-9 {
+		t.Errorf("expected %d with %v, got %d with %v", best, bestCost, m, cost)
+	}
+
+	// A sample of the demand still finds the center of a large grid
+	big := generateGridGraph(21, 21)
+	all := make(map[NodeID]float64)
+	for v := range NodeID(441) {
+		all[v] = 1
+	}
+	if m, _ := WeightedMedianWithOptions(big, all, MedianOptions{Samples: 100, Seed: 1}); m%21 < 7 || m%21 > 13 || m/21 < 7 || m/21 > 13 {
+		t.Errorf("expected a median near the center, got %d", m)
+	}
+
+	g.AddEdge(25, 0, 1)
+	g.AddEdge(26, 0, 1)
+	if _, cost := WeightedMedian(g, map[NodeID]float64{25: 1, 26: 1}); !math.IsInf(cost, 1) {
+		t.Errorf("expected +Inf when no node reaches all demand, got %v", cost)
+	}
+}
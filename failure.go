@@ -0,0 +1,231 @@
+package bmssp
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+)
+
+// FailureImpact describes the effect of a single edge failure.
+type FailureImpact struct {
+	Edge     EdgeID
+	Before   Dist // distance (or average distance) with the edge in place
+	After    Dist // distance (or average distance) with the edge removed
+	Increase Dist // After - Before; INF if the target became unreachable
+	// Unreachable counts nodes that were reachable before the failure but
+	// not after it.
+	Unreachable int
+}
+
+// closedEdge is an overlay that removes every edge with the given ID.
+type closedEdge EdgeID
+
+func (c closedEdge) Weight(from NodeID, e Edge) Dist {
+	if from == c.From && e.To == c.To {
+		return INF
+	}
+	return e.Weight
+}
+
+// closedEdges is an overlay that removes every edge in the set.
+type closedEdges map[EdgeID]bool
+
+func (c closedEdges) Weight(from NodeID, e Edge) Dist {
+	if c[EdgeID{From: from, To: e.To}] {
+		return INF
+	}
+	return e.Weight
+}
+
+// allEdgeIDs returns the IDs of all edges of g in ascending order.
+func allEdgeIDs(g *Graph) []EdgeID {
+	var ids []EdgeID
+	for u, edges := range g.adj {
+		for _, e := range edges {
+			ids = append(ids, EdgeID{From: u, To: e.To})
+		}
+	}
+	slices.SortFunc(ids, func(a, b EdgeID) int {
+		if c := cmp.Compare(a.From, b.From); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.To, b.To)
+	})
+	return slices.Compact(ids)
+}
+
+// FailureSweep computes, for each candidate edge, how much the shortest
+// distance from source to target grows if that edge fails. Only edges on the
+// baseline shortest path can change the distance, so every other candidate is
+// answered without a search; the remaining ones are recomputed in parallel.
+//
+// Parameters:
+//   - g: input graph (not modified)
+//   - source, target: endpoints of the measured route
+//   - candidates: edges to fail one at a time (nil for every edge of g)
+//
+// Returns:
+//   - one impact per candidate, in the same order
+func FailureSweep(g *Graph, source, target NodeID, candidates []EdgeID) []FailureImpact {
+	if candidates == nil {
+		candidates = allEdgeIDs(g)
+	}
+	path, before := ShortestPath(g, source, target)
+	onPath := make(map[EdgeID]bool, len(path))
+	for i := 1; i < len(path); i++ {
+		onPath[EdgeID{From: path[i-1], To: path[i]}] = true
+	}
+
+	out := make([]FailureImpact, len(candidates))
+	parallelFor(len(candidates), 0, func(i int) {
+		id := candidates[i]
+		out[i] = FailureImpact{Edge: id, Before: before, After: before}
+		if !onPath[id] {
+			return
+		}
+		_, after := ShortestPath(g, source, target, WithOverlay(closedEdge(id)))
+		out[i].After = after
+		out[i].Increase = after - before
+		if after == INF {
+			out[i].Increase = INF
+			out[i].Unreachable = 1
+		}
+	})
+	return out
+}
+
+// FailureSweepAverage computes, for each candidate edge, how much the average
+// distance from source to the nodes it reaches grows if that edge fails.
+// The baseline shortest-path tree is computed once: failures of non-tree
+// edges cannot change any distance, and a tree edge failure only re-settles
+// the subtree below it. Failures are evaluated in parallel.
+//
+// Nodes that become unreachable are excluded from the new average and
+// counted in FailureImpact.Unreachable.
+//
+// Parameters:
+//   - g: input graph (not modified)
+//   - source: source node
+//   - candidates: edges to fail one at a time (nil for every edge of g)
+//
+// Returns:
+//   - one impact per candidate, in the same order
+func FailureSweepAverage(g *Graph, source NodeID, candidates []EdgeID) []FailureImpact {
+	if candidates == nil {
+		candidates = allEdgeIDs(g)
+	}
+
+	g.InEdges(source) // build the reverse index before the parallel repairs
+	base := newSolver(g, nil)
+	base.pred = make(map[NodeID]NodeID)
+	base.dhat[source] = 0
+	S := NewNodeSet()
+	S.Add(source)
+	base.run(INF, S)
+
+	var baseSum Dist
+	baseCount := 0
+	for v, d := range base.dhat {
+		if v != source && d < INF {
+			baseSum += d
+			baseCount++
+		}
+	}
+	before := average(baseSum, baseCount)
+	children := childrenOf(base.pred)
+
+	out := make([]FailureImpact, len(candidates))
+	parallelFor(len(candidates), 0, func(i int) {
+		id := candidates[i]
+		out[i] = FailureImpact{Edge: id, Before: before, After: before}
+		if p, ok := base.pred[id.To]; !ok || p != id.From {
+			return
+		}
+
+		s := &solver{
+			g:    g,
+			dhat: maps.Clone(base.dhat),
+			pred: maps.Clone(base.pred),
+			cfg:  newConfig([]Option{WithOverlay(closedEdge(id))}),
+		}
+		subtree := subtreeOf(children, id.To)
+		s.resettle(subtree, INF)
+
+		// Only the subtree changed: adjust the baseline sum instead of
+		// re-scanning every node.
+		sum, count := baseSum, baseCount
+		for v := range subtree {
+			sum -= base.dhat[v]
+			if d := s.dhat[v]; d < INF {
+				sum += d
+			} else {
+				count--
+				out[i].Unreachable++
+			}
+		}
+		out[i].After = average(sum, count)
+		out[i].Increase = out[i].After - before
+	})
+	return out
+}
+
+func average(sum Dist, count int) Dist {
+	if count == 0 {
+		return 0
+	}
+	return sum / Dist(count)
+}
+
+// FailureScenario is the best route under one combination of failed edges.
+type FailureScenario struct {
+	Failed []EdgeID // edges failed simultaneously
+	Path   []NodeID // best remaining path, nil if the target is cut off
+	Dist   Dist     // length of Path, INF if the target is cut off
+}
+
+// FailureCombinations enumerates every combination of up to k failed edges
+// from candidates and reports the best source-target path under each one,
+// e.g. to certify that no small cut disconnects a critical route.
+//
+// Combinations are explored depth-first, extending each one with later
+// candidates only. A search is needed only when the newly failed edge lies on
+// the best path of the combination being extended; otherwise that path
+// remains optimal and is reused. The number of combinations still grows as
+// len(candidates)^k, so keep both small.
+//
+// Returns:
+//   - one scenario per combination (the empty one first), in depth-first
+//     lexicographic order of candidate indices
+func FailureCombinations(g *Graph, source, target NodeID, candidates []EdgeID, k int) []FailureScenario {
+	var out []FailureScenario
+
+	var visit func(start int, failed []EdgeID, path []NodeID, d Dist)
+	visit = func(start int, failed []EdgeID, path []NodeID, d Dist) {
+		out = append(out, FailureScenario{Failed: failed, Path: path, Dist: d})
+		if len(failed) == k {
+			return
+		}
+
+		used := make(map[EdgeID]bool, len(path))
+		for i := 1; i < len(path); i++ {
+			used[EdgeID{From: path[i-1], To: path[i]}] = true
+		}
+
+		for i := start; i < len(candidates); i++ {
+			next := append(slices.Clone(failed), candidates[i])
+			p, nd := path, d
+			if used[candidates[i]] {
+				closed := make(closedEdges, len(next))
+				for _, id := range next {
+					closed[id] = true
+				}
+				p, nd = ShortestPath(g, source, target, WithOverlay(closed))
+			}
+			visit(i+1, next, p, nd)
+		}
+	}
+
+	path, d := ShortestPath(g, source, target)
+	visit(0, nil, path, d)
+	return out
+}
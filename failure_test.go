@@ -0,0 +1,105 @@
+package bmssp
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// withoutEdge runs fn with every edge id removed from g, restoring it afterwards.
+func withoutEdge(g *Graph, id EdgeID, fn func()) {
+	saved := slices.Clone(g.adj[id.From])
+	g.RemoveEdge(id.From, id.To)
+	fn()
+	g.numEdges += len(saved) - len(g.adj[id.From])
+	g.adj[id.From] = saved
+}
+
+func TestFailureSweep(t *testing.T) {
+	g := generateRandomGraph(80, 320, 10.0, 21)
+
+	for _, impact := range FailureSweep(g, 0, 40, nil) {
+		withoutEdge(g, impact.Edge, func() {
+			want := Dijkstra(g, 0)[40]
+			if impact.After != want && math.Abs(float64(impact.After-want)) > 1e-9 {
+				t.Errorf("edge %v: expected distance %v, got %v", impact.Edge, want, impact.After)
+			}
+		})
+	}
+}
+
+func TestFailureSweepAverage(t *testing.T) {
+	g := generateRandomGraph(80, 240, 10.0, 22)
+
+	impacts := FailureSweepAverage(g, 0, nil)
+	if len(impacts) == 0 {
+		t.Fatal("expected impacts for every edge")
+	}
+	changed := 0
+	for _, impact := range impacts {
+		withoutEdge(g, impact.Edge, func() {
+			var sum Dist
+			count := 0
+			for v, d := range Dijkstra(g, 0) {
+				if v != 0 && d < INF {
+					sum += d
+					count++
+				}
+			}
+			if want := average(sum, count); math.Abs(float64(impact.After-want)) > 1e-9 {
+				t.Errorf("edge %v: expected average %v, got %v", impact.Edge, want, impact.After)
+			}
+		})
+		if impact.Increase != 0 {
+			changed++
+		}
+	}
+	if changed == 0 {
+		t.Error("expected at least one tree edge failure to change the average")
+	}
+}
+
+func TestFailureCombinations(t *testing.T) {
+	// Three parallel routes of increasing cost between 0 and 9
+	g := NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 9, 1)
+	g.AddEdge(0, 2, 2)
+	g.AddEdge(2, 9, 2)
+	g.AddEdge(0, 3, 3)
+	g.AddEdge(3, 9, 3)
+
+	candidates := []EdgeID{{From: 0, To: 1}, {From: 2, To: 9}, {From: 3, To: 9}}
+	scenarios := FailureCombinations(g, 0, 9, candidates, 2)
+
+	// 1 + 3 + 3 combinations of at most two failures
+	if len(scenarios) != 7 {
+		t.Fatalf("expected 7 scenarios, got %d", len(scenarios))
+	}
+	for _, sc := range scenarios {
+		var want Dist
+		withoutEdges(g, sc.Failed, func() { want = Dijkstra(g, 0)[9] })
+		if sc.Dist != want {
+			t.Errorf("failed %v: expected %v, got %v", sc.Failed, want, sc.Dist)
+		}
+		if cost, ok := PathCost(g, sc.Path); sc.Path != nil && (!ok || cost != sc.Dist) {
+			t.Errorf("failed %v: path %v does not match distance %v", sc.Failed, sc.Path, sc.Dist)
+		}
+	}
+	// Depth-first order: {}, {a}, {a,b}, {a,c}, {b}, {b,c}, {c}
+	if sc := scenarios[2]; sc.Dist != 6 {
+		t.Errorf("failing routes via 1 and 2: expected 6, got %v", sc.Dist)
+	}
+	if sc := scenarios[3]; sc.Dist != 4 {
+		t.Errorf("failing routes via 1 and 3: expected 4, got %v", sc.Dist)
+	}
+}
+
+// withoutEdges runs fn with all of ids removed from g.
+func withoutEdges(g *Graph, ids []EdgeID, fn func()) {
+	if len(ids) == 0 {
+		fn()
+		return
+	}
+	withoutEdge(g, ids[0], func() { withoutEdges(g, ids[1:], fn) })
+}
@@ -0,0 +1,25 @@
+package bmssp
+
+// WithExcludedNodes keeps the query out of the nodes in set, e.g. a closed
+// junction or an area to avoid: edges entering them are treated as closed.
+// Sources are searched from even if excluded. The graph is neither
+// modified nor copied.
+func WithExcludedNodes(set NodeSet) Option {
+	return func(c *config) { c.excluded = set }
+}
+
+// WithEdgeFilter restricts the query to the edges for which keep returns
+// true, e.g. to close a road temporarily or to ban a vehicle class from
+// some edges; rejected edges are treated as closed. keep is called for
+// every edge scanned and must be safe for concurrent use if the query is.
+func WithEdgeFilter(keep func(u NodeID, e Edge) bool) Option {
+	return func(c *config) { c.edgeFilter = keep }
+}
+
+// allowed reports whether the query may use the edge e leaving u.
+func (s *solver) allowed(u NodeID, e Edge) bool {
+	if s.cfg.excluded.Has(e.To) {
+		return false
+	}
+	return s.cfg.edgeFilter == nil || s.cfg.edgeFilter(u, e)
+}
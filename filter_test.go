@@ -0,0 +1,42 @@
+package bmssp
+
+import "testing"
+
+func TestWithExcludedNodes(t *testing.T) {
+	g := generateGridGraph(3, 3)
+	avoid := NewNodeSet()
+	avoid.Add(1)
+	avoid.Add(4)
+	path, d := ShortestPath(g, 0, 2, WithExcludedNodes(avoid))
+	if d != 6 || len(path) != 7 {
+		t.Errorf("expected a detour of length 6, got %v via %v", d, path)
+	}
+	for _, v := range path {
+		if avoid.Has(v) {
+			t.Errorf("path %v enters excluded node %d", path, v)
+		}
+	}
+	if res := Solve(g, sources(0), INF, WithExcludedNodes(avoid)); res.Dist[4] != INF {
+		t.Errorf("excluded node reached at %v", res.Dist[4])
+	}
+	if _, d := ShortestPath(g, 0, 2); d != 2 {
+		t.Errorf("graph was modified: distance %v", d)
+	}
+}
+
+func TestWithEdgeFilter(t *testing.T) {
+	g := generateGridGraph(3, 3)
+	noRight := func(u NodeID, e Edge) bool { return e.To != u+1 }
+	for _, opts := range [][]Option{{WithEdgeFilter(noRight)}, {WithEdgeFilter(noRight), WithoutFastPaths()}} {
+		if d := BMSSPSingleSource(g, 0, INF, opts...)[2]; d != INF {
+			t.Errorf("expected node 2 unreachable without rightward edges, got %v", d)
+		}
+	}
+	// Backward searches see the original edge directions
+	if d := BMSSPSingleTarget(g, 2, INF, WithEdgeFilter(noRight))[0]; d != INF {
+		t.Errorf("expected node 0 unable to reach 2, got %v", d)
+	}
+	if d := BMSSPSingleTarget(g, 0, INF, WithEdgeFilter(noRight))[2]; d != 2 {
+		t.Errorf("expected distance 2 from node 2 to 0, got %v", d)
+	}
+}
@@ -0,0 +1,167 @@
+package bmssp
+
+import (
+	"container/heap"
+	"math"
+	"slices"
+)
+
+// float32Unit is the unit roundoff of float32 arithmetic: a rounded sum is
+// within float32Unit times its magnitude of the exact sum.
+const float32Unit = 0x1p-24
+
+// Float32Result holds shortest distances computed in float32 arithmetic,
+// together with a conservative bound on the rounding error of each distance.
+// Nodes are stored in dense arrays, so a result takes about 12 bytes per node
+// instead of the two maps of a Result.
+type Float32Result struct {
+	nodes []NodeID
+	index map[NodeID]int32
+	dist  []float32
+	err   []float32
+	pred  []int32 // -1 for sources and unreached nodes
+}
+
+// float32Item is a heap entry; stale entries are skipped when popped.
+type float32Item struct {
+	node int32
+	dist float32
+}
+
+type float32Heap []float32Item
+
+func (h float32Heap) Len() int           { return len(h) }
+func (h float32Heap) Less(i, j int) bool { return h[i].dist < h[j].dist }
+func (h float32Heap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *float32Heap) Push(x any)        { *h = append(*h, x.(float32Item)) }
+func (h *float32Heap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// SolveFloat32 computes shortest distances from sources within bound B using
+// float32 arithmetic, for memory-constrained deployments. Alongside each
+// distance it reports an upper bound on the rounding error accumulated along
+// the returned path: the error of converting each edge weight to float32 plus
+// the rounding of every addition. Comparing the bound with the distance tells
+// whether the precision loss matters for a result.
+//
+// Parameters:
+//   - g: input graph (not modified)
+//   - sources: set of source nodes, all at distance 0
+//   - B: distance bound
+//
+// Returns:
+//   - float32 distances and error bounds for every node of g
+func SolveFloat32(g *Graph, sources NodeSet, B Dist) *Float32Result {
+	r := &Float32Result{index: make(map[NodeID]int32, len(g.adj))}
+	for u := range g.adj {
+		r.nodes = append(r.nodes, u)
+	}
+	slices.Sort(r.nodes)
+	for i, u := range r.nodes {
+		r.index[u] = int32(i)
+	}
+
+	n := len(r.nodes)
+	inf := float32(math.Inf(1))
+	r.dist = make([]float32, n)
+	r.err = make([]float32, n)
+	r.pred = make([]int32, n)
+	for i := range r.dist {
+		r.dist[i] = inf
+		r.pred[i] = -1
+	}
+
+	var pq float32Heap
+	for v := range sources {
+		if i, ok := r.index[v]; ok {
+			r.dist[i] = 0
+			heap.Push(&pq, float32Item{node: i})
+		}
+	}
+
+	settled := make([]bool, n)
+	for pq.Len() > 0 {
+		item := heap.Pop(&pq).(float32Item)
+		u := item.node
+		if settled[u] || item.dist > r.dist[u] {
+			continue
+		}
+		settled[u] = true
+
+		for _, e := range g.adj[r.nodes[u]] {
+			v := r.index[e.To]
+			w := float32(e.Weight)
+			d := r.dist[u] + w
+			if d >= r.dist[v] || Dist(d) > B {
+				continue
+			}
+			r.dist[v] = d
+			r.pred[v] = u
+
+			// Conversion error of w plus rounding of the addition
+			bound := float64(r.err[u]) +
+				math.Abs(float64(w)-float64(e.Weight)) +
+				float32Unit*math.Abs(float64(d))
+			r.err[v] = roundUp32(bound)
+			heap.Push(&pq, float32Item{node: v, dist: d})
+		}
+	}
+	return r
+}
+
+// roundUp32 converts x to the smallest float32 not below it.
+func roundUp32(x float64) float32 {
+	f := float32(x)
+	if float64(f) < x {
+		f = math.Nextafter32(f, float32(math.Inf(1)))
+	}
+	return f
+}
+
+// Dist returns the float32 distance of v, or +Inf if v is unreachable or
+// unknown.
+func (r *Float32Result) Dist(v NodeID) float32 {
+	i, ok := r.index[v]
+	if !ok {
+		return float32(math.Inf(1))
+	}
+	return r.dist[i]
+}
+
+// ErrorBound returns an upper bound on |Dist(v) - c| where c is the exact
+// float64 cost of the path returned by PathTo(v). It is 0 for sources and
+// unreachable nodes.
+func (r *Float32Result) ErrorBound(v NodeID) float32 {
+	i, ok := r.index[v]
+	if !ok {
+		return 0
+	}
+	return r.err[i]
+}
+
+// MaxErrorBound returns the largest ErrorBound over all nodes.
+func (r *Float32Result) MaxErrorBound() float32 {
+	var m float32
+	for _, e := range r.err {
+		m = max(m, e)
+	}
+	return m
+}
+
+// PathTo returns the path found to v, or nil if v is unreachable.
+func (r *Float32Result) PathTo(v NodeID) []NodeID {
+	i, ok := r.index[v]
+	if !ok || math.IsInf(float64(r.dist[i]), 1) {
+		return nil
+	}
+	var path []NodeID
+	for ; i >= 0; i = r.pred[i] {
+		path = append(path, r.nodes[i])
+	}
+	slices.Reverse(path)
+	return path
+}
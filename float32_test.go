@@ -0,0 +1,40 @@
+package bmssp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSolveFloat32(t *testing.T) {
+	g := generateRandomGraph(500, 3000, 1000.1, 7)
+	want := Dijkstra(g, 0)
+	res := SolveFloat32(g, sources(0), INF)
+
+	for v, d := range want {
+		got := res.Dist(v)
+		if d == INF {
+			if !math.IsInf(float64(got), 1) {
+				t.Errorf("node %d: expected unreachable, got %v", v, got)
+			}
+			continue
+		}
+
+		// The bound must cover the float64 cost of the reported path
+		path := res.PathTo(v)
+		cost, ok := PathCost(g, path)
+		if !ok {
+			t.Fatalf("node %d: invalid path %v", v, path)
+		}
+		if diff := math.Abs(float64(got) - float64(cost)); diff > float64(res.ErrorBound(v)) {
+			t.Errorf("node %d: |%v - %v| = %v exceeds bound %v", v, got, cost, diff, res.ErrorBound(v))
+		}
+		if rel := math.Abs(float64(got)-float64(d)) / float64(d+1); rel > 1e-4 {
+			t.Errorf("node %d: expected ~%v, got %v", v, d, got)
+		}
+	}
+
+	if res.ErrorBound(0) != 0 || res.MaxErrorBound() <= 0 {
+		t.Errorf("expected zero bound at the source and a positive maximum, got %v and %v",
+			res.ErrorBound(0), res.MaxErrorBound())
+	}
+}
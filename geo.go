@@ -0,0 +1,216 @@
+package bmssp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// ErrInvalidCellSize is returned by NewGeoIndex for a GridScheme whose cell
+// size is not positive and finite.
+var ErrInvalidCellSize = errors.New("bmssp: invalid grid cell size")
+
+// earthRadiusMeters is the mean Earth radius used for great-circle distances.
+const earthRadiusMeters = 6371008.8
+
+// LatLng is a geographic coordinate in degrees.
+type LatLng struct {
+	Lat float64
+	Lng float64
+}
+
+// CellID identifies a cell of a geographic cell scheme (an S2 cell ID,
+// an H3 index, a grid cell, ...).
+type CellID uint64
+
+// CellScheme maps coordinates onto cells. Implementations can wrap S2 or H3
+// (CellOf is s2.CellIDFromLatLng / h3.LatLngToCell, Covering is a region
+// coverer / grid disk); GridScheme is a dependency-free fallback.
+type CellScheme interface {
+	// CellOf returns the cell containing p.
+	CellOf(p LatLng) CellID
+	// Covering returns a set of cells that together contain every point
+	// within radiusMeters of center. It may over-cover.
+	Covering(center LatLng, radiusMeters float64) []CellID
+}
+
+// GridScheme is an equirectangular grid of CellSize×CellSize degree cells.
+type GridScheme struct {
+	CellSize float64 // cell edge length in degrees; must be positive
+}
+
+// valid reports whether the cell size is positive and finite.
+func (s GridScheme) valid() bool {
+	return s.CellSize > 0 && !math.IsInf(s.CellSize, 1)
+}
+
+func (s GridScheme) rows() int { return int(math.Ceil(180 / s.CellSize)) }
+func (s GridScheme) cols() int { return int(math.Ceil(360 / s.CellSize)) }
+
+func (s GridScheme) cell(row, col int) CellID {
+	return CellID(uint64(row)<<32 | uint64(col)) //nolint:gosec // row and col are non-negative
+}
+
+func (s GridScheme) rowCol(p LatLng) (row, col int) {
+	row = int(math.Floor((p.Lat + 90) / s.CellSize))
+	col = int(math.Floor((p.Lng + 180) / s.CellSize))
+	row = min(max(row, 0), s.rows()-1)
+	col = ((col % s.cols()) + s.cols()) % s.cols()
+	return row, col
+}
+
+// CellOf returns the grid cell containing p.
+func (s GridScheme) CellOf(p LatLng) CellID {
+	return s.cell(s.rowCol(p))
+}
+
+// Covering returns the grid cells overlapping the bounding box of the circle
+// around center, wrapping across the antimeridian and covering every
+// longitude if the circle contains a pole. It returns nil for a negative or
+// NaN radius and for an invalid cell size.
+func (s GridScheme) Covering(center LatLng, radiusMeters float64) []CellID {
+	if !(radiusMeters >= 0) || !s.valid() {
+		return nil
+	}
+	r := radiusMeters / earthRadiusMeters // angular radius in radians
+	dLat := r * 180 / math.Pi
+
+	minRow, _ := s.rowCol(LatLng{Lat: center.Lat - dLat})
+	maxRow, _ := s.rowCol(LatLng{Lat: center.Lat + dLat})
+
+	// A circle containing a pole spans every longitude; otherwise its
+	// longitude extent is asin(sin r / cos lat) on either side
+	firstCol, span := 0, s.cols()
+	if center.Lat-dLat > -90 && center.Lat+dLat < 90 {
+		if x := math.Sin(r) / math.Cos(center.Lat*math.Pi/180); x < 1 {
+			dLng := math.Asin(x) * 180 / math.Pi
+			_, firstCol = s.rowCol(LatLng{Lng: center.Lng - dLng})
+			span = min(int(math.Ceil(2*dLng/s.CellSize))+1, s.cols())
+		}
+	}
+
+	out := make([]CellID, 0, (maxRow-minRow+1)*span)
+	for row := minRow; row <= maxRow; row++ {
+		for i := 0; i < span; i++ {
+			out = append(out, s.cell(row, (firstCol+i)%s.cols()))
+		}
+	}
+	return out
+}
+
+// HaversineDistance returns the great-circle distance between a and b in meters.
+func HaversineDistance(a, b LatLng) float64 {
+	const rad = math.Pi / 180
+	dLat := (b.Lat - a.Lat) * rad
+	dLng := (b.Lng - a.Lng) * rad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(a.Lat*rad)*math.Cos(b.Lat*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// GeoIndex buckets graph nodes by the cell of their location, supporting
+// geofenced search and partitioning of geographic graphs.
+type GeoIndex struct {
+	scheme CellScheme
+	locs   map[NodeID]LatLng
+	cellOf map[NodeID]CellID
+	cells  map[CellID]NodeSet
+}
+
+// NewGeoIndex creates an empty index over the given cell scheme.
+//
+// Returns:
+//   - the index
+//   - ErrInvalidCellSize for a GridScheme whose CellSize is not positive
+//     and finite, as every cell lookup would fail
+func NewGeoIndex(scheme CellScheme) (*GeoIndex, error) {
+	if gs, ok := scheme.(GridScheme); ok && !gs.valid() {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCellSize, gs.CellSize)
+	}
+	return &GeoIndex{
+		scheme: scheme,
+		locs:   make(map[NodeID]LatLng),
+		cellOf: make(map[NodeID]CellID),
+		cells:  make(map[CellID]NodeSet),
+	}, nil
+}
+
+// Scheme returns the cell scheme used by the index.
+func (gi *GeoIndex) Scheme() CellScheme {
+	return gi.scheme
+}
+
+// Set records (or moves) the location of node v.
+func (gi *GeoIndex) Set(v NodeID, p LatLng) {
+	gi.Remove(v)
+
+	c := gi.scheme.CellOf(p)
+	if gi.cells[c] == nil {
+		gi.cells[c] = NewNodeSet()
+	}
+	gi.cells[c].Add(v)
+	gi.locs[v] = p
+	gi.cellOf[v] = c
+}
+
+// Remove drops node v from the index.
+func (gi *GeoIndex) Remove(v NodeID) {
+	c, ok := gi.cellOf[v]
+	if !ok {
+		return
+	}
+	delete(gi.cells[c], v)
+	if len(gi.cells[c]) == 0 {
+		delete(gi.cells, c)
+	}
+	delete(gi.locs, v)
+	delete(gi.cellOf, v)
+}
+
+// Location returns the recorded location of node v.
+func (gi *GeoIndex) Location(v NodeID) (LatLng, bool) {
+	p, ok := gi.locs[v]
+	return p, ok
+}
+
+// CellOf returns the cell node v was bucketed into.
+func (gi *GeoIndex) CellOf(v NodeID) (CellID, bool) {
+	c, ok := gi.cellOf[v]
+	return c, ok
+}
+
+// NodesInCell returns the nodes located in cell c, sorted by ID.
+func (gi *GeoIndex) NodesInCell(c CellID) []NodeID {
+	out := gi.cells[c].ToSlice()
+	slices.Sort(out)
+	return out
+}
+
+// NodesInRadius returns the nodes within radiusMeters of center, sorted by ID.
+// Only the cells of the scheme's covering are scanned.
+func (gi *GeoIndex) NodesInRadius(center LatLng, radiusMeters float64) []NodeID {
+	var out []NodeID
+	for _, c := range gi.scheme.Covering(center, radiusMeters) {
+		for v := range gi.cells[c] {
+			if HaversineDistance(center, gi.locs[v]) <= radiusMeters {
+				out = append(out, v)
+			}
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// Partition returns the nodes grouped by cell. The returned sets are copies.
+func (gi *GeoIndex) Partition() map[CellID]NodeSet {
+	out := make(map[CellID]NodeSet, len(gi.cells))
+	for c, set := range gi.cells {
+		cp := NewNodeSet()
+		for v := range set {
+			cp.Add(v)
+		}
+		out[c] = cp
+	}
+	return out
+}
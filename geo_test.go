@@ -0,0 +1,93 @@
+package bmssp
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestGeoIndex_CellsAndRadius(t *testing.T) {
+	gi, err := NewGeoIndex(GridScheme{CellSize: 0.01})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Three points in central Berlin and one in Potsdam (~27km away)
+	gi.Set(0, LatLng{Lat: 52.5200, Lng: 13.4050})
+	gi.Set(1, LatLng{Lat: 52.5205, Lng: 13.4060})
+	gi.Set(2, LatLng{Lat: 52.5160, Lng: 13.3777})
+	gi.Set(3, LatLng{Lat: 52.3906, Lng: 13.0645})
+
+	c0, _ := gi.CellOf(0)
+	if got := gi.NodesInCell(c0); !slices.Equal(got, []NodeID{0, 1}) {
+		t.Errorf("NodesInCell: expected [0 1], got %v", got)
+	}
+
+	center := LatLng{Lat: 52.5200, Lng: 13.4050}
+	if got := gi.NodesInRadius(center, 3000); !slices.Equal(got, []NodeID{0, 1, 2}) {
+		t.Errorf("NodesInRadius(3km): expected [0 1 2], got %v", got)
+	}
+	if got := gi.NodesInRadius(center, 50000); !slices.Equal(got, []NodeID{0, 1, 2, 3}) {
+		t.Errorf("NodesInRadius(50km): expected all nodes, got %v", got)
+	}
+
+	// Moving a node updates its bucket
+	gi.Set(1, LatLng{Lat: 52.3906, Lng: 13.0645})
+	if got := gi.NodesInCell(c0); !slices.Equal(got, []NodeID{0}) {
+		t.Errorf("after move: expected [0], got %v", got)
+	}
+	if n := len(gi.Partition()); n != 3 {
+		t.Errorf("Partition: expected 3 cells, got %d", n)
+	}
+}
+
+func TestGridScheme_CoveringAntimeridian(t *testing.T) {
+	gi, _ := NewGeoIndex(GridScheme{CellSize: 1})
+	gi.Set(0, LatLng{Lat: 0, Lng: 179.9})
+	gi.Set(1, LatLng{Lat: 0, Lng: -179.9})
+
+	if got := gi.NodesInRadius(LatLng{Lat: 0, Lng: 179.95}, 20000); !slices.Equal(got, []NodeID{0, 1}) {
+		t.Errorf("expected both sides of the antimeridian, got %v", got)
+	}
+}
+
+func TestGridScheme_InvalidInput(t *testing.T) {
+	s := GridScheme{CellSize: 1}
+	for _, r := range []float64{-1, math.NaN()} {
+		if got := s.Covering(LatLng{}, r); got != nil {
+			t.Errorf("radius %v: expected no cells, got %d", r, len(got))
+		}
+	}
+	if got := (GridScheme{}).Covering(LatLng{}, 1000); got != nil {
+		t.Errorf("zero cell size: expected no cells, got %d", len(got))
+	}
+
+	for _, size := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if gi, err := NewGeoIndex(GridScheme{CellSize: size}); gi != nil || !errors.Is(err, ErrInvalidCellSize) {
+			t.Errorf("cell size %v: expected ErrInvalidCellSize, got %v", size, err)
+		}
+	}
+}
+
+func TestGridScheme_CoveringPole(t *testing.T) {
+	gi, _ := NewGeoIndex(GridScheme{CellSize: 1})
+	far := LatLng{Lat: 89.5, Lng: 180}
+	gi.Set(0, far)
+	gi.Set(1, LatLng{Lat: 89.5, Lng: 90})
+	gi.Set(2, LatLng{Lat: 80, Lng: 180})
+
+	// The circle around center crosses the north pole
+	center := LatLng{Lat: 89.5, Lng: 0}
+	if got := gi.NodesInRadius(center, HaversineDistance(center, far)+1000); !slices.Equal(got, []NodeID{0, 1}) {
+		t.Errorf("expected the nodes across the pole, got %v", got)
+	}
+
+	// Short of a pole the longitude extent still grows with latitude
+	center = LatLng{Lat: 85, Lng: 0}
+	edge := LatLng{Lat: 85, Lng: 60}
+	gi.Set(3, edge)
+	if got := gi.NodesInRadius(center, HaversineDistance(center, edge)+1000); !slices.Contains(got, 3) {
+		t.Errorf("expected node 3 at longitude 60, got %v", got)
+	}
+}
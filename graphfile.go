@@ -0,0 +1,179 @@
+package bmssp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"iter"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"sort"
+)
+
+// Graph file format: a 24-byte header of the magic "BMSC", a format version
+// byte and three bytes of padding, the node count n and the edge count m,
+// followed by the sorted node IDs (n int64), the CSR offsets of every
+// node's edges (n+1 uint64), the edges' head indexes (m uint64) and their
+// weights (m float64). All fields are little-endian and 8-byte aligned, so
+// any of them is read in place without decoding the file.
+const (
+	graphFileMagic   = "BMSC"
+	graphFileVersion = 1
+	graphFileHeader  = 24
+)
+
+// WriteGraphFile writes g to path in the memory-mappable CSR format read by
+// OpenGraphFile. Edge data is not stored.
+//
+// Returns:
+//   - the first error creating or writing the file
+func WriteGraphFile(g *Graph, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	nodes := slices.Sorted(maps.Keys(g.adj))
+	index := make(map[NodeID]uint64, len(nodes))
+	var m uint64
+	for i, v := range nodes {
+		index[v] = uint64(i)
+		m += uint64(len(g.adj[v]))
+	}
+
+	var buf [8]byte
+	put := func(x uint64) {
+		binary.LittleEndian.PutUint64(buf[:], x)
+		w.Write(buf[:]) // errors are sticky and reported by Flush
+	}
+	w.WriteString(graphFileMagic)
+	w.Write([]byte{graphFileVersion, 0, 0, 0})
+	put(uint64(len(nodes)))
+	put(m)
+	for _, v := range nodes {
+		put(uint64(v))
+	}
+	var off uint64
+	put(0)
+	for _, v := range nodes {
+		off += uint64(len(g.adj[v]))
+		put(off)
+	}
+	for _, v := range nodes {
+		for _, e := range g.adj[v] {
+			put(index[e.To])
+		}
+	}
+	for _, v := range nodes {
+		for _, e := range g.adj[v] {
+			put(math.Float64bits(float64(e.Weight)))
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// GraphFile is a graph in the CSR file format, memory-mapped where the
+// platform supports it: the edge arrays stay on disk and the operating
+// system pages in the parts a query touches, so graphs larger than memory
+// can be queried. It is a GraphReader, searched with SolveReader and
+// SolveDijkstraReader; searches still keep their distances in memory.
+//
+// A GraphFile is safe for concurrent use until Close.
+type GraphFile struct {
+	data  []byte
+	n, m  int
+	close func() error
+}
+
+// OpenGraphFile maps a graph written by WriteGraphFile. Only the header is
+// checked; offsets and heads out of range are skipped when read.
+//
+// Returns:
+//   - the mapped graph; Close releases it
+//   - ErrCorruptGraph if the file is not in the format or is truncated, or
+//     the error opening or mapping it
+func OpenGraphFile(path string) (*GraphFile, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < graphFileHeader || string(data[:4]) != graphFileMagic {
+		unmap()
+		return nil, fmt.Errorf("%w: not a graph file", ErrCorruptGraph)
+	}
+	if version := data[4]; version != graphFileVersion {
+		unmap()
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrCorruptGraph, version)
+	}
+	n, m := binary.LittleEndian.Uint64(data[8:]), binary.LittleEndian.Uint64(data[16:])
+	size := uint64(len(data)-graphFileHeader) / 8
+	if n > size || m > size || 2*n+1+2*m != size {
+		unmap()
+		return nil, fmt.Errorf("%w: truncated", ErrCorruptGraph)
+	}
+	return &GraphFile{data: data, n: int(n), m: int(m), close: unmap}, nil
+}
+
+// word returns the i-th 8-byte word after the header.
+func (gf *GraphFile) word(i int) uint64 {
+	return binary.LittleEndian.Uint64(gf.data[graphFileHeader+8*i:])
+}
+
+// id returns the node ID at index i.
+func (gf *GraphFile) id(i int) NodeID {
+	return NodeID(int64(gf.word(i)))
+}
+
+// NodeCount returns the number of nodes.
+func (gf *GraphFile) NodeCount() int {
+	return gf.n
+}
+
+// Nodes yields the nodes in increasing ID order.
+func (gf *GraphFile) Nodes() iter.Seq[NodeID] {
+	return func(yield func(NodeID) bool) {
+		for i := range gf.n {
+			if !yield(gf.id(i)) {
+				return
+			}
+		}
+	}
+}
+
+// OutEdges yields the edges leaving u, found by binary search over the
+// node IDs.
+func (gf *GraphFile) OutEdges(u NodeID) iter.Seq[Edge] {
+	return func(yield func(Edge) bool) {
+		i := sort.Search(gf.n, func(i int) bool { return gf.id(i) >= u })
+		if i == gf.n || gf.id(i) != u {
+			return
+		}
+		offsets, heads, weights := gf.n, 2*gf.n+1, 2*gf.n+1+gf.m
+		lo, hi := gf.word(offsets+i), gf.word(offsets+i+1)
+		if lo > hi || hi > uint64(gf.m) {
+			return
+		}
+		for j := int(lo); j < int(hi); j++ {
+			h := gf.word(heads + j)
+			if h >= uint64(gf.n) {
+				continue
+			}
+			w := Dist(math.Float64frombits(gf.word(weights + j)))
+			if !yield(Edge{To: gf.id(int(h)), Weight: w}) {
+				return
+			}
+		}
+	}
+}
+
+// Close unmaps the file. The GraphFile must not be used afterwards.
+func (gf *GraphFile) Close() error {
+	return gf.close()
+}
@@ -0,0 +1,63 @@
+package bmssp
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestGraphFile(t *testing.T) {
+	g := generateRandomGraph(300, 1200, 10, 4)
+	g.AddEdge(-5, 0, 2.5)
+	path := filepath.Join(t.TempDir(), "graph.bmsc")
+	if err := WriteGraphFile(g, path); err != nil {
+		t.Fatal(err)
+	}
+	gf, err := OpenGraphFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gf.Close()
+
+	if gf.NodeCount() != len(g.adj) || !slices.Equal(slices.Collect(gf.Nodes()), slices.Sorted(maps.Keys(g.adj))) {
+		t.Errorf("expected the graph's %d nodes in order", len(g.adj))
+	}
+	if edges := slices.Collect(gf.OutEdges(-5)); len(edges) != 1 || edges[0] != (Edge{To: 0, Weight: 2.5}) {
+		t.Errorf("expected the edge -5->0, got %v", edges)
+	}
+	if edges := slices.Collect(gf.OutEdges(1000)); edges != nil {
+		t.Errorf("expected no edges for an unknown node, got %v", edges)
+	}
+	want := Solve(g, sources(-5), INF)
+	if res := SolveReader(gf, sources(-5), INF); !maps.Equal(res.Dist, want.Dist) {
+		t.Errorf("distances over the mapped file differ from the graph's")
+	}
+}
+
+func TestOpenGraphFile_Corrupt(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good")
+	if err := WriteGraphFile(generateGridGraph(3, 3), good); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(good)
+
+	for name, content := range map[string][]byte{
+		"empty":     nil,
+		"magic":     append([]byte("XXXX"), data[4:]...),
+		"version":   append(append([]byte("BMSC"), 9), data[5:]...),
+		"truncated": data[:len(data)-8],
+	} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, content, 0o644)
+		if _, err := OpenGraphFile(path); !errors.Is(err, ErrCorruptGraph) {
+			t.Errorf("%s: expected ErrCorruptGraph, got %v", name, err)
+		}
+	}
+	if _, err := OpenGraphFile(filepath.Join(dir, "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a missing file to fail, got %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+package bmssp
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrBadGTFS is returned for GTFS feeds that cannot be interpreted.
+var ErrBadGTFS = errors.New("bmssp: malformed GTFS feed")
+
+// GTFSOptions configures ReadGTFS.
+type GTFSOptions struct {
+	// MinTransferTime is the time in seconds needed to change vehicles at a
+	// stop. Staying on the same trip needs no transfer time.
+	MinTransferTime Dist
+}
+
+// transitEvent is an arrival or departure of a trip at a stop.
+type transitEvent struct {
+	stop string
+	time Dist // seconds since midnight of the service day
+}
+
+// TransitGraph is a time-expanded graph of a transit timetable. Every
+// arrival and departure of a trip at a stop is a node, and every departure
+// also has a boarding node for passengers waiting at the stop. Edges ride a
+// vehicle to its next stop, stay on it while it dwells, wait at a stop for
+// the next boarding, board, or transfer from an arrival to a later boarding.
+// Every edge weighs the time between its events, so the distance from a
+// boarding node is the time elapsed since it.
+type TransitGraph struct {
+	g        *Graph
+	events   []transitEvent
+	boarding map[string][]NodeID // boarding nodes of each stop by time
+	arrivals map[string][]NodeID // arrival events of each stop
+}
+
+// stopTime is a timed row of stop_times.txt.
+type stopTime struct {
+	seq                int
+	stop               string
+	arrival, departure Dist
+}
+
+// ReadGTFS builds a time-expanded graph from the stop_times.txt file of a
+// GTFS feed. fsys may be a directory (os.DirFS) or a zipped feed
+// (zip.Reader). Rows without arrival and departure times are skipped.
+//
+// Returns:
+//   - the transit graph
+//   - ErrBadGTFS if stop_times.txt is missing columns or has invalid values,
+//     or the error opening or reading it
+func ReadGTFS(fsys fs.FS, opts GTFSOptions) (*TransitGraph, error) {
+	f, err := fsys.Open("stop_times.txt")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	trips, err := readStopTimes(f)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &TransitGraph{
+		g:        NewGraph(),
+		boarding: make(map[string][]NodeID),
+		arrivals: make(map[string][]NodeID),
+	}
+	event := func(stop string, time Dist) NodeID {
+		v := NodeID(len(t.events))
+		t.events = append(t.events, transitEvent{stop: stop, time: time})
+		return v
+	}
+
+	// Number events in trip order so that node IDs are stable across runs
+	ids := slices.Sorted(maps.Keys(trips))
+	for _, id := range ids {
+		stops := trips[id]
+		slices.SortFunc(stops, func(a, b stopTime) int { return cmp.Compare(a.seq, b.seq) })
+		prev := NodeID(-1)
+		for _, st := range stops {
+			arr := event(st.stop, st.arrival)
+			dep := event(st.stop, st.departure)
+			board := event(st.stop, st.departure)
+			t.arrivals[st.stop] = append(t.arrivals[st.stop], arr)
+			t.boarding[st.stop] = append(t.boarding[st.stop], board)
+			t.g.AddEdge(arr, dep, st.departure-st.arrival) // dwell
+			t.g.AddEdge(board, dep, 0)
+			if prev >= 0 {
+				t.g.AddEdge(prev, arr, st.arrival-t.events[prev].time) // ride
+			}
+			prev = dep
+		}
+	}
+
+	for stop, boards := range t.boarding {
+		slices.SortFunc(boards, func(a, b NodeID) int { return cmp.Compare(t.events[a].time, t.events[b].time) })
+		for i := 1; i < len(boards); i++ {
+			t.g.AddEdge(boards[i-1], boards[i], t.events[boards[i]].time-t.events[boards[i-1]].time) // wait
+		}
+		for _, arr := range t.arrivals[stop] {
+			if board, ok := t.nextBoarding(stop, t.events[arr].time+opts.MinTransferTime); ok {
+				t.g.AddEdge(arr, board, t.events[board].time-t.events[arr].time) // transfer
+			}
+		}
+	}
+	return t, nil
+}
+
+// readStopTimes reads the timed rows of stop_times.txt grouped by trip.
+func readStopTimes(r io.Reader) (map[string][]stopTime, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading stop_times.txt header: %v", ErrBadGTFS, err)
+	}
+	col := make(map[string]int)
+	for i, name := range header {
+		col[strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")] = i
+	}
+	var idx [5]int
+	for i, name := range []string{"trip_id", "arrival_time", "departure_time", "stop_id", "stop_sequence"} {
+		c, ok := col[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: stop_times.txt has no %s column", ErrBadGTFS, name)
+		}
+		idx[i] = c
+	}
+
+	trips := make(map[string][]stopTime)
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return trips, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		if len(rec) <= slices.Max(idx[:]) {
+			return nil, fmt.Errorf("%w: stop_times.txt line %d: too few columns", ErrBadGTFS, line)
+		}
+		if rec[idx[1]] == "" && rec[idx[2]] == "" {
+			continue
+		}
+
+		st := stopTime{stop: rec[idx[3]]}
+		if st.seq, err = strconv.Atoi(strings.TrimSpace(rec[idx[4]])); err != nil {
+			return nil, fmt.Errorf("%w: stop_times.txt line %d: bad stop_sequence %q", ErrBadGTFS, line, rec[idx[4]])
+		}
+		if st.arrival, err = parseGTFSTime(rec[idx[1]], rec[idx[2]]); err != nil {
+			return nil, fmt.Errorf("%w: stop_times.txt line %d: %v", ErrBadGTFS, line, err)
+		}
+		if st.departure, err = parseGTFSTime(rec[idx[2]], rec[idx[1]]); err != nil {
+			return nil, fmt.Errorf("%w: stop_times.txt line %d: %v", ErrBadGTFS, line, err)
+		}
+		if st.departure < st.arrival {
+			return nil, fmt.Errorf("%w: stop_times.txt line %d: departure before arrival", ErrBadGTFS, line)
+		}
+		trip := rec[idx[0]]
+		trips[trip] = append(trips[trip], st)
+	}
+}
+
+// parseGTFSTime parses an H:MM:SS time, which may exceed 24:00:00 for trips
+// running past midnight, into seconds. An empty s falls back to other.
+func parseGTFSTime(s, other string) (Dist, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		s = strings.TrimSpace(other)
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("bad time %q", s)
+	}
+	var secs int
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("bad time %q", s)
+		}
+		secs = secs*60 + n
+	}
+	return Dist(secs), nil
+}
+
+// nextBoarding returns the first boarding node of stop at or after time.
+func (t *TransitGraph) nextBoarding(stop string, time Dist) (NodeID, bool) {
+	deps := t.boarding[stop]
+	i, _ := slices.BinarySearchFunc(deps, time, func(v NodeID, time Dist) int {
+		return cmp.Compare(t.events[v].time, time)
+	})
+	if i == len(deps) {
+		return 0, false
+	}
+	return deps[i], true
+}
+
+// Graph returns the time-expanded graph. Its node IDs identify events; see
+// Event.
+func (t *TransitGraph) Graph() *Graph {
+	return t.g
+}
+
+// Event returns the stop and time in seconds of event node v, reporting
+// false if v is not an event.
+func (t *TransitGraph) Event(v NodeID) (stop string, time Dist, ok bool) {
+	if v < 0 || int(v) >= len(t.events) {
+		return "", 0, false
+	}
+	e := t.events[v]
+	return e.stop, e.time, true
+}
+
+// EarliestArrival returns the earliest time at which stop 'to' can be reached
+// when leaving stop 'from' no earlier than depart. Times are in seconds since
+// midnight of the service day.
+//
+// Parameters:
+//   - from, to: stop IDs
+//   - depart: earliest departure time
+//   - opts: optional query settings
+//
+// Returns:
+//   - the arrival time
+//   - false if 'to' cannot be reached
+func (t *TransitGraph) EarliestArrival(from, to string, depart Dist, opts ...Option) (Dist, bool) {
+	if from == to {
+		return depart, true
+	}
+	first, ok := t.nextBoarding(from, depart)
+	if !ok {
+		return INF, false
+	}
+
+	S := NewNodeSet()
+	S.Add(first)
+	res := Solve(t.g, S, INF, opts...)
+
+	best := INF
+	for _, arr := range t.arrivals[to] {
+		if d := res.Dist[arr]; d < INF {
+			best = min(best, t.events[first].time+d)
+		}
+	}
+	return best, best < INF
+}
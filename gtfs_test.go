@@ -0,0 +1,70 @@
+package bmssp
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReadGTFS(t *testing.T) {
+	// Trip a runs A -> B -> C; trip b runs B -> D and leaves B 3 minutes
+	// after a arrives there; trip c runs A -> D directly but slowly.
+	feed := fstest.MapFS{"stop_times.txt": {Data: []byte(
+		"\ufefftrip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+			"a,08:00:00,08:00:00,A,1\n" +
+			"a,08:10:00,08:11:00,B,2\n" +
+			"a,08:20:00,08:20:00,C,3\n" +
+			"b,08:13:00,08:13:00,B,1\n" +
+			"b,08:25:00,08:25:00,D,2\n" +
+			"c,08:05:00,08:05:00,A,1\n" +
+			"c,08:40:00,08:40:00,D,2\n" +
+			"c,,,X,3\n",
+	)}}
+
+	tg, err := ReadGTFS(feed, GTFSOptions{MinTransferTime: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		from, to string
+		depart   Dist
+		want     Dist
+	}{
+		{"A", "C", 8 * 3600, 8*3600 + 20*60},
+		{"A", "D", 8 * 3600, 8*3600 + 25*60},    // a, transfer at B, b
+		{"A", "D", 8*3600 + 60, 8*3600 + 40*60}, // misses a, takes c
+		{"B", "B", 9 * 3600, 9 * 3600},
+		{"C", "A", 7 * 3600, INF},
+	}
+	for _, tt := range tests {
+		got, ok := tg.EarliestArrival(tt.from, tt.to, tt.depart)
+		if got != tt.want || ok != (tt.want < INF) {
+			t.Errorf("%s -> %s at %v: expected %v, got %v (%v)", tt.from, tt.to, tt.depart, tt.want, got, ok)
+		}
+	}
+
+	// A transfer time above 3 minutes breaks the connection at B
+	tg, _ = ReadGTFS(feed, GTFSOptions{MinTransferTime: 240})
+	if got, _ := tg.EarliestArrival("A", "D", 8*3600); got != 8*3600+40*60 {
+		t.Errorf("expected the transfer at B to be missed, got arrival %v", got)
+	}
+	if stop, time, ok := tg.Event(0); !ok || stop != "A" || time != 8*3600 {
+		t.Errorf("expected event 0 to be trip a at A, got %q at %v", stop, time)
+	}
+}
+
+func TestReadGTFS_Errors(t *testing.T) {
+	for name, data := range map[string]string{
+		"missing column": "trip_id,arrival_time,stop_id,stop_sequence\n",
+		"bad time":       "trip_id,arrival_time,departure_time,stop_id,stop_sequence\na,8h,08:00:00,A,1\n",
+		"bad sequence":   "trip_id,arrival_time,departure_time,stop_id,stop_sequence\na,08:00:00,08:00:00,A,x\n",
+	} {
+		_, err := ReadGTFS(fstest.MapFS{"stop_times.txt": {Data: []byte(data)}}, GTFSOptions{})
+		if !errors.Is(err, ErrBadGTFS) {
+			t.Errorf("%s: expected ErrBadGTFS, got %v", name, err)
+		}
+	}
+	if _, err := ReadGTFS(fstest.MapFS{}, GTFSOptions{}); err == nil {
+		t.Error("expected an error for a feed without stop_times.txt")
+	}
+}
@@ -0,0 +1,276 @@
+package bmssp
+
+import (
+	"maps"
+	"slices"
+)
+
+// chArc is an arc of a contraction hierarchy between node indexes.
+type chArc struct {
+	to int
+	w  Dist
+}
+
+// witnessSettleLimit bounds the local searches that decide whether a
+// shortcut is needed. A search cut short only adds a superfluous shortcut.
+const witnessSettleLimit = 500
+
+// Hierarchy is a contraction hierarchy of a graph: the nodes are ranked and
+// contracted in that order, adding shortcut edges that preserve the
+// distances between the remaining nodes. Every shortest path then has a
+// counterpart that first climbs and then descends in rank, so queries only
+// search upward from both ends, settling a few hundred nodes even on large
+// road networks.
+//
+// The hierarchy reflects the graph when it was built; rebuild it after the
+// graph changes. Weights must be non-negative. A Hierarchy is safe for
+// concurrent use.
+type Hierarchy struct {
+	ids   []NodeID
+	index map[NodeID]int
+	rank  []int
+	up    [][]chArc // arcs to higher ranked heads
+	down  [][]chArc // reversed arcs from higher ranked tails
+}
+
+// NewHierarchy contracts g into a hierarchy. Nodes are contracted in order
+// of their edge difference, the shortcuts added minus the edges removed,
+// which keeps the hierarchy sparse.
+//
+// Parameters:
+//   - g: input graph (not modified)
+//
+// Returns:
+//   - the hierarchy of g
+func NewHierarchy(g *Graph) *Hierarchy {
+	h := &Hierarchy{index: make(map[NodeID]int, len(g.adj))}
+	// Sorted IDs make the contraction order deterministic
+	h.ids = slices.Sorted(maps.Keys(g.adj))
+	for i, v := range h.ids {
+		h.index[v] = i
+	}
+	n := len(h.ids)
+	c := contraction{
+		out:     make([][]chArc, n),
+		in:      make([][]chArc, n),
+		deleted: make([]int, n),
+		dist:    make([]Dist, n),
+		head:    make([]bool, n),
+	}
+	for a, u := range h.ids {
+		for _, e := range g.adj[u] {
+			b := h.index[e.To]
+			if a != b && e.Weight < INF {
+				c.addArc(a, b, e.Weight)
+			}
+		}
+	}
+	for i := range c.dist {
+		c.dist[i] = INF
+	}
+
+	h.rank = make([]int, n)
+	h.up, h.down = make([][]chArc, n), make([][]chArc, n)
+	prio := make([]Dist, n)
+	pq := BinaryHeap()
+	for v := range n {
+		prio[v] = c.priority(v)
+		pq.Push(NodeID(v), prio[v])
+	}
+	done := make([]bool, n)
+	for r := 0; pq.Len() > 0; {
+		x, p, _ := pq.Pop()
+		v := int(x)
+		if done[v] || p != prio[v] {
+			continue
+		}
+		done[v] = true
+		h.rank[v] = r
+		r++
+		h.up[v], h.down[v] = slices.Clone(c.out[v]), slices.Clone(c.in[v])
+		c.contract(v, true)
+		for _, a := range h.up[v] {
+			c.in[a.to] = dropArc(c.in[a.to], v)
+			c.deleted[a.to]++
+		}
+		for _, a := range h.down[v] {
+			c.out[a.to] = dropArc(c.out[a.to], v)
+			c.deleted[a.to]++
+		}
+		c.out[v], c.in[v] = nil, nil
+		// Only the priorities of the neighbors change
+		for _, a := range slices.Concat(h.up[v], h.down[v]) {
+			if np := c.priority(a.to); np != prio[a.to] {
+				prio[a.to] = np
+				pq.Push(NodeID(a.to), np)
+			}
+		}
+	}
+	return h
+}
+
+// contraction is the remaining graph while a hierarchy is built. out and in
+// hold the lightest arc between every pair of uncontracted nodes, with
+// chArc.to holding the tail in in.
+type contraction struct {
+	out, in [][]chArc
+	deleted []int  // number of contracted neighbors of each node
+	dist    []Dist // distances of the last witness search, INF if unreached
+	touched []int  // nodes whose dist is set
+	head    []bool // heads of the node being contracted
+}
+
+// addArc adds the arc u→v unless a lighter one exists.
+func (c *contraction) addArc(u, v int, w Dist) {
+	for i, a := range c.out[u] {
+		if a.to == v {
+			if w < a.w {
+				c.out[u][i].w = w
+				for j, b := range c.in[v] {
+					if b.to == u {
+						c.in[v][j].w = w
+					}
+				}
+			}
+			return
+		}
+	}
+	c.out[u] = append(c.out[u], chArc{v, w})
+	c.in[v] = append(c.in[v], chArc{u, w})
+}
+
+// dropArc removes the arc to v from arcs.
+func dropArc(arcs []chArc, v int) []chArc {
+	return slices.DeleteFunc(arcs, func(a chArc) bool { return a.to == v })
+}
+
+// priority is the edge difference of v plus its contracted neighbors, which
+// spreads the contraction evenly over the graph.
+func (c *contraction) priority(v int) Dist {
+	return Dist(c.contract(v, false) - len(c.out[v]) - len(c.in[v]) + c.deleted[v])
+}
+
+// contract counts the shortcuts needed to bypass v, adding them if apply is
+// set. A shortcut u→x is needed unless a witness path from u to x avoiding
+// v is no longer than the path through v.
+func (c *contraction) contract(v int, apply bool) int {
+	var limit Dist
+	for _, a := range c.out[v] {
+		limit = max(limit, a.w)
+		c.head[a.to] = true
+	}
+	defer func() {
+		for _, a := range c.out[v] {
+			c.head[a.to] = false
+		}
+	}()
+
+	shortcuts := 0
+	// Shortcuts added below never leave v, so ranging over the arcs of v
+	// is safe
+	for _, in := range c.in[v] {
+		u := in.to
+		c.witness(u, v, in.w+limit, len(c.out[v]), apply)
+		for _, out := range c.out[v] {
+			if out.to == u || c.dist[out.to] <= in.w+out.w {
+				continue
+			}
+			shortcuts++
+			if apply {
+				c.addArc(u, out.to, in.w+out.w)
+			}
+		}
+	}
+	return shortcuts
+}
+
+// witness runs a Dijkstra search from u avoiding v into c.dist, up to
+// distance limit or until the heads of v, pending of them, are settled.
+// Estimating a priority only needs a rough count, so the search settles
+// fewer nodes unless exact is set.
+func (c *contraction) witness(u, v int, limit Dist, pending int, exact bool) {
+	settleLimit := witnessSettleLimit
+	if !exact {
+		settleLimit /= 25
+	}
+	for _, x := range c.touched {
+		c.dist[x] = INF
+	}
+	c.dist[u] = 0
+	c.touched = append(c.touched[:0], u)
+	if c.head[u] {
+		pending--
+	}
+	pq := BinaryHeap()
+	pq.Push(NodeID(u), 0)
+	for settled := 0; pq.Len() > 0 && settled < settleLimit && pending > 0; settled++ {
+		x, d, _ := pq.Pop()
+		if d > c.dist[x] {
+			continue
+		}
+		if d > limit {
+			break
+		}
+		if c.head[x] && int(x) != u {
+			pending--
+		}
+		for _, a := range c.out[x] {
+			if a.to == v || d+a.w >= c.dist[a.to] {
+				continue
+			}
+			if c.dist[a.to] == INF {
+				c.touched = append(c.touched, a.to)
+			}
+			c.dist[a.to] = d + a.w
+			pq.Push(NodeID(a.to), d+a.w)
+		}
+	}
+}
+
+// upward searches the hierarchy from node index x along arcs, which is up
+// for a forward and down for a backward search. Nodes for which stop
+// returns true are reached but not scanned, unless stop is nil. It returns
+// the distance to every node reached.
+func (h *Hierarchy) upward(x int, arcs [][]chArc, stop func(v int) bool) map[int]Dist {
+	dist := map[int]Dist{x: 0}
+	pq := BinaryHeap()
+	pq.Push(NodeID(x), 0)
+	for pq.Len() > 0 {
+		v, d, _ := pq.Pop()
+		if d > dist[int(v)] {
+			continue
+		}
+		if stop != nil && stop(int(v)) {
+			continue
+		}
+		for _, a := range arcs[v] {
+			if old, ok := dist[a.to]; !ok || d+a.w < old {
+				dist[a.to] = d + a.w
+				pq.Push(NodeID(a.to), d+a.w)
+			}
+		}
+	}
+	return dist
+}
+
+// Distance returns the shortest distance from s to t, meeting the upward
+// searches from both ends at their highest ranked common node.
+//
+// Returns:
+//   - the distance; INF if t is unreachable or either node is not in the
+//     hierarchy
+func (h *Hierarchy) Distance(s, t NodeID) Dist {
+	i, ok := h.index[s]
+	j, ok2 := h.index[t]
+	if !ok || !ok2 {
+		return INF
+	}
+	fwd, bwd := h.upward(i, h.up, nil), h.upward(j, h.down, nil)
+	best := INF
+	for v, d := range fwd {
+		if e, ok := bwd[v]; ok {
+			best = min(best, d+e)
+		}
+	}
+	return best
+}
@@ -0,0 +1,33 @@
+package bmssp
+
+import "testing"
+
+func TestHierarchy_Distance(t *testing.T) {
+	for name, g := range map[string]*Graph{
+		"grid":   generateGridGraph(15, 15),
+		"random": generateRandomGraph(200, 800, 10, 3),
+	} {
+		h := NewHierarchy(g)
+		for _, s := range []NodeID{0, 37, 199} {
+			want := Dijkstra(g, s)
+			for v := range g.adj {
+				w, ok := want[v]
+				if !ok {
+					w = INF
+				}
+				if got := h.Distance(s, v); got != w && !approxEqual(got, w, 1e-9) {
+					t.Fatalf("%s: %d -> %d: expected %v, got %v", name, s, v, w, got)
+				}
+			}
+		}
+	}
+
+	g := NewGraph()
+	g.AddEdge(0, 1, 2)
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 1, 0)
+	h := NewHierarchy(g)
+	if h.Distance(0, 1) != 1 || h.Distance(1, 0) != INF || h.Distance(0, 5) != INF {
+		t.Errorf("unexpected distances %v, %v, %v", h.Distance(0, 1), h.Distance(1, 0), h.Distance(0, 5))
+	}
+}
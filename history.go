@@ -0,0 +1,155 @@
+package bmssp
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrUnknownVersion is returned when a graph version is not in the log.
+var ErrUnknownVersion = errors.New("bmssp: unknown graph version")
+
+// MutationOp is the kind of a recorded graph mutation.
+type MutationOp int
+
+const (
+	OpAddEdge          MutationOp = iota // Graph.AddEdge
+	OpRemoveEdge                         // Graph.RemoveEdge
+	OpUpdateEdgeWeight                   // Graph.UpdateEdgeWeight
+	OpRemoveNode                         // Graph.RemoveNode; From is the removed node
+)
+
+// Mutation is one entry of a MutationLog.
+type Mutation struct {
+	Version uint64 // graph version after the mutation; the initial graph is version 0
+	Time    time.Time
+	Op      MutationOp
+	From    NodeID
+	To      NodeID
+	Weight  Dist
+}
+
+// MutationLog applies mutations to a graph and records them, so the graph
+// can be reconstructed as of any past version or time, e.g. to replay the
+// routing decisions made before an incident. It is safe for concurrent use;
+// the live graph returned by Graph must not be mutated directly.
+type MutationLog struct {
+	mu      sync.Mutex
+	g       *Graph
+	base    *Graph // copy of the graph at version 0
+	entries []Mutation
+	now     func() time.Time
+}
+
+// NewMutationLog starts logging mutations of g. The current state of g
+// becomes version 0.
+func NewMutationLog(g *Graph) *MutationLog {
+	return &MutationLog{g: g, base: g.Clone(), now: time.Now}
+}
+
+// Graph returns the live graph.
+func (l *MutationLog) Graph() *Graph {
+	return l.g
+}
+
+// Version returns the current graph version.
+func (l *MutationLog) Version() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return uint64(len(l.entries))
+}
+
+// Entries returns a copy of the recorded mutations in order.
+func (l *MutationLog) Entries() []Mutation {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Mutation(nil), l.entries...)
+}
+
+// AddEdge adds an edge to the live graph and records it.
+func (l *MutationLog) AddEdge(from, to NodeID, weight Dist) {
+	l.apply(Mutation{Op: OpAddEdge, From: from, To: to, Weight: weight})
+}
+
+// RemoveEdge removes every edge from 'from' to 'to' and records it. It
+// reports whether any edge was removed; no-ops are not recorded.
+func (l *MutationLog) RemoveEdge(from, to NodeID) bool {
+	return l.apply(Mutation{Op: OpRemoveEdge, From: from, To: to})
+}
+
+// UpdateEdgeWeight sets the weight of every edge from 'from' to 'to' and
+// records it. It reports whether such an edge exists; no-ops are not
+// recorded.
+func (l *MutationLog) UpdateEdgeWeight(from, to NodeID, weight Dist) bool {
+	return l.apply(Mutation{Op: OpUpdateEdgeWeight, From: from, To: to, Weight: weight})
+}
+
+// RemoveNode removes v and its edges and records it. It reports whether v
+// was present; no-ops are not recorded.
+func (l *MutationLog) RemoveNode(v NodeID) bool {
+	return l.apply(Mutation{Op: OpRemoveNode, From: v})
+}
+
+// apply performs m on the live graph and records it if it changed anything.
+func (l *MutationLog) apply(m Mutation) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !applyMutation(l.g, m) {
+		return false
+	}
+	m.Version = uint64(len(l.entries)) + 1
+	m.Time = l.now()
+	l.entries = append(l.entries, m)
+	return true
+}
+
+// applyMutation performs m on g and reports whether g changed.
+func applyMutation(g *Graph, m Mutation) bool {
+	switch m.Op {
+	case OpAddEdge:
+		g.AddEdge(m.From, m.To, m.Weight)
+		return true
+	case OpRemoveEdge:
+		return g.RemoveEdge(m.From, m.To)
+	case OpUpdateEdgeWeight:
+		return g.UpdateEdgeWeight(m.From, m.To, m.Weight)
+	case OpRemoveNode:
+		return g.RemoveNode(m.From)
+	}
+	return false
+}
+
+// AsOfVersion reconstructs the graph as it was at the given version by
+// replaying the log onto a copy of version 0. The result is independent of
+// the live graph and can be queried with any algorithm.
+//
+// Returns:
+//   - the reconstructed graph
+//   - ErrUnknownVersion if version is newer than the log
+func (l *MutationLog) AsOfVersion(version uint64) (*Graph, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if version > uint64(len(l.entries)) {
+		return nil, fmt.Errorf("%w: %d (latest is %d)", ErrUnknownVersion, version, len(l.entries))
+	}
+	g := l.base.Clone()
+	for _, m := range l.entries[:version] {
+		applyMutation(g, m)
+	}
+	return g, nil
+}
+
+// AsOf reconstructs the graph as it was at time t: the initial graph plus
+// every mutation recorded at or before t.
+func (l *MutationLog) AsOf(t time.Time) *Graph {
+	l.mu.Lock()
+	n := sort.Search(len(l.entries), func(i int) bool {
+		return l.entries[i].Time.After(t)
+	})
+	l.mu.Unlock()
+
+	g, _ := l.AsOfVersion(uint64(n))
+	return g
+}
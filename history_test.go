@@ -0,0 +1,49 @@
+package bmssp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMutationLog_TimeTravel(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 2, 1)
+
+	clock := time.Unix(1000, 0)
+	log := NewMutationLog(g)
+	log.now = func() time.Time { return clock }
+
+	clock = clock.Add(time.Minute)
+	log.UpdateEdgeWeight(1, 2, 10) // v1
+	clock = clock.Add(time.Minute)
+	log.AddEdge(0, 2, 5) // v2
+	clock = clock.Add(time.Minute)
+	log.RemoveNode(1) // v3
+	if log.RemoveEdge(7, 8) || log.Version() != 3 {
+		t.Fatalf("expected no-ops to be skipped at version 3, got %d", log.Version())
+	}
+
+	for version, want := range []Dist{2, 11, 5, 5} {
+		past, err := log.AsOfVersion(uint64(version))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, d := ShortestPath(past, 0, 2); d != want {
+			t.Errorf("version %d: expected distance %v, got %v", version, want, d)
+		}
+	}
+	if _, err := log.AsOfVersion(4); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("expected ErrUnknownVersion, got %v", err)
+	}
+
+	// Halfway between the first and second mutation
+	past := log.AsOf(time.Unix(1000, 0).Add(90 * time.Second))
+	if _, d := ShortestPath(past, 0, 2); d != 11 {
+		t.Errorf("expected distance 11 after the first update, got %v", d)
+	}
+	if _, d := ShortestPath(g, 0, 2); d != 5 || len(g.OutEdges(0)) != 1 {
+		t.Errorf("expected the live graph at the latest version, got distance %v", d)
+	}
+}
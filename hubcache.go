@@ -0,0 +1,148 @@
+package bmssp
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// hubTree is a retained shortest-path tree of one hub.
+type hubTree struct {
+	hub     NodeID
+	res     *Result
+	version uint64 // graph version the tree was computed on
+}
+
+// HubCache retains the shortest-path trees of registered hub nodes, turning
+// repeated distance and path queries from those hubs into map lookups. Trees
+// are computed when a hub is registered and recomputed on first use after the
+// graph changes. When the trees together exceed the node budget, the least
+// recently used ones are dropped and recomputed on demand.
+//
+// A HubCache is safe for concurrent use, provided the graph is not mutated
+// while queries are running.
+type HubCache struct {
+	g      *Graph
+	budget int // maximum number of retained tree nodes; 0 for no limit
+	opts   []Option
+
+	mu       sync.Mutex
+	hubs     NodeSet
+	trees    map[NodeID]*list.Element
+	lru      *list.List // most recently used at the front
+	resident int        // nodes held by the retained trees
+}
+
+// NewHubCache creates a hub cache over g.
+//
+// Parameters:
+//   - g: input graph
+//   - budget: maximum total number of nodes held across retained trees, each
+//     tree holding one entry per node of g (0 for no limit)
+//   - opts: query options applied when computing trees, e.g. WithDelta
+func NewHubCache(g *Graph, budget int, opts ...Option) *HubCache {
+	return &HubCache{
+		g:      g,
+		budget: budget,
+		opts:   opts,
+		hubs:   NewNodeSet(),
+		trees:  make(map[NodeID]*list.Element),
+		lru:    list.New(),
+	}
+}
+
+// Register adds hubs to the cache and computes their trees.
+//
+// Returns:
+//   - ErrNodeNotFound if a hub is not in the graph; no hub is registered then
+func (c *HubCache) Register(hubs ...NodeID) error {
+	for _, h := range hubs {
+		if _, ok := c.g.adj[h]; !ok {
+			return fmt.Errorf("%w: %d", ErrNodeNotFound, h)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, h := range hubs {
+		c.hubs.Add(h)
+		c.tree(h)
+	}
+	return nil
+}
+
+// Unregister removes a hub and releases its tree.
+func (c *HubCache) Unregister(hub NodeID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.hubs, hub)
+	if el, ok := c.trees[hub]; ok {
+		c.evict(el)
+	}
+}
+
+// Dist returns the distance from hub to v. It reports false if hub is not
+// registered.
+func (c *HubCache) Dist(hub, v NodeID) (Dist, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.hubs.Has(hub) {
+		return INF, false
+	}
+	d, ok := c.tree(hub).Dist[v]
+	if !ok {
+		d = INF
+	}
+	return d, true
+}
+
+// PathTo returns a shortest path from hub to v, or nil if v is unreachable or
+// hub is not registered.
+func (c *HubCache) PathTo(hub, v NodeID) []NodeID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.hubs.Has(hub) {
+		return nil
+	}
+	return c.tree(hub).PathTo(v)
+}
+
+// Len returns the number of retained trees.
+func (c *HubCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// tree returns the up-to-date tree of a registered hub, computing it if it
+// is missing or stale. The caller must hold c.mu.
+func (c *HubCache) tree(hub NodeID) *Result {
+	if el, ok := c.trees[hub]; ok {
+		t := el.Value.(*hubTree)
+		if t.version == c.g.version {
+			c.lru.MoveToFront(el)
+			return t.res
+		}
+		c.evict(el)
+	}
+
+	S := NewNodeSet()
+	S.Add(hub)
+	t := &hubTree{hub: hub, res: Solve(c.g, S, INF, c.opts...), version: c.g.version}
+	c.trees[hub] = c.lru.PushFront(t)
+	c.resident += len(t.res.Dist)
+
+	// Keep at least the tree just computed, even if it alone exceeds the budget
+	for c.budget > 0 && c.resident > c.budget && c.lru.Len() > 1 {
+		c.evict(c.lru.Back())
+	}
+	return t.res
+}
+
+// evict releases a retained tree. The caller must hold c.mu.
+func (c *HubCache) evict(el *list.Element) {
+	t := el.Value.(*hubTree)
+	c.lru.Remove(el)
+	delete(c.trees, t.hub)
+	c.resident -= len(t.res.Dist)
+}
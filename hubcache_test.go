@@ -0,0 +1,60 @@
+package bmssp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHubCache(t *testing.T) {
+	g := generateGridGraph(10, 10)
+	c := NewHubCache(g, 0)
+	if err := c.Register(0, 99); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Register(1000); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("expected ErrNodeNotFound for an unknown hub, got %v", err)
+	}
+
+	want := Dijkstra(g, 0)
+	for v, d := range want {
+		if got, ok := c.Dist(0, v); !ok || got != d {
+			t.Errorf("node %d: expected %v, got %v (%v)", v, d, got, ok)
+		}
+	}
+	if path := c.PathTo(99, 0); len(path) != 19 {
+		t.Errorf("expected an 18-edge path from 99 to 0, got %v", path)
+	}
+	if _, ok := c.Dist(5, 0); ok {
+		t.Errorf("expected unregistered hub to miss")
+	}
+
+	// A graph change invalidates the retained trees
+	g.AddEdge(0, 99, 1)
+	if d, _ := c.Dist(0, 99); d != 1 {
+		t.Errorf("expected distance 1 after adding a shortcut, got %v", d)
+	}
+
+	c.Unregister(99)
+	if _, ok := c.Dist(99, 0); ok || c.Len() != 1 {
+		t.Errorf("expected hub 99 to be released, %d trees retained", c.Len())
+	}
+}
+
+func TestHubCache_Budget(t *testing.T) {
+	g := generateGridGraph(10, 10)
+	c := NewHubCache(g, 250)
+	if err := c.Register(0, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected a budget of 250 nodes to retain 2 trees of 100, got %d", c.Len())
+	}
+
+	// Evicted trees are recomputed on demand
+	if d, ok := c.Dist(0, 99); !ok || d != 18 {
+		t.Errorf("expected distance 18 from an evicted hub, got %v (%v)", d, ok)
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected the budget to hold after recomputation, got %d trees", c.Len())
+	}
+}
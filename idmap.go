@@ -0,0 +1,125 @@
+package bmssp
+
+import (
+	"slices"
+	"sync"
+)
+
+// IDMapper assigns dense NodeIDs to external node keys such as OSM IDs,
+// UUIDs or hostnames, and maps them back. IDs are handed out in order
+// starting at 0. An IDMapper is safe for concurrent use.
+type IDMapper[K comparable] struct {
+	mu   sync.RWMutex
+	ids  map[K]NodeID
+	keys []K
+}
+
+// NewIDMapper creates an empty mapper.
+func NewIDMapper[K comparable]() *IDMapper[K] {
+	return &IDMapper[K]{ids: make(map[K]NodeID)}
+}
+
+// Intern returns the NodeID of key, assigning the next free ID if key is new.
+func (m *IDMapper[K]) Intern(key K) NodeID {
+	m.mu.RLock()
+	id, ok := m.ids[key]
+	m.mu.RUnlock()
+	if ok {
+		return id
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id, ok := m.ids[key]; ok {
+		return id
+	}
+	id = NodeID(len(m.keys))
+	m.ids[key] = id
+	m.keys = append(m.keys, key)
+	return id
+}
+
+// ID returns the NodeID of key, reporting false if key was never interned.
+func (m *IDMapper[K]) ID(key K) (NodeID, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	id, ok := m.ids[key]
+	return id, ok
+}
+
+// Lookup returns the key of id, reporting false if id was not assigned.
+func (m *IDMapper[K]) Lookup(id NodeID) (K, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if id < 0 || int(id) >= len(m.keys) {
+		var zero K
+		return zero, false
+	}
+	return m.keys[id], true
+}
+
+// Keys maps a path of NodeIDs back to keys. Unassigned IDs map to the zero
+// key.
+func (m *IDMapper[K]) Keys(path []NodeID) []K {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]K, len(path))
+	for i, id := range path {
+		if id >= 0 && int(id) < len(m.keys) {
+			out[i] = m.keys[id]
+		}
+	}
+	return out
+}
+
+// Len returns the number of interned keys.
+func (m *IDMapper[K]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.keys)
+}
+
+// Renumber returns a copy of g with its nodes renumbered to the dense IDs
+// 0..n-1, in ascending order of the original IDs, together with the mapping
+// from original to new IDs. Use it after importing a dataset with sparse
+// external IDs, such as a binary or JSON graph, so that dense-array code
+// paths apply; importers that intern keys themselves, like ReadEdgeListCSV
+// with CSVOptions.IDs, produce dense IDs directly. Edge data is carried over.
+//
+// Returns:
+//   - the renumbered graph
+//   - the mapping; ids.Lookup(v) gives the original ID of node v
+func Renumber(g *Graph) (*Graph, *IDMapper[NodeID]) {
+	nodes := make([]NodeID, 0, len(g.adj))
+	for u := range g.adj {
+		nodes = append(nodes, u)
+	}
+	slices.Sort(nodes)
+
+	ids := NewIDMapper[NodeID]()
+	for _, u := range nodes {
+		ids.Intern(u)
+	}
+
+	r := &Graph{
+		adj:       make(map[NodeID][]Edge, len(g.adj)),
+		numEdges:  g.numEdges,
+		maxWeight: g.maxWeight,
+		shape:     g.shape,
+		uniform:   g.uniform,
+	}
+	for i, u := range nodes {
+		edges := make([]Edge, len(g.adj[u]))
+		for j, e := range g.adj[u] {
+			edges[j] = Edge{To: ids.ids[e.To], Weight: e.Weight}
+		}
+		if len(edges) == 0 {
+			edges = nil
+		}
+		r.adj[NodeID(i)] = edges
+	}
+	for id, v := range g.data {
+		r.SetEdgeData(EdgeID{From: ids.ids[id.From], To: ids.ids[id.To]}, v)
+	}
+	return r, ids
+}
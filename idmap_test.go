@@ -0,0 +1,63 @@
+package bmssp
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIDMapper(t *testing.T) {
+	ids := NewIDMapper[string]()
+	g := NewGraph()
+	g.AddEdge(ids.Intern("berlin"), ids.Intern("hamburg"), 290)
+	g.AddEdge(ids.Intern("hamburg"), ids.Intern("kiel"), 95)
+	g.AddEdge(ids.Intern("berlin"), ids.Intern("kiel"), 400)
+
+	if ids.Len() != 3 || ids.Intern("berlin") != 0 {
+		t.Fatalf("expected 3 dense IDs starting at 0, got %d", ids.Len())
+	}
+
+	from, _ := ids.ID("berlin")
+	to, _ := ids.ID("kiel")
+	path, d := ShortestPath(g, from, to)
+	if want := []string{"berlin", "hamburg", "kiel"}; d != 385 || !slices.Equal(ids.Keys(path), want) {
+		t.Errorf("expected %v of cost 385, got %v of cost %v", want, ids.Keys(path), d)
+	}
+
+	if key, ok := ids.Lookup(1); !ok || key != "hamburg" {
+		t.Errorf("expected ID 1 to be hamburg, got %q", key)
+	}
+	if _, ok := ids.Lookup(3); ok {
+		t.Error("expected ID 3 to be unassigned")
+	}
+	if _, ok := ids.ID("munich"); ok {
+		t.Error("expected munich to be unknown")
+	}
+}
+
+func TestRenumber(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(9000, -40, 2)
+	g.AddEdge(-40, 123456789, 3)
+	g.AddEdge(9000, 123456789, 10)
+	g.SetEdgeData(EdgeID{From: -40, To: 123456789}, "bridge")
+
+	r, ids := Renumber(g)
+	if ids.Len() != 3 || r.numEdges != 3 {
+		t.Fatalf("expected 3 nodes and 3 edges, got %d and %d", ids.Len(), r.numEdges)
+	}
+	for v := range r.adj {
+		if v < 0 || v >= 3 {
+			t.Errorf("expected dense IDs, got %d", v)
+		}
+	}
+
+	from, _ := ids.ID(9000)
+	to, _ := ids.ID(123456789)
+	path, d := ShortestPath(r, from, to)
+	if want := []NodeID{9000, -40, 123456789}; d != 5 || !slices.Equal(ids.Keys(path), want) {
+		t.Errorf("expected %v of cost 5, got %v of cost %v", want, ids.Keys(path), d)
+	}
+	if data, ok := r.EdgeData(EdgeID{From: path[1], To: path[2]}); !ok || data != "bridge" {
+		t.Errorf("expected edge data to carry over, got %v", data)
+	}
+}
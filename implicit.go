@@ -0,0 +1,69 @@
+package bmssp
+
+import "time"
+
+// Neighbors is an implicit graph: one whose edges are generated on demand
+// rather than stored, such as a grid computed from a game map or the moves
+// of a puzzle. Neighbors returns the edges leaving u; the slice is only read
+// until the next call. The graph may be infinite, so queries over it should
+// carry a bound or targets.
+type Neighbors interface {
+	Neighbors(u NodeID) []Edge
+}
+
+// NeighborsFunc adapts a function to the Neighbors interface.
+type NeighborsFunc func(u NodeID) []Edge
+
+// Neighbors calls f(u).
+func (f NeighborsFunc) Neighbors(u NodeID) []Edge {
+	return f(u)
+}
+
+// SolveImplicit runs BMSSP over an implicit graph, like Solve. Nodes enter
+// the result only once reached: Dist holds the nodes discovered by the
+// search and Unreachable stays empty. As nothing
+// is known about the graph in advance, the Δ-stepping bucket width defaults
+// to 1, WithDAG is ignored and the progress callback reports a total of 0;
+// pass WithDelta with a width near the typical edge weight for best
+// performance.
+//
+// Parameters:
+//   - nb: input graph
+//   - sources: set of source nodes
+//   - B: distance bound; with INF the search runs until no new node is
+//     reached, which never happens on an infinite graph
+//   - opts: optional query settings, e.g. WithTargets to stop early
+func SolveImplicit(nb Neighbors, sources NodeSet, B Dist, opts ...Option) *Result {
+	start := time.Now()
+
+	s := &solver{nb: nb, dhat: make(map[NodeID]Dist, len(sources)), cfg: newConfig(opts)}
+	s.prepare()
+	s.pred = make(map[NodeID]NodeID)
+	for v := range sources {
+		s.dhat[v] = 0
+	}
+	s.run(B, sources)
+
+	return s.result(start)
+}
+
+// ShortestPathImplicit computes a shortest path from source to target over
+// an implicit graph, stopping once target is settled. On an infinite graph
+// an unreachable target is searched for forever unless the options bound
+// the search, e.g. with WithNodeBudget or WithDeadline. See SolveImplicit.
+//
+// Returns:
+//   - the node sequence from source to target (nil if target is unreachable)
+//   - the path length (INF if target is unreachable)
+func ShortestPathImplicit(nb Neighbors, source, target NodeID, opts ...Option) ([]NodeID, Dist) {
+	S := NewNodeSet()
+	S.Add(source)
+	T := NewNodeSet()
+	T.Add(target)
+	res := SolveImplicit(nb, S, INF, append(opts[:len(opts):len(opts)], WithTargets(T))...)
+	d, ok := res.Dist[target]
+	if !ok || d == INF {
+		return nil, INF
+	}
+	return res.PathTo(target), d
+}
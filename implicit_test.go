@@ -0,0 +1,56 @@
+package bmssp
+
+import "testing"
+
+// gridNeighbors generates the edges of generateGridGraph(width, height) on
+// demand.
+func gridNeighbors(width, height int) NeighborsFunc {
+	return func(u NodeID) []Edge {
+		x, y := int(u)%width, int(u)/width
+		var edges []Edge
+		for _, d := range [][2]int{{1, 0}, {0, 1}, {-1, 0}, {0, -1}} {
+			if nx, ny := x+d[0], y+d[1]; nx >= 0 && nx < width && ny >= 0 && ny < height {
+				edges = append(edges, Edge{To: NodeID(ny*width + nx), Weight: 1})
+			}
+		}
+		return edges
+	}
+}
+
+func TestSolveImplicit(t *testing.T) {
+	want := Solve(generateGridGraph(20, 20), sources(0), INF)
+	for _, opts := range [][]Option{nil, {WithoutFastPaths()}} {
+		got := SolveImplicit(gridNeighbors(20, 20), sources(0), INF, opts...)
+		if len(got.Dist) != 400 {
+			t.Fatalf("expected 400 nodes discovered, got %d", len(got.Dist))
+		}
+		for v, d := range want.Dist {
+			if got.Dist[v] != d {
+				t.Errorf("node %d: expected %v, got %v", v, d, got.Dist[v])
+			}
+		}
+	}
+
+	// An infinite line is only explored up to the bound
+	line := NeighborsFunc(func(u NodeID) []Edge {
+		return []Edge{{To: u + 1, Weight: 1}, {To: u - 1, Weight: 1}}
+	})
+	res := SolveImplicit(line, sources(0), 10, WithoutFastPaths())
+	if res.Dist[10] != 10 || res.Dist[-10] != 10 || len(res.Dist) > 30 {
+		t.Errorf("unexpected bounded search over %d nodes", len(res.Dist))
+	}
+}
+
+func TestShortestPathImplicit(t *testing.T) {
+	line := NeighborsFunc(func(u NodeID) []Edge {
+		return []Edge{{To: u + 1, Weight: 2}, {To: u - 1, Weight: 1}}
+	})
+	path, d := ShortestPathImplicit(line, 0, 25)
+	if d != 50 || len(path) != 26 || path[25] != 25 {
+		t.Errorf("expected length 50 over 26 nodes, got %v over %v", d, path)
+	}
+	path, d = ShortestPathImplicit(gridNeighbors(5, 5), 0, 99, WithNodeBudget(100))
+	if path != nil || d != INF {
+		t.Errorf("expected no path to a node off the grid, got %v", path)
+	}
+}
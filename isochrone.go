@@ -0,0 +1,78 @@
+package bmssp
+
+import (
+	"cmp"
+	"slices"
+)
+
+// FrontierEdge is an edge that leaves an isochrone: its tail is reachable
+// within the bound and its head is not.
+type FrontierEdge struct {
+	EdgeID
+	Weight Dist // effective weight of the edge
+	Reach  Dist // how far along the edge the bound is reached, in [0, Weight)
+}
+
+// IsochroneResult is the set of nodes reachable within a bound.
+type IsochroneResult struct {
+	Dist     map[NodeID]Dist // distances of the nodes within the bound only
+	Frontier []FrontierEdge  // edges crossing the bound, sorted by EdgeID
+
+	// Stopped tells why the search ended early, e.g. StopDeadline; the
+	// region may then be incomplete. StopComplete if it was fully explored.
+	Stopped StopReason
+}
+
+// Approximate reports whether the region may be incomplete or its
+// distances not shortest, as Result.Approximate.
+func (r *IsochroneResult) Approximate() bool {
+	return r.Stopped != StopComplete && r.Stopped != StopTargets
+}
+
+// Isochrone computes the nodes reachable from sources within cost B, with
+// their distances, and the edges through which the reachable region is left.
+// Only the region within B is explored.
+//
+// Parameters:
+//   - g: input graph
+//   - sources: set of source nodes, all at distance 0
+//   - B: cost bound (inclusive)
+//   - opts: optional query settings
+//
+// Returns:
+//   - the reachable nodes and the frontier edges
+func Isochrone(g *Graph, sources NodeSet, B Dist, opts ...Option) *IsochroneResult {
+	s := newSolver(g, opts)
+	for v := range sources {
+		s.dhat[v] = 0
+	}
+	s.run(B, sources)
+
+	r := &IsochroneResult{Dist: make(map[NodeID]Dist), Stopped: s.stopReason()}
+	for v, d := range s.dhat {
+		if d <= B {
+			r.Dist[v] = d
+		}
+	}
+	for u, du := range r.Dist {
+		for _, e := range s.outEdges(u) {
+			if _, inside := r.Dist[e.To]; inside {
+				continue
+			}
+			if w := s.weight(u, e); w < INF {
+				r.Frontier = append(r.Frontier, FrontierEdge{
+					EdgeID: EdgeID{From: u, To: e.To},
+					Weight: w,
+					Reach:  B - du,
+				})
+			}
+		}
+	}
+	slices.SortFunc(r.Frontier, func(a, b FrontierEdge) int {
+		if c := cmp.Compare(a.From, b.From); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.To, b.To)
+	})
+	return r
+}
@@ -0,0 +1,32 @@
+package bmssp
+
+import "testing"
+
+func TestIsochrone(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 2)
+	g.AddEdge(1, 2, 2)
+	g.AddEdge(0, 3, 5)
+	g.AddEdge(2, 4, 1)
+
+	iso := Isochrone(g, sources(0), 4)
+	want := map[NodeID]Dist{0: 0, 1: 2, 2: 4}
+	if len(iso.Dist) != len(want) {
+		t.Fatalf("expected %v, got %v", want, iso.Dist)
+	}
+	for v, d := range want {
+		if iso.Dist[v] != d {
+			t.Errorf("node %d: expected %v, got %v", v, d, iso.Dist[v])
+		}
+	}
+
+	if len(iso.Frontier) != 2 {
+		t.Fatalf("expected 2 frontier edges, got %+v", iso.Frontier)
+	}
+	if f := iso.Frontier[0]; f.EdgeID != (EdgeID{From: 0, To: 3}) || f.Reach != 4 || f.Weight != 5 {
+		t.Errorf("expected 0->3 reached 4 of 5, got %+v", f)
+	}
+	if f := iso.Frontier[1]; f.EdgeID != (EdgeID{From: 2, To: 4}) || f.Reach != 0 {
+		t.Errorf("expected 2->4 reached 0 of 1, got %+v", f)
+	}
+}
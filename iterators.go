@@ -0,0 +1,111 @@
+package bmssp
+
+import (
+	"iter"
+	"maps"
+)
+
+// Settled runs a one-to-all query lazily: ranging over the sequence yields
+// each node reachable from sources within bound B with its final distance, in
+// non-decreasing distance order. The search advances only as far as the
+// caller consumes it; breaking out of the loop stops it.
+//
+// Parameters:
+//   - g: input graph
+//   - sources: set of source nodes, all at distance 0
+//   - B: distance bound
+//   - opts: optional query settings
+func Settled(g *Graph, sources NodeSet, B Dist, opts ...Option) iter.Seq2[NodeID, Dist] {
+	return func(yield func(NodeID, Dist) bool) {
+		StreamDistances(g, sources, B, yield, opts...)
+	}
+}
+
+// Nodes yields the nodes of g in no particular order. The graph must not be
+// mutated during iteration.
+func (g *Graph) Nodes() iter.Seq[NodeID] {
+	return maps.Keys(g.adj)
+}
+
+// AllNodes yields the nodes of g like Nodes, for callers that range over a
+// graph's nodes and edges with AllNodes and AllEdges.
+func (g *Graph) AllNodes() iter.Seq[NodeID] {
+	return g.Nodes()
+}
+
+// AllEdges yields every edge of g with its tail, in no particular order;
+// parallel edges are yielded separately. The graph must not be mutated
+// during iteration.
+func (g *Graph) AllEdges() iter.Seq2[NodeID, Edge] {
+	return func(yield func(NodeID, Edge) bool) {
+		for u, edges := range g.adj {
+			for _, e := range edges {
+				if !yield(u, e) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Successors yields the targets and weights of the edges leaving u.
+func (g *Graph) Successors(u NodeID) iter.Seq2[NodeID, Dist] {
+	return func(yield func(NodeID, Dist) bool) {
+		for _, e := range g.adj[u] {
+			if !yield(e.To, e.Weight) {
+				return
+			}
+		}
+	}
+}
+
+// Distances yields every reachable node with its distance, in no particular
+// order.
+func (r *Result) Distances() iter.Seq2[NodeID, Dist] {
+	return func(yield func(NodeID, Dist) bool) {
+		for v, d := range r.Dist {
+			if d < INF && !yield(v, d) {
+				return
+			}
+		}
+	}
+}
+
+// PathEdges yields the edges of a shortest path to v, from the source
+// onwards, each with its cost along the path. It yields nothing if v is
+// unreachable.
+func (r *Result) PathEdges(v NodeID) iter.Seq2[EdgeID, Dist] {
+	return func(yield func(EdgeID, Dist) bool) {
+		path := r.PathTo(v)
+		for i := 1; i < len(path); i++ {
+			u, w := path[i-1], path[i]
+			if !yield(EdgeID{From: u, To: w}, r.Dist[w]-r.Dist[u]) {
+				return
+			}
+		}
+	}
+}
+
+// Row yields the finite distances from u to every other node of the table.
+func (t *DistanceTable) Row(u NodeID) iter.Seq2[NodeID, Dist] {
+	return func(yield func(NodeID, Dist) bool) {
+		i, ok := t.index[u]
+		if !ok {
+			return
+		}
+		if t.sparse != nil {
+			for v, d := range t.sparse[i] {
+				if !yield(v, d) {
+					return
+				}
+			}
+			return
+		}
+		row := t.dense[i*len(t.nodes) : (i+1)*len(t.nodes)]
+		for j, d := range row {
+			if d < INF && !yield(t.nodes[j], d) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package bmssp
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSettled(t *testing.T) {
+	g := generateGridGraph(10, 10)
+
+	var got []Dist
+	for v, d := range Settled(g, sources(0), INF) {
+		if v == 0 && d != 0 {
+			t.Errorf("expected source at 0, got %v", d)
+		}
+		got = append(got, d)
+		if len(got) == 6 {
+			break
+		}
+	}
+	if len(got) != 6 || got[0] != 0 || got[5] != 2 {
+		t.Errorf("expected the 6 closest nodes at distances 0, 1, 1, 2, 2, 2, got %v", got)
+	}
+}
+
+func TestResultIterators(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 2)
+	g.AddEdge(1, 2, 3)
+	g.AddEdge(3, 0, 1)
+	res := Solve(g, sources(0), INF)
+
+	reachable := 0
+	for v, d := range res.Distances() {
+		if d != res.Dist[v] || v == 3 {
+			t.Errorf("unexpected node %d at %v", v, d)
+		}
+		reachable++
+	}
+	if reachable != 3 {
+		t.Errorf("expected 3 reachable nodes, got %d", reachable)
+	}
+
+	var cost Dist
+	var edges []EdgeID
+	for id, w := range res.PathEdges(2) {
+		edges = append(edges, id)
+		cost += w
+	}
+	if len(edges) != 2 || edges[0] != (EdgeID{From: 0, To: 1}) || cost != 5 {
+		t.Errorf("expected path 0->1->2 of cost 5, got %v of cost %v", edges, cost)
+	}
+
+	degree := 0
+	for v, w := range g.Successors(1) {
+		if v != 2 || w != 3 {
+			t.Errorf("unexpected successor %d with weight %v", v, w)
+		}
+		degree++
+	}
+	if degree != 1 {
+		t.Errorf("expected 1 successor, got %d", degree)
+	}
+}
+
+func TestDistanceTable_Row(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 2)
+	g.AddEdge(1, 2, 3)
+	table, err := AllPairs(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row := make(map[NodeID]Dist)
+	for v, d := range table.Row(1) {
+		row[v] = d
+	}
+	if len(row) != 2 || row[1] != 0 || row[2] != 3 {
+		t.Errorf("expected row {1: 0, 2: 3}, got %v", row)
+	}
+}
+
+func TestGraph_AllNodesAndEdges(t *testing.T) {
+	g := generateRandomGraph(50, 200, 10, 6)
+	g.AddEdge(0, 1, 2)
+	g.AddEdge(0, 1, 2) // parallel edges are yielded separately
+
+	nodes := 0
+	for v := range g.AllNodes() {
+		if _, ok := g.adj[v]; !ok {
+			t.Errorf("unknown node %d yielded", v)
+		}
+		nodes++
+	}
+	if nodes != g.NumNodes() {
+		t.Errorf("expected %d nodes, got %d", g.NumNodes(), nodes)
+	}
+
+	degree := make(map[NodeID]int)
+	for u, e := range g.AllEdges() {
+		if !slices.Contains(g.OutEdges(u), e) {
+			t.Errorf("edge %d->%d (%v) is not in the graph", u, e.To, e.Weight)
+		}
+		degree[u]++
+	}
+	for v := range g.AllNodes() {
+		if degree[v] != g.OutDegree(v) {
+			t.Errorf("node %d: expected %d edges, got %d", v, g.OutDegree(v), degree[v])
+		}
+	}
+
+	for range g.AllEdges() {
+		break // stopping early must not panic
+	}
+}
@@ -0,0 +1,198 @@
+package bmssp
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"time"
+)
+
+// nodeLinkGraph is the node-link JSON format used by NetworkX
+// (node_link_data) and d3.js.
+type nodeLinkGraph struct {
+	Directed   bool           `json:"directed"`
+	Multigraph bool           `json:"multigraph"`
+	Graph      map[string]any `json:"graph"`
+	Nodes      []nodeLinkNode `json:"nodes"`
+	Links      []nodeLinkLink `json:"links"`
+	Edges      []nodeLinkLink `json:"edges,omitempty"` // newer NetworkX versions
+}
+
+type nodeLinkNode struct {
+	ID NodeID `json:"id"`
+}
+
+type nodeLinkLink struct {
+	Source NodeID   `json:"source"`
+	Target NodeID   `json:"target"`
+	Weight *float64 `json:"weight"` // null for closed (+Inf) edges
+}
+
+// MarshalJSON encodes g in the node-link format read by NetworkX
+// (node_link_graph) and d3.js. Nodes are sorted by ID and links by source,
+// with each node's edges in insertion order. Infinite weights are encoded as
+// null.
+func (g *Graph) MarshalJSON() ([]byte, error) {
+	nl := nodeLinkGraph{
+		Directed: true,
+		Graph:    map[string]any{},
+		Nodes:    make([]nodeLinkNode, 0, len(g.adj)),
+		Links:    make([]nodeLinkLink, 0, g.numEdges),
+	}
+	nodes := make([]NodeID, 0, len(g.adj))
+	for u := range g.adj {
+		nodes = append(nodes, u)
+	}
+	slices.Sort(nodes)
+
+	for _, u := range nodes {
+		nl.Nodes = append(nl.Nodes, nodeLinkNode{ID: u})
+		seen := make(map[NodeID]bool, len(g.adj[u]))
+		for _, e := range g.adj[u] {
+			link := nodeLinkLink{Source: u, Target: e.To}
+			if w := float64(e.Weight); !math.IsInf(w, 1) {
+				link.Weight = &w
+			}
+			nl.Links = append(nl.Links, link)
+			nl.Multigraph = nl.Multigraph || seen[e.To]
+			seen[e.To] = true
+		}
+	}
+	return json.Marshal(nl)
+}
+
+// UnmarshalJSON decodes a node-link graph as written by MarshalJSON or
+// NetworkX, replacing the contents of g. Edges may be listed under "links" or
+// "edges"; a missing weight defaults to 1 and null means +Inf. Undirected
+// graphs get an edge in each direction.
+func (g *Graph) UnmarshalJSON(data []byte) error {
+	g.mustBeMutable()
+	var nl nodeLinkGraph
+	nl.Directed = true
+	if err := json.Unmarshal(data, &nl); err != nil {
+		return err
+	}
+
+	// Distinguish a missing weight from an explicit null
+	var raw struct {
+		Links []map[string]json.RawMessage `json:"links"`
+		Edges []map[string]json.RawMessage `json:"edges"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	links, rawLinks := nl.Links, raw.Links
+	if len(links) == 0 {
+		links, rawLinks = nl.Edges, raw.Edges
+	}
+
+	*g = *NewGraph()
+	for _, n := range nl.Nodes {
+		g.adj[n.ID] = nil
+	}
+	for i, l := range links {
+		w := INF
+		switch {
+		case l.Weight != nil:
+			w = Dist(*l.Weight)
+		case rawLinks[i]["weight"] == nil:
+			w = 1
+		}
+		g.AddEdge(l.Source, l.Target, w)
+		if !nl.Directed && l.Source != l.Target {
+			g.AddEdge(l.Target, l.Source, w)
+		}
+	}
+	return nil
+}
+
+// resultJSON is the JSON form of a Result. Unreachable nodes are omitted
+// from dist and listed in ascending order under unreachable.
+type resultJSON struct {
+	Dist        map[NodeID]Dist   `json:"dist"`
+	Pred        map[NodeID]NodeID `json:"pred,omitempty"`
+	Unreachable []NodeID          `json:"unreachable,omitempty"`
+	Truncated   bool              `json:"truncated,omitempty"`
+	Stats       statsJSON         `json:"stats"`
+	Stopped     string            `json:"stopped"`
+}
+
+type statsJSON struct {
+	NodesSettled   int     `json:"nodes_settled"`
+	EdgesScanned   int     `json:"edges_scanned"`
+	Relaxations    int     `json:"relaxations"`
+	QueueOps       int     `json:"queue_ops"`
+	MaxBucket      int     `json:"max_bucket"`
+	RecursionDepth int     `json:"recursion_depth"`
+	Delta          float64 `json:"delta"`
+	DurationNS     int64   `json:"duration_ns"`
+}
+
+// MarshalJSON encodes the finite distances, predecessors, unreachable nodes,
+// statistics and stop reason of r.
+func (r *Result) MarshalJSON() ([]byte, error) {
+	out := resultJSON{
+		Dist: make(map[NodeID]Dist, len(r.Dist)),
+		Pred: r.Pred,
+		Stats: statsJSON{
+			NodesSettled:   r.Stats.NodesSettled,
+			EdgesScanned:   r.Stats.EdgesScanned,
+			Relaxations:    r.Stats.Relaxations,
+			QueueOps:       r.Stats.QueueOps,
+			MaxBucket:      r.Stats.MaxBucket,
+			RecursionDepth: r.Stats.RecursionDepth,
+			Delta:          float64(r.Stats.Delta),
+			DurationNS:     r.Stats.Duration.Nanoseconds(),
+		},
+		Stopped:   r.Stopped.String(),
+		Truncated: r.Truncated,
+	}
+	for v, d := range r.Dist {
+		if d < INF {
+			out.Dist[v] = d
+		}
+	}
+	out.Unreachable = r.Unreachable.ToSlice()
+	slices.Sort(out.Unreachable)
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a Result written by MarshalJSON. Unreachable nodes
+// are absent from the decoded Dist map and listed in Unreachable.
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var in resultJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*r = Result{
+		Dist:      in.Dist,
+		Pred:      in.Pred,
+		Truncated: in.Truncated,
+		Stats: Stats{
+			NodesSettled:   in.Stats.NodesSettled,
+			EdgesScanned:   in.Stats.EdgesScanned,
+			Relaxations:    in.Stats.Relaxations,
+			QueueOps:       in.Stats.QueueOps,
+			MaxBucket:      in.Stats.MaxBucket,
+			RecursionDepth: in.Stats.RecursionDepth,
+			Delta:          Dist(in.Stats.Delta),
+			Duration:       time.Duration(in.Stats.DurationNS),
+		},
+	}
+	if r.Dist == nil {
+		r.Dist = make(map[NodeID]Dist)
+	}
+	if r.Pred == nil {
+		r.Pred = make(map[NodeID]NodeID)
+	}
+	r.Unreachable = NewNodeSet()
+	for _, v := range in.Unreachable {
+		r.Unreachable.Add(v)
+	}
+	for reason := StopComplete; reason <= StopCanceled; reason++ {
+		if reason.String() == in.Stopped {
+			r.Stopped = reason
+		}
+	}
+	return nil
+}
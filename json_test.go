@@ -0,0 +1,75 @@
+package bmssp
+
+import (
+	"encoding/json"
+	"maps"
+	"testing"
+)
+
+func TestGraph_JSONRoundTrip(t *testing.T) {
+	g := generateRandomGraph(50, 200, 10, 31)
+	g.AddEdge(3, 4, INF)
+	g.AddEdge(200, 201, 1)
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Graph
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(back.adj) != len(g.adj) || back.numEdges != g.numEdges {
+		t.Fatalf("expected %d nodes and %d edges, got %d and %d", len(g.adj), g.numEdges, len(back.adj), back.numEdges)
+	}
+	want := Dijkstra(g, 0)
+	for v, d := range Dijkstra(&back, 0) {
+		if d != want[v] {
+			t.Errorf("node %d: expected %v, got %v", v, want[v], d)
+		}
+	}
+}
+
+func TestGraph_UnmarshalNetworkX(t *testing.T) {
+	// As written by networkx.node_link_data for an undirected graph
+	data := `{"directed": false, "multigraph": false, "graph": {},
+		"nodes": [{"id": 0}, {"id": 1}, {"id": 2}, {"id": 3}],
+		"edges": [{"source": 0, "target": 1, "weight": 2.5}, {"source": 1, "target": 2}]}`
+
+	var g Graph
+	if err := json.Unmarshal([]byte(data), &g); err != nil {
+		t.Fatal(err)
+	}
+	dist := BMSSPSingleSource(&g, 2, INF)
+	if dist[0] != 3.5 || dist[3] != INF {
+		t.Errorf("expected node 0 at 3.5 via undirected edges and node 3 isolated, got %v", dist)
+	}
+}
+
+func TestResult_JSONRoundTrip(t *testing.T) {
+	g := generateGridGraph(5, 5)
+	g.AddEdge(100, 101, 1)
+	res := Solve(g, sources(0), INF, WithNodeBudget(10))
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Result
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Stopped != StopNodeBudget || back.Stats.NodesSettled != 10 {
+		t.Errorf("expected stop reason and stats to survive, got %v and %+v", back.Stopped, back.Stats)
+	}
+	if _, ok := back.Dist[100]; ok || back.Dist[1] != 1 {
+		t.Errorf("expected finite distances only, got %v", back.Dist)
+	}
+	if !maps.Equal(back.Unreachable, res.Unreachable) || !back.Unreachable.Has(100) {
+		t.Errorf("expected unreachable nodes %v, got %v", res.Unreachable, back.Unreachable)
+	}
+	if p := back.PathTo(5); len(p) != 2 {
+		t.Errorf("expected a path to node 5, got %v", p)
+	}
+}
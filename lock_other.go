@@ -0,0 +1,35 @@
+//go:build !unix
+
+package bmssp
+
+import (
+	"errors"
+	"os"
+)
+
+// lockFile takes an exclusive lock on path by creating it, where file
+// locks are not supported. A process that dies leaves the file behind; it
+// must then be removed by hand.
+//
+// Returns:
+//   - the function releasing the lock
+//   - ErrStoreLocked if the file exists, or the file system error
+func lockFile(path string) (func() error, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return nil, ErrStoreLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		f.Close()
+		return os.Remove(path)
+	}, nil
+}
+
+// syncDir is a no-op where directories cannot be synced; their entries are
+// made durable by the file system itself.
+func syncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,41 @@
+//go:build unix
+
+package bmssp
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive lock on the file at path, creating it if
+// needed. The operating system releases the lock if the process dies.
+//
+// Returns:
+//   - the function releasing the lock
+//   - ErrStoreLocked if another process holds it, or the file system error
+func lockFile(path string) (func() error, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, ErrStoreLocked
+		}
+		return nil, err
+	}
+	return f.Close, nil
+}
+
+// syncDir flushes the entries of directory dir to disk, making a rename or
+// file creation in it durable.
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
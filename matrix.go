@@ -0,0 +1,101 @@
+package bmssp
+
+// DistanceMatrix computes the shortest distances from every origin to every
+// destination, e.g. the cost matrix of a fleet routing or VRP solver. It
+// runs one query per origin in parallel, each stopping as soon as all
+// destinations are settled.
+//
+// Parameters:
+//   - g: input graph, not mutated while the queries run
+//   - origins, destinations: the rows and columns of the matrix
+//   - opts: optional query settings applied to every query
+//
+// Returns:
+//   - m[i][j], the distance from origins[i] to destinations[j]; INF if it
+//     is unreachable or either node is not in g
+func DistanceMatrix(g *Graph, origins, destinations []NodeID, opts ...Option) [][]Dist {
+	return distanceMatrix(g, origins, destinations, 0, opts)
+}
+
+// distanceMatrix is DistanceMatrix on a pool of workers (0 for GOMAXPROCS).
+func distanceMatrix(g *Graph, origins, destinations []NodeID, workers int, opts []Option) [][]Dist {
+	want := NewNodeSet()
+	for _, t := range destinations {
+		want.Add(t)
+	}
+	opts = append(opts[:len(opts):len(opts)], WithTargets(want))
+
+	m := make([][]Dist, len(origins))
+	parallelFor(len(origins), workers, func(i int) {
+		m[i] = make([]Dist, len(destinations))
+		if _, ok := g.adj[origins[i]]; !ok {
+			for j := range m[i] {
+				m[i][j] = INF
+			}
+			return
+		}
+		S := NewNodeSet()
+		S.Add(origins[i])
+		res := Solve(g, S, INF, opts...)
+		for j, t := range destinations {
+			if d, ok := res.Dist[t]; ok {
+				m[i][j] = d
+			} else {
+				m[i][j] = INF
+			}
+		}
+	})
+	return m
+}
+
+// bucketEntry records that destination column reaches a node of a
+// hierarchy at distance dist.
+type bucketEntry struct {
+	column int
+	dist   Dist
+}
+
+// Matrix computes the distances from every origin to every destination with
+// the bucket technique, sharing the work across origins: one backward
+// upward search per destination leaves its distance in a bucket at every
+// node reached, and one forward upward search per origin combines the
+// buckets of the nodes it reaches. Each search is small, so a 1000×1000
+// matrix costs 2000 hierarchy searches instead of 1000 full searches.
+//
+// Parameters:
+//   - origins, destinations: the rows and columns of the matrix
+//
+// Returns:
+//   - m[i][j], the distance from origins[i] to destinations[j]; INF if it
+//     is unreachable or either node is not in the hierarchy
+func (h *Hierarchy) Matrix(origins, destinations []NodeID) [][]Dist {
+	buckets := make(map[int][]bucketEntry)
+	for j, t := range destinations {
+		x, ok := h.index[t]
+		if !ok {
+			continue
+		}
+		for v, d := range h.upward(x, h.down, nil) {
+			buckets[v] = append(buckets[v], bucketEntry{j, d})
+		}
+	}
+
+	m := make([][]Dist, len(origins))
+	parallelFor(len(origins), 0, func(i int) {
+		row := make([]Dist, len(destinations))
+		for j := range row {
+			row[j] = INF
+		}
+		m[i] = row
+		x, ok := h.index[origins[i]]
+		if !ok {
+			return
+		}
+		for v, d := range h.upward(x, h.up, nil) {
+			for _, b := range buckets[v] {
+				row[b.column] = min(row[b.column], d+b.dist)
+			}
+		}
+	})
+	return m
+}
@@ -0,0 +1,49 @@
+package bmssp
+
+import "testing"
+
+func TestDistanceMatrix(t *testing.T) {
+	g := generateRandomGraph(300, 1200, 10, 7)
+	origins := []NodeID{0, 17, 150, 299}
+	destinations := []NodeID{5, 0, 299, 42, 5}
+
+	m := DistanceMatrix(g, origins, destinations)
+	if len(m) != len(origins) {
+		t.Fatalf("expected %d rows, got %d", len(origins), len(m))
+	}
+	for i, s := range origins {
+		want := Dijkstra(g, s)
+		for j, d := range destinations {
+			w, ok := want[d]
+			if !ok {
+				w = INF
+			}
+			if m[i][j] != w && !approxEqual(m[i][j], w, 1e-9) {
+				t.Errorf("%d -> %d: expected %v, got %v", s, d, w, m[i][j])
+			}
+		}
+	}
+
+	// Unknown nodes give INF rather than a bogus zero on the diagonal
+	m = DistanceMatrix(g, []NodeID{-1}, []NodeID{-1, 0})
+	if m[0][0] != INF || m[0][1] != INF {
+		t.Errorf("expected INF for an unknown origin, got %v", m[0])
+	}
+}
+
+func TestHierarchy_Matrix(t *testing.T) {
+	g := generateRandomGraph(300, 1200, 10, 7)
+	h := NewHierarchy(g)
+	origins := []NodeID{0, 17, 150, 299, -1}
+	destinations := []NodeID{5, 0, 299, 42, 5, -1, 150}
+
+	want := DistanceMatrix(g, origins, destinations)
+	got := h.Matrix(origins, destinations)
+	for i := range origins {
+		for j := range destinations {
+			if got[i][j] != want[i][j] && !approxEqual(got[i][j], want[i][j], 1e-9) {
+				t.Errorf("%d -> %d: expected %v, got %v", origins[i], destinations[j], want[i][j], got[i][j])
+			}
+		}
+	}
+}
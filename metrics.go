@@ -0,0 +1,48 @@
+package bmssp
+
+import "time"
+
+// Metrics receives work counters and latencies of finished queries, e.g. to
+// feed Prometheus counters and histograms. Each method is called once per
+// query with the query's totals; alg is "bmssp" or "dijkstra" and suits a
+// metric label. Implementations must be safe for concurrent use when
+// queries run concurrently.
+type Metrics interface {
+	AddRelaxations(alg string, n int)
+	AddNodesSettled(alg string, n int)
+	AddQueueOps(alg string, n int)
+	ObserveRecursionDepth(alg string, depth int)
+	ObserveLatency(alg string, d time.Duration)
+}
+
+// NopMetrics is a Metrics that discards everything, for embedding in
+// implementations that only need some of the methods.
+type NopMetrics struct{}
+
+func (NopMetrics) AddRelaxations(string, int)           {}
+func (NopMetrics) AddNodesSettled(string, int)          {}
+func (NopMetrics) AddQueueOps(string, int)              {}
+func (NopMetrics) ObserveRecursionDepth(string, int)    {}
+func (NopMetrics) ObserveLatency(string, time.Duration) {}
+
+// WithMetrics reports the statistics of the query to m when it finishes.
+func WithMetrics(m Metrics) Option {
+	return func(c *config) { c.metrics = m }
+}
+
+// report hands the statistics of a finished query to the configured Metrics
+// and progress callback.
+func (s *solver) report(alg string) {
+	if s.cfg.progress != nil {
+		s.cfg.progress(min(s.stats.NodesSettled, s.total), s.total)
+	}
+	m := s.cfg.metrics
+	if m == nil {
+		return
+	}
+	m.AddRelaxations(alg, s.stats.Relaxations)
+	m.AddNodesSettled(alg, s.stats.NodesSettled)
+	m.AddQueueOps(alg, s.stats.QueueOps)
+	m.ObserveRecursionDepth(alg, s.stats.RecursionDepth)
+	m.ObserveLatency(alg, time.Since(s.start))
+}
@@ -0,0 +1,53 @@
+package bmssp
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingMetrics sums everything reported per algorithm.
+type recordingMetrics struct {
+	NopMetrics
+	mu       sync.Mutex
+	queries  map[string]int
+	settled  map[string]int
+	queueOps map[string]int
+}
+
+func (m *recordingMetrics) AddNodesSettled(alg string, n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.settled[alg] += n
+}
+
+func (m *recordingMetrics) AddQueueOps(alg string, n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queueOps[alg] += n
+}
+
+func (m *recordingMetrics) ObserveLatency(alg string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queries[alg]++
+}
+
+func TestWithMetrics(t *testing.T) {
+	m := &recordingMetrics{queries: map[string]int{}, settled: map[string]int{}, queueOps: map[string]int{}}
+	g := generateGridGraph(10, 10)
+
+	res := Solve(g, sources(0), INF, WithMetrics(m))
+	ref := SolveDijkstra(g, sources(0), WithMetrics(m))
+	SolveDijkstra(g, sources(0), WithMetrics(m))
+
+	if m.queries["bmssp"] != 1 || m.queries["dijkstra"] != 2 {
+		t.Errorf("expected 1 BMSSP and 2 Dijkstra queries, got %v", m.queries)
+	}
+	if m.settled["bmssp"] != res.Stats.NodesSettled || m.settled["dijkstra"] != 2*ref.Stats.NodesSettled {
+		t.Errorf("expected settled counts to match the stats, got %v", m.settled)
+	}
+	if res.Stats.QueueOps == 0 || m.queueOps["bmssp"] != res.Stats.QueueOps {
+		t.Errorf("expected %d queue operations reported, got %v", res.Stats.QueueOps, m.queueOps)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package bmssp
+
+import "os"
+
+// mapFile reads the file at path into memory where mapping it is not
+// supported.
+func mapFile(path string) ([]byte, func() error, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
@@ -0,0 +1,221 @@
+package bmssp
+
+import (
+	"context"
+	"time"
+)
+
+// Option configures a shortest-path query.
+type Option func(*config)
+
+// config holds the query settings assembled from Options.
+type config struct {
+	overlay WeightOverlay
+	extra   edgeExtender // set when overlay also contributes edges
+	visitor Visitor
+	targets NodeSet // stop once these are settled; nil searches everything
+
+	maxHops    int             // maximum edges per path; 0 for no limit
+	nodeBudget int             // maximum nodes to settle; 0 for no limit
+	deadline   time.Time       // stop searching at this time; zero for none
+	ctx        context.Context // stop searching once done; nil for none
+
+	newQueue func() PriorityQueue // queue of the Dijkstra-based searches; nil for BinaryHeap
+	delta    Dist                 // Δ-stepping bucket width; 0 for automatic
+	epsilon  Dist                 // relative tolerance of distance comparisons
+	workers  int                  // goroutines scanning a Δ-stepping bucket; 0 or 1 for sequential
+	branches int                  // recursive BMSSP calls run in parallel; 0 or 1 for sequential
+
+	weightFunc func(u NodeID, e Edge) Dist // transforms the weights seen by the query; nil for none
+	excluded   NodeSet                     // nodes the query must not enter; nil for none
+	edgeFilter func(u NodeID, e Edge) bool // edges the query may use; nil for all
+
+	unitWeights bool     // every edge weighs 1
+	tieBreak    TieBreak // how equal-length paths are ranked
+	dag         bool     // the reachable graph is acyclic
+	noFastPaths bool     // never dispatch to BFS or DAG relaxation
+
+	shadow *Shadow // verifies a sample of queries against Dijkstra
+
+	explain bool    // record per-node history for Explain
+	metrics Metrics // receives the statistics of every query; nil for none
+	tracer  Tracer  // receives spans for the phases of every query; nil for none
+
+	progress func(settled, total int) // called every progressInterval settles; nil for none
+}
+
+// newConfig applies opts on top of the default settings.
+func newConfig(opts []Option) config {
+	cfg := config{epsilon: DefaultEpsilon}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WeightOverlay adjusts edge weights at query time without modifying the graph.
+// Weight returns the effective weight of edge e leaving node from; returning
+// INF closes the edge for the query.
+type WeightOverlay interface {
+	Weight(from NodeID, e Edge) Dist
+}
+
+// WithOverlay evaluates the query against the weights reported by o instead
+// of the stored edge weights.
+func WithOverlay(o WeightOverlay) Option {
+	return func(c *config) {
+		c.overlay = o
+		c.extra, _ = o.(edgeExtender)
+	}
+}
+
+// WithWeightFunc evaluates the query under the cost model fn, so one stored
+// graph can answer queries by distance, travel time or energy: fn receives
+// every edge scanned, with the weight the overlay gives it if one is set,
+// and returns the weight to relax it with. Edges already closed (INF) stay
+// closed without calling fn; fn may close others by returning INF. Weights
+// must be non-negative, and fn must be safe for concurrent use if the query
+// is.
+func WithWeightFunc(fn func(u NodeID, e Edge) Dist) Option {
+	return func(c *config) { c.weightFunc = fn }
+}
+
+// edgeExtender is implemented by overlays that add edges which are not
+// stored in the graph.
+type edgeExtender interface {
+	ExtraEdges(from NodeID) []Edge
+}
+
+// Visitor observes a running query. BMSSP and Dijkstra call OnRelax whenever
+// an edge improves a tentative distance and OnSettle whenever a node's
+// outgoing edges are scanned. With Δ-stepping a node can be settled more than
+// once if it is improved again within the same bucket.
+type Visitor interface {
+	OnSettle(v NodeID, d Dist)
+	OnRelax(u, v NodeID, oldDist, newDist Dist)
+}
+
+// VisitorFuncs adapts plain functions to the Visitor interface. Nil fields
+// are ignored.
+type VisitorFuncs struct {
+	Settle func(v NodeID, d Dist)
+	Relax  func(u, v NodeID, oldDist, newDist Dist)
+}
+
+// OnSettle calls f.Settle if set.
+func (f VisitorFuncs) OnSettle(v NodeID, d Dist) {
+	if f.Settle != nil {
+		f.Settle(v, d)
+	}
+}
+
+// OnRelax calls f.Relax if set.
+func (f VisitorFuncs) OnRelax(u, v NodeID, oldDist, newDist Dist) {
+	if f.Relax != nil {
+		f.Relax(u, v, oldDist, newDist)
+	}
+}
+
+// WithVisitor reports settle and relax events of the query to v.
+func WithVisitor(v Visitor) Option {
+	return func(c *config) {
+		c.visitor = v
+	}
+}
+
+// WithTargets stops the search as soon as the distances of all nodes in set
+// are final (or the bound is exceeded). Distances of other nodes are left as
+// upper bounds and may be INF even if the node is reachable.
+func WithTargets(set NodeSet) Option {
+	return func(c *config) {
+		c.targets = set
+	}
+}
+
+// WithMaxHops limits paths to at most h edges. Nodes are still settled in
+// distance order, so a node first reached by a short path with many hops is
+// not revisited by a longer path with fewer hops: distances beyond the limit
+// are best-effort upper bounds of the hop-constrained optimum.
+// Result.Stopped is StopMaxHops if the limit cut off any edge.
+func WithMaxHops(h int) Option {
+	return func(c *config) {
+		c.maxHops = h
+	}
+}
+
+// WithNodeBudget stops the search after n nodes have been settled. Settled
+// nodes have exact distances; the others are left as upper bounds.
+// Result.Stopped is StopNodeBudget if the budget ran out.
+func WithNodeBudget(n int) Option {
+	return func(c *config) {
+		c.nodeBudget = n
+	}
+}
+
+// WithDeadline degrades the query instead of failing it when deadline passes:
+// the search stops and returns the distances found so far, with
+// Result.Stopped set to StopDeadline. Settled nodes have exact distances; the
+// others are upper bounds. Servers typically pass the request deadline minus
+// a margin for encoding the response.
+func WithDeadline(deadline time.Time) Option {
+	return func(c *config) {
+		c.deadline = deadline
+	}
+}
+
+// WithContext stops the query like WithDeadline once ctx is done, e.g. when
+// an HTTP client disconnects. Result.Stopped is StopDeadline if the
+// context's deadline passed and StopCanceled if it was canceled.
+func WithContext(ctx context.Context) Option {
+	return func(c *config) {
+		c.ctx = ctx
+	}
+}
+
+// WithDelta overrides the Δ-stepping bucket width used by BMSSP. By default
+// Δ is chosen from the graph as maxWeight/avgDegree. Non-positive values
+// select the default.
+func WithDelta(delta Dist) Option {
+	return func(c *config) {
+		c.delta = delta
+	}
+}
+
+// WithParallelism spreads the edge scans of each Δ-stepping bucket over up
+// to workers goroutines; 0 or 1 scans sequentially. Improvements are applied
+// in order on the query's goroutine, so distances match a sequential query
+// and predecessors differ at most between equally short paths. Buckets with
+// few nodes are scanned sequentially, and so are queries over a GraphReader.
+// Overlays, weight functions, edge filters and Neighbors implementations
+// must be safe for concurrent use.
+func WithParallelism(workers int) Option {
+	return func(c *config) { c.workers = workers }
+}
+
+// WithParallelRecursion runs the BMSSP recursion on up to branches
+// goroutines: once a pivot pass leaves a large frontier, the recursive call
+// on it is split into parallel calls on parts of the frontier, each with
+// its own distance map, merged when they return. Distances match a
+// sequential query and predecessors differ at most between equally short
+// paths. The branches may explore overlapping regions, so the total work
+// grows; it pays off for frontiers spread over the graph, e.g. many
+// scattered sources. Combined with WithParallelism, the branches share the
+// workers. Queries with targets, a node budget, a hop limit, a tie-break,
+// a visitor, progress reports, a tracer or Explain, and queries over a
+// GraphReader, recurse sequentially. Overlays, weight functions, edge
+// filters and Neighbors implementations must be safe for concurrent use.
+func WithParallelRecursion(branches int) Option {
+	return func(c *config) { c.branches = branches }
+}
+
+// progressInterval is the number of settled nodes between progress reports.
+const progressInterval = 1024
+
+// WithProgress calls fn every 1024 settled nodes and once when the query
+// finishes, with the number of nodes settled so far and the number of nodes
+// in the graph, e.g. to drive a progress bar or detect stalls. Nodes
+// settled again after an improvement count once more, so settled is capped
+// at total. fn runs on the query's goroutine and should return quickly.
+func WithProgress(fn func(settled, total int)) Option {
+	return func(c *config) { c.progress = fn }
+}
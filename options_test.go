@@ -0,0 +1,143 @@
+package bmssp
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestWithWeightFunc(t *testing.T) {
+	// Edge weights are lengths; the cost model times the 0->1->3 motorway
+	// at a third of the speed limit of the rest.
+	g := NewGraph()
+	g.AddEdge(0, 1, 6)
+	g.AddEdge(1, 3, 6)
+	g.AddEdge(0, 2, 4)
+	g.AddEdge(2, 3, 4)
+	motorway := func(u NodeID, e Edge) bool { return u == 0 && e.To == 1 || u == 1 && e.To == 3 }
+	time := func(u NodeID, e Edge) Dist {
+		if motorway(u, e) {
+			return e.Weight / 3
+		}
+		return e.Weight
+	}
+
+	if d := BMSSPSingleSource(g, 0, INF)[3]; d != 8 {
+		t.Errorf("expected length 8, got %v", d)
+	}
+	for _, opts := range [][]Option{{WithWeightFunc(time)}, {WithWeightFunc(time), WithoutFastPaths()}} {
+		if d := BMSSPSingleSource(g, 0, INF, opts...)[3]; d != 4 {
+			t.Errorf("expected travel time 4, got %v", d)
+		}
+	}
+	if path, _ := ShortestPath(g, 0, 3, WithWeightFunc(time)); len(path) != 3 || path[1] != 1 {
+		t.Errorf("expected the motorway, got %v", path)
+	}
+	if d := BMSSPSingleTarget(g, 3, INF, WithWeightFunc(time))[0]; d != 4 {
+		t.Errorf("expected travel time 4 backwards, got %v", d)
+	}
+
+	// Overlays apply first and closed edges stay closed
+	closed := closedEdge(EdgeID{From: 0, To: 2})
+	calls := 0
+	count := func(u NodeID, e Edge) Dist { calls++; return time(u, e) }
+	if d := BMSSPSingleSource(g, 0, INF, WithOverlay(closed), WithWeightFunc(count))[2]; d != INF || calls != 2 {
+		t.Errorf("expected node 2 closed off with 2 calls, got %v after %d", d, calls)
+	}
+
+	// A bound is checked against the transformed weights
+	hour := WithWeightFunc(func(NodeID, Edge) Dist { return 60 })
+	if res := Solve(g, sources(0), 100, hour); !res.Truncated || res.Dist[1] != 60 {
+		t.Errorf("expected a truncated search, got %v", res.Dist)
+	}
+}
+
+func TestWithParallelism(t *testing.T) {
+	// Wide buckets so that most of them are scanned in parallel
+	g := generateRandomGraph(3000, 15000, 10.0, 21)
+	r := rand.New(rand.NewSource(23))
+	for i := 0; i < 10; i++ {
+		S := sources(NodeID(r.Intn(3000)), NodeID(r.Intn(3000)))
+		B := Dist(r.Float64() * 40)
+		want := Solve(g, S, B, WithDelta(5))
+		got := Solve(g, S, B, WithDelta(5), WithParallelism(4))
+		if got.Stopped != want.Stopped || got.Truncated != want.Truncated {
+			t.Fatalf("query %d: expected %v (truncated %v), got %v (truncated %v)", i, want.Stopped, want.Truncated, got.Stopped, got.Truncated)
+		}
+		for v, d := range want.Dist {
+			if d <= B && got.Dist[v] != d {
+				t.Fatalf("query %d: node %d: expected %v, got %v", i, v, d, got.Dist[v])
+			}
+		}
+		for v := range got.Pred {
+			if got.Dist[v] > B {
+				continue
+			}
+			u := got.Pred[v]
+			if w, _ := PathCost(g, []NodeID{u, v}); !approxEqual(got.Dist[u]+w, got.Dist[v], DefaultEpsilon) {
+				t.Fatalf("query %d: node %d: predecessor %d does not lie on a shortest path", i, v, u)
+			}
+		}
+	}
+
+	// Weight functions and targets behave as in a sequential query
+	double := WithWeightFunc(func(_ NodeID, e Edge) Dist { return 2 * e.Weight })
+	want := BMSSPSingleSource(g, 0, INF, double)
+	for v, d := range BMSSPSingleSource(g, 0, INF, double, WithParallelism(8)) {
+		if d != want[v] {
+			t.Fatalf("weight func: node %d: expected %v, got %v", v, want[v], d)
+		}
+	}
+	res := Solve(g, sources(0), INF, WithTargets(sources(2999)), WithParallelism(8))
+	if res.Stopped != StopTargets || res.Dist[2999] != Dijkstra(g, 0)[2999] {
+		t.Errorf("targets: expected the exact distance %v, got %v (%v)", Dijkstra(g, 0)[2999], res.Dist[2999], res.Stopped)
+	}
+}
+
+func TestWithParallelRecursion(t *testing.T) {
+	g := generateRandomGraph(3000, 15000, 10.0, 25)
+	r := rand.New(rand.NewSource(27))
+	for i := 0; i < 10; i++ {
+		// Many seeded sources leave a large frontier after the first pivot
+		seeds := make(map[NodeID]Dist)
+		for range 400 {
+			seeds[NodeID(r.Intn(3000))] = Dist(r.Float64() * 20)
+		}
+		B := Dist(10 + r.Float64()*30)
+		want := SolveFrom(g, seeds, B)
+		for _, opts := range [][]Option{{WithParallelRecursion(4)}, {WithParallelRecursion(8), WithParallelism(8)}} {
+			got := SolveFrom(g, seeds, B, opts...)
+			if got.Stopped != want.Stopped || got.Truncated != want.Truncated {
+				t.Fatalf("query %d: expected %v (truncated %v), got %v (truncated %v)", i, want.Stopped, want.Truncated, got.Stopped, got.Truncated)
+			}
+			for v, d := range want.Dist {
+				if d <= B && got.Dist[v] != d {
+					t.Fatalf("query %d: node %d: expected %v, got %v", i, v, d, got.Dist[v])
+				}
+			}
+			for v, u := range got.Pred {
+				if got.Dist[v] > B {
+					continue
+				}
+				if w, _ := PathCost(g, []NodeID{u, v}); !approxEqual(got.Dist[u]+w, got.Dist[v], DefaultEpsilon) {
+					t.Fatalf("query %d: node %d: predecessor %d does not lie on a shortest path", i, v, u)
+				}
+			}
+		}
+	}
+
+	// The recursion forks: branches count their work in the query's stats
+	seeds := make(map[NodeID]Dist)
+	for v := range NodeID(3000) {
+		seeds[v] = Dist(v%100) / 10
+	}
+	seq := SolveFrom(g, seeds, INF)
+	par := SolveFrom(g, seeds, INF, WithParallelRecursion(4))
+	if par.Stats.NodesSettled < seq.Stats.NodesSettled || par.Stats.RecursionDepth < 2 {
+		t.Errorf("expected the branches' work in the stats, got %+v", par.Stats)
+	}
+	for v, d := range seq.Dist {
+		if par.Dist[v] != d {
+			t.Fatalf("node %d: expected %v, got %v", v, d, par.Dist[v])
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package bmssp
+
+import (
+	"cmp"
+	"iter"
+)
+
+// lazyHeap is a binary heap that is built in O(n) and popped on demand, so
+// ordered iteration costs O(n + k log n) for the first k items instead of a
+// full sort.
+type lazyHeap[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+func newLazyHeap[T any](items []T, less func(a, b T) bool) *lazyHeap[T] {
+	h := &lazyHeap[T]{items: items, less: less}
+	for i := len(items)/2 - 1; i >= 0; i-- {
+		h.down(i)
+	}
+	return h
+}
+
+func (h *lazyHeap[T]) down(i int) {
+	n := len(h.items)
+	for {
+		least := i
+		if l := 2*i + 1; l < n && h.less(h.items[l], h.items[least]) {
+			least = l
+		}
+		if r := 2*i + 2; r < n && h.less(h.items[r], h.items[least]) {
+			least = r
+		}
+		if least == i {
+			return
+		}
+		h.items[i], h.items[least] = h.items[least], h.items[i]
+		i = least
+	}
+}
+
+func (h *lazyHeap[T]) pop() T {
+	top := h.items[0]
+	n := len(h.items) - 1
+	h.items[0] = h.items[n]
+	h.items = h.items[:n]
+	h.down(0)
+	return top
+}
+
+// all pops every item in order, stopping early if yield returns false.
+func (h *lazyHeap[T]) all(yield func(T) bool) {
+	for len(h.items) > 0 {
+		if !yield(h.pop()) {
+			return
+		}
+	}
+}
+
+// NodesByDegree yields the nodes of g with their out-degrees, ordered by
+// degree (ascending, or descending if descending is set) and then by ID.
+// Nodes are ordered lazily: stopping after k nodes costs O(n + k log n).
+// The graph must not be mutated during iteration.
+func (g *Graph) NodesByDegree(descending bool) iter.Seq2[NodeID, int] {
+	return func(yield func(NodeID, int) bool) {
+		nodes := make([]NodeID, 0, len(g.adj))
+		for u := range g.adj {
+			nodes = append(nodes, u)
+		}
+		h := newLazyHeap(nodes, func(a, b NodeID) bool {
+			c := cmp.Compare(len(g.adj[a]), len(g.adj[b]))
+			if descending {
+				c = -c
+			}
+			return c < 0 || c == 0 && a < b
+		})
+		h.all(func(u NodeID) bool {
+			return yield(u, len(g.adj[u]))
+		})
+	}
+}
+
+// weightedEdge is an edge with its tail, for ordered edge iteration.
+type weightedEdge struct {
+	id     EdgeID
+	weight Dist
+}
+
+// EdgesByWeight yields the edges of g with their weights, ordered by weight
+// (ascending, or descending if descending is set) and then by EdgeID.
+// Parallel edges are yielded separately. Edges are ordered lazily: stopping
+// after k edges costs O(m + k log m). The graph must not be mutated during
+// iteration.
+func (g *Graph) EdgesByWeight(descending bool) iter.Seq2[EdgeID, Dist] {
+	return func(yield func(EdgeID, Dist) bool) {
+		edges := make([]weightedEdge, 0, g.numEdges)
+		for u, out := range g.adj {
+			for _, e := range out {
+				edges = append(edges, weightedEdge{id: EdgeID{From: u, To: e.To}, weight: e.Weight})
+			}
+		}
+		h := newLazyHeap(edges, func(a, b weightedEdge) bool {
+			c := cmp.Compare(a.weight, b.weight)
+			if descending {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+			if a.id.From != b.id.From {
+				return a.id.From < b.id.From
+			}
+			return a.id.To < b.id.To
+		})
+		h.all(func(e weightedEdge) bool {
+			return yield(e.id, e.weight)
+		})
+	}
+}
+
+// ByDistance yields every reachable node with its distance, ordered by
+// distance and then by ID, e.g. to list the nearest nodes first. Nodes are
+// ordered lazily: stopping after k nodes costs O(n + k log n).
+func (r *Result) ByDistance() iter.Seq2[NodeID, Dist] {
+	return func(yield func(NodeID, Dist) bool) {
+		nodes := make([]NodeID, 0, len(r.Dist))
+		for v, d := range r.Dist {
+			if d < INF {
+				nodes = append(nodes, v)
+			}
+		}
+		h := newLazyHeap(nodes, func(a, b NodeID) bool {
+			c := cmp.Compare(r.Dist[a], r.Dist[b])
+			return c < 0 || c == 0 && a < b
+		})
+		h.all(func(v NodeID) bool {
+			return yield(v, r.Dist[v])
+		})
+	}
+}
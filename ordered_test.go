@@ -0,0 +1,80 @@
+package bmssp
+
+import "testing"
+
+func TestNodesByDegree(t *testing.T) {
+	g := generateRandomGraph(100, 600, 10, 4)
+
+	for _, descending := range []bool{false, true} {
+		seen := 0
+		last := -1
+		for u, deg := range g.NodesByDegree(descending) {
+			if deg != len(g.OutEdges(u)) {
+				t.Errorf("node %d: expected degree %d, got %d", u, len(g.OutEdges(u)), deg)
+			}
+			if seen > 0 && (descending && deg > last || !descending && deg < last) {
+				t.Errorf("descending=%v: degree %d after %d", descending, deg, last)
+			}
+			last = deg
+			seen++
+		}
+		if seen != 100 {
+			t.Errorf("expected 100 nodes, got %d", seen)
+		}
+	}
+}
+
+func TestEdgesByWeight(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 3)
+	g.AddEdge(1, 2, 1)
+	g.AddEdge(2, 0, 2)
+	g.AddEdge(0, 1, 1) // parallel edge
+
+	var ids []EdgeID
+	var weights []Dist
+	for id, w := range g.EdgesByWeight(false) {
+		ids = append(ids, id)
+		weights = append(weights, w)
+	}
+	if len(ids) != 4 || ids[0] != (EdgeID{From: 0, To: 1}) || ids[1] != (EdgeID{From: 1, To: 2}) ||
+		weights[2] != 2 || weights[3] != 3 {
+		t.Errorf("expected 0->1 (1), 1->2 (1), 2->0 (2), 0->1 (3), got %v %v", ids, weights)
+	}
+
+	for id, w := range g.EdgesByWeight(true) {
+		if id != (EdgeID{From: 0, To: 1}) || w != 3 {
+			t.Errorf("expected heaviest edge 0->1 (3), got %v (%v)", id, w)
+		}
+		break
+	}
+}
+
+func TestResult_ByDistance(t *testing.T) {
+	g := generateRandomGraph(100, 400, 10, 5)
+	g.AddEdge(500, 0, 1) // unreachable from 0
+	res := Solve(g, sources(0), INF)
+
+	seen := 0
+	last := Dist(-1)
+	for v, d := range res.ByDistance() {
+		if d != res.Dist[v] || d < last {
+			t.Errorf("node %d at %v after %v, want non-decreasing distances from the result", v, d, last)
+		}
+		if v == 500 {
+			t.Error("unreachable node yielded")
+		}
+		last = d
+		seen++
+	}
+	if want := len(res.Dist) - len(res.Unreachable); seen != want {
+		t.Errorf("expected %d reachable nodes, got %d", want, seen)
+	}
+
+	for v := range res.ByDistance() {
+		if v != 0 {
+			t.Errorf("expected the source first, got %d", v)
+		}
+		break
+	}
+}
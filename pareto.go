@@ -0,0 +1,176 @@
+package bmssp
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// CostFunc gives the second criterion of an edge for bi-criteria queries,
+// e.g. the toll of a road whose weight is its travel time. Costs must be
+// non-negative.
+type CostFunc func(from NodeID, e Edge) Dist
+
+// ParetoOptions configures Pareto and ParetoPaths.
+type ParetoOptions struct {
+	Bound Dist // bound on the distance (first criterion) of every path (default: INF)
+
+	// MaxLabels caps the number of Pareto-optimal labels kept per node
+	// (default: unlimited). Fronts can grow large on big graphs; with a cap
+	// the labels found first, those of smallest distance, are kept and
+	// the front may be incomplete.
+	MaxLabels int
+}
+
+// ParetoPath is a Pareto-optimal path: no other path is at most as long and
+// at most as costly while being strictly better in one of the two.
+type ParetoPath struct {
+	Dist Dist     // total edge weight
+	Cost Dist     // total cost
+	Path []NodeID // nodes from source to the target, inclusive
+}
+
+// ParetoResult holds the Pareto fronts from a source to every node.
+type ParetoResult struct {
+	labels []paretoLabel
+	front  map[NodeID][]int32 // labels of each node, by increasing distance
+}
+
+// paretoLabel is a (distance, cost) pair at a node, reached from pred.
+type paretoLabel struct {
+	node       NodeID
+	dist, cost Dist
+	pred       int32 // index of the predecessor label, -1 at the source
+}
+
+// paretoHeap orders label indices lexicographically by (dist, cost).
+type paretoHeap struct {
+	labels *[]paretoLabel
+	items  []int32
+}
+
+func (h *paretoHeap) Len() int { return len(h.items) }
+func (h *paretoHeap) Less(i, j int) bool {
+	a, b := (*h.labels)[h.items[i]], (*h.labels)[h.items[j]]
+	return a.dist < b.dist || a.dist == b.dist && a.cost < b.cost
+}
+func (h *paretoHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *paretoHeap) Push(x any)    { h.items = append(h.items, x.(int32)) }
+func (h *paretoHeap) Pop() any {
+	item := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return item
+}
+
+// Pareto computes the Pareto front of (distance, cost) pairs from source to
+// every node, with distance given by the edge weights and cost by cost.
+//
+// It is a multi-criteria label-setting search (Martins' algorithm): labels
+// are expanded in lexicographic (distance, cost) order, so a label is
+// dominated exactly when an earlier label at its node has a cost no larger,
+// and every label that survives is part of the front.
+//
+// Parameters:
+//   - g: input graph
+//   - source: source node
+//   - cost: second criterion of every edge
+//   - opts: bound and label cap
+//
+// Returns:
+//   - the fronts of every node reachable within the bound
+func Pareto(g *Graph, source NodeID, cost CostFunc, opts ParetoOptions) *ParetoResult {
+	return paretoSearch(g, source, cost, opts, nil)
+}
+
+// ParetoPaths returns the Pareto-optimal paths from source to target, by
+// increasing distance and so decreasing cost, e.g. the trade-offs between
+// fast toll roads and slower free ones. The search stops expanding labels
+// that the target's front already dominates. See Pareto.
+//
+// Returns:
+//   - the non-dominated paths, nil if target is unreachable within the bound
+func ParetoPaths(g *Graph, source, target NodeID, cost CostFunc, opts ParetoOptions) []ParetoPath {
+	return paretoSearch(g, source, cost, opts, &target).Frontier(target)
+}
+
+// paretoSearch runs the label-setting search, pruning against the front of
+// target if it is not nil.
+func paretoSearch(g *Graph, source NodeID, cost CostFunc, opts ParetoOptions, target *NodeID) *ParetoResult {
+	r := &ParetoResult{front: make(map[NodeID][]int32)}
+	if _, ok := g.adj[source]; !ok {
+		return r
+	}
+	bound := opts.Bound
+	if bound == 0 {
+		bound = INF
+	}
+
+	// Labels are permanent when popped. As they come in lexicographic
+	// order, a label is dominated iff its cost is at least the lowest cost
+	// among the permanent labels of its node.
+	minCost := make(map[NodeID]Dist)
+	dominated := func(v NodeID, c Dist) bool {
+		if best, ok := minCost[v]; ok && c >= best {
+			return true
+		}
+		// Extensions only grow, so the target's front bounds every node
+		if target != nil {
+			if best, ok := minCost[*target]; ok && c >= best {
+				return true
+			}
+		}
+		return false
+	}
+
+	r.labels = append(r.labels, paretoLabel{node: source, pred: -1})
+	pq := &paretoHeap{labels: &r.labels, items: []int32{0}}
+	for pq.Len() > 0 {
+		i := heap.Pop(pq).(int32)
+		l := r.labels[i]
+		if dominated(l.node, l.cost) {
+			continue
+		}
+		if opts.MaxLabels > 0 && len(r.front[l.node]) >= opts.MaxLabels {
+			continue
+		}
+		minCost[l.node] = l.cost
+		r.front[l.node] = append(r.front[l.node], i)
+		if target != nil && l.node == *target {
+			continue
+		}
+
+		for _, e := range g.adj[l.node] {
+			d := l.dist + e.Weight
+			c := l.cost + cost(l.node, e)
+			if d > bound || d == INF || dominated(e.To, c) {
+				continue
+			}
+			r.labels = append(r.labels, paretoLabel{node: e.To, dist: d, cost: c, pred: i})
+			heap.Push(pq, int32(len(r.labels)-1))
+		}
+	}
+	return r
+}
+
+// Frontier returns the Pareto-optimal paths to v by increasing distance,
+// or nil if v was not reached.
+func (r *ParetoResult) Frontier(v NodeID) []ParetoPath {
+	ids := r.front[v]
+	if len(ids) == 0 {
+		return nil
+	}
+	out := make([]ParetoPath, len(ids))
+	for k, i := range ids {
+		l := r.labels[i]
+		out[k] = ParetoPath{Dist: l.dist, Cost: l.cost}
+		for j := i; j >= 0; j = r.labels[j].pred {
+			out[k].Path = append(out[k].Path, r.labels[j].node)
+		}
+		slices.Reverse(out[k].Path)
+	}
+	return out
+}
+
+// Size returns the number of Pareto-optimal labels at v.
+func (r *ParetoResult) Size(v NodeID) int {
+	return len(r.front[v])
+}
@@ -0,0 +1,120 @@
+package bmssp
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// tollCost reads the toll of an edge from its edge data.
+func tollCost(g *Graph) CostFunc {
+	return func(from NodeID, e Edge) Dist {
+		toll, _ := g.EdgeData(EdgeID{From: from, To: e.To})
+		c, _ := toll.(Dist)
+		return c
+	}
+}
+
+func TestParetoPaths(t *testing.T) {
+	// Three routes from 0 to 3: a fast toll road, a slower cheap road and a
+	// detour that is slower and pricier than the cheap road.
+	g := NewGraph()
+	g.AddEdgeData(0, 1, 10, Dist(5))
+	g.AddEdgeData(1, 3, 10, Dist(5))
+	g.AddEdgeData(0, 2, 20, Dist(1))
+	g.AddEdgeData(2, 3, 20, Dist(0))
+	g.AddEdgeData(0, 4, 25, Dist(3))
+	g.AddEdgeData(4, 3, 20, Dist(3))
+
+	got := ParetoPaths(g, 0, 3, tollCost(g), ParetoOptions{})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 Pareto-optimal paths, got %+v", got)
+	}
+	if got[0].Dist != 20 || got[0].Cost != 10 || !slices.Equal(got[0].Path, []NodeID{0, 1, 3}) {
+		t.Errorf("unexpected fastest path %+v", got[0])
+	}
+	if got[1].Dist != 40 || got[1].Cost != 1 || !slices.Equal(got[1].Path, []NodeID{0, 2, 3}) {
+		t.Errorf("unexpected cheapest path %+v", got[1])
+	}
+
+	if got := ParetoPaths(g, 0, 3, tollCost(g), ParetoOptions{Bound: 30}); len(got) != 1 || got[0].Dist != 20 {
+		t.Errorf("expected only the fast path within bound 30, got %+v", got)
+	}
+	if got := ParetoPaths(g, 3, 0, tollCost(g), ParetoOptions{}); got != nil {
+		t.Errorf("expected no path backwards, got %+v", got)
+	}
+}
+
+// bruteFront enumerates every simple path from source to target and keeps
+// the non-dominated (distance, cost) pairs, sorted by distance.
+func bruteFront(g *Graph, source, target NodeID, cost CostFunc) [][2]Dist {
+	var all [][2]Dist
+	onPath := NewNodeSet()
+	var walk func(u NodeID, d, c Dist)
+	walk = func(u NodeID, d, c Dist) {
+		if u == target {
+			all = append(all, [2]Dist{d, c})
+			return
+		}
+		onPath.Add(u)
+		for _, e := range g.adj[u] {
+			if !onPath.Has(e.To) {
+				walk(e.To, d+e.Weight, c+cost(u, e))
+			}
+		}
+		delete(onPath, u)
+	}
+	walk(source, 0, 0)
+
+	var front [][2]Dist
+	for _, p := range all {
+		dominated := false
+		for _, q := range all {
+			if q[0] <= p[0] && q[1] <= p[1] && q != p {
+				dominated = true
+			}
+		}
+		if !dominated && !slices.Contains(front, p) {
+			front = append(front, p)
+		}
+	}
+	slices.SortFunc(front, func(a, b [2]Dist) int { return int(a[0] - b[0]) })
+	return front
+}
+
+func TestPareto_MatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	g := NewGraph()
+	for range 40 {
+		u, v := NodeID(rng.Intn(12)), NodeID(rng.Intn(12))
+		if u != v {
+			g.AddEdgeData(u, v, Dist(1+rng.Intn(9)), Dist(rng.Intn(9)))
+		}
+	}
+	cost := tollCost(g)
+
+	all := Pareto(g, 0, cost, ParetoOptions{})
+	for v := range g.adj {
+		want := bruteFront(g, 0, v, cost)
+		for name, got := range map[string][]ParetoPath{
+			"all":    all.Frontier(v),
+			"target": ParetoPaths(g, 0, v, cost, ParetoOptions{}),
+		} {
+			if len(got) != len(want) {
+				t.Fatalf("%s: node %d: expected front %v, got %+v", name, v, want, got)
+			}
+			for i, p := range got {
+				if p.Dist != want[i][0] || p.Cost != want[i][1] || p.Path[0] != 0 || p.Path[len(p.Path)-1] != v {
+					t.Errorf("%s: node %d: expected %v, got %+v", name, v, want[i], p)
+				}
+			}
+		}
+	}
+
+	capped := Pareto(g, 0, cost, ParetoOptions{MaxLabels: 1})
+	for v := range g.adj {
+		if capped.Size(v) > 1 {
+			t.Errorf("node %d keeps %d labels despite the cap", v, capped.Size(v))
+		}
+	}
+}
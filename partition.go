@@ -0,0 +1,162 @@
+package bmssp
+
+import (
+	"maps"
+	"slices"
+)
+
+// partitionImbalance is the slack Partition allows above the average part
+// size while refining the cut.
+const partitionImbalance = 0.03
+
+// partitionPasses bounds the refinement passes of Partition.
+const partitionPasses = 8
+
+// Partition splits the nodes of g into k parts of nearly equal size with
+// few edges between them, the prerequisite of BuildArcFlags and of
+// splitting work across cores. Parts are grown breadth-first by recursive
+// bisection, which suits mesh-like graphs such as road networks, and then
+// refined by moving boundary nodes to the part most of their edges lead
+// to. Edge directions are ignored.
+//
+// Parameters:
+//   - g: input graph (not modified)
+//   - k: number of parts; clamped to the number of nodes
+//
+// Returns:
+//   - the part of every node, from 0 to k-1; part sizes stay within 3% of
+//     the average, plus one node
+func Partition(g *Graph, k int) map[NodeID]int {
+	nodes := slices.Sorted(maps.Keys(g.adj))
+	part := make(map[NodeID]int, len(nodes))
+	k = min(k, len(nodes))
+	if k <= 1 {
+		for _, v := range nodes {
+			part[v] = 0
+		}
+		return part
+	}
+
+	var split func(nodes []NodeID, k, first int)
+	split = func(nodes []NodeID, k, first int) {
+		if k == 1 {
+			for _, v := range nodes {
+				part[v] = first
+			}
+			return
+		}
+		left := k / 2
+		a, b := bisect(g, nodes, len(nodes)*left/k)
+		split(a, left, first)
+		split(b, k-left, first+left)
+	}
+	split(nodes, k, 0)
+	refinePartition(g, nodes, part, k)
+	return part
+}
+
+// refinePartition greedily moves boundary nodes to the neighboring part
+// that most reduces the edge cut, as long as the part sizes stay balanced.
+func refinePartition(g *Graph, nodes []NodeID, part map[NodeID]int, k int) {
+	size := make([]int, k)
+	for _, p := range part {
+		size[p]++
+	}
+	limit := int(float64(len(nodes))/float64(k)*(1+partitionImbalance)) + 1
+	floor := max(len(nodes)/k-(limit-len(nodes)/k), 1)
+
+	links := make([]int, k)
+	for range partitionPasses {
+		moved := false
+		for _, v := range nodes {
+			clear(links)
+			for _, e := range slices.Concat(g.adj[v], g.InEdges(v)) {
+				if e.To != v {
+					links[part[e.To]]++
+				}
+			}
+			from, best := part[v], part[v]
+			for p, n := range links {
+				if n > links[best] && size[p] < limit && size[from] > floor {
+					best = p
+				}
+			}
+			if best != from {
+				part[v] = best
+				size[from]--
+				size[best]++
+				moved = true
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+}
+
+// EdgeCut returns the number of edges of g between different parts of
+// part, e.g. to compare partitions. Edges leaving unassigned nodes or
+// entering them count as cut.
+func EdgeCut(g *Graph, part map[NodeID]int) int {
+	cut := 0
+	for u, edges := range g.adj {
+		pu, ok := part[u]
+		for _, e := range edges {
+			if pv, ok2 := part[e.To]; !ok || !ok2 || pu != pv {
+				cut++
+			}
+		}
+	}
+	return cut
+}
+
+// splitUntil bisects nodes recursively until every part holds at most size
+// nodes.
+func splitUntil(g *Graph, nodes []NodeID, size int) [][]NodeID {
+	if len(nodes) <= size {
+		return [][]NodeID{nodes}
+	}
+	a, b := bisect(g, nodes, len(nodes)/2)
+	return append(splitUntil(g, a, size), splitUntil(g, b, size)...)
+}
+
+// bisect splits off the first nodes of a breadth-first order from a
+// peripheral node, ignoring edge directions, so that few edges are cut on
+// mesh-like graphs such as road networks.
+func bisect(g *Graph, nodes []NodeID, first int) ([]NodeID, []NodeID) {
+	in := make(map[NodeID]bool, len(nodes))
+	for _, v := range nodes {
+		in[v] = true
+	}
+	order := bfsOrder(g, in, nodes, nodes[0])
+	order = bfsOrder(g, in, nodes, order[len(order)-1])
+	return order[:first], order[first:]
+}
+
+// bfsOrder lists the nodes of set in breadth-first order from start over
+// edges in both directions, continuing with the remaining nodes in the
+// order of nodes.
+func bfsOrder(g *Graph, set map[NodeID]bool, nodes []NodeID, start NodeID) []NodeID {
+	seen := make(map[NodeID]bool, len(nodes))
+	order := make([]NodeID, 0, len(nodes))
+	visit := func(root NodeID) {
+		seen[root] = true
+		order = append(order, root)
+		for i := len(order) - 1; i < len(order); i++ {
+			u := order[i]
+			for _, e := range slices.Concat(g.adj[u], g.InEdges(u)) {
+				if set[e.To] && !seen[e.To] {
+					seen[e.To] = true
+					order = append(order, e.To)
+				}
+			}
+		}
+	}
+	visit(start)
+	for _, v := range nodes {
+		if !seen[v] {
+			visit(v)
+		}
+	}
+	return order
+}
@@ -0,0 +1,39 @@
+package bmssp
+
+import "testing"
+
+func TestPartition(t *testing.T) {
+	g := generateGridGraph(40, 40)
+	for _, k := range []int{2, 3, 4, 7} {
+		part := Partition(g, k)
+		size := make([]int, k)
+		for _, p := range part {
+			size[p]++
+		}
+		for p, n := range size {
+			if n < 1600/k*97/100-1 || n > 1600/k*103/100+1 {
+				t.Errorf("k=%d: part %d holds %d nodes, unbalanced: %v", k, p, n, size)
+			}
+		}
+		// Cutting the grid into k strips or blocks takes at most 2(k-1)
+		// lines of 40 edge pairs
+		if cut := EdgeCut(g, part); cut > 2*2*40*(k-1) {
+			t.Errorf("k=%d: expected a cut of at most %d edges, got %d", k, 4*40*(k-1), cut)
+		}
+	}
+
+	if part := Partition(g, 1); EdgeCut(g, part) != 0 || len(part) != 1600 {
+		t.Errorf("expected a single part without cut edges")
+	}
+	small := NewGraph()
+	small.AddEdge(0, 1, 1)
+	if part := Partition(small, 5); len(part) != 2 || part[0] == part[1] {
+		t.Errorf("expected k clamped to 2 parts, got %v", part)
+	}
+
+	// Partitions feed arc flags directly
+	af := BuildArcFlags(g, Partition(g, 4))
+	if res := Solve(g, sources(0), INF, WithArcFlags(af, 1599)); res.Dist[1599] != 78 {
+		t.Errorf("expected exact distance 78 with arc flags, got %v", res.Dist[1599])
+	}
+}
@@ -0,0 +1,147 @@
+package bmssp
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// SolvePartitioned answers the same query as Solve on all cores at once,
+// for graphs too large for one core to search quickly. Every part of the
+// partition is searched by BMSSP on its own subgraph, the parts in
+// parallel, in rounds: a round settles every part from the nodes whose
+// distance improved, then relaxes the edges between parts, seeding the next
+// round. The search ends when a round improves no distance across the cut,
+// after at most one round per part boundary crossed by a shortest path, so
+// partitions with few cut edges, such as from Partition, work best.
+//
+// Stop options such as WithTargets and WithNodeBudget apply to every part
+// and round separately.
+//
+// Parameters:
+//   - g: input graph
+//   - sources: nodes at distance 0
+//   - B: distance bound; nodes at B or farther are left at INF
+//   - part: part of every node; unassigned nodes form one more part
+//   - opts: optional query settings
+//
+// Returns:
+//   - the distances and predecessors; Stats add up the work of all parts
+//     and rounds
+func SolvePartitioned(g *Graph, sources NodeSet, B Dist, part map[NodeID]int, opts ...Option) *Result {
+	start := time.Now()
+	partOf := func(v NodeID) int {
+		if p, ok := part[v]; ok {
+			return p
+		}
+		return -1
+	}
+
+	members := make(map[int]NodeSet)
+	cut := make(map[NodeID][]Edge) // edges leaving the part of their tail
+	for u, edges := range g.adj {
+		p := partOf(u)
+		if members[p] == nil {
+			members[p] = NewNodeSet()
+		}
+		members[p].Add(u)
+		for _, e := range edges {
+			if partOf(e.To) != p {
+				cut[u] = append(cut[u], e)
+			}
+		}
+	}
+	subs := make(map[int]*Graph, len(members))
+	for p, nodes := range members {
+		subs[p] = g.Subgraph(nodes)
+	}
+
+	res := &Result{Dist: newDistanceMap(g), Pred: make(map[NodeID]NodeID), Unreachable: NewNodeSet()}
+	frontier := make(map[int]map[NodeID]Dist)
+	for v := range sources {
+		if _, ok := g.adj[v]; !ok {
+			continue
+		}
+		if frontier[partOf(v)] == nil {
+			frontier[partOf(v)] = make(map[NodeID]Dist)
+		}
+		frontier[partOf(v)][v] = 0
+		res.Dist[v] = 0
+	}
+
+	weigh := newSolver(g, opts)
+	for len(frontier) > 0 {
+		parts := slices.Sorted(maps.Keys(frontier))
+		rounds := make([]*Result, len(parts))
+		parallelFor(len(parts), 0, func(i int) {
+			rounds[i] = SolveFrom(subs[parts[i]], frontier[parts[i]], B, opts...)
+		})
+
+		var improved []NodeID
+		for i, r := range rounds {
+			res.addStats(r)
+			seeds := frontier[parts[i]]
+			for v, d := range r.Dist {
+				if d >= B {
+					continue
+				}
+				seed, seeded := seeds[v]
+				if d < res.Dist[v] {
+					res.Dist[v] = d
+					if u, ok := r.Pred[v]; ok {
+						res.Pred[v] = u
+					}
+				} else if !seeded || d != seed {
+					continue
+				}
+				improved = append(improved, v)
+			}
+		}
+
+		// Synchronize across the cut
+		frontier = make(map[int]map[NodeID]Dist)
+		for _, u := range improved {
+			for _, e := range cut[u] {
+				d := res.Dist[u] + weigh.weight(u, e)
+				if d >= B {
+					res.Truncated = res.Truncated || d < INF
+					continue
+				}
+				if d >= res.Dist[e.To] {
+					continue
+				}
+				res.Dist[e.To] = d
+				res.Pred[e.To] = u
+				p := partOf(e.To)
+				if frontier[p] == nil {
+					frontier[p] = make(map[NodeID]Dist)
+				}
+				frontier[p][e.To] = d
+			}
+		}
+	}
+
+	for v, d := range res.Dist {
+		if d == INF {
+			res.Unreachable.Add(v)
+		}
+	}
+	res.Stats.Duration = time.Since(start)
+	return res
+}
+
+// addStats adds the work and stop state of a partial query r to res.
+func (res *Result) addStats(r *Result) {
+	st := &res.Stats
+	st.NodesSettled += r.Stats.NodesSettled
+	st.EdgesScanned += r.Stats.EdgesScanned
+	st.Relaxations += r.Stats.Relaxations
+	st.QueueOps += r.Stats.QueueOps
+	st.MaxBucket = max(st.MaxBucket, r.Stats.MaxBucket)
+	st.RecursionDepth = max(st.RecursionDepth, r.Stats.RecursionDepth)
+	st.Delta = max(st.Delta, r.Stats.Delta)
+	res.Truncated = res.Truncated || r.Truncated
+	if res.Stopped == StopComplete {
+		res.Stopped = r.Stopped
+	}
+}
@@ -0,0 +1,49 @@
+package bmssp
+
+import "testing"
+
+func TestSolvePartitioned(t *testing.T) {
+	for name, g := range map[string]*Graph{
+		"grid":   roadGrid(30, 30, 6),
+		"random": generateRandomGraph(500, 2000, 10, 8),
+	} {
+		part := Partition(g, 6)
+		for _, S := range []NodeSet{sources(0), sources(17, 400, 899)} {
+			want := SolveDijkstra(g, S)
+			res := SolvePartitioned(g, S, INF, part)
+			for v, d := range want.Dist {
+				if got := res.Dist[v]; got != d && !approxEqual(got, d, 1e-9) {
+					t.Fatalf("%s: node %d: expected %v, got %v", name, v, d, got)
+				}
+			}
+			// Predecessors form shortest paths
+			for v, u := range res.Pred {
+				w, _ := PathCost(g, []NodeID{u, v})
+				if !approxEqual(res.Dist[u]+w, res.Dist[v], 1e-9) {
+					t.Fatalf("%s: predecessor %d of %d is not on a shortest path", name, u, v)
+				}
+			}
+			if res.Stats.NodesSettled < len(g.adj)-len(res.Unreachable) {
+				t.Errorf("%s: expected every reachable node settled, got %d", name, res.Stats.NodesSettled)
+			}
+		}
+	}
+
+	// Bounded queries leave far nodes at INF
+	g := roadGrid(20, 20, 3)
+	want := Dijkstra(g, 0)
+	res := SolvePartitioned(g, sources(0), 30, Partition(g, 4))
+	for v, d := range res.Dist {
+		if want[v] < 30 && d != want[v] || want[v] >= 30 && d != INF {
+			t.Fatalf("node %d: expected %v within bound 30, got %v", v, want[v], d)
+		}
+	}
+	if !res.Truncated {
+		t.Errorf("expected the bound to truncate the search")
+	}
+
+	// Nodes outside the partition are searched as one more part
+	if res := SolvePartitioned(g, sources(0), INF, map[NodeID]int{0: 0}); res.Dist[399] != want[399] {
+		t.Errorf("expected %v for an unassigned node, got %v", want[399], res.Dist[399])
+	}
+}
@@ -0,0 +1,76 @@
+package bmssp
+
+// ShortestPath computes a shortest path from source to target, applying the
+// given query options.
+//
+// Returns:
+//   - the node sequence from source to target (nil if target is unreachable)
+//   - the path length (INF if target is unreachable)
+func ShortestPath(g *Graph, source, target NodeID, opts ...Option) ([]NodeID, Dist) {
+	s := newSolver(g, opts)
+	s.pred = make(map[NodeID]NodeID)
+	s.dhat[source] = 0
+
+	S := NewNodeSet()
+	S.Add(source)
+	s.run(INF, S)
+
+	d, ok := s.dhat[target]
+	if !ok || d == INF {
+		return nil, INF
+	}
+	return pathTo(s.pred, target), d
+}
+
+// pathTo walks the predecessor map back from v and returns the path in
+// source-to-v order. Nodes without a predecessor are treated as sources.
+func pathTo(pred map[NodeID]NodeID, v NodeID) []NodeID {
+	path := []NodeID{v}
+	for {
+		u, ok := pred[v]
+		// A predecessor chain can never be longer than the map itself;
+		// stop rather than loop forever on a corrupted map.
+		if !ok || len(path) > len(pred) {
+			break
+		}
+		path = append(path, u)
+		v = u
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// PathCost returns the total weight of path in g, using the cheapest edge
+// where parallel edges exist. It reports false if a consecutive pair of
+// nodes is not connected by an edge.
+func PathCost(g *Graph, path []NodeID) (Dist, bool) {
+	var total Dist
+	for i := 1; i < len(path); i++ {
+		best := INF
+		for _, e := range g.adj[path[i-1]] {
+			if e.To == path[i] && e.Weight < best {
+				best = e.Weight
+			}
+		}
+		if best == INF {
+			return INF, false
+		}
+		total += best
+	}
+	return total, true
+}
+
+// PathEdgeIDs returns the IDs of the edges along a node path, e.g. to look up
+// edge data for each leg of a route.
+func PathEdgeIDs(path []NodeID) []EdgeID {
+	if len(path) < 2 {
+		return nil
+	}
+	ids := make([]EdgeID, len(path)-1)
+	for i := range ids {
+		ids[i] = EdgeID{From: path[i], To: path[i+1]}
+	}
+	return ids
+}
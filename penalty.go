@@ -0,0 +1,116 @@
+package bmssp
+
+import (
+	"sync"
+	"time"
+)
+
+// penalty is a multiplicative weight penalty that decays linearly to 1
+// over its time-to-live.
+type penalty struct {
+	factor float64
+	start  time.Time
+	ttl    time.Duration
+}
+
+// at returns the multiplier in effect at time now, or 1 once expired.
+func (p penalty) at(now time.Time) float64 {
+	elapsed := now.Sub(p.start)
+	if elapsed >= p.ttl {
+		return 1
+	}
+	remaining := 1 - float64(elapsed)/float64(p.ttl)
+	return 1 + (p.factor-1)*remaining
+}
+
+// PenaltyBox keeps time-decaying weight penalties for recently failed edges,
+// so routes can be steered away from flapping links without topology edits.
+// Queries see the penalties through Overlay.
+//
+// A PenaltyBox is safe for concurrent use.
+type PenaltyBox struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	penalties map[EdgeID]penalty
+}
+
+// NewPenaltyBox creates an empty penalty box.
+func NewPenaltyBox() *PenaltyBox {
+	return &PenaltyBox{
+		now:       time.Now,
+		penalties: make(map[EdgeID]penalty),
+	}
+}
+
+// Penalize multiplies the weight of edge id by factor, decaying linearly back
+// to the original weight over ttl. Penalizing an edge that is still in the box
+// restarts its decay from the larger of the current and the new factor.
+// A factor below 1 or NaN is clamped to 1, so a penalty never makes an edge
+// cheaper or its weight invalid.
+func (b *PenaltyBox) Penalize(id EdgeID, factor float64, ttl time.Duration) {
+	if !(factor >= 1) {
+		factor = 1
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if p, ok := b.penalties[id]; ok {
+		factor = max(factor, p.at(now))
+	}
+	b.penalties[id] = penalty{factor: factor, start: now, ttl: ttl}
+}
+
+// Release removes the penalty of edge id.
+func (b *PenaltyBox) Release(id EdgeID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.penalties, id)
+}
+
+// Factor returns the multiplier currently applied to edge id (1 if none).
+func (b *PenaltyBox) Factor(id EdgeID) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if p, ok := b.penalties[id]; ok {
+		return p.at(b.now())
+	}
+	return 1
+}
+
+// Len returns the number of edges in the box, expired ones included until
+// the next call to Overlay.
+func (b *PenaltyBox) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.penalties)
+}
+
+// Overlay drops expired penalties and returns a WeightOverlay freezing the
+// current multipliers, so a query sees one consistent set of weights.
+func (b *PenaltyBox) Overlay() WeightOverlay {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	factors := make(penaltyOverlay, len(b.penalties))
+	for id, p := range b.penalties {
+		if f := p.at(now); f != 1 {
+			factors[id] = f
+		} else {
+			delete(b.penalties, id)
+		}
+	}
+	return factors
+}
+
+// penaltyOverlay is a frozen set of edge weight multipliers.
+type penaltyOverlay map[EdgeID]float64
+
+// Weight returns the penalized weight of edge e leaving from.
+func (o penaltyOverlay) Weight(from NodeID, e Edge) Dist {
+	if f, ok := o[EdgeID{From: from, To: e.To}]; ok {
+		return e.Weight * Dist(f)
+	}
+	return e.Weight
+}
@@ -0,0 +1,53 @@
+package bmssp
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPenaltyBox(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 3, 1)
+	g.AddEdge(0, 2, 2)
+	g.AddEdge(2, 3, 2)
+
+	clock := time.Unix(0, 0)
+	box := NewPenaltyBox()
+	box.now = func() time.Time { return clock }
+
+	box.Penalize(EdgeID{From: 1, To: 3}, 5, time.Minute)
+	path, d := ShortestPath(g, 0, 3, WithOverlay(box.Overlay()))
+	if d != 4 || !slices.Equal(path, []NodeID{0, 2, 3}) {
+		t.Errorf("penalized: expected detour [0 2 3] of cost 4, got %v of cost %v", path, d)
+	}
+
+	// Halfway through the TTL the factor has decayed from 5 to 3
+	clock = clock.Add(30 * time.Second)
+	if f := box.Factor(EdgeID{From: 1, To: 3}); f != 3 {
+		t.Errorf("half-decayed factor: expected 3, got %v", f)
+	}
+
+	// Once expired the penalty is dropped and the direct route is back
+	clock = clock.Add(time.Minute)
+	path, d = ShortestPath(g, 0, 3, WithOverlay(box.Overlay()))
+	if d != 2 || !slices.Equal(path, []NodeID{0, 1, 3}) {
+		t.Errorf("expired: expected [0 1 3] of cost 2, got %v of cost %v", path, d)
+	}
+	if box.Len() != 0 {
+		t.Errorf("expected expired penalty to be dropped, %d left", box.Len())
+	}
+
+	// Factors below 1 and NaN are clamped instead of producing invalid weights
+	for _, f := range []float64{-2, 0.5, math.NaN()} {
+		box.Penalize(EdgeID{From: 0, To: 1}, f, time.Minute)
+		if got := box.Factor(EdgeID{From: 0, To: 1}); got != 1 {
+			t.Errorf("factor %v: expected clamped factor 1, got %v", f, got)
+		}
+		if _, d := ShortestPath(g, 0, 3, WithOverlay(box.Overlay())); d != 2 {
+			t.Errorf("factor %v: expected cost 2, got %v", f, d)
+		}
+	}
+}
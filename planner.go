@@ -0,0 +1,142 @@
+package bmssp
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PlannerOptions configures a Planner.
+type PlannerOptions struct {
+	// Index maps nodes to locations. With CacheCapacity > 0 it enables the
+	// approximate route cache (see RouteCache).
+	Index          *GeoIndex
+	CacheCapacity  int  // maximum number of cached cell-pair routes
+	CacheTolerance Dist // relative cost growth a cached route may accumulate
+
+	Workers int      // worker goroutines for Matrix (default: GOMAXPROCS)
+	Options []Option // query settings applied to every query, e.g. WithDelta
+}
+
+// Planner bundles a graph with its caches and tuning behind a single entry
+// point for routing, distance matrices and isochrones. Queries may run
+// concurrently; Update waits for running queries and blocks new ones while it
+// modifies the graph.
+type Planner struct {
+	mu    sync.RWMutex
+	g     *Graph
+	cache *RouteCache // nil without an index
+	opts  PlannerOptions
+}
+
+// NewPlanner creates a planner over g. The planner takes ownership of g:
+// later changes must go through Update.
+//
+// Returns:
+//   - the planner
+//   - an error from g.Validate if the graph is empty or has invalid weights
+func NewPlanner(g *Graph, opts PlannerOptions) (*Planner, error) {
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	p := &Planner{g: g, opts: opts}
+	if opts.Index != nil && opts.CacheCapacity > 0 {
+		p.cache = NewRouteCache(g, opts.Index, opts.CacheCapacity, opts.CacheTolerance)
+	}
+	return p, nil
+}
+
+// checkNodes returns ErrNodeNotFound for the first node missing from the
+// graph. The caller must hold p.mu.
+func (p *Planner) checkNodes(nodes ...NodeID) error {
+	for _, v := range nodes {
+		if _, ok := p.g.adj[v]; !ok {
+			return fmt.Errorf("%w: %d", ErrNodeNotFound, v)
+		}
+	}
+	return nil
+}
+
+// Route returns a shortest route from 'from' to 'to', served from the route
+// cache when one is configured. An unreachable destination yields a Route
+// with a nil Path and INF Cost.
+//
+// Returns:
+//   - the route
+//   - ErrNodeNotFound if either node is not in the graph
+func (p *Planner) Route(from, to NodeID) (Route, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if err := p.checkNodes(from, to); err != nil {
+		return Route{}, err
+	}
+
+	if p.cache != nil {
+		if r, ok := p.cache.Route(from, to); ok {
+			return r, nil
+		}
+		return Route{Cost: INF}, nil
+	}
+	path, d := ShortestPath(p.g, from, to, p.opts.Options...)
+	return Route{Path: path, Cost: d}, nil
+}
+
+// Matrix computes the shortest distances from every source to every target,
+// running one query per source in parallel. Each query stops as soon as all
+// targets are settled.
+//
+// Returns:
+//   - m[i][j], the distance from sources[i] to targets[j] (INF if unreachable)
+//   - ErrNodeNotFound if a node is not in the graph
+func (p *Planner) Matrix(sources, targets []NodeID) ([][]Dist, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if err := p.checkNodes(append(sources[:len(sources):len(sources)], targets...)...); err != nil {
+		return nil, err
+	}
+
+	return distanceMatrix(p.g, sources, targets, p.opts.Workers, p.opts.Options), nil
+}
+
+// Isochrone returns the nodes reachable from sources within cost B; see
+// Isochrone.
+//
+// Returns:
+//   - the reachable region
+//   - ErrNodeNotFound if a source is not in the graph
+func (p *Planner) Isochrone(sources NodeSet, B Dist) (*IsochroneResult, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if err := p.checkNodes(sources.ToSlice()...); err != nil {
+		return nil, err
+	}
+	return Isochrone(p.g, sources, B, p.opts.Options...), nil
+}
+
+// Update applies edge changes to the graph in order: removals, weight
+// updates, and additions of edges that do not exist yet. Cached routes are
+// dropped. Either every change is applied or, if a weight is invalid, none.
+//
+// Returns:
+//   - ErrInvalidWeight for a negative or NaN weight
+func (p *Planner) Update(changes []EdgeUpdate) error {
+	for _, c := range changes {
+		if !c.Remove && !validWeight(c.Weight) {
+			return fmt.Errorf("%w: edge %d->%d has weight %v", ErrInvalidWeight, c.From, c.To, c.Weight)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range changes {
+		switch {
+		case c.Remove:
+			p.g.RemoveEdge(c.From, c.To)
+		case !p.g.UpdateEdgeWeight(c.From, c.To, c.Weight):
+			p.g.AddEdge(c.From, c.To, c.Weight)
+		}
+	}
+	if p.cache != nil {
+		p.cache.Purge()
+	}
+	return nil
+}
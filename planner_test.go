@@ -0,0 +1,55 @@
+package bmssp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlanner(t *testing.T) {
+	g := generateGridGraph(10, 10)
+	p, err := NewPlanner(g, PlannerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := p.Route(0, 99)
+	if err != nil || r.Cost != 18 || len(r.Path) != 19 {
+		t.Errorf("expected an 18-step route, got %+v (%v)", r, err)
+	}
+	if _, err := p.Route(0, 500); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("expected ErrNodeNotFound, got %v", err)
+	}
+
+	m, err := p.Matrix([]NodeID{0, 99}, []NodeID{9, 90, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m[0][0] != 9 || m[0][1] != 9 || m[0][2] != 0 || m[1][2] != 18 {
+		t.Errorf("unexpected matrix %v", m)
+	}
+
+	iso, err := p.Isochrone(sources(0), 1)
+	if err != nil || len(iso.Dist) != 3 {
+		t.Errorf("expected 3 nodes within 1 step, got %v (%v)", iso, err)
+	}
+
+	// Close the edges out of the corner except a slow shortcut
+	err = p.Update([]EdgeUpdate{
+		{From: 0, To: 1, Remove: true},
+		{From: 0, To: 10, Weight: 5},
+		{From: 0, To: 99, Weight: 7},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, _ := p.Route(0, 99); r.Cost != 7 || len(r.Path) != 2 {
+		t.Errorf("expected the new direct edge of cost 7, got %+v", r)
+	}
+	if err := p.Update([]EdgeUpdate{{From: 0, To: 1, Weight: -1}}); !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("expected ErrInvalidWeight, got %v", err)
+	}
+
+	if _, err := NewPlanner(NewGraph(), PlannerOptions{}); !errors.Is(err, ErrEmptyGraph) {
+		t.Errorf("expected ErrEmptyGraph, got %v", err)
+	}
+}
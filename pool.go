@@ -0,0 +1,84 @@
+package bmssp
+
+// Freeze makes g read-only: it builds the lazily created reverse index, so
+// that no query writes to the graph, and makes every later mutation panic.
+// A frozen graph is safe for any number of concurrent queries. Use Clone to
+// get a mutable copy.
+//
+// Freeze also classifies the edge weights and orders acyclic graphs
+// topologically, so that queries can take the BFS and DAG fast paths.
+func (g *Graph) Freeze() {
+	if g.frozen {
+		return
+	}
+	if g.radj == nil {
+		g.buildReverseIndex()
+	}
+	g.shape, g.uniform = g.measureShape()
+	g.topo, _ = TopologicalOrder(g)
+	g.frozen = true
+}
+
+// Frozen reports whether Freeze has been called on g.
+func (g *Graph) Frozen() bool {
+	return g.frozen
+}
+
+// mustBeMutable panics if g is frozen. Mutating a graph that concurrent
+// queries rely on is a programming error, like a concurrent map write.
+func (g *Graph) mustBeMutable() {
+	if g.frozen {
+		panic("bmssp: mutation of a frozen graph")
+	}
+}
+
+// PoolQuery is a shortest-path query for a QueryPool.
+type PoolQuery struct {
+	Sources NodeSet
+	Bound   Dist     // distance bound; INF for an unbounded search
+	Options []Option // per-query settings, applied after the pool's
+}
+
+// QueryPool runs shortest-path queries in parallel over a frozen graph. Each
+// query gets its own scratch state (distances, predecessors, queues), so
+// queries share nothing but the read-only graph. A QueryPool is safe for
+// concurrent use.
+type QueryPool struct {
+	g       *Graph
+	workers int
+	opts    []Option
+}
+
+// NewQueryPool creates a pool over g and freezes g.
+//
+// Parameters:
+//   - g: input graph; frozen by the call
+//   - workers: maximum goroutines per Run (default: GOMAXPROCS)
+//   - opts: query settings applied to every query, e.g. WithDelta
+func NewQueryPool(g *Graph, workers int, opts ...Option) *QueryPool {
+	g.Freeze()
+	return &QueryPool{g: g, workers: workers, opts: opts}
+}
+
+// Graph returns the pool's frozen graph.
+func (p *QueryPool) Graph() *Graph {
+	return p.g
+}
+
+// Solve runs a single query like Solve.
+func (p *QueryPool) Solve(q PoolQuery) *Result {
+	opts := p.opts
+	if len(q.Options) > 0 {
+		opts = append(opts[:len(opts):len(opts)], q.Options...)
+	}
+	return Solve(p.g, q.Sources, q.Bound, opts...)
+}
+
+// Run answers queries in parallel, returning results[i] for queries[i].
+func (p *QueryPool) Run(queries []PoolQuery) []*Result {
+	results := make([]*Result, len(queries))
+	parallelFor(len(queries), p.workers, func(i int) {
+		results[i] = p.Solve(queries[i])
+	})
+	return results
+}
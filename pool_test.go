@@ -0,0 +1,77 @@
+package bmssp
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestGraph_Freeze(t *testing.T) {
+	g := generateGridGraph(5, 5)
+	g.Freeze()
+	if !g.Frozen() || g.radj == nil {
+		t.Fatal("expected a frozen graph with its reverse index built")
+	}
+
+	for name, mutate := range map[string]func(){
+		"AddEdge":          func() { g.AddEdge(0, 24, 1) },
+		"RemoveEdge":       func() { g.RemoveEdge(0, 1) },
+		"RemoveNode":       func() { g.RemoveNode(0) },
+		"UpdateEdgeWeight": func() { g.UpdateEdgeWeight(0, 1, 5) },
+		"SetEdgeData":      func() { g.SetEdgeData(EdgeID{From: 0, To: 1}, "x") },
+		"UnmarshalJSON":    func() { _ = g.UnmarshalJSON([]byte(`{"nodes":[]}`)) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected a panic on a frozen graph", name)
+				}
+			}()
+			mutate()
+		}()
+	}
+
+	c := g.Clone()
+	c.AddEdge(0, 24, 1)
+	if c.Frozen() || len(g.adj[0]) == len(c.adj[0]) {
+		t.Error("expected a mutable clone independent of the frozen graph")
+	}
+}
+
+func TestQueryPool(t *testing.T) {
+	g := generateRandomGraph(300, 2000, 10, 4)
+	p := NewQueryPool(g, 4)
+	if !g.Frozen() {
+		t.Fatal("expected the pool to freeze its graph")
+	}
+
+	queries := make([]PoolQuery, 40)
+	for i := range queries {
+		queries[i] = PoolQuery{Sources: sources(NodeID(i)), Bound: INF}
+	}
+	queries[3].Options = []Option{WithNodeBudget(5)}
+
+	// Concurrent Run calls share the pool
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, res := range p.Run(queries) {
+				if i == 3 {
+					if res.Stopped != StopNodeBudget {
+						t.Errorf("query 3: expected the per-query budget to apply, got %v", res.Stopped)
+					}
+					continue
+				}
+				want := Dijkstra(g, NodeID(i))
+				for v, d := range want {
+					if res.Dist[v] != d {
+						t.Errorf("query %d: node %d: expected %v, got %v", i, v, d, res.Dist[v])
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,69 @@
+package bmssp
+
+import "math"
+
+// ProbableResult holds the most probable paths from a set of sources.
+type ProbableResult struct {
+	Prob map[NodeID]float64 // probability of the most probable path; 0 if unreachable, 1 at the sources
+	Pred map[NodeID]NodeID  // predecessors on the most probable paths; sources have none
+}
+
+// PathTo returns a most probable path from one of the sources to v, or nil
+// if v is unreachable.
+func (r *ProbableResult) PathTo(v NodeID) []NodeID {
+	if r.Prob[v] == 0 {
+		return nil
+	}
+	return pathTo(r.Pred, v)
+}
+
+// logProbOverlay weighs an edge of probability p by -log p, so that the
+// shortest path maximizes the product of the probabilities. Weights outside
+// (0, 1] close the edge.
+type logProbOverlay struct{}
+
+func (logProbOverlay) Weight(_ NodeID, e Edge) Dist {
+	p := float64(e.Weight)
+	if !(p > 0 && p <= 1) {
+		return INF
+	}
+	return Dist(-math.Log(p))
+}
+
+// MostProbablePaths computes, for every node, the path from sources whose
+// product of edge weights is largest, reading the weights as independent
+// success probabilities in (0, 1], e.g. link reliabilities or the chance
+// each step of an attack succeeds. Edges with other weights are closed.
+//
+// The query runs BMSSP over the weights -log p, which turns products into
+// sums and keeps them non-negative.
+//
+// Parameters:
+//   - g: input graph with probabilities as weights
+//   - sources: set of source nodes, each with probability 1
+//   - opts: optional query settings; WithOverlay is not supported
+//
+// Returns:
+//   - the probabilities of and paths to every node
+func MostProbablePaths(g *Graph, sources NodeSet, opts ...Option) *ProbableResult {
+	res := Solve(g, sources, INF, append(opts[:len(opts):len(opts)], WithOverlay(logProbOverlay{}))...)
+	r := &ProbableResult{Prob: make(map[NodeID]float64, len(res.Dist)), Pred: res.Pred}
+	for v, d := range res.Dist {
+		r.Prob[v] = math.Exp(-float64(d))
+	}
+	return r
+}
+
+// MostProbablePath returns the most probable path from source to target and
+// its probability. See MostProbablePaths.
+//
+// Returns:
+//   - the node sequence from source to target (nil if target is unreachable)
+//   - the product of the probabilities along the path (0 if unreachable)
+func MostProbablePath(g *Graph, source, target NodeID, opts ...Option) ([]NodeID, float64) {
+	S := NewNodeSet()
+	S.Add(source)
+	opts = append(opts[:len(opts):len(opts)], WithTargets(NodeSet{target: {}}))
+	r := MostProbablePaths(g, S, opts...)
+	return r.PathTo(target), r.Prob[target]
+}
@@ -0,0 +1,57 @@
+package bmssp
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestMostProbablePath(t *testing.T) {
+	g := NewGraph()
+	// Direct link is unreliable; the two-hop route is safer overall
+	g.AddEdge(0, 3, 0.5)
+	g.AddEdge(0, 1, 0.9)
+	g.AddEdge(1, 3, 0.9)
+	g.AddEdge(3, 4, 1)
+	g.AddEdge(0, 2, 1.5) // not a probability: closed
+	g.AddEdge(0, 5, 0)   // impossible: closed
+
+	path, p := MostProbablePath(g, 0, 4)
+	if math.Abs(p-0.81) > 1e-12 || !slices.Equal(path, []NodeID{0, 1, 3, 4}) {
+		t.Errorf("expected 0.81 via 1, got %v along %v", p, path)
+	}
+
+	r := MostProbablePaths(g, sources(0))
+	if r.Prob[0] != 1 || math.Abs(r.Prob[1]-0.9) > 1e-12 {
+		t.Errorf("unexpected probabilities %v", r.Prob)
+	}
+	for _, v := range []NodeID{2, 5} {
+		if r.Prob[v] != 0 || r.PathTo(v) != nil {
+			t.Errorf("node %d should be unreachable, got %v", v, r.Prob[v])
+		}
+	}
+}
+
+func TestMostProbablePaths_MatchesBruteForce(t *testing.T) {
+	g := generateRandomGraph(100, 400, 1, 21) // weights in [0, 1)
+	r := MostProbablePaths(g, sources(0))
+
+	// Bellman-Ford style relaxation on products
+	want := map[NodeID]float64{0: 1}
+	for changed := true; changed; {
+		changed = false
+		for u, edges := range g.adj {
+			for _, e := range edges {
+				if p := want[u] * float64(e.Weight); e.Weight > 0 && p > want[e.To] {
+					want[e.To] = p
+					changed = true
+				}
+			}
+		}
+	}
+	for v := range g.adj {
+		if math.Abs(r.Prob[v]-want[v]) > 1e-9*want[v] {
+			t.Errorf("node %d: expected %v, got %v", v, want[v], r.Prob[v])
+		}
+	}
+}
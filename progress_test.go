@@ -0,0 +1,59 @@
+package bmssp
+
+import "testing"
+
+func TestWithProgress(t *testing.T) {
+	g := generateGridGraph(60, 60)
+
+	for name, solve := range map[string]func(...Option) *Result{
+		"bmssp":    func(opts ...Option) *Result { return Solve(g, sources(0), INF, opts...) },
+		"dijkstra": func(opts ...Option) *Result { return SolveDijkstra(g, sources(0), opts...) },
+	} {
+		var calls, last, lastTotal int
+		r := solve(WithProgress(func(settled, total int) {
+			if settled < last {
+				t.Errorf("%s: progress went backwards: %d after %d", name, settled, last)
+			}
+			if settled > total {
+				t.Errorf("%s: settled %d exceeds total %d", name, settled, total)
+			}
+			calls++
+			last, lastTotal = settled, total
+		}))
+		if calls < 2 {
+			t.Errorf("%s: expected periodic and final reports, got %d calls", name, calls)
+		}
+		if lastTotal != 3600 {
+			t.Errorf("%s: total = %d, want 3600", name, lastTotal)
+		}
+		if want := min(r.Stats.NodesSettled, lastTotal); last != want {
+			t.Errorf("%s: final report %d, want %d", name, last, want)
+		}
+	}
+}
+
+func TestAllPairs_Progress(t *testing.T) {
+	g := generateRandomGraph(50, 200, 10.0, 3)
+
+	var done []int
+	_, err := AllPairsWithOptions(g, AllPairsOptions{
+		Workers: 4,
+		Progress: func(d, total int) {
+			if total != 50 {
+				t.Errorf("total = %d, want 50", total)
+			}
+			done = append(done, d)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(done) != 50 {
+		t.Fatalf("got %d progress calls, want 50", len(done))
+	}
+	for i, d := range done {
+		if d != i+1 {
+			t.Errorf("call %d reported %d done, want %d", i, d, i+1)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package bmssp
+
+import (
+	"math"
+	"math/bits"
+)
+
+// PriorityQueue is a min-priority queue of nodes keyed by distance, used by
+// the Dijkstra-based searches. Entries are never updated in place: an
+// improved node is pushed again and stale entries are skipped by the search,
+// so implementations need no decrease-key.
+//
+// Searches are monotone: a pushed key is never below the last popped key.
+// Implementations may rely on this.
+type PriorityQueue interface {
+	Push(v NodeID, d Dist)
+	Pop() (v NodeID, d Dist, ok bool)
+	Len() int
+}
+
+// WithQueue selects the priority queue of the Dijkstra-based searches
+// (SolveDijkstra, StreamDistances and Settled). newQueue is called once per
+// query, e.g. WithQueue(RadixHeap). The default is BinaryHeap. BMSSP's
+// Δ-stepping passes keep their bucket queue.
+func WithQueue(newQueue func() PriorityQueue) Option {
+	return func(c *config) {
+		c.newQueue = newQueue
+	}
+}
+
+// queueEntry is a queued node with its key.
+type queueEntry struct {
+	node NodeID
+	dist Dist
+}
+
+// binaryHeap is a binary min-heap of queue entries.
+type binaryHeap []queueEntry
+
+// BinaryHeap returns an empty binary heap, the default queue: O(log n) per
+// operation for any non-negative weights.
+func BinaryHeap() PriorityQueue {
+	return &binaryHeap{}
+}
+
+func (h *binaryHeap) Len() int {
+	return len(*h)
+}
+
+func (h *binaryHeap) Push(v NodeID, d Dist) {
+	*h = append(*h, queueEntry{node: v, dist: d})
+	q := *h
+	for i := len(q) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if q[parent].dist <= q[i].dist {
+			break
+		}
+		q[parent], q[i] = q[i], q[parent]
+		i = parent
+	}
+}
+
+func (h *binaryHeap) Pop() (NodeID, Dist, bool) {
+	q := *h
+	if len(q) == 0 {
+		return 0, 0, false
+	}
+	top := q[0]
+	n := len(q) - 1
+	q[0] = q[n]
+	q = q[:n]
+	for i := 0; ; {
+		least := i
+		for _, c := range []int{2*i + 1, 2*i + 2} {
+			if c < n && q[c].dist < q[least].dist {
+				least = c
+			}
+		}
+		if least == i {
+			break
+		}
+		q[i], q[least] = q[least], q[i]
+		i = least
+	}
+	*h = q
+	return top.node, top.dist, true
+}
+
+// radixHeap is a monotone radix heap over the IEEE-754 bit patterns of the
+// keys. For non-negative floats the bit patterns are ordered like the values,
+// so bucket i holds the keys whose highest bit differing from the last popped
+// key is bit i-1. Each entry moves to a lower bucket at most 64 times, giving
+// amortized O(1) pushes and O(log C) pops; with integer-like weights most
+// entries stay in the low buckets.
+type radixHeap struct {
+	buckets [65][]radixEntry
+	last    uint64 // key of the last popped entry
+	n       int
+}
+
+type radixEntry struct {
+	key  uint64
+	node NodeID
+	dist Dist // original key, returned unclamped
+}
+
+// RadixHeap returns an empty radix heap. It requires monotone use (never
+// pushing a key below the last popped one), which every shortest-path search
+// with non-negative weights satisfies, and is usually faster than the binary
+// heap for such workloads.
+func RadixHeap() PriorityQueue {
+	return &radixHeap{}
+}
+
+func (h *radixHeap) Len() int {
+	return h.n
+}
+
+// radixKey maps a distance to its ordered bit pattern. Keys below the last
+// popped key (e.g. from rounding) are clamped to it.
+func (h *radixHeap) radixKey(d Dist) uint64 {
+	f := float64(d)
+	if !(f > 0) { // zero, negative zero, negative or NaN
+		f = 0
+	}
+	return max(math.Float64bits(f), h.last)
+}
+
+func (h *radixHeap) Push(v NodeID, d Dist) {
+	key := h.radixKey(d)
+	i := bits.Len64(key ^ h.last)
+	h.buckets[i] = append(h.buckets[i], radixEntry{key: key, node: v, dist: d})
+	h.n++
+}
+
+func (h *radixHeap) Pop() (NodeID, Dist, bool) {
+	if h.n == 0 {
+		return 0, 0, false
+	}
+	if len(h.buckets[0]) == 0 {
+		// Redistribute the first non-empty bucket around its minimum
+		i := 1
+		for len(h.buckets[i]) == 0 {
+			i++
+		}
+		minKey := h.buckets[i][0].key
+		for _, e := range h.buckets[i][1:] {
+			minKey = min(minKey, e.key)
+		}
+		h.last = minKey
+		for _, e := range h.buckets[i] {
+			j := bits.Len64(e.key ^ h.last)
+			h.buckets[j] = append(h.buckets[j], e)
+		}
+		h.buckets[i] = h.buckets[i][:0]
+	}
+
+	b := h.buckets[0]
+	e := b[len(b)-1]
+	h.buckets[0] = b[:len(b)-1]
+	h.n--
+	return e.node, e.dist, true
+}
@@ -0,0 +1,62 @@
+package bmssp
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPriorityQueues_Monotone(t *testing.T) {
+	for name, newQueue := range map[string]func() PriorityQueue{
+		"binary": BinaryHeap,
+		"radix":  RadixHeap,
+	} {
+		r := rand.New(rand.NewSource(1))
+		q := newQueue()
+		last := Dist(0)
+		pushed, popped := 0, 0
+		for i := 0; i < 10000; i++ {
+			if r.Intn(3) > 0 {
+				q.Push(NodeID(i), last+Dist(r.Intn(50))+Dist(r.Float64()))
+				pushed++
+				continue
+			}
+			_, d, ok := q.Pop()
+			if !ok {
+				continue
+			}
+			if d < last {
+				t.Fatalf("%s: popped %v after %v", name, d, last)
+			}
+			last = d
+			popped++
+		}
+		for q.Len() > 0 {
+			_, d, _ := q.Pop()
+			if d < last {
+				t.Fatalf("%s: popped %v after %v", name, d, last)
+			}
+			last = d
+			popped++
+		}
+		if popped != pushed {
+			t.Errorf("%s: pushed %d entries, popped %d", name, pushed, popped)
+		}
+	}
+}
+
+func TestWithQueue(t *testing.T) {
+	g := generateRandomGraph(500, 3000, 100, 11)
+	want := Dijkstra(g, 0)
+
+	for name, newQueue := range map[string]func() PriorityQueue{
+		"binary": BinaryHeap,
+		"radix":  RadixHeap,
+	} {
+		res := SolveDijkstra(g, sources(0), WithQueue(newQueue))
+		for v, d := range want {
+			if res.Dist[v] != d {
+				t.Errorf("%s: node %d: expected %v, got %v", name, v, d, res.Dist[v])
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package bmssp
+
+import (
+	"iter"
+	"slices"
+	"time"
+)
+
+// GraphReader is a read-only view of a graph, such as one owned outside this
+// package (an ECS world, a simulation state or a service's own adjacency
+// structure) or an alternative storage backend (CSR arrays, a memory-mapped
+// file, a database). Searches read the graph only through these methods and
+// never copy it. A *Graph is viewed as a GraphReader through Graph.Reader.
+type GraphReader interface {
+	// OutEdges yields the edges leaving u.
+	OutEdges(u NodeID) iter.Seq[Edge]
+	// Nodes yields every node once, in any order.
+	Nodes() iter.Seq[NodeID]
+	// NodeCount returns the number of nodes.
+	NodeCount() int
+}
+
+// Reader returns a GraphReader view of g, e.g. to pass a stored graph to
+// code written against the interface. Searches over the view use g
+// directly, fast paths included.
+func (g *Graph) Reader() GraphReader {
+	return graphReader{g}
+}
+
+// graphReader adapts a *Graph to GraphReader. Graph.OutEdges returns a slice,
+// so the graph cannot implement the interface itself.
+type graphReader struct {
+	g *Graph
+}
+
+func (r graphReader) OutEdges(u NodeID) iter.Seq[Edge] { return slices.Values(r.g.adj[u]) }
+func (r graphReader) Nodes() iter.Seq[NodeID]          { return r.g.Nodes() }
+func (r graphReader) NodeCount() int                   { return r.g.NumNodes() }
+
+// newReaderDistanceMap returns a distance map with every node of r set to
+// infinity.
+func newReaderDistanceMap(r GraphReader) map[NodeID]Dist {
+	dhat := make(map[NodeID]Dist, r.NodeCount())
+	for v := range r.Nodes() {
+		dhat[v] = INF
+	}
+	return dhat
+}
+
+// newReaderSolver prepares a query over r with every node at distance INF.
+// Views of a *Graph are searched like the graph itself.
+func newReaderSolver(r GraphReader, opts []Option) *solver {
+	if gr, ok := r.(graphReader); ok {
+		return newSolver(gr.g, opts)
+	}
+	s := &solver{src: r, dhat: newReaderDistanceMap(r), cfg: newConfig(opts)}
+	s.prepare()
+	return s
+}
+
+// SolveReader runs BMSSP on any GraphReader, like Solve. Unless r is a view
+// of a *Graph its weights are not known in advance, so the Δ-stepping
+// bucket width defaults to 1; pass WithDelta with a width near the typical
+// edge weight for best performance.
+//
+// Parameters:
+//   - r: input graph
+//   - sources: set of source nodes
+//   - B: distance bound
+//   - opts: optional query settings
+func SolveReader(r GraphReader, sources NodeSet, B Dist, opts ...Option) *Result {
+	start := time.Now()
+
+	s := newReaderSolver(r, opts)
+	s.pred = make(map[NodeID]NodeID)
+	for v := range sources {
+		s.dhat[v] = 0
+	}
+	s.run(B, sources)
+
+	return s.result(start)
+}
+
+// SolveDijkstraReader answers the same query as SolveReader with Dijkstra,
+// like SolveDijkstra.
+func SolveDijkstraReader(r GraphReader, sources NodeSet, opts ...Option) *Result {
+	start := time.Now()
+
+	s := newReaderSolver(r, opts)
+	s.pred = make(map[NodeID]NodeID)
+	for v := range sources {
+		s.dhat[v] = 0
+	}
+	s.dijkstra(sources, INF)
+
+	return s.result(start)
+}
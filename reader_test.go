@@ -0,0 +1,111 @@
+package bmssp
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"testing"
+)
+
+// gridReader is an externally owned w×h grid with unit weights between
+// horizontal and vertical neighbors, laid out like generateGridGraph.
+type gridReader struct {
+	w, h int
+}
+
+func (g gridReader) NodeCount() int { return g.w * g.h }
+
+func (g gridReader) Nodes() iter.Seq[NodeID] {
+	return func(yield func(NodeID) bool) {
+		for v := range NodeID(g.w * g.h) {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func (g gridReader) OutEdges(u NodeID) iter.Seq[Edge] {
+	return func(yield func(Edge) bool) {
+		x, y := int(u)%g.w, int(u)/g.w
+		for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			nx, ny := x+d[0], y+d[1]
+			if nx < 0 || ny < 0 || nx >= g.w || ny >= g.h {
+				continue
+			}
+			if !yield(Edge{To: NodeID(ny*g.w + nx), Weight: 1}) {
+				return
+			}
+		}
+	}
+}
+
+func TestSolveReader(t *testing.T) {
+	r := gridReader{w: 20, h: 15}
+	want := Dijkstra(generateGridGraph(20, 15), 0)
+
+	for name, res := range map[string]*Result{
+		"bmssp":    SolveReader(r, sources(0), INF),
+		"dijkstra": SolveDijkstraReader(r, sources(0)),
+	} {
+		if len(res.Dist) != r.NodeCount() {
+			t.Errorf("%s: expected %d distances, got %d", name, r.NodeCount(), len(res.Dist))
+		}
+		for v, d := range want {
+			if res.Dist[v] != d {
+				t.Fatalf("%s: node %d: expected %v, got %v", name, v, d, res.Dist[v])
+			}
+		}
+		if path := res.PathTo(299); len(path) != 34 {
+			t.Errorf("%s: expected a 33-edge path, got %v", name, path)
+		}
+	}
+
+	res := SolveReader(r, sources(0), 5, WithShadow(NewShadow(1, func(m Mismatch) {
+		t.Errorf("shadow mismatch: %+v", m)
+	})))
+	if res.Dist[5] != 5 || res.Dist[299] <= 5 && res.Dist[299] != INF {
+		t.Errorf("expected the bound to apply, got %v and %v", res.Dist[5], res.Dist[299])
+	}
+}
+
+// sparseReader is a GraphReader over a map with arbitrary node IDs.
+type sparseReader map[NodeID][]Edge
+
+func (r sparseReader) OutEdges(u NodeID) iter.Seq[Edge] { return slices.Values(r[u]) }
+func (r sparseReader) Nodes() iter.Seq[NodeID]          { return maps.Keys(r) }
+func (r sparseReader) NodeCount() int                   { return len(r) }
+
+func TestSolveReader_SparseIDs(t *testing.T) {
+	r := sparseReader{
+		-7:   {{To: 1000, Weight: 2}, {To: 42, Weight: 5}},
+		1000: {{To: 42, Weight: 1}},
+		42:   nil,
+		9:    nil,
+	}
+	res := SolveReader(r, sources(-7), INF)
+	if res.Dist[42] != 3 || res.Dist[9] != INF || !res.Unreachable.Has(9) || len(res.Dist) != 4 {
+		t.Errorf("unexpected distances %v", res.Dist)
+	}
+}
+
+func TestGraphReader(t *testing.T) {
+	g := generateGridGraph(20, 15)
+	r := g.Reader()
+	if r.NodeCount() != 300 || len(slices.Collect(r.Nodes())) != 300 || len(slices.Collect(r.OutEdges(0))) != 2 {
+		t.Errorf("view does not match the graph")
+	}
+	want := Solve(g, sources(0), INF, WithoutFastPaths())
+	for _, res := range []*Result{
+		SolveReader(r, sources(0), INF, WithoutFastPaths()),
+		SolveDijkstraReader(r, sources(0)),
+	} {
+		if !maps.Equal(res.Dist, want.Dist) {
+			t.Errorf("reader results differ from the graph's")
+		}
+	}
+	// The view is searched as the graph itself, so statistics match too
+	if got := SolveReader(r, sources(0), INF, WithoutFastPaths()); got.Stats.NodesSettled != want.Stats.NodesSettled {
+		t.Errorf("expected %d nodes settled, got %d", want.Stats.NodesSettled, got.Stats.NodesSettled)
+	}
+}
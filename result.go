@@ -0,0 +1,183 @@
+package bmssp
+
+import (
+	"fmt"
+	"time"
+)
+
+// Stats reports how much work a query performed. The counters are meant for
+// tuning parameters and for comparing BMSSP against Dijkstra on a given graph.
+type Stats struct {
+	NodesSettled   int           // nodes whose outgoing edges were scanned
+	EdgesScanned   int           // edges examined during relaxation
+	Relaxations    int           // edge relaxations that improved a distance
+	QueueOps       int           // priority queue insertions, updates and extractions
+	MaxBucket      int           // highest bucket index used by the Δ-stepping queue
+	RecursionDepth int           // deepest level of the BMSSP recursion
+	Delta          Dist          // Δ-stepping bucket width used by BMSSP
+	Duration       time.Duration // wall time of the query
+}
+
+// StopReason describes why a query ended.
+type StopReason int
+
+const (
+	// StopComplete means the search explored everything within the bound.
+	StopComplete StopReason = iota
+	// StopTargets means the search stopped once all WithTargets nodes were settled.
+	StopTargets
+	// StopNodeBudget means the WithNodeBudget limit was reached.
+	StopNodeBudget
+	// StopMaxHops means the search completed but the WithMaxHops limit cut
+	// off at least one edge.
+	StopMaxHops
+	// StopDeadline means the WithDeadline deadline passed.
+	StopDeadline
+	// StopCanceled means the caller stopped the query, e.g. by returning
+	// false from a streaming callback.
+	StopCanceled
+)
+
+// String returns the name of the stop reason.
+func (r StopReason) String() string {
+	switch r {
+	case StopComplete:
+		return "complete"
+	case StopTargets:
+		return "targets"
+	case StopNodeBudget:
+		return "node budget"
+	case StopMaxHops:
+		return "max hops"
+	case StopDeadline:
+		return "deadline"
+	case StopCanceled:
+		return "canceled"
+	}
+	return fmt.Sprintf("StopReason(%d)", int(r))
+}
+
+// Result is the outcome of a shortest-path query. Unless Stopped is
+// StopComplete the distances are a best-effort partial answer.
+type Result struct {
+	Dist    map[NodeID]Dist   // shortest distances, INF for unreachable nodes
+	Pred    map[NodeID]NodeID // shortest-path predecessors; sources have none
+	Stats   Stats
+	Stopped StopReason // why the search ended
+
+	// Unreachable holds the nodes left at INF: not reachable from the
+	// sources, beyond a hop limit, or not reached before the search stopped.
+	Unreachable NodeSet
+
+	// Truncated reports that the bound cut the search short: some node is
+	// reachable but farther than the bound, so its entry in Dist is INF or
+	// a tentative distance above the bound. Query without a bound (INF) or
+	// with AutoBound for complete results.
+	Truncated bool
+
+	trace *explainTrace // recorded with WithExplain
+
+	// The query, kept by Solve and SolveFrom for ResumeBMSSP
+	g       *Graph
+	version uint64 // version of g at the end of the query
+	cfg     config
+	bound   Dist // effective bound of the query
+}
+
+// PathTo returns a shortest path from one of the sources to v, or nil if v
+// is unreachable.
+func (r *Result) PathTo(v NodeID) []NodeID {
+	if d, ok := r.Dist[v]; !ok || d == INF {
+		return nil
+	}
+	return pathTo(r.Pred, v)
+}
+
+// Solve runs BMSSP from the given sources (all at distance 0) within bound B
+// and returns distances, predecessors and query statistics.
+//
+// Parameters:
+//   - g: input graph
+//   - sources: set of source nodes
+//   - B: distance bound
+//   - opts: optional query settings
+func Solve(g *Graph, sources NodeSet, B Dist, opts ...Option) *Result {
+	start := time.Now()
+
+	s := newSolver(g, opts)
+	s.pred = make(map[NodeID]NodeID)
+	for v := range sources {
+		s.dhat[v] = 0
+	}
+	s.run(B, sources)
+
+	return s.resumable(s.result(start))
+}
+
+// SolveFrom runs BMSSP from sources seeded with initial distances, e.g. the
+// walking time from an address to each nearby station. Every node gets the
+// smallest seed plus path length over all sources; a source may itself be
+// reached more cheaply from another one, and then has a predecessor. Seeds
+// are not checked; use SolveFromChecked for untrusted input.
+//
+// Parameters:
+//   - g: input graph
+//   - seeds: initial distance of every source; non-negative
+//   - B: distance bound, which applies to the seeded distances
+//   - opts: optional query settings
+func SolveFrom(g *Graph, seeds map[NodeID]Dist, B Dist, opts ...Option) *Result {
+	start := time.Now()
+
+	s := newSolver(g, opts)
+	s.pred = make(map[NodeID]NodeID)
+	S := NewNodeSet()
+	for v, d := range seeds {
+		s.dhat[v] = d
+		S.Add(v)
+	}
+	s.run(B, S)
+
+	return s.resumable(s.result(start))
+}
+
+// SolveDijkstra answers the same query as Solve with a binary-heap Dijkstra,
+// collecting comparable statistics.
+func SolveDijkstra(g *Graph, sources NodeSet, opts ...Option) *Result {
+	start := time.Now()
+
+	s := newSolver(g, opts)
+	s.pred = make(map[NodeID]NodeID)
+	for v := range sources {
+		s.dhat[v] = 0
+	}
+	s.dijkstra(sources, INF)
+
+	return s.result(start)
+}
+
+// Approximate reports whether some distances may not be shortest: the
+// search stopped on a budget, a hop limit or a deadline. Queries stopped by
+// WithTargets are exact for their targets and not considered approximate.
+func (r *Result) Approximate() bool {
+	return r.Stopped != StopComplete && r.Stopped != StopTargets
+}
+
+// result packages the solver state as a Result.
+func (s *solver) result(start time.Time) *Result {
+	s.stats.Duration = time.Since(start)
+	unreachable := NewNodeSet()
+	for v, d := range s.dhat {
+		if d == INF {
+			unreachable.Add(v)
+		}
+	}
+	return &Result{Dist: s.dhat, Pred: s.pred, Stats: s.stats, Stopped: s.stopReason(), Unreachable: unreachable, Truncated: s.cut, trace: s.trace}
+}
+
+// stopReason returns why the query ended.
+func (s *solver) stopReason() StopReason {
+	if s.stopped == StopComplete && s.truncated {
+		return StopMaxHops
+	}
+	return s.stopped
+}
@@ -0,0 +1,269 @@
+package bmssp
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSolve_Stats(t *testing.T) {
+	g := generateGridGraph(20, 20)
+	S := NewNodeSet()
+	S.Add(0)
+
+	res := Solve(g, S, 1000, WithoutFastPaths())
+	ref := SolveDijkstra(g, S)
+
+	for v, want := range ref.Dist {
+		if math.Abs(float64(res.Dist[v]-want)) > 1e-9 {
+			t.Fatalf("node %d: expected %v, got %v", v, want, res.Dist[v])
+		}
+	}
+	if path := res.PathTo(399); len(path) != 39 || path[0] != 0 || path[38] != 399 {
+		t.Errorf("expected a 38-edge path from 0 to 399, got %v", path)
+	}
+
+	st := res.Stats
+	if st.NodesSettled != 400 || ref.Stats.NodesSettled != 400 {
+		t.Errorf("expected 400 settled nodes, got %d (BMSSP) and %d (Dijkstra)", st.NodesSettled, ref.Stats.NodesSettled)
+	}
+	if st.EdgesScanned != 4*20*19 {
+		t.Errorf("expected every edge scanned once, got %d", st.EdgesScanned)
+	}
+	if st.Relaxations < 399 || st.RecursionDepth != 1 || st.Duration < 0 {
+		t.Errorf("unexpected stats: %+v", st)
+	}
+
+	// Unit weights at average degree 3.8 give Δ = 1/3.8; the farthest node
+	// is 38 away
+	if st.Delta != 1/3.8 || st.MaxBucket != int(38/st.Delta) {
+		t.Errorf("expected automatic Δ of 1/3.8 and %d buckets, got %v and %d", int(38/st.Delta), st.Delta, st.MaxBucket)
+	}
+	if st := Solve(g, S, 1000, WithDelta(1), WithoutFastPaths()).Stats; st.Delta != 1 || st.MaxBucket != 38 {
+		t.Errorf("expected Δ override of 1 with 38 buckets, got %v and %d", st.Delta, st.MaxBucket)
+	}
+}
+
+func TestWithVisitor(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 5)
+	g.AddEdge(0, 2, 1)
+	g.AddEdge(2, 1, 1)
+
+	for name, solve := range map[string]func(...Option) *Result{
+		"bmssp":    func(opts ...Option) *Result { return Solve(g, sources(0), 1000, opts...) },
+		"dijkstra": func(opts ...Option) *Result { return SolveDijkstra(g, sources(0), opts...) },
+	} {
+		var settled []NodeID
+		var improvements []Dist
+		solve(WithVisitor(VisitorFuncs{
+			Settle: func(v NodeID, _ Dist) { settled = append(settled, v) },
+			Relax: func(u, v NodeID, oldDist, newDist Dist) {
+				if v == 1 {
+					improvements = append(improvements, oldDist, newDist)
+				}
+			},
+		}))
+
+		if len(settled) != 3 || settled[0] != 0 || settled[2] != 1 {
+			t.Errorf("%s: expected settle order 0, 2, 1, got %v", name, settled)
+		}
+		if len(improvements) != 4 || improvements[0] != INF || improvements[1] != 5 || improvements[3] != 2 {
+			t.Errorf("%s: expected node 1 relaxed from +Inf to 5 then to 2, got %v", name, improvements)
+		}
+	}
+}
+
+// sources builds a source set from node IDs.
+func sources(vs ...NodeID) NodeSet {
+	S := NewNodeSet()
+	for _, v := range vs {
+		S.Add(v)
+	}
+	return S
+}
+
+func TestWithTargets(t *testing.T) {
+	g := generateGridGraph(30, 30)
+	targets := sources(31, 62)
+	want := Dijkstra(g, 0)
+
+	for name, solve := range map[string]func(...Option) *Result{
+		"bmssp":    func(opts ...Option) *Result { return Solve(g, sources(0), 1000, opts...) },
+		"dijkstra": func(opts ...Option) *Result { return SolveDijkstra(g, sources(0), opts...) },
+	} {
+		full := solve()
+		early := solve(WithTargets(targets))
+		if early.Stopped != StopTargets {
+			t.Errorf("%s: expected StopTargets, got %v", name, early.Stopped)
+		}
+		for v := range targets {
+			if early.Dist[v] != want[v] {
+				t.Errorf("%s: target %d: expected %v, got %v", name, v, want[v], early.Dist[v])
+			}
+			if path := early.PathTo(v); len(path) != int(want[v])+1 {
+				t.Errorf("%s: target %d: expected path of %v hops, got %v", name, v, want[v], path)
+			}
+		}
+		if early.Stats.NodesSettled >= full.Stats.NodesSettled/4 {
+			t.Errorf("%s: expected early termination, settled %d of %d nodes",
+				name, early.Stats.NodesSettled, full.Stats.NodesSettled)
+		}
+	}
+
+	// Unreachable targets fall back to a full search
+	res := Solve(g, sources(0), 1000, WithTargets(sources(-1)))
+	if res.Dist[899] != want[899] {
+		t.Errorf("unreachable target: expected full search, got dist %v for node 899", res.Dist[899])
+	}
+}
+
+func TestWithTargets_MultiSource(t *testing.T) {
+	// Node 3 is deferred beyond the first pass's bound, after 4 was reached
+	// directly; 4 must not be reported before 3 improves it
+	g := NewGraph()
+	g.AddEdge(1, 3, 5.5)
+	g.AddEdge(1, 4, 6.2)
+	g.AddEdge(2, 5, 8)
+	g.AddEdge(3, 4, 0.1)
+	res := Solve(g, sources(1, 2), INF, WithTargets(sources(4)), WithDelta(1))
+	if !approxEqual(res.Dist[4], 5.6, 1e-9) {
+		t.Errorf("expected distance 5.6 to node 4, got %v", res.Dist[4])
+	}
+
+	r := rand.New(rand.NewSource(11))
+	for i := range 300 {
+		g := generateRandomGraph(40, 120, 10, int64(i))
+		S := sources(NodeID(r.Intn(40)), NodeID(r.Intn(40)), NodeID(r.Intn(40)))
+		want := Solve(g, S, INF)
+		targets := sources(NodeID(r.Intn(40)), NodeID(r.Intn(40)))
+		got := Solve(g, S, INF, WithTargets(targets))
+		for v := range targets {
+			if !approxEqual(got.Dist[v], want.Dist[v], 1e-9) {
+				t.Fatalf("graph %d, sources %v: target %d: expected %v, got %v", i, S.ToSlice(), v, want.Dist[v], got.Dist[v])
+			}
+		}
+	}
+}
+
+func TestWithNodeBudget(t *testing.T) {
+	g := generateGridGraph(20, 20)
+	want := Dijkstra(g, 0)
+
+	for name, solve := range map[string]func(...Option) *Result{
+		"bmssp":    func(opts ...Option) *Result { return Solve(g, sources(0), 1000, opts...) },
+		"dijkstra": func(opts ...Option) *Result { return SolveDijkstra(g, sources(0), opts...) },
+	} {
+		if res := solve(WithNodeBudget(1000)); res.Stopped != StopComplete {
+			t.Errorf("%s: expected an ample budget to complete, got %v", name, res.Stopped)
+		}
+
+		res := solve(WithNodeBudget(10))
+		if res.Stopped != StopNodeBudget || res.Stats.NodesSettled != 10 {
+			t.Errorf("%s: expected to stop after 10 nodes, got %v after %d", name, res.Stopped, res.Stats.NodesSettled)
+		}
+		for v, d := range res.Dist {
+			if d < want[v] {
+				t.Errorf("%s: node %d: partial distance %v below shortest %v", name, v, d, want[v])
+			}
+		}
+	}
+}
+
+func TestWithMaxHops(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 2, 1)
+	g.AddEdge(0, 2, 10)
+	g.AddEdge(2, 3, 1)
+
+	for name, solve := range map[string]func(...Option) *Result{
+		"bmssp":    func(opts ...Option) *Result { return Solve(g, sources(0), 1000, opts...) },
+		"dijkstra": func(opts ...Option) *Result { return SolveDijkstra(g, sources(0), opts...) },
+	} {
+		res := solve(WithMaxHops(1))
+		if res.Dist[2] != 10 || res.Dist[3] != INF || res.Stopped != StopMaxHops {
+			t.Errorf("%s: expected direct edge to 2 and 3 out of reach, got %v (%v)", name, res.Dist, res.Stopped)
+		}
+		if res := solve(WithMaxHops(3)); res.Dist[3] != 3 || res.Stopped != StopComplete {
+			t.Errorf("%s: expected a complete search within 3 hops, got %v (%v)", name, res.Dist, res.Stopped)
+		}
+	}
+}
+
+func TestWithDeadline(t *testing.T) {
+	g := generateGridGraph(20, 20)
+
+	res := Solve(g, sources(0), 1000, WithDeadline(time.Now().Add(-time.Second)))
+	if res.Stopped != StopDeadline || !res.Approximate() {
+		t.Errorf("expected a passed deadline to degrade the query, got %v", res.Stopped)
+	}
+	if res.Dist[0] != 0 {
+		t.Errorf("expected source distance to be kept, got %v", res.Dist[0])
+	}
+
+	res = Solve(g, sources(0), 1000, WithDeadline(time.Now().Add(time.Hour)))
+	if res.Stopped != StopComplete || res.Approximate() {
+		t.Errorf("expected a distant deadline to complete, got %v", res.Stopped)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	g := generateGridGraph(20, 20)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if res := Solve(g, sources(0), 1000, WithContext(ctx)); res.Stopped != StopCanceled || !res.Approximate() {
+		t.Errorf("expected a canceled context to stop the query, got %v", res.Stopped)
+	}
+
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if res := Solve(g, sources(0), 1000, WithContext(ctx)); res.Stopped != StopDeadline {
+		t.Errorf("expected an expired context to stop the query on its deadline, got %v", res.Stopped)
+	}
+	if res := Isochrone(g, sources(0), 10, WithContext(ctx)); res.Stopped != StopDeadline || !res.Approximate() {
+		t.Errorf("expected an expired context to stop the isochrone, got %v", res.Stopped)
+	}
+
+	if res := Solve(g, sources(0), 1000, WithContext(context.Background())); res.Stopped != StopComplete {
+		t.Errorf("expected a live context to complete, got %v", res.Stopped)
+	}
+}
+
+func TestSolveFrom(t *testing.T) {
+	// Two stations on a line 0-1-...-9, reached after 3 and 1 minutes' walk
+	g := NewGraph()
+	for i := range NodeID(9) {
+		g.AddEdge(i, i+1, 2)
+		g.AddEdge(i+1, i, 2)
+	}
+	seeds := map[NodeID]Dist{2: 3, 8: 1}
+	for _, opts := range [][]Option{nil, {WithoutFastPaths()}} {
+		res := SolveFrom(g, seeds, INF, opts...)
+		for v := range NodeID(10) {
+			want := min(3+2*Dist(max(v-2, 2-v)), 1+2*Dist(max(v-8, 8-v)))
+			if res.Dist[v] != want {
+				t.Errorf("node %d: expected %v, got %v", v, want, res.Dist[v])
+			}
+		}
+		if path := res.PathTo(6); len(path) != 3 || path[0] != 8 {
+			t.Errorf("expected the path from station 8, got %v", path)
+		}
+	}
+
+	// A seed improved from another source gets a predecessor
+	res := SolveFrom(g, map[NodeID]Dist{0: 0, 1: 5}, INF)
+	if res.Dist[1] != 2 || res.Pred[1] != 0 {
+		t.Errorf("expected node 1 reached from 0 at 2, got %v", res.Dist[1])
+	}
+
+	// The bound applies to the seeded distances, even when it exceeds every
+	// path length of the graph
+	res = SolveFrom(g, map[NodeID]Dist{0: 100}, 110)
+	if res.Dist[5] != 110 || res.Dist[6] <= 110 && res.Dist[6] != INF || !res.Truncated {
+		t.Errorf("expected the search to stop at 110, got %v and %v", res.Dist[5], res.Dist[6])
+	}
+}
@@ -0,0 +1,149 @@
+package bmssp
+
+import (
+	"container/list"
+	"slices"
+	"sync"
+)
+
+// Route is a path answered by a RouteCache.
+type Route struct {
+	Path []NodeID // node sequence of the route
+	Cost Dist     // cost of Path on the live graph
+	// Approximate is set when Path was computed for other nodes of the same
+	// origin/destination cells and therefore does not start at the requested
+	// origin or end at the requested destination.
+	Approximate bool
+}
+
+type routeKey struct {
+	from, to CellID
+}
+
+type routeEntry struct {
+	key  routeKey
+	path []NodeID
+	cost Dist // cost of path when it was computed, the reference for drift
+}
+
+// RouteCache is an approximate route cache keyed by coarse origin/destination
+// cells. Cached paths are revalidated against the live graph on every hit:
+// a path whose edges disappeared, or whose cost grew by more than the
+// configured tolerance over its cost when computed, is recomputed.
+//
+// A RouteCache is safe for concurrent use, provided the graph is not mutated
+// while queries are running.
+type RouteCache struct {
+	g         *Graph
+	index     *GeoIndex
+	capacity  int
+	tolerance Dist
+
+	mu      sync.Mutex
+	entries map[routeKey]*list.Element
+	lru     *list.List // most recently used at the front
+}
+
+// NewRouteCache creates a cache of at most capacity cell-pair routes over g.
+// Nodes are mapped to cells through index. tolerance is the relative cost
+// increase (e.g. 0.1 for 10%) a cached path may accumulate before it is
+// recomputed.
+func NewRouteCache(g *Graph, index *GeoIndex, capacity int, tolerance Dist) *RouteCache {
+	return &RouteCache{
+		g:         g,
+		index:     index,
+		capacity:  capacity,
+		tolerance: tolerance,
+		entries:   make(map[routeKey]*list.Element),
+		lru:       list.New(),
+	}
+}
+
+// Route returns a route from 'from' to 'to', serving it from the cache when a
+// valid route between the same cells is available. Nodes without a location
+// in the index bypass the cache. It reports false if 'to' is unreachable.
+func (c *RouteCache) Route(from, to NodeID) (Route, bool) {
+	fromCell, ok1 := c.index.CellOf(from)
+	toCell, ok2 := c.index.CellOf(to)
+	if !ok1 || !ok2 {
+		return c.compute(from, to)
+	}
+	key := routeKey{from: fromCell, to: toCell}
+
+	if r, ok := c.lookup(key); ok {
+		r.Approximate = r.Path[0] != from || r.Path[len(r.Path)-1] != to
+		return r, true
+	}
+
+	r, ok := c.compute(from, to)
+	if ok {
+		c.store(key, r)
+	}
+	return r, ok
+}
+
+// lookup returns the cached route for key after revalidating it.
+func (c *RouteCache) lookup(key routeKey) (Route, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return Route{}, false
+	}
+	entry := el.Value.(*routeEntry)
+
+	cost, valid := PathCost(c.g, entry.path)
+	if !valid || cost > entry.cost*(1+c.tolerance) {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+		return Route{}, false
+	}
+
+	c.lru.MoveToFront(el)
+	return Route{Path: slices.Clone(entry.path), Cost: cost}, true
+}
+
+// store caches a copy of r's path, so that callers may modify r.
+func (c *RouteCache) store(key routeKey, r Route) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &routeEntry{key: key, path: slices.Clone(r.Path), cost: r.Cost}
+	if el, ok := c.entries[key]; ok {
+		el.Value = entry
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+
+	for c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*routeEntry).key)
+	}
+}
+
+// compute runs an exact shortest-path query.
+func (c *RouteCache) compute(from, to NodeID) (Route, bool) {
+	path, cost := ShortestPath(c.g, from, to)
+	if path == nil {
+		return Route{}, false
+	}
+	return Route{Path: path, Cost: cost}, true
+}
+
+// Len returns the number of cached cell-pair routes.
+func (c *RouteCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Purge drops every cached route, e.g. after a bulk graph update.
+func (c *RouteCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[routeKey]*list.Element)
+	c.lru.Init()
+}
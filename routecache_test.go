@@ -0,0 +1,112 @@
+package bmssp
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestShortestPath(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 2)
+	g.AddEdge(0, 2, 5)
+	g.AddEdge(1, 3, 4)
+	g.AddEdge(2, 3, 1)
+	g.AddEdge(1, 4, 1)
+	g.AddEdge(4, 3, 1)
+
+	path, d := ShortestPath(g, 0, 3)
+	if d != 4 || !slices.Equal(path, []NodeID{0, 1, 4, 3}) {
+		t.Errorf("expected path [0 1 4 3] of length 4, got %v of length %v", path, d)
+	}
+	if cost, ok := PathCost(g, path); !ok || cost != d {
+		t.Errorf("PathCost: expected %v, got %v (ok=%v)", d, cost, ok)
+	}
+	if path, d := ShortestPath(g, 3, 0); path != nil || d != INF {
+		t.Errorf("expected unreachable, got %v of length %v", path, d)
+	}
+}
+
+func TestRouteCache(t *testing.T) {
+	// Two clusters of nodes: 0,1 near the origin cell and 2,3 near the destination cell
+	g := NewGraph()
+	g.AddEdge(0, 2, 10)
+	g.AddEdge(1, 0, 1)
+	g.AddEdge(0, 3, 12)
+	g.AddEdge(0, 4, 3)
+	g.AddEdge(4, 2, 3)
+
+	gi, _ := NewGeoIndex(GridScheme{CellSize: 1})
+	gi.Set(0, LatLng{Lat: 10.1, Lng: 10.1})
+	gi.Set(1, LatLng{Lat: 10.2, Lng: 10.2})
+	gi.Set(2, LatLng{Lat: 20.1, Lng: 20.1})
+	gi.Set(3, LatLng{Lat: 20.2, Lng: 20.2})
+
+	c := NewRouteCache(g, gi, 16, 0.1)
+
+	r, ok := c.Route(0, 2)
+	if !ok || r.Approximate || r.Cost != 6 {
+		t.Fatalf("first query: expected exact route of cost 6, got %+v (ok=%v)", r, ok)
+	}
+	if c.Len() != 1 {
+		t.Fatalf("expected 1 cached route, got %d", c.Len())
+	}
+
+	// Same cells, different endpoints: served from the cache as an approximation
+	r, ok = c.Route(1, 3)
+	if !ok || !r.Approximate || !slices.Equal(r.Path, []NodeID{0, 4, 2}) {
+		t.Errorf("cell hit: expected approximate cached path, got %+v", r)
+	}
+
+	// Small cost drift is accepted and reported against the live graph
+	g.UpdateEdgeWeight(4, 2, 3.5)
+	if r, _ = c.Route(0, 2); r.Cost != 6.5 {
+		t.Errorf("after small drift: expected revalidated cost 6.5, got %v", r.Cost)
+	}
+
+	// Modifying a returned path leaves the cache intact
+	r.Path[0] = 99
+	if cached := c.lru.Front().Value.(*routeEntry).path; cached[0] != 0 {
+		t.Errorf("expected the cached path to be unaffected, got %v", cached)
+	}
+
+	// Large drift forces a recomputation
+	g.UpdateEdgeWeight(4, 2, 20)
+	if r, _ = c.Route(0, 2); r.Cost != 10 || !slices.Equal(r.Path, []NodeID{0, 2}) {
+		t.Errorf("after large drift: expected recomputed path [0 2] of cost 10, got %+v", r)
+	}
+
+	// Broken paths are recomputed as well
+	g.RemoveEdge(0, 2)
+	if r, _ = c.Route(0, 2); r.Cost != 23 {
+		t.Errorf("after edge removal: expected cost 23, got %+v", r)
+	}
+
+	c.Purge()
+	if c.Len() != 0 {
+		t.Errorf("expected empty cache after Purge, got %d", c.Len())
+	}
+}
+
+func TestRouteCache_DriftReference(t *testing.T) {
+	// 0->4->2 costs 6, the alternative 0->5->2 costs 7
+	g := NewGraph()
+	g.AddEdge(0, 4, 3)
+	g.AddEdge(4, 2, 3)
+	g.AddEdge(0, 5, 3.5)
+	g.AddEdge(5, 2, 3.5)
+	gi, _ := NewGeoIndex(GridScheme{CellSize: 1})
+	gi.Set(0, LatLng{Lat: 10.1, Lng: 10.1})
+	gi.Set(2, LatLng{Lat: 20.1, Lng: 20.1})
+	c := NewRouteCache(g, gi, 16, 0.1)
+	c.Route(0, 2)
+
+	// Each step stays within 10% of the previous cost, but 7.1 is more than
+	// 10% above the 6 the route cost when cached
+	for _, w := range []Dist{3.5, 4.1} {
+		g.UpdateEdgeWeight(4, 2, w)
+		c.Route(0, 2)
+	}
+	if r, _ := c.Route(0, 2); r.Cost != 7 || !slices.Equal(r.Path, []NodeID{0, 5, 2}) {
+		t.Errorf("after accumulated drift: expected recomputed path [0 5 2] of cost 7, got %+v", r)
+	}
+}
@@ -0,0 +1,250 @@
+package bmssp
+
+import "slices"
+
+// Components is a partition of the nodes of a graph into connected
+// components, numbered from 0.
+type Components struct {
+	comp    map[NodeID]int
+	members [][]NodeID // nodes of each component, ascending
+	cond    *Graph     // condensation; nil for weak components
+}
+
+// Count returns the number of components.
+func (c *Components) Count() int {
+	return len(c.members)
+}
+
+// Of returns the component of v, reporting false if v is not a node of the
+// graph.
+func (c *Components) Of(v NodeID) (int, bool) {
+	id, ok := c.comp[v]
+	return id, ok
+}
+
+// Members returns the nodes of component id in ascending order. The slice
+// must not be modified.
+func (c *Components) Members(id int) []NodeID {
+	return c.members[id]
+}
+
+// Condensation returns the condensation DAG of strongly connected
+// components: node i stands for component i, and an edge i->j carries the
+// smallest weight of the edges from component i to component j. Components
+// are numbered in topological order, so every edge leads to a higher ID.
+// It returns nil for weakly connected components.
+func (c *Components) Condensation() *Graph {
+	return c.cond
+}
+
+// SCC computes the strongly connected components of g with Tarjan's
+// algorithm. Edges with weight INF are closed and ignored. Components are
+// numbered in topological order of the condensation, with ties broken
+// deterministically by node ID.
+//
+// Parameters:
+//   - g: input graph
+//
+// Returns:
+//   - the strongly connected components and their condensation
+func SCC(g *Graph) *Components {
+	nodes := make([]NodeID, 0, len(g.adj))
+	for u := range g.adj {
+		nodes = append(nodes, u)
+	}
+	slices.Sort(nodes)
+
+	type frame struct {
+		u    NodeID
+		next int // index of the next edge to explore
+	}
+	index := make(map[NodeID]int, len(g.adj))
+	low := make(map[NodeID]int, len(g.adj))
+	onStack := NewNodeSet()
+	var stack []NodeID
+	var calls []frame
+	var sccs [][]NodeID // in reverse topological order
+
+	visit := func(u NodeID) {
+		index[u], low[u] = len(index), len(index)
+		stack = append(stack, u)
+		onStack.Add(u)
+		calls = append(calls, frame{u: u})
+	}
+	for _, root := range nodes {
+		if _, seen := index[root]; seen {
+			continue
+		}
+		visit(root)
+		for len(calls) > 0 {
+			top := &calls[len(calls)-1]
+			u := top.u
+			if edges := g.adj[u]; top.next < len(edges) {
+				e := edges[top.next]
+				top.next++
+				if e.Weight == INF {
+					continue
+				}
+				if _, seen := index[e.To]; !seen {
+					visit(e.To)
+				} else if onStack.Has(e.To) {
+					low[u] = min(low[u], index[e.To])
+				}
+				continue
+			}
+
+			calls = calls[:len(calls)-1]
+			if len(calls) > 0 {
+				parent := calls[len(calls)-1].u
+				low[parent] = min(low[parent], low[u])
+			}
+			if low[u] != index[u] {
+				continue
+			}
+			// u is the root of a component: pop it off the stack
+			i := len(stack) - 1
+			for stack[i] != u {
+				i--
+			}
+			members := slices.Clone(stack[i:])
+			stack = stack[:i]
+			for _, v := range members {
+				delete(onStack, v)
+			}
+			slices.Sort(members)
+			sccs = append(sccs, members)
+		}
+	}
+
+	slices.Reverse(sccs)
+	c := &Components{comp: make(map[NodeID]int, len(g.adj)), members: sccs}
+	for id, members := range sccs {
+		for _, v := range members {
+			c.comp[v] = id
+		}
+	}
+	c.cond = c.condense(g)
+	return c
+}
+
+// condense builds the condensation of g over the components of c.
+func (c *Components) condense(g *Graph) *Graph {
+	cond := NewGraph()
+	for id, members := range c.members {
+		cond.adj[NodeID(id)] = nil
+		best := make(map[int]Dist)
+		var order []int
+		for _, u := range members {
+			for _, e := range g.adj[u] {
+				to := c.comp[e.To]
+				if to == id || e.Weight == INF {
+					continue
+				}
+				if w, ok := best[to]; !ok {
+					best[to] = e.Weight
+					order = append(order, to)
+				} else if e.Weight < w {
+					best[to] = e.Weight
+				}
+			}
+		}
+		slices.Sort(order)
+		for _, to := range order {
+			cond.AddEdge(NodeID(id), NodeID(to), best[to])
+		}
+	}
+	return cond
+}
+
+// Reachable reports whether a path may lead from from to to, without
+// searching g, e.g. to skip a query whose target cannot be reached. For
+// strongly connected components the answer is exact and found on the
+// condensation. For weakly connected components only false is certain:
+// nodes of one weak component need not reach each other.
+func (c *Components) Reachable(from, to NodeID) bool {
+	cf, ok1 := c.comp[from]
+	ct, ok2 := c.comp[to]
+	if !ok1 || !ok2 {
+		return false
+	}
+	if cf == ct || c.cond == nil {
+		return cf == ct
+	}
+	if cf > ct {
+		return false
+	}
+
+	// Components are numbered topologically, so the search can ignore
+	// every component after ct.
+	seen := map[int]bool{cf: true}
+	queue := []int{cf}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, e := range c.cond.adj[NodeID(id)] {
+			next := int(e.To)
+			if next == ct {
+				return true
+			}
+			if next < ct && !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+// ConnectedComponents computes the weakly connected components of g: the
+// components of g with edge directions ignored. Edges with weight INF are
+// closed and ignored. Components are numbered by their smallest node.
+//
+// Parameters:
+//   - g: input graph
+//
+// Returns:
+//   - the weakly connected components
+func ConnectedComponents(g *Graph) *Components {
+	parent := make(map[NodeID]NodeID, len(g.adj))
+	find := func(v NodeID) NodeID {
+		for parent[v] != v {
+			parent[v] = parent[parent[v]] // path halving
+			v = parent[v]
+		}
+		return v
+	}
+	for u := range g.adj {
+		parent[u] = u
+	}
+	for u, edges := range g.adj {
+		for _, e := range edges {
+			if e.Weight == INF {
+				continue
+			}
+			// Keep the smaller node as the root, so roots are minima
+			a, b := find(u), find(e.To)
+			if a > b {
+				a, b = b, a
+			}
+			parent[b] = a
+		}
+	}
+
+	nodes := make([]NodeID, 0, len(g.adj))
+	for u := range g.adj {
+		nodes = append(nodes, u)
+	}
+	slices.Sort(nodes)
+	c := &Components{comp: make(map[NodeID]int, len(g.adj))}
+	for _, v := range nodes {
+		root := find(v)
+		if root == v {
+			c.comp[v] = len(c.members)
+			c.members = append(c.members, nil)
+		}
+		id := c.comp[root]
+		c.comp[v] = id
+		c.members[id] = append(c.members[id], v)
+	}
+	return c
+}
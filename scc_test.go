@@ -0,0 +1,123 @@
+package bmssp
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSCC(t *testing.T) {
+	g := NewGraph()
+	// {0,1,2} -> {3,4} -> {5}, with 6 isolated and a closed edge back
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 2, 1)
+	g.AddEdge(2, 0, 1)
+	g.AddEdge(2, 3, 4)
+	g.AddEdge(1, 4, 2)
+	g.AddEdge(3, 4, 1)
+	g.AddEdge(4, 3, 1)
+	g.AddEdge(4, 5, 1)
+	g.AddEdge(5, 0, INF)
+	g.AddEdge(6, 6, 1)
+
+	c := SCC(g)
+	if c.Count() != 4 {
+		t.Fatalf("expected 4 components, got %d", c.Count())
+	}
+	same := func(u, v NodeID) bool {
+		cu, _ := c.Of(u)
+		cv, _ := c.Of(v)
+		return cu == cv
+	}
+	if !same(0, 2) || !same(3, 4) || same(0, 3) || same(4, 5) {
+		t.Errorf("unexpected components: %v", c.members)
+	}
+	id, _ := c.Of(1)
+	if got := c.Members(id); !slices.Equal(got, []NodeID{0, 1, 2}) {
+		t.Errorf("expected members [0 1 2], got %v", got)
+	}
+	if _, ok := c.Of(99); ok {
+		t.Error("unknown node should have no component")
+	}
+
+	cond := c.Condensation()
+	if _, err := TopologicalOrder(cond); err != nil {
+		t.Fatalf("condensation is not a DAG: %v", err)
+	}
+	for u, edges := range cond.adj {
+		for _, e := range edges {
+			if e.To <= u {
+				t.Errorf("condensation edge %d->%d is not in topological order", u, e.To)
+			}
+		}
+	}
+	a, _ := c.Of(0)
+	b, _ := c.Of(3)
+	if edges := cond.OutEdges(NodeID(a)); len(edges) != 1 || edges[0].To != NodeID(b) || edges[0].Weight != 2 {
+		t.Errorf("expected a single edge of weight 2 between the first components, got %v", edges)
+	}
+
+	for _, tt := range []struct {
+		from, to NodeID
+		want     bool
+	}{
+		{0, 5, true}, {2, 1, true}, {5, 0, false}, {3, 1, false}, {0, 6, false}, {6, 6, true},
+	} {
+		if got := c.Reachable(tt.from, tt.to); got != tt.want {
+			t.Errorf("Reachable(%d, %d) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestSCC_Reachable(t *testing.T) {
+	g := generateRandomGraph(300, 500, 10.0, 11)
+	c := SCC(g)
+	for _, src := range []NodeID{0, 17, 150} {
+		dist := Dijkstra(g, src)
+		for v, d := range dist {
+			if got := c.Reachable(src, v); got != (d < INF) {
+				t.Fatalf("Reachable(%d, %d) = %v, distance %v", src, v, got, d)
+			}
+		}
+	}
+}
+
+func TestConnectedComponents(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(3, 1, 1)
+	g.AddEdge(1, 2, 1)
+	g.AddEdge(7, 5, 1)
+	g.AddEdge(9, 9, 1)
+	g.AddEdge(2, 9, INF)
+
+	c := ConnectedComponents(g)
+	if c.Count() != 3 {
+		t.Fatalf("expected 3 components, got %d: %v", c.Count(), c.members)
+	}
+	for i, want := range [][]NodeID{{1, 2, 3}, {5, 7}, {9}} {
+		if got := c.Members(i); !slices.Equal(got, want) {
+			t.Errorf("component %d: expected %v, got %v", i, want, got)
+		}
+	}
+	if c.Condensation() != nil {
+		t.Error("weak components have no condensation")
+	}
+	if c.Reachable(1, 5) || !c.Reachable(1, 3) {
+		t.Error("expected weak reachability to separate components only")
+	}
+}
+
+func TestResult_Unreachable(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(2, 0, 1)
+	g.AddEdge(1, 3, INF)
+
+	for name, res := range map[string]*Result{
+		"bmssp":    Solve(g, sources(0), INF),
+		"dijkstra": SolveDijkstra(g, sources(0)),
+	} {
+		if res.Unreachable.Len() != 2 || !res.Unreachable.Has(2) || !res.Unreachable.Has(3) {
+			t.Errorf("%s: expected nodes 2 and 3 unreachable, got %v", name, res.Unreachable)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The same methods are served over gRPC, with the binary protobuf codec of
+// proto.go, to calls with the content type application/grpc or
+// application/grpc+proto. Every call answers with HTTP status 200, its
+// response messages in gRPC length-prefixed framing (the same layout as
+// Connect envelopes) and the outcome in the Grpc-Status and Grpc-Message
+// trailers. The Grpc-Timeout header bounds the call. gRPC clients need
+// HTTP/2: serve with TLS, or enable unencrypted HTTP/2 (h2c) through
+// http.Server.Protocols.
+
+// grpcCodes maps the error codes used here to gRPC status codes.
+var grpcCodes = map[string]int{
+	"invalid_argument":   3,
+	"not_found":          5,
+	"resource_exhausted": 8,
+	"unimplemented":      12,
+	"unavailable":        14,
+}
+
+// grpcUnknown is the gRPC status of errors without a code of their own.
+const grpcUnknown = 2
+
+// isGRPC reports whether r is a gRPC call with the protobuf codec.
+func isGRPC(r *http.Request) bool {
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return ct == "application/grpc" || ct == "application/grpc+proto"
+}
+
+// grpcTimeout parses a Grpc-Timeout header: up to 8 digits and a unit.
+func grpcTimeout(v string) (time.Duration, bool) {
+	if len(v) < 2 || len(v) > 9 {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(v[:len(v)-1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	unit, ok := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}[v[len(v)-1]]
+	return time.Duration(n) * unit, ok
+}
+
+// grpcMessage percent-encodes msg for the Grpc-Message trailer.
+func grpcMessage(msg string) string {
+	var b []byte
+	for i := 0; i < len(msg); i++ {
+		if c := msg[i]; c < 0x20 || c > 0x7e || c == '%' {
+			b = fmt.Appendf(b, "%%%02X", c)
+		} else {
+			b = append(b, c)
+		}
+	}
+	return string(b)
+}
+
+// grpcCall serves a gRPC call: it reads the request message and passes it
+// to fn, which sends the response messages, then writes the status
+// trailers.
+func (s *Server) grpcCall(w http.ResponseWriter, r *http.Request, fn func(r *http.Request, msg []byte, send func(protoMessage) error) error) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Add("Trailer", "Grpc-Status")
+	w.Header().Add("Trailer", "Grpc-Message")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	send := func(m protoMessage) error {
+		data := m.appendProto(nil)
+		if _, err := w.Write(binary.BigEndian.AppendUint32([]byte{0}, uint32(len(data)))); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	err := func() error {
+		if enc := r.Header.Get("Grpc-Encoding"); enc != "" && enc != "identity" {
+			return &rpcError{Code: "unimplemented", Message: fmt.Sprintf("unsupported encoding %q", enc)}
+		}
+		if v := r.Header.Get("Grpc-Timeout"); v != "" {
+			d, ok := grpcTimeout(v)
+			if !ok {
+				return &rpcError{Code: "invalid_argument", Message: fmt.Sprintf("invalid Grpc-Timeout %q", v)}
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+		msg, err := readMessage(r.Body, s.MaxGraphBytes)
+		if err != nil {
+			return err
+		}
+		return fn(r, msg, send)
+	}()
+	status, message := 0, ""
+	if err != nil {
+		re := toRPCError(err)
+		status, message = cmp.Or(grpcCodes[re.Code], grpcUnknown), re.Message
+	}
+	w.Header().Set("Grpc-Status", strconv.Itoa(status))
+	w.Header().Set("Grpc-Message", grpcMessage(message))
+}
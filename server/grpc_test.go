@@ -0,0 +1,181 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/mfreeman451/bmssp-go"
+)
+
+// grpcResult is a decoded gRPC response: its messages and status trailers.
+type grpcResult struct {
+	msgs    [][]byte
+	status  string
+	message string
+}
+
+// grpcInvoke makes a gRPC call over HTTP/2 and reads the whole response.
+func grpcInvoke(t *testing.T, ts *httptest.Server, method string, req protoMessage, header ...string) grpcResult {
+	t.Helper()
+	data := req.appendProto(nil)
+	body := append(binary.BigEndian.AppendUint32([]byte{0}, uint32(len(data))), data...)
+	hreq, _ := http.NewRequest(http.MethodPost, ts.URL+rpcPrefix+method, bytes.NewReader(body))
+	hreq.Header.Set("Content-Type", "application/grpc")
+	hreq.Header.Set("TE", "trailers")
+	for i := 0; i+1 < len(header); i += 2 {
+		hreq.Header.Set(header[i], header[i+1])
+	}
+	resp, err := ts.Client().Do(hreq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.ProtoMajor != 2 || resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/grpc" {
+		t.Fatalf("%s: expected an HTTP/2 gRPC response, got %s %d %q", method, resp.Proto, resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	var res grpcResult
+	for {
+		var prefix [5]byte
+		if _, err := io.ReadFull(resp.Body, prefix[:]); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("%s: reading message: %v", method, err)
+		}
+		msg := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+		if _, err := io.ReadFull(resp.Body, msg); err != nil {
+			t.Fatalf("%s: reading message: %v", method, err)
+		}
+		res.msgs = append(res.msgs, msg)
+	}
+	res.status, res.message = resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	return res
+}
+
+func TestGRPC(t *testing.T) {
+	// Unencrypted HTTP/2, as in proto/bmssp/v1/README.md
+	ts := httptest.NewUnstartedServer(New(nil))
+	ts.Config.Protocols = new(http.Protocols)
+	ts.Config.Protocols.SetHTTP1(true)
+	ts.Config.Protocols.SetUnencryptedHTTP2(true)
+	ts.Start()
+	defer ts.Close()
+	transport := ts.Client().Transport.(*http.Transport)
+	transport.Protocols = new(http.Protocols)
+	transport.Protocols.SetUnencryptedHTTP2(true)
+
+	if res := grpcInvoke(t, ts, "ShortestPath", &shortestPathRequest{From: 0, To: 1}); res.status != "14" || len(res.msgs) != 0 {
+		t.Errorf("expected UNAVAILABLE without a graph, got %+v", res)
+	}
+
+	load := &loadGraphRequest{Edges: &edgeList{
+		Edges:      []rpcEdge{{From: 0, To: 1, Weight: 2}, {From: 1, To: 2, Weight: 3}, {From: 0, To: 2, Weight: 10}},
+		ExtraNodes: []protoInt64{7},
+	}}
+	res := grpcInvoke(t, ts, "LoadGraph", load)
+	var loaded loadGraphResponse
+	if res.status != "0" || len(res.msgs) != 1 || loaded.unmarshalProto(res.msgs[0]) != nil || loaded.Nodes != 4 || loaded.Edges != 3 {
+		t.Fatalf("expected 4 nodes and 3 edges loaded, got %+v %+v", res, loaded)
+	}
+
+	res = grpcInvoke(t, ts, "ShortestPath", &shortestPathRequest{From: 0, To: 2})
+	var path shortestPathResponse
+	if res.status != "0" || path.unmarshalProto(res.msgs[0]) != nil || path.Cost != 5 || !slices.Equal(path.Path, []protoInt64{0, 1, 2}) {
+		t.Errorf("expected the path [0 1 2] of cost 5, got %+v", path)
+	}
+	res = grpcInvoke(t, ts, "ShortestPath", &shortestPathRequest{From: 0, To: 9})
+	if res.status != "5" || res.message != "bmssp: node not found: 9" {
+		t.Errorf("expected NOT_FOUND for an unknown node, got %+v", res)
+	}
+
+	bound := protoDouble(4)
+	res = grpcInvoke(t, ts, "MultiSource", &multiSourceRequest{Sources: []protoInt64{0, 7}, Bound: &bound})
+	var multi multiSourceResponse
+	want := []nodeDistance{{Node: 0, Dist: 0}, {Node: 1, Dist: 2}, {Node: 7, Dist: 0}}
+	if res.status != "0" || multi.unmarshalProto(res.msgs[0]) != nil || !slices.Equal(multi.Distances, want) {
+		t.Errorf("expected %v, got %+v", want, multi)
+	}
+	bound = -1
+	if res := grpcInvoke(t, ts, "MultiSource", &multiSourceRequest{Sources: []protoInt64{0}, Bound: &bound}); res.status != "3" {
+		t.Errorf("expected INVALID_ARGUMENT for a negative bound, got %+v", res)
+	}
+
+	// The stream sends one message per batch
+	res = grpcInvoke(t, ts, "DistanceUpdates", &multiSourceRequest{Sources: []protoInt64{0}, BatchSize: 2})
+	if res.status != "0" || len(res.msgs) != 2 {
+		t.Fatalf("expected 2 batches, got %+v", res)
+	}
+	var last distanceBatch
+	if err := last.unmarshalProto(res.msgs[1]); err != nil || !last.Done || len(last.Distances) != 1 || last.Distances[0].Dist != 5 {
+		t.Errorf("expected a last batch with node 2 at 5, got %+v %v", last, err)
+	}
+	if res := grpcInvoke(t, ts, "DistanceUpdates", &multiSourceRequest{Sources: []protoInt64{42}}); res.status != "5" || len(res.msgs) != 0 {
+		t.Errorf("expected NOT_FOUND, got %+v", res)
+	}
+
+	if res := grpcInvoke(t, ts, "ShortestPath", &shortestPathRequest{}, "Grpc-Timeout", "soon"); res.status != "3" {
+		t.Errorf("expected INVALID_ARGUMENT for a malformed timeout, got %+v", res)
+	}
+	if res := grpcInvoke(t, ts, "ShortestPath", &shortestPathRequest{}, "Grpc-Encoding", "gzip"); res.status != "12" {
+		t.Errorf("expected UNIMPLEMENTED for compression, got %+v", res)
+	}
+}
+
+func TestGRPC_Timeout(t *testing.T) {
+	for v, want := range map[string]int64{"1S": 1e9, "250m": 250e6, "3u": 3e3, "2H": 7200e9} {
+		if d, ok := grpcTimeout(v); !ok || int64(d) != want {
+			t.Errorf("%s: expected %d ns, got %v %v", v, want, d, ok)
+		}
+	}
+	for _, v := range []string{"", "5", "S", "123456789S", "1x", "-1S"} {
+		if _, ok := grpcTimeout(v); ok {
+			t.Errorf("%q: expected an invalid timeout", v)
+		}
+	}
+	if got := grpcMessage("bad\nvalue 100%"); got != "bad%0Avalue 100%25" {
+		t.Errorf("unexpected encoding %q", got)
+	}
+}
+
+func TestProto_RoundTrip(t *testing.T) {
+	bound, zero := protoDouble(2.5), protoDouble(0)
+	for _, m := range []protoMessage{
+		&loadGraphRequest{Edges: &edgeList{Edges: []rpcEdge{{From: -1, To: 1 << 40, Weight: protoDouble(math.Inf(1))}}}},
+		&loadGraphRequest{Binary: []byte{}},
+		&loadGraphResponse{Nodes: 3, Edges: 1},
+		&shortestPathRequest{From: 5, To: 0},
+		&shortestPathResponse{Path: []protoInt64{}, Cost: protoDouble(math.Inf(1)), Stopped: stopReason(bmssp.StopDeadline)},
+		&multiSourceRequest{Sources: []protoInt64{1, 2}, Bound: &bound, BatchSize: 10},
+		&multiSourceRequest{Sources: []protoInt64{0}, Bound: &zero, BatchSize: -1},
+		&multiSourceResponse{Distances: []nodeDistance{{Node: 1, Dist: 0.5}}, Stopped: stopReason(bmssp.StopCanceled)},
+		&distanceBatch{Distances: []nodeDistance{}, Done: true},
+	} {
+		got := reflect.New(reflect.TypeOf(m).Elem()).Interface().(protoMessage)
+		if err := got.unmarshalProto(m.appendProto(nil)); err != nil || !reflect.DeepEqual(got, m) {
+			t.Errorf("%T: round trip gave %+v, %v, expected %+v", m, got, err, m)
+		}
+	}
+
+	// Unpacked repeated fields and unknown fields are accepted
+	data := appendTag(nil, 1, wireVarint)
+	data = append(data, 4)
+	data = appendTag(data, 9, wireFixed32)
+	data = append(data, 0, 0, 0, 0)
+	data = appendTag(data, 1, wireVarint)
+	data = append(data, 6)
+	var req multiSourceRequest
+	if err := req.unmarshalProto(data); err != nil || !slices.Equal(req.Sources, []protoInt64{4, 6}) {
+		t.Errorf("expected sources [4 6], got %v %v", req.Sources, err)
+	}
+	for _, data := range [][]byte{{0x08}, {0x12, 5, 1}, {0x11, 0}, {0x0b}} {
+		if err := req.unmarshalProto(data); err == nil {
+			t.Errorf("%x: expected an error", data)
+		}
+	}
+}
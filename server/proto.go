@@ -0,0 +1,390 @@
+package server
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// The binary protobuf encoding of the messages of proto/bmssp/v1, written by
+// hand to keep the module free of dependencies. Scalars use proto3 implicit
+// presence: zero values are not written, except in the oneof of
+// LoadGraphRequest and for the optional bound of MultiSourceRequest.
+// Repeated scalars are written packed and read packed or not.
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+
+	// wirePacked stands for a repeated scalar field, packed or not, in the
+	// wire types passed to parseFields.
+	wirePacked = -1
+)
+
+// errTruncatedProto is returned for a message that ends inside a field.
+var errTruncatedProto = errors.New("truncated protobuf message")
+
+// protoMessage is implemented by the pointer types of the messages.
+type protoMessage interface {
+	// appendProto appends the encoded message to b.
+	appendProto(b []byte) []byte
+	// unmarshalProto decodes data into the message, which must be zero.
+	unmarshalProto(data []byte) error
+}
+
+func appendTag(b []byte, field, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wire))
+}
+
+func appendVarintField(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.AppendUvarint(appendTag(b, field, wireVarint), v)
+}
+
+func appendDoubleField(b []byte, field int, v float64) []byte {
+	if v == 0 && !math.Signbit(v) {
+		return b
+	}
+	return binary.LittleEndian.AppendUint64(appendTag(b, field, wireFixed64), math.Float64bits(v))
+}
+
+func appendBytesField(b []byte, field int, data []byte) []byte {
+	b = binary.AppendUvarint(appendTag(b, field, wireBytes), uint64(len(data)))
+	return append(b, data...)
+}
+
+func appendMessageField(b []byte, field int, m protoMessage) []byte {
+	return appendBytesField(b, field, m.appendProto(nil))
+}
+
+func appendPackedField(b []byte, field int, ids []protoInt64) []byte {
+	if len(ids) == 0 {
+		return b
+	}
+	var packed []byte
+	for _, v := range ids {
+		packed = binary.AppendUvarint(packed, uint64(v))
+	}
+	return appendBytesField(b, field, packed)
+}
+
+// protoField is a decoded field: varints and fixed-size values in v, the
+// contents of length-delimited fields in data.
+type protoField struct {
+	num  int
+	wire int
+	v    uint64
+	data []byte
+}
+
+// float64 returns the value of a double field.
+func (f protoField) float64() float64 {
+	return math.Float64frombits(f.v)
+}
+
+// check returns an error unless the field has the wire type wire.
+func (f protoField) check(wire int) error {
+	if f.wire != wire {
+		return fmt.Errorf("field %d: unexpected wire type %d", f.num, f.wire)
+	}
+	return nil
+}
+
+// appendPacked decodes a repeated int64 field, packed or not, into ids.
+func (f protoField) appendPacked(ids []protoInt64) ([]protoInt64, error) {
+	if f.wire == wireVarint {
+		return append(ids, protoInt64(f.v)), nil
+	}
+	for data := f.data; len(data) > 0; {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errTruncatedProto
+		}
+		ids, data = append(ids, protoInt64(v)), data[n:]
+	}
+	return ids, nil
+}
+
+// parseProto calls fn with every field of an encoded message, in order.
+func parseProto(data []byte, fn func(f protoField) error) error {
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errTruncatedProto
+		}
+		data = data[n:]
+		f := protoField{num: int(tag >> 3), wire: int(tag & 7)}
+		if f.num == 0 {
+			return errors.New("invalid protobuf field number 0")
+		}
+		switch f.wire {
+		case wireVarint:
+			if f.v, n = binary.Uvarint(data); n <= 0 {
+				return errTruncatedProto
+			}
+		case wireFixed64:
+			if n = 8; len(data) < n {
+				return errTruncatedProto
+			}
+			f.v = binary.LittleEndian.Uint64(data)
+		case wireFixed32:
+			if n = 4; len(data) < n {
+				return errTruncatedProto
+			}
+			f.v = uint64(binary.LittleEndian.Uint32(data))
+		case wireBytes:
+			size, m := binary.Uvarint(data)
+			if m <= 0 || uint64(len(data)-m) < size {
+				return errTruncatedProto
+			}
+			f.data, n = data[m:m+int(size)], m+int(size)
+		default:
+			return fmt.Errorf("field %d: unsupported wire type %d", f.num, f.wire)
+		}
+		data = data[n:]
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseFields decodes a message whose known fields have the given wire
+// types, calling fn for each of them and skipping unknown fields.
+func parseFields(data []byte, wires map[int]int, fn func(f protoField) error) error {
+	return parseProto(data, func(f protoField) error {
+		wire, ok := wires[f.num]
+		if !ok {
+			return nil
+		}
+		if wire == wirePacked {
+			wire = wireBytes
+			if f.wire == wireVarint {
+				wire = wireVarint
+			}
+		}
+		if err := f.check(wire); err != nil {
+			return err
+		}
+		return fn(f)
+	})
+}
+
+func (m *rpcEdge) appendProto(b []byte) []byte {
+	b = appendVarintField(b, 1, uint64(m.From))
+	b = appendVarintField(b, 2, uint64(m.To))
+	return appendDoubleField(b, 3, float64(m.Weight))
+}
+
+func (m *rpcEdge) unmarshalProto(data []byte) error {
+	return parseFields(data, map[int]int{1: wireVarint, 2: wireVarint, 3: wireFixed64}, func(f protoField) error {
+		switch f.num {
+		case 1:
+			m.From = protoInt64(f.v)
+		case 2:
+			m.To = protoInt64(f.v)
+		case 3:
+			m.Weight = protoDouble(f.float64())
+		}
+		return nil
+	})
+}
+
+func (m *edgeList) appendProto(b []byte) []byte {
+	for i := range m.Edges {
+		b = appendMessageField(b, 1, &m.Edges[i])
+	}
+	return appendPackedField(b, 2, m.ExtraNodes)
+}
+
+func (m *edgeList) unmarshalProto(data []byte) error {
+	return parseFields(data, map[int]int{1: wireBytes, 2: wirePacked}, func(f protoField) (err error) {
+		if f.num == 1 {
+			var e rpcEdge
+			err = e.unmarshalProto(f.data)
+			m.Edges = append(m.Edges, e)
+		} else {
+			m.ExtraNodes, err = f.appendPacked(m.ExtraNodes)
+		}
+		return err
+	})
+}
+
+func (m *loadGraphRequest) appendProto(b []byte) []byte {
+	if m.Edges != nil {
+		b = appendMessageField(b, 1, m.Edges)
+	}
+	if m.Binary != nil {
+		b = appendBytesField(b, 2, m.Binary)
+	}
+	return b
+}
+
+// unmarshalProto keeps the last member of the oneof set, as protobuf
+// decoders do.
+func (m *loadGraphRequest) unmarshalProto(data []byte) error {
+	return parseFields(data, map[int]int{1: wireBytes, 2: wireBytes}, func(f protoField) error {
+		switch f.num {
+		case 1:
+			m.Edges, m.Binary = &edgeList{}, nil
+			return m.Edges.unmarshalProto(f.data)
+		case 2:
+			m.Edges, m.Binary = nil, append([]byte{}, f.data...)
+		}
+		return nil
+	})
+}
+
+func (m *loadGraphResponse) appendProto(b []byte) []byte {
+	b = appendVarintField(b, 1, uint64(m.Nodes))
+	return appendVarintField(b, 2, uint64(m.Edges))
+}
+
+func (m *loadGraphResponse) unmarshalProto(data []byte) error {
+	return parseFields(data, map[int]int{1: wireVarint, 2: wireVarint}, func(f protoField) error {
+		if f.num == 1 {
+			m.Nodes = protoInt64(f.v)
+		} else {
+			m.Edges = protoInt64(f.v)
+		}
+		return nil
+	})
+}
+
+func (m *shortestPathRequest) appendProto(b []byte) []byte {
+	b = appendVarintField(b, 1, uint64(m.From))
+	return appendVarintField(b, 2, uint64(m.To))
+}
+
+func (m *shortestPathRequest) unmarshalProto(data []byte) error {
+	return parseFields(data, map[int]int{1: wireVarint, 2: wireVarint}, func(f protoField) error {
+		if f.num == 1 {
+			m.From = protoInt64(f.v)
+		} else {
+			m.To = protoInt64(f.v)
+		}
+		return nil
+	})
+}
+
+func (m *shortestPathResponse) appendProto(b []byte) []byte {
+	b = appendPackedField(b, 1, m.Path)
+	b = appendDoubleField(b, 2, float64(m.Cost))
+	return appendVarintField(b, 3, uint64(m.Stopped))
+}
+
+func (m *shortestPathResponse) unmarshalProto(data []byte) error {
+	m.Path = []protoInt64{}
+	return parseFields(data, map[int]int{1: wirePacked, 2: wireFixed64, 3: wireVarint}, func(f protoField) (err error) {
+		switch f.num {
+		case 1:
+			m.Path, err = f.appendPacked(m.Path)
+		case 2:
+			m.Cost = protoDouble(f.float64())
+		case 3:
+			m.Stopped = stopReason(f.v)
+		}
+		return err
+	})
+}
+
+func (m *multiSourceRequest) appendProto(b []byte) []byte {
+	b = appendPackedField(b, 1, m.Sources)
+	if m.Bound != nil {
+		// Explicit presence: written even when zero
+		b = binary.LittleEndian.AppendUint64(appendTag(b, 2, wireFixed64), math.Float64bits(float64(*m.Bound)))
+	}
+	return appendVarintField(b, 3, uint64(int64(m.BatchSize)))
+}
+
+func (m *multiSourceRequest) unmarshalProto(data []byte) error {
+	return parseFields(data, map[int]int{1: wirePacked, 2: wireFixed64, 3: wireVarint}, func(f protoField) (err error) {
+		switch f.num {
+		case 1:
+			m.Sources, err = f.appendPacked(m.Sources)
+		case 2:
+			bound := protoDouble(f.float64())
+			m.Bound = &bound
+		case 3:
+			m.BatchSize = int32(f.v)
+		}
+		return err
+	})
+}
+
+func (m *nodeDistance) appendProto(b []byte) []byte {
+	b = appendVarintField(b, 1, uint64(m.Node))
+	return appendDoubleField(b, 2, float64(m.Dist))
+}
+
+func (m *nodeDistance) unmarshalProto(data []byte) error {
+	return parseFields(data, map[int]int{1: wireVarint, 2: wireFixed64}, func(f protoField) error {
+		if f.num == 1 {
+			m.Node = protoInt64(f.v)
+		} else {
+			m.Dist = protoDouble(f.float64())
+		}
+		return nil
+	})
+}
+
+// appendDistances appends repeated NodeDistance field 1.
+func appendDistances(b []byte, ds []nodeDistance) []byte {
+	for i := range ds {
+		b = appendMessageField(b, 1, &ds[i])
+	}
+	return b
+}
+
+// appendDistance decodes a NodeDistance field and appends it to ds.
+func appendDistance(ds []nodeDistance, f protoField) ([]nodeDistance, error) {
+	var d nodeDistance
+	err := d.unmarshalProto(f.data)
+	return append(ds, d), err
+}
+
+func (m *multiSourceResponse) appendProto(b []byte) []byte {
+	b = appendDistances(b, m.Distances)
+	return appendVarintField(b, 2, uint64(m.Stopped))
+}
+
+func (m *multiSourceResponse) unmarshalProto(data []byte) error {
+	m.Distances = []nodeDistance{}
+	return parseFields(data, map[int]int{1: wireBytes, 2: wireVarint}, func(f protoField) (err error) {
+		if f.num == 1 {
+			m.Distances, err = appendDistance(m.Distances, f)
+		} else {
+			m.Stopped = stopReason(f.v)
+		}
+		return err
+	})
+}
+
+func (m *distanceBatch) appendProto(b []byte) []byte {
+	b = appendDistances(b, m.Distances)
+	if m.Done {
+		b = appendVarintField(b, 2, 1)
+	}
+	return appendVarintField(b, 3, uint64(m.Stopped))
+}
+
+func (m *distanceBatch) unmarshalProto(data []byte) error {
+	m.Distances = []nodeDistance{}
+	return parseFields(data, map[int]int{1: wireBytes, 2: wireVarint, 3: wireVarint}, func(f protoField) (err error) {
+		switch f.num {
+		case 1:
+			m.Distances, err = appendDistance(m.Distances, f)
+		case 2:
+			m.Done = f.v != 0
+		case 3:
+			m.Stopped = stopReason(f.v)
+		}
+		return err
+	})
+}
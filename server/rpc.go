@@ -0,0 +1,564 @@
+package server
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mfreeman451/bmssp-go"
+)
+
+// The BMSSP service of proto/bmssp/v1 is served over two protocols without
+// generated code or an RPC runtime in this module: gRPC with the protobuf
+// codec (see grpc.go), and the Connect protocol with its JSON codec, for
+// clients that only speak HTTP/1.1 and JSON:
+//
+//	POST /bmssp.v1.BMSSP/LoadGraph        application/json
+//	POST /bmssp.v1.BMSSP/ShortestPath     application/json
+//	POST /bmssp.v1.BMSSP/MultiSource      application/json
+//	POST /bmssp.v1.BMSSP/DistanceUpdates  application/connect+json
+//
+// Unary Connect calls exchange one JSON message each way. DistanceUpdates
+// frames its request and every DistanceBatch in Connect envelopes: a flags
+// byte, the big-endian message length and the message; the last envelope
+// ends the stream and carries the error, if any. Messages use the proto3
+// JSON mapping: lowerCamelCase fields, int64 as decimal strings and +Inf as
+// "Infinity".
+
+// rpcPrefix is the path of the service's methods.
+const rpcPrefix = "/bmssp.v1.BMSSP/"
+
+// defaultBatchSize is the number of distances per DistanceBatch when the
+// request leaves batch_size unset.
+const defaultBatchSize = 4096
+
+// Envelope flags of the Connect streaming protocol.
+const (
+	flagCompressed = 0x01
+	flagEndStream  = 0x02
+)
+
+// registerRPC adds the service's methods to the server's mux.
+func (s *Server) registerRPC() {
+	s.mux.HandleFunc("POST "+rpcPrefix+"LoadGraph", unary(s, s.rpcLoadGraph))
+	s.mux.HandleFunc("POST "+rpcPrefix+"ShortestPath", unary(s, s.rpcShortestPath))
+	s.mux.HandleFunc("POST "+rpcPrefix+"MultiSource", unary(s, s.rpcMultiSource))
+	s.mux.HandleFunc("POST "+rpcPrefix+"DistanceUpdates", s.rpcDistanceUpdates)
+}
+
+// protoInt64 is an int64 in the proto3 JSON mapping: written as a decimal
+// string and read from a string or a number.
+type protoInt64 int64
+
+func (v protoInt64) MarshalJSON() ([]byte, error) {
+	return strconv.AppendQuote(nil, strconv.FormatInt(int64(v), 10)), nil
+}
+
+func (v *protoInt64) UnmarshalJSON(data []byte) error {
+	text, err := unquote(data)
+	if err != nil {
+		return err
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return fmt.Errorf("expected an integer, got %s", data)
+	}
+	*v = protoInt64(n)
+	return nil
+}
+
+// protoDouble is a double in the proto3 JSON mapping, where infinities and
+// NaN are the strings "Infinity", "-Infinity" and "NaN".
+type protoDouble float64
+
+func (v protoDouble) MarshalJSON() ([]byte, error) {
+	switch f := float64(v); {
+	case math.IsInf(f, 1):
+		return []byte(`"Infinity"`), nil
+	case math.IsInf(f, -1):
+		return []byte(`"-Infinity"`), nil
+	case math.IsNaN(f):
+		return []byte(`"NaN"`), nil
+	default:
+		return strconv.AppendFloat(nil, f, 'g', -1, 64), nil
+	}
+}
+
+func (v *protoDouble) UnmarshalJSON(data []byte) error {
+	text, err := unquote(data)
+	if err != nil {
+		return err
+	}
+	var f float64
+	switch text {
+	case "Infinity":
+		f = math.Inf(1)
+	case "-Infinity":
+		f = math.Inf(-1)
+	case "NaN":
+		f = math.NaN()
+	default:
+		if f, err = strconv.ParseFloat(text, 64); err != nil || math.IsInf(f, 0) {
+			return fmt.Errorf("expected a number, got %s", data)
+		}
+	}
+	*v = protoDouble(f)
+	return nil
+}
+
+// unquote returns the text of a JSON string or the literal of a number.
+func unquote(data []byte) (string, error) {
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		err := json.Unmarshal(data, &text)
+		return text, err
+	}
+	return string(data), nil
+}
+
+// stopReason is a bmssp.StopReason in the proto3 JSON mapping, e.g.
+// "STOP_REASON_NODE_BUDGET".
+type stopReason bmssp.StopReason
+
+func (r stopReason) MarshalJSON() ([]byte, error) {
+	name := "STOP_REASON_" + strings.ToUpper(strings.ReplaceAll(bmssp.StopReason(r).String(), " ", "_"))
+	return strconv.AppendQuote(nil, name), nil
+}
+
+func (r *stopReason) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		var n int
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("expected a stop reason, got %s", data)
+		}
+		*r = stopReason(n)
+		return nil
+	}
+	for v := bmssp.StopComplete; v <= bmssp.StopCanceled; v++ {
+		if text, _ := stopReason(v).MarshalJSON(); string(text) == strconv.Quote(name) {
+			*r = stopReason(v)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown stop reason %q", name)
+}
+
+// The messages of proto/bmssp/v1/bmssp.proto.
+type (
+	rpcEdge struct {
+		From   protoInt64  `json:"from"`
+		To     protoInt64  `json:"to"`
+		Weight protoDouble `json:"weight"`
+	}
+
+	edgeList struct {
+		Edges      []rpcEdge    `json:"edges"`
+		ExtraNodes []protoInt64 `json:"extraNodes"`
+	}
+
+	loadGraphRequest struct {
+		Edges  *edgeList `json:"edges,omitempty"`
+		Binary []byte    `json:"binary,omitempty"`
+	}
+
+	loadGraphResponse struct {
+		Nodes protoInt64 `json:"nodes"`
+		Edges protoInt64 `json:"edges"`
+	}
+
+	shortestPathRequest struct {
+		From protoInt64 `json:"from"`
+		To   protoInt64 `json:"to"`
+	}
+
+	shortestPathResponse struct {
+		Path    []protoInt64 `json:"path"`
+		Cost    protoDouble  `json:"cost"`
+		Stopped stopReason   `json:"stopped"`
+	}
+
+	multiSourceRequest struct {
+		Sources   []protoInt64 `json:"sources"`
+		Bound     *protoDouble `json:"bound,omitempty"`
+		BatchSize int32        `json:"batchSize"`
+	}
+
+	nodeDistance struct {
+		Node protoInt64  `json:"node"`
+		Dist protoDouble `json:"dist"`
+	}
+
+	multiSourceResponse struct {
+		Distances []nodeDistance `json:"distances"`
+		Stopped   stopReason     `json:"stopped"`
+	}
+
+	distanceBatch struct {
+		Distances []nodeDistance `json:"distances"`
+		Done      bool           `json:"done"`
+		Stopped   stopReason     `json:"stopped"`
+	}
+)
+
+// rpcError is a failed call: a Connect error code and a message.
+type rpcError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// rpcStatus maps the Connect error codes used here to the HTTP status of a
+// failed unary call.
+var rpcStatus = map[string]int{
+	"invalid_argument":   http.StatusBadRequest,
+	"not_found":          http.StatusNotFound,
+	"resource_exhausted": http.StatusTooManyRequests,
+	"unimplemented":      http.StatusNotImplemented,
+	"unavailable":        http.StatusServiceUnavailable,
+}
+
+// toRPCError maps query errors to Connect error codes, as writeError maps
+// them to HTTP status codes.
+func toRPCError(err error) *rpcError {
+	var re *rpcError
+	if errors.As(err, &re) {
+		return re
+	}
+	code := "invalid_argument"
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, bmssp.ErrNodeNotFound):
+		code = "not_found"
+	case errors.Is(err, errNoGraph):
+		code = "unavailable"
+	case errors.As(err, &tooLarge):
+		code = "resource_exhausted"
+	}
+	return &rpcError{Code: code, Message: err.Error()}
+}
+
+// rpcRequest applies the call's Connect-Timeout-Ms header to r's context
+// and rejects compressed messages. Call cancel when the call has finished.
+func rpcRequest(r *http.Request, encodingHeader string) (_ *http.Request, cancel context.CancelFunc, err error) {
+	cancel = func() {}
+	if enc := r.Header.Get(encodingHeader); enc != "" && enc != "identity" {
+		return r, cancel, &rpcError{Code: "unimplemented", Message: fmt.Sprintf("unsupported encoding %q", enc)}
+	}
+	if v := r.Header.Get("Connect-Timeout-Ms"); v != "" {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || ms <= 0 || len(v) > 10 {
+			return r, cancel, &rpcError{Code: "invalid_argument", Message: fmt.Sprintf("invalid Connect-Timeout-Ms %q", v)}
+		}
+		ctx, c := context.WithTimeout(r.Context(), time.Duration(ms)*time.Millisecond)
+		return r.WithContext(ctx), c, nil
+	}
+	return r, cancel, nil
+}
+
+// unary serves a unary method: it decodes the JSON request, calls fn and
+// writes the JSON response, or the error with its HTTP status. gRPC calls
+// exchange protobuf messages instead.
+func unary[Req, Resp any, PReq interface {
+	*Req
+	protoMessage
+}, PResp interface {
+	*Resp
+	protoMessage
+}](s *Server, fn func(r *http.Request, req *Req) (*Resp, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isGRPC(r) {
+			s.grpcCall(w, r, func(r *http.Request, msg []byte, send func(protoMessage) error) error {
+				var req Req
+				if err := PReq(&req).unmarshalProto(msg); err != nil {
+					return fmt.Errorf("decoding request: %w", err)
+				}
+				resp, err := fn(r, &req)
+				if err != nil {
+					return err
+				}
+				return send(PResp(resp))
+			})
+			return
+		}
+		if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != "application/json" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		resp, err := func() (*Resp, error) {
+			r, cancel, err := rpcRequest(r, "Content-Encoding")
+			if err != nil {
+				return nil, err
+			}
+			defer cancel()
+			var req Req
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.MaxGraphBytes)).Decode(&req); err != nil {
+				return nil, fmt.Errorf("decoding request: %w", err)
+			}
+			return fn(r, &req)
+		}()
+		if err != nil {
+			re := toRPCError(err)
+			writeJSON(w, cmp.Or(rpcStatus[re.Code], http.StatusInternalServerError), re)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// nodeSet converts the node IDs of a request.
+func nodeSet(ids []protoInt64) bmssp.NodeSet {
+	S := bmssp.NewNodeSet()
+	for _, v := range ids {
+		S.Add(bmssp.NodeID(v))
+	}
+	return S
+}
+
+// rpcLoadGraph replaces the graph with an edge list or a graph in binary
+// format.
+func (s *Server) rpcLoadGraph(_ *http.Request, req *loadGraphRequest) (*loadGraphResponse, error) {
+	var g *bmssp.Graph
+	switch {
+	case req.Edges != nil && req.Binary != nil:
+		return nil, errors.New("graph: expected either edges or binary, got both")
+	case req.Edges != nil:
+		g = bmssp.NewGraph()
+		for _, e := range req.Edges.Edges {
+			g.AddEdge(bmssp.NodeID(e.From), bmssp.NodeID(e.To), bmssp.Dist(e.Weight))
+		}
+		for _, v := range req.Edges.ExtraNodes {
+			g.AddNode(bmssp.NodeID(v))
+		}
+	case req.Binary != nil:
+		var err error
+		if g, err = bmssp.ReadGraph(bytes.NewReader(req.Binary)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("graph: expected edges or binary")
+	}
+	if err := s.SetGraph(g); err != nil {
+		return nil, err
+	}
+	return &loadGraphResponse{Nodes: protoInt64(g.NumNodes()), Edges: protoInt64(g.NumEdges())}, nil
+}
+
+// rpcShortestPath answers like GET /route.
+func (s *Server) rpcShortestPath(r *http.Request, req *shortestPathRequest) (*shortestPathResponse, error) {
+	g, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	from, to := bmssp.NodeID(req.From), bmssp.NodeID(req.To)
+
+	if err := checkNodes(g, from, to); err != nil {
+		return nil, err
+	}
+	opts, cancel := s.queryOptions(r, s.RouteTimeout)
+	defer cancel()
+	S, targets := bmssp.NewNodeSet(), bmssp.NewNodeSet()
+	S.Add(from)
+	targets.Add(to)
+	res := bmssp.Solve(g, S, bmssp.INF, append(opts, bmssp.WithTargets(targets))...)
+
+	resp := &shortestPathResponse{Path: []protoInt64{}, Cost: protoDouble(bmssp.INF), Stopped: stopReason(res.Stopped)}
+	if path := res.PathTo(to); path != nil {
+		for _, v := range path {
+			resp.Path = append(resp.Path, protoInt64(v))
+		}
+		resp.Cost = protoDouble(res.Dist[to])
+	}
+	return resp, nil
+}
+
+// multiSourceQuery returns the sources and bound of a MultiSource or
+// DistanceUpdates request.
+func multiSourceQuery(req *multiSourceRequest) (bmssp.NodeSet, bmssp.Dist, error) {
+	if len(req.Sources) == 0 {
+		return nil, 0, errors.New("sources: expected at least one node")
+	}
+	B := bmssp.INF
+	if req.Bound != nil {
+		B = bmssp.Dist(*req.Bound)
+	}
+	if !(B >= 0) {
+		return nil, 0, fmt.Errorf("bound: expected a non-negative number, got %v", B)
+	}
+	return nodeSet(req.Sources), B, nil
+}
+
+// rpcMultiSource returns the distances of the nodes within the bound, by
+// node ID.
+func (s *Server) rpcMultiSource(r *http.Request, req *multiSourceRequest) (*multiSourceResponse, error) {
+	g, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	S, B, err := multiSourceQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	opts, cancel := s.queryOptions(r, s.IsochroneTimeout)
+	defer cancel()
+	res, err := bmssp.SolveChecked(g, S, B, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &multiSourceResponse{Distances: []nodeDistance{}, Stopped: stopReason(res.Stopped)}
+	for v, d := range res.Dist {
+		if d < bmssp.INF && d <= B {
+			resp.Distances = append(resp.Distances, nodeDistance{Node: protoInt64(v), Dist: protoDouble(d)})
+		}
+	}
+	slices.SortFunc(resp.Distances, func(a, b nodeDistance) int { return cmp.Compare(a.Node, b.Node) })
+	return resp, nil
+}
+
+// rpcDistanceUpdates streams the distances of the nodes within the bound in
+// settled order, batch_size at a time. The last batch has done set and the
+// stop reason; a client that cancels the call stops the search.
+func (s *Server) rpcDistanceUpdates(w http.ResponseWriter, r *http.Request) {
+	if isGRPC(r) {
+		s.grpcCall(w, r, func(r *http.Request, msg []byte, send func(protoMessage) error) error {
+			var req multiSourceRequest
+			if err := req.unmarshalProto(msg); err != nil {
+				return fmt.Errorf("decoding request: %w", err)
+			}
+			return s.streamDistances(r, &req, func(batch distanceBatch) error { return send(&batch) })
+		})
+		return
+	}
+	if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != "application/connect+json" {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/connect+json")
+	rc := http.NewResponseController(w)
+	send := func(flags byte, msg any) error {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(binary.BigEndian.AppendUint32([]byte{flags}, uint32(len(data)))); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	err := func() error {
+		r, cancel, err := rpcRequest(r, "Connect-Content-Encoding")
+		if err != nil {
+			return err
+		}
+		defer cancel()
+		var req multiSourceRequest
+		if err := readEnvelope(r.Body, s.MaxGraphBytes, &req); err != nil {
+			return err
+		}
+		return s.streamDistances(r, &req, func(batch distanceBatch) error { return send(0, batch) })
+	}()
+	end := struct {
+		Error *rpcError `json:"error,omitempty"`
+	}{}
+	if err != nil {
+		end.Error = toRPCError(err)
+	}
+	_ = send(flagEndStream, end)
+}
+
+// streamDistances runs a DistanceUpdates call, passing every batch to send.
+func (s *Server) streamDistances(r *http.Request, req *multiSourceRequest, send func(distanceBatch) error) error {
+	g, err := s.snapshot()
+	if err != nil {
+		return err
+	}
+	S, B, err := multiSourceQuery(req)
+	if err != nil {
+		return err
+	}
+	size := int(req.BatchSize)
+	switch {
+	case size < 0:
+		return fmt.Errorf("batch_size: expected a non-negative number, got %d", size)
+	case size == 0:
+		size = defaultBatchSize
+	}
+	for v := range S {
+		if err := checkNodes(g, v); err != nil {
+			return err
+		}
+	}
+
+	opts, cancelQuery := s.queryOptions(r, s.IsochroneTimeout)
+	defer cancelQuery()
+	batch := make([]nodeDistance, 0, size)
+	var sendErr error
+	stopped := bmssp.StreamDistances(g, S, B, func(v bmssp.NodeID, d bmssp.Dist) bool {
+		batch = append(batch, nodeDistance{Node: protoInt64(v), Dist: protoDouble(d)})
+		if len(batch) < size {
+			return true
+		}
+		sendErr = send(distanceBatch{Distances: batch})
+		batch = batch[:0]
+		return sendErr == nil
+	}, opts...)
+	if sendErr != nil {
+		return sendErr
+	}
+	return send(distanceBatch{Distances: batch, Done: true, Stopped: stopReason(stopped)})
+}
+
+// readEnvelope reads a single uncompressed Connect envelope of at most max
+// bytes and decodes its JSON message into v.
+func readEnvelope(r io.Reader, max int64, v any) error {
+	data, err := readMessage(r, max)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decoding request: %w", err)
+	}
+	return nil
+}
+
+// readMessage reads a single uncompressed message of at most max bytes in
+// a Connect envelope or, with the same layout, gRPC length-prefixed
+// framing.
+func readMessage(r io.Reader, max int64) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, fmt.Errorf("reading request envelope: %w", err)
+	}
+	if prefix[0]&flagCompressed != 0 {
+		return nil, &rpcError{Code: "unimplemented", Message: "compressed messages are not supported"}
+	}
+	n := binary.BigEndian.Uint32(prefix[1:])
+	if int64(n) > max {
+		return nil, &rpcError{Code: "resource_exhausted", Message: fmt.Sprintf("request of %d bytes exceeds the limit of %d", n, max)}
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("reading request: %w", err)
+	}
+	return data, nil
+}
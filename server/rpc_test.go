@@ -0,0 +1,218 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mfreeman451/bmssp-go"
+)
+
+// call makes a unary RPC and decodes the response into v.
+func call(t *testing.T, h http.Handler, method, body string, v any) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, rpcPrefix+method, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(rec, req)
+	if v != nil && rec.Code != http.StatusUnsupportedMediaType {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s: decoding %q: %v", method, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+// envelope frames msg as a Connect streaming message.
+func envelope(flags byte, msg string) []byte {
+	return append(binary.BigEndian.AppendUint32([]byte{flags}, uint32(len(msg))), msg...)
+}
+
+// streamResult is a decoded DistanceUpdates response.
+type streamResult struct {
+	batches []distanceBatch
+	err     *rpcError
+}
+
+// readStream decodes the envelopes of a DistanceUpdates response.
+func readStream(t *testing.T, r io.Reader) streamResult {
+	t.Helper()
+	var res streamResult
+	for {
+		var prefix [5]byte
+		if _, err := io.ReadFull(r, prefix[:]); err != nil {
+			t.Fatalf("reading envelope: %v", err)
+		}
+		data := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+		if _, err := io.ReadFull(r, data); err != nil {
+			t.Fatalf("reading message: %v", err)
+		}
+		if prefix[0]&flagEndStream != 0 {
+			var end struct {
+				Error *rpcError `json:"error"`
+			}
+			if err := json.Unmarshal(data, &end); err != nil {
+				t.Fatalf("decoding end of stream %q: %v", data, err)
+			}
+			res.err = end.Error
+			return res
+		}
+		var batch distanceBatch
+		if err := json.Unmarshal(data, &batch); err != nil {
+			t.Fatalf("decoding batch %q: %v", data, err)
+		}
+		res.batches = append(res.batches, batch)
+	}
+}
+
+func TestRPC_Unary(t *testing.T) {
+	s := New(nil)
+	var e rpcError
+	if code := call(t, s, "ShortestPath", `{"from":"0","to":"1"}`, &e); code != http.StatusServiceUnavailable || e.Code != "unavailable" {
+		t.Errorf("expected unavailable without a graph, got %d %+v", code, e)
+	}
+
+	var loaded loadGraphResponse
+	body := `{"edges":{"edges":[{"from":"0","to":"1","weight":2},{"from":1,"to":2,"weight":3},{"from":"0","to":"2","weight":10}],"extraNodes":["7"]}}`
+	if code := call(t, s, "LoadGraph", body, &loaded); code != http.StatusOK || loaded.Nodes != 4 || loaded.Edges != 3 {
+		t.Fatalf("expected 4 nodes and 3 edges loaded, got %d %+v", code, loaded)
+	}
+	for _, body := range []string{`{}`, `{"edges":{"edges":[{"from":"0","to":"1","weight":-1}]}}`, `{"edges":{},"binary":"AA=="}`, `{"binary":"AA=="}`} {
+		if code := call(t, s, "LoadGraph", body, &e); code != http.StatusBadRequest || e.Code != "invalid_argument" {
+			t.Errorf("%s: expected invalid_argument, got %d %+v", body, code, e)
+		}
+	}
+
+	var path shortestPathResponse
+	if code := call(t, s, "ShortestPath", `{"from":"0","to":"2"}`, &path); code != http.StatusOK || path.Cost != 5 || !slices.Equal(path.Path, []protoInt64{0, 1, 2}) {
+		t.Errorf("expected the path [0 1 2] of cost 5, got %d %+v", code, path)
+	}
+	path = shortestPathResponse{}
+	if call(t, s, "ShortestPath", `{"from":"2","to":"7"}`, &path); !math.IsInf(float64(path.Cost), 1) || len(path.Path) != 0 {
+		t.Errorf("expected an unreachable node at Infinity, got %+v", path)
+	}
+	if code := call(t, s, "ShortestPath", `{"from":"0","to":"9"}`, &e); code != http.StatusNotFound || e.Code != "not_found" {
+		t.Errorf("expected not_found for an unknown node, got %d %+v", code, e)
+	}
+
+	var multi multiSourceResponse
+	want := []nodeDistance{{Node: 0, Dist: 0}, {Node: 1, Dist: 2}, {Node: 7, Dist: 0}}
+	if code := call(t, s, "MultiSource", `{"sources":["0","7"],"bound":4}`, &multi); code != http.StatusOK || !slices.Equal(multi.Distances, want) {
+		t.Errorf("expected %v, got %d %+v", want, code, multi)
+	}
+	for _, body := range []string{`{"sources":[]}`, `{"sources":["0"],"bound":-1}`, `{"sources":["0"],"bound":"x"}`} {
+		if code := call(t, s, "MultiSource", body, &e); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d %+v", body, code, e)
+		}
+	}
+
+	// The wire format follows the proto3 JSON mapping
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, rpcPrefix+"ShortestPath", strings.NewReader(`{"from":"2","to":"0"}`))
+	req.Header.Set("Content-Type", "application/json")
+	s.ServeHTTP(rec, req)
+	if got := strings.TrimSpace(rec.Body.String()); got != `{"path":[],"cost":"Infinity","stopped":"STOP_REASON_COMPLETE"}` {
+		t.Errorf("unexpected encoding %s", got)
+	}
+
+	// A binary graph replaces the edge list
+	g := bmssp.NewGraph()
+	g.AddEdge(0, 1, 7)
+	var buf bytes.Buffer
+	g.WriteTo(&buf)
+	data, _ := json.Marshal(loadGraphRequest{Binary: buf.Bytes()})
+	if code := call(t, s, "LoadGraph", string(data), &loaded); code != http.StatusOK || loaded.Nodes != 2 || loaded.Edges != 1 {
+		t.Errorf("expected the binary graph to load, got %d %+v", code, loaded)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, rpcPrefix+"ShortestPath", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/proto")
+	if s.ServeHTTP(rec, req); rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415 for the binary codec, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, rpcPrefix+"ShortestPath", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Connect-Timeout-Ms", "soon")
+	if s.ServeHTTP(rec, req); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a malformed timeout, got %d", rec.Code)
+	}
+}
+
+func TestRPC_DistanceUpdates(t *testing.T) {
+	g := bmssp.NewGraph()
+	for i := range bmssp.NodeID(10) {
+		g.AddEdge(i, i+1, 1)
+	}
+	ts := httptest.NewServer(New(g))
+	defer ts.Close()
+	stream := func(ctx context.Context, msg string) streamResult {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, ts.URL+rpcPrefix+"DistanceUpdates", bytes.NewReader(envelope(0, msg)))
+		req.Header.Set("Content-Type", "application/connect+json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+		return readStream(t, resp.Body)
+	}
+
+	res := stream(context.Background(), `{"sources":["0"],"bound":4.5,"batchSize":2}`)
+	if res.err != nil || len(res.batches) != 3 {
+		t.Fatalf("expected 3 batches, got %+v", res)
+	}
+	var got []nodeDistance
+	for i, b := range res.batches {
+		if b.Done != (i == len(res.batches)-1) {
+			t.Errorf("batch %d: done = %v", i, b.Done)
+		}
+		got = append(got, b.Distances...)
+	}
+	for i, d := range got {
+		if d.Node != protoInt64(i) || d.Dist != protoDouble(i) {
+			t.Fatalf("expected nodes 0-4 in distance order, got %v", got)
+		}
+	}
+	if len(got) != 5 || res.batches[2].Stopped != stopReason(bmssp.StopComplete) {
+		t.Errorf("expected 5 nodes and a complete search, got %v (%v)", got, res.batches[2].Stopped)
+	}
+
+	// Errors end the stream
+	if res := stream(context.Background(), `{"sources":["42"]}`); res.err == nil || res.err.Code != "not_found" || len(res.batches) != 0 {
+		t.Errorf("expected not_found, got %+v", res)
+	}
+	if res := stream(context.Background(), `{"sources":["0"],"batchSize":-1}`); res.err == nil || res.err.Code != "invalid_argument" {
+		t.Errorf("expected invalid_argument for a negative batch size, got %+v", res)
+	}
+
+	// A call canceled by its deadline stops the search
+	s := New(g)
+	s.IsochroneTimeout = 1
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, rpcPrefix+"DistanceUpdates", bytes.NewReader(envelope(0, `{"sources":["0"]}`)))
+	req.Header.Set("Content-Type", "application/connect+json")
+	s.ServeHTTP(rec, req)
+	res = readStream(t, rec.Body)
+	if last := res.batches[len(res.batches)-1]; !last.Done || last.Stopped != stopReason(bmssp.StopDeadline) {
+		t.Errorf("expected a search stopped by the deadline, got %+v", last)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, rpcPrefix+"DistanceUpdates", bytes.NewReader(envelope(flagCompressed, `{}`)))
+	req.Header.Set("Content-Type", "application/connect+json")
+	if s.ServeHTTP(rec, req); readStream(t, rec.Body).err.Code != "unimplemented" {
+		t.Errorf("expected compressed requests to be rejected")
+	}
+}
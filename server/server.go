@@ -0,0 +1,290 @@
+// Package server exposes shortest-path queries over a graph as a small HTTP
+// service.
+//
+// Endpoints:
+//
+//	POST /graph                     replace the graph (node-link JSON, CSV or binary)
+//	GET  /route?from=&to=           shortest path between two nodes
+//	GET  /isochrone?source=&bound=  nodes within a bound of a source
+//	POST /bmssp.v1.BMSSP/<method>   the RPC service of proto/bmssp/v1 over gRPC
+//	                                or Connect JSON; see rpc.go
+//
+// Queries run concurrently against an immutable snapshot of the graph; a
+// POST /graph swaps in a new snapshot without blocking running queries.
+// A query that runs out of time, by the endpoint's timeout or the request's
+// context, answers with what it found so far and "approximate": true.
+package server
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/mfreeman451/bmssp-go"
+)
+
+// DefaultMaxGraphBytes is the default limit on the size of an uploaded graph.
+const DefaultMaxGraphBytes = 256 << 20
+
+// Server answers shortest-path queries over HTTP. Create it with New.
+type Server struct {
+	graph atomic.Pointer[bmssp.Graph] // current snapshot; never mutated
+	opts  []bmssp.Option
+	mux   *http.ServeMux
+
+	// MaxGraphBytes limits the size of an uploaded graph (default
+	// DefaultMaxGraphBytes).
+	MaxGraphBytes int64
+
+	// RouteTimeout and IsochroneTimeout limit the search time of the
+	// endpoints' queries; 0 leaves only the request's own deadline.
+	RouteTimeout     time.Duration
+	IsochroneTimeout time.Duration
+}
+
+// New creates a server over g, which may be nil until a graph is posted. The
+// server freezes g. opts are applied to every query.
+func New(g *bmssp.Graph, opts ...bmssp.Option) *Server {
+	s := &Server{opts: opts, mux: http.NewServeMux(), MaxGraphBytes: DefaultMaxGraphBytes}
+	if g != nil {
+		g.Freeze()
+		s.graph.Store(g)
+	}
+	s.mux.HandleFunc("POST /graph", s.postGraph)
+	s.mux.HandleFunc("GET /route", s.route)
+	s.mux.HandleFunc("GET /isochrone", s.isochrone)
+	s.registerRPC()
+	return s
+}
+
+// SetGraph replaces the graph served and freezes it. Running queries finish
+// on the previous graph.
+//
+// Returns:
+//   - an error from g.Validate if the graph is empty or has invalid weights
+func (s *Server) SetGraph(g *bmssp.Graph) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+	g.Freeze()
+	s.graph.Store(g)
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// errorResponse is the body of every failed request.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError maps query errors to HTTP status codes.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, bmssp.ErrNodeNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, errNoGraph):
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+var errNoGraph = errors.New("no graph loaded")
+
+// snapshot returns the current graph.
+func (s *Server) snapshot() (*bmssp.Graph, error) {
+	g := s.graph.Load()
+	if g == nil {
+		return nil, errNoGraph
+	}
+	return g, nil
+}
+
+// queryOptions returns the server's query options plus a stop once the
+// request is done or timeout, if positive, has passed. Call cancel when the
+// query has finished.
+func (s *Server) queryOptions(r *http.Request, timeout time.Duration) (opts []bmssp.Option, cancel context.CancelFunc) {
+	ctx, cancel := r.Context(), context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	return append(slices.Clip(s.opts), bmssp.WithContext(ctx)), cancel
+}
+
+// nodeParam parses a node ID query parameter.
+func nodeParam(r *http.Request, name string) (bmssp.NodeID, error) {
+	v, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil {
+		return 0, fmt.Errorf("parameter %s: expected a node ID, got %q", name, r.URL.Query().Get(name))
+	}
+	return bmssp.NodeID(v), nil
+}
+
+// checkNodes returns bmssp.ErrNodeNotFound for the first of nodes missing
+// from g.
+func checkNodes(g *bmssp.Graph, nodes ...bmssp.NodeID) error {
+	for _, v := range nodes {
+		if !g.HasNode(v) {
+			return fmt.Errorf("%w: %d", bmssp.ErrNodeNotFound, v)
+		}
+	}
+	return nil
+}
+
+// postGraph replaces the graph with the request body, read according to its
+// content type: application/json (node-link JSON, the default), text/csv
+// (edge list) or application/octet-stream (binary format).
+func (s *Server) postGraph(w http.ResponseWriter, r *http.Request) {
+	body := http.MaxBytesReader(w, r.Body, s.MaxGraphBytes)
+
+	var g *bmssp.Graph
+	var err error
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch ct {
+	case "", "application/json":
+		g = bmssp.NewGraph()
+		var data []byte
+		if data, err = io.ReadAll(body); err == nil {
+			err = g.UnmarshalJSON(data)
+		}
+	case "text/csv":
+		g, err = bmssp.ReadEdgeListCSV(body, bmssp.CSVOptions{})
+	case "application/octet-stream":
+		g, err = bmssp.ReadGraph(body)
+	default:
+		writeJSON(w, http.StatusUnsupportedMediaType, errorResponse{Error: fmt.Sprintf("unsupported content type %q", ct)})
+		return
+	}
+	if err == nil {
+		err = s.SetGraph(g)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// routeResponse is the body of GET /route.
+type routeResponse struct {
+	Path []bmssp.NodeID `json:"path"`
+	Cost *bmssp.Dist    `json:"cost"` // null if unreachable
+
+	// Approximate is set when the search ran out of time: the path may not
+	// be shortest, or be missing although the target is reachable.
+	Approximate bool `json:"approximate"`
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	g, err := s.snapshot()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	from, err := nodeParam(r, "from")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	to, err := nodeParam(r, "to")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := checkNodes(g, from, to); err != nil {
+		writeError(w, err)
+		return
+	}
+	opts, cancel := s.queryOptions(r, s.RouteTimeout)
+	defer cancel()
+	S, targets := bmssp.NewNodeSet(), bmssp.NewNodeSet()
+	S.Add(from)
+	targets.Add(to)
+	res := bmssp.Solve(g, S, bmssp.INF, append(opts, bmssp.WithTargets(targets))...)
+	resp := routeResponse{Path: res.PathTo(to), Approximate: res.Approximate()}
+	if resp.Path != nil {
+		cost := res.Dist[to]
+		resp.Cost = &cost
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// isochroneNode and isochroneEdge make up the body of GET /isochrone.
+type isochroneNode struct {
+	ID   bmssp.NodeID `json:"id"`
+	Dist bmssp.Dist   `json:"dist"`
+}
+
+type isochroneEdge struct {
+	From   bmssp.NodeID `json:"from"`
+	To     bmssp.NodeID `json:"to"`
+	Weight bmssp.Dist   `json:"weight"`
+	Reach  bmssp.Dist   `json:"reach"`
+}
+
+type isochroneResponse struct {
+	Nodes    []isochroneNode `json:"nodes"`
+	Frontier []isochroneEdge `json:"frontier"`
+
+	// Approximate is set when the search ran out of time: nodes may be
+	// missing and distances not shortest.
+	Approximate bool `json:"approximate"`
+}
+
+func (s *Server) isochrone(w http.ResponseWriter, r *http.Request) {
+	g, err := s.snapshot()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	source, err := nodeParam(r, "source")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	bound, err := strconv.ParseFloat(r.URL.Query().Get("bound"), 64)
+	if err != nil || !(bound >= 0) {
+		writeError(w, fmt.Errorf("parameter bound: expected a non-negative number, got %q", r.URL.Query().Get("bound")))
+		return
+	}
+
+	if err := checkNodes(g, source); err != nil {
+		writeError(w, err)
+		return
+	}
+	S := bmssp.NewNodeSet()
+	S.Add(source)
+	opts, cancel := s.queryOptions(r, s.IsochroneTimeout)
+	defer cancel()
+	res := bmssp.Isochrone(g, S, bmssp.Dist(bound), opts...)
+
+	resp := isochroneResponse{Nodes: []isochroneNode{}, Frontier: []isochroneEdge{}, Approximate: res.Approximate()}
+	for v, d := range res.Dist {
+		resp.Nodes = append(resp.Nodes, isochroneNode{ID: v, Dist: d})
+	}
+	slices.SortFunc(resp.Nodes, func(a, b isochroneNode) int { return cmp.Compare(a.ID, b.ID) })
+	for _, e := range res.Frontier {
+		resp.Frontier = append(resp.Frontier, isochroneEdge{From: e.From, To: e.To, Weight: e.Weight, Reach: e.Reach})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
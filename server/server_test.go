@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mfreeman451/bmssp-go"
+)
+
+func get(t *testing.T, h http.Handler, url string, v any) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+	if v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s: decoding %q: %v", url, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+func post(h http.Handler, contentType, body string) int {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/graph", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestServer(t *testing.T) {
+	s := New(nil)
+	var e errorResponse
+	if code := get(t, s, "/route?from=0&to=1", &e); code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without a graph, got %d (%s)", code, e.Error)
+	}
+
+	if code := post(s, "text/csv", "0,1,2\n1,2,3\n0,2,10\n"); code != http.StatusNoContent {
+		t.Fatalf("expected the graph to load, got %d", code)
+	}
+
+	var route routeResponse
+	if code := get(t, s, "/route?from=0&to=2", &route); code != http.StatusOK || route.Cost == nil || *route.Cost != 5 {
+		t.Errorf("expected a route of cost 5, got %d %+v", code, route)
+	}
+	route = routeResponse{}
+	if code := get(t, s, "/route?from=2&to=0", &route); code != http.StatusOK || route.Cost != nil || route.Path != nil {
+		t.Errorf("expected an unreachable route, got %d %+v", code, route)
+	}
+	if code := get(t, s, "/route?from=0&to=7", &e); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown node, got %d", code)
+	}
+	if code := get(t, s, "/route?from=x&to=1", &e); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a bad node ID, got %d", code)
+	}
+
+	var iso isochroneResponse
+	if code := get(t, s, "/isochrone?source=0&bound=4", &iso); code != http.StatusOK || len(iso.Nodes) != 2 || len(iso.Frontier) != 2 {
+		t.Errorf("expected 2 nodes and 2 frontier edges, got %d %+v", code, iso)
+	}
+	for _, bound := range []string{"-1", "NaN"} {
+		if code := get(t, s, "/isochrone?source=0&bound="+bound, &e); code != http.StatusBadRequest {
+			t.Errorf("bound %s: expected 400, got %d", bound, code)
+		}
+	}
+	if code := get(t, s, "/isochrone?source=7&bound=4", &e); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown source, got %d", code)
+	}
+
+	// A JSON upload replaces the graph
+	if code := post(s, "application/json", `{"nodes":[{"id":0},{"id":1}],"links":[{"source":0,"target":1,"weight":7}]}`); code != http.StatusNoContent {
+		t.Fatalf("expected the JSON graph to load, got %d", code)
+	}
+	if get(t, s, "/route?from=0&to=1", &route); route.Cost == nil || *route.Cost != 7 {
+		t.Errorf("expected the new graph to be served, got %+v", route)
+	}
+	if code := post(s, "text/csv", "0,1,-1\n"); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid graph, got %d", code)
+	}
+	if code := post(s, "image/png", ""); code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415 for an unknown content type, got %d", code)
+	}
+}
+
+func TestServer_Concurrent(t *testing.T) {
+	g := bmssp.NewGraph()
+	for i := range bmssp.NodeID(100) {
+		g.AddEdge(i, i+1, 1)
+	}
+	s := New(g)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				var route routeResponse
+				if code := get(t, s, "/route?from=0&to=100", &route); code != http.StatusOK || route.Cost == nil || *route.Cost != 100 {
+					t.Errorf("expected a route of cost 100, got %d %+v", code, route)
+					return
+				}
+				if i == 0 {
+					next := bmssp.NewGraph()
+					for j := range bmssp.NodeID(100) {
+						next.AddEdge(j, j+1, 1)
+					}
+					if err := s.SetGraph(next); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestServer_Timeouts(t *testing.T) {
+	g := bmssp.NewGraph()
+	for i := range bmssp.NodeID(2000) {
+		g.AddEdge(i, i+1, 1)
+	}
+	s := New(g)
+
+	var route routeResponse
+	if code := get(t, s, "/route?from=0&to=2000", &route); code != http.StatusOK || route.Approximate || route.Cost == nil {
+		t.Errorf("expected an exact route, got %d %+v", code, route)
+	}
+
+	// Timeouts that pass at once degrade the answers instead of failing them
+	s.RouteTimeout, s.IsochroneTimeout = time.Nanosecond, time.Nanosecond
+	route = routeResponse{}
+	if code := get(t, s, "/route?from=0&to=2000", &route); code != http.StatusOK || !route.Approximate {
+		t.Errorf("expected an approximate route, got %d %+v", code, route)
+	}
+	var iso isochroneResponse
+	if code := get(t, s, "/isochrone?source=0&bound=1000", &iso); code != http.StatusOK || !iso.Approximate {
+		t.Errorf("expected an approximate isochrone, got %d approximate=%v", code, iso.Approximate)
+	}
+
+	// So does a request whose client has gone away
+	s.RouteTimeout = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/route?from=0&to=2000", nil).WithContext(ctx))
+	route = routeResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &route); err != nil || !route.Approximate {
+		t.Errorf("expected an approximate route for a canceled request, got %q", rec.Body.String())
+	}
+}
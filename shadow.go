@@ -0,0 +1,141 @@
+package bmssp
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Mismatch reports a query whose BMSSP answer differs from Dijkstra's.
+type Mismatch struct {
+	Sources []NodeID // sources of the query, in ascending order
+	Node    NodeID   // first mismatching node, in ascending order
+	Got     Dist     // BMSSP distance of Node
+	Want    Dist     // Dijkstra distance of Node
+	Count   int      // number of mismatching nodes
+}
+
+// Shadow recomputes a random sample of queries with plain Dijkstra and
+// reports disagreements, giving continuous correctness checks in production
+// after algorithm or tuning changes. A Shadow is safe for concurrent use and
+// is typically shared by all queries of a service.
+type Shadow struct {
+	rate   float64
+	report func(Mismatch)
+
+	mu         sync.Mutex
+	rng        *rand.Rand
+	checked    int
+	mismatched int
+}
+
+// NewShadow creates a shadow checker that verifies a fraction rate (0 to 1)
+// of queries and passes every mismatch to report, e.g. to log it or bump a
+// metric. Distances agree within the epsilon of the query; see WithEpsilon.
+func NewShadow(rate float64, report func(Mismatch)) *Shadow {
+	return &Shadow{
+		rate:   rate,
+		report: report,
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// WithShadow verifies a sample of the queries using this option against
+// plain Dijkstra; see Shadow. Verification runs synchronously after the
+// query, so sampled queries take longer.
+func WithShadow(sh *Shadow) Option {
+	return func(c *config) {
+		c.shadow = sh
+	}
+}
+
+// Checked returns the number of queries verified so far.
+func (sh *Shadow) Checked() int {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.checked
+}
+
+// Mismatched returns the number of verified queries that disagreed.
+func (sh *Shadow) Mismatched() int {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.mismatched
+}
+
+// sample decides whether to verify the next query.
+func (sh *Shadow) sample() bool {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	return sh.rng.Float64() < sh.rate
+}
+
+// seedsOf captures the initial distances of the sources of a query.
+func seedsOf(dhat map[NodeID]Dist, S NodeSet) map[NodeID]Dist {
+	seeds := make(map[NodeID]Dist, len(S))
+	for v := range S {
+		seeds[v] = dhat[v]
+	}
+	return seeds
+}
+
+// verify recomputes the query of s, which started from seeds within bound B,
+// with Dijkstra under the same weights and compares the distances within B.
+// Queries that stopped early are skipped, as their answers are partial.
+func (sh *Shadow) verify(s *solver, seeds map[NodeID]Dist, B Dist) {
+	if s.stopReason() != StopComplete {
+		return
+	}
+
+	ref := &solver{
+		g:    s.g,
+		src:  s.src,
+		nb:   s.nb,
+		dhat: s.initialDistances(),
+		cfg: config{
+			overlay:     s.cfg.overlay,
+			extra:       s.cfg.extra,
+			weightFunc:  s.cfg.weightFunc,
+			excluded:    s.cfg.excluded,
+			edgeFilter:  s.cfg.edgeFilter,
+			unitWeights: s.cfg.unitWeights,
+		},
+	}
+	S := NewNodeSet()
+	for v, d := range seeds {
+		ref.dhat[v] = d
+		S.Add(v)
+	}
+	ref.dijkstra(S, B)
+
+	m := Mismatch{Sources: S.ToSlice()}
+	slices.Sort(m.Sources)
+	for v, want := range ref.dhat {
+		if want > B {
+			want = INF
+		}
+		got := s.dist(v)
+		if got > B {
+			got = INF
+		}
+		if approxEqual(got, want, s.cfg.epsilon) {
+			continue
+		}
+		if m.Count == 0 || v < m.Node {
+			m.Node, m.Got, m.Want = v, got, want
+		}
+		m.Count++
+	}
+
+	sh.mu.Lock()
+	sh.checked++
+	if m.Count > 0 {
+		sh.mismatched++
+	}
+	sh.mu.Unlock()
+
+	if m.Count > 0 && sh.report != nil {
+		sh.report(m)
+	}
+}
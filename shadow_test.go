@@ -0,0 +1,32 @@
+package bmssp
+
+import "testing"
+
+func TestShadow(t *testing.T) {
+	g := generateRandomGraph(300, 1500, 10, 29)
+
+	var reports []Mismatch
+	sh := NewShadow(1, func(m Mismatch) { reports = append(reports, m) })
+
+	Solve(g, sources(0, 1), INF, WithShadow(sh))
+	BMSSPSingleSource(g, 2, 20, WithShadow(sh))
+	ShortestPath(g, 3, 4, WithShadow(sh), WithTargets(sources(4))) // partial, skipped
+	if sh.Checked() != 2 || sh.Mismatched() != 0 || len(reports) != 0 {
+		t.Fatalf("expected 2 clean checks, got %d checked, %v", sh.Checked(), reports)
+	}
+
+	// A corrupted answer is reported
+	s := newSolver(g, nil)
+	s.dhat[0] = 0
+	seeds := seedsOf(s.dhat, sources(0))
+	s.run(INF, sources(0))
+	s.dhat[7]++
+	sh.verify(s, seeds, INF)
+	if len(reports) != 1 || reports[0].Node != 7 || reports[0].Count != 1 || reports[0].Got != reports[0].Want+1 {
+		t.Errorf("expected a mismatch at node 7, got %+v", reports)
+	}
+
+	if sh := NewShadow(0, nil); Solve(g, sources(0), INF, WithShadow(sh)) != nil && sh.Checked() != 0 {
+		t.Errorf("expected no checks at rate 0, got %d", sh.Checked())
+	}
+}
@@ -0,0 +1,77 @@
+package bmssp
+
+// NodeDistance is a node with its shortest distance.
+type NodeDistance struct {
+	Node NodeID `json:"node"`
+	Dist Dist   `json:"dist"`
+}
+
+// ResultSink receives query results as nodes are settled, so results can be
+// written to external storage (a key-value store, a file) without collecting
+// them into a result map first. The search itself still keeps a tentative
+// distance for every node it reaches, so memory stays O(n) in the nodes
+// visited.
+type ResultSink interface {
+	// Write stores the final distance of v. An error stops the query.
+	Write(v NodeID, d Dist) error
+	// Flush is called once after the last Write.
+	Flush() error
+}
+
+// SolveTo runs a one-to-all query and writes every node reachable within B
+// to sink in settled order.
+//
+// Parameters:
+//   - sink: destination of the results
+//   - g: input graph
+//   - sources: set of source nodes, all at distance 0
+//   - B: distance bound
+//   - opts: optional query settings
+//
+// Returns:
+//   - the first error returned by the sink
+func SolveTo(sink ResultSink, g *Graph, sources NodeSet, B Dist, opts ...Option) error {
+	var err error
+	StreamDistances(g, sources, B, func(v NodeID, d Dist) bool {
+		err = sink.Write(v, d)
+		return err == nil
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	return sink.Flush()
+}
+
+// BatchSink groups results into fixed-size batches, e.g. for pipelined writes
+// to a remote store.
+type BatchSink struct {
+	batch []NodeDistance
+	size  int
+	fn    func([]NodeDistance) error
+}
+
+// NewBatchSink returns a sink that calls fn with up to size results at a
+// time. The slice passed to fn is reused after it returns.
+func NewBatchSink(size int, fn func(batch []NodeDistance) error) *BatchSink {
+	size = max(size, 1)
+	return &BatchSink{batch: make([]NodeDistance, 0, size), size: size, fn: fn}
+}
+
+// Write adds a result to the current batch, handing it to fn when full.
+func (b *BatchSink) Write(v NodeID, d Dist) error {
+	b.batch = append(b.batch, NodeDistance{Node: v, Dist: d})
+	if len(b.batch) < b.size {
+		return nil
+	}
+	return b.Flush()
+}
+
+// Flush hands any buffered results to fn.
+func (b *BatchSink) Flush() error {
+	if len(b.batch) == 0 {
+		return nil
+	}
+	err := b.fn(b.batch)
+	b.batch = b.batch[:0]
+	return err
+}
@@ -0,0 +1,50 @@
+package bmssp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSolveTo_BatchSink(t *testing.T) {
+	g := generateGridGraph(10, 10)
+	want := Dijkstra(g, 0)
+
+	var sizes []int
+	got := make(map[NodeID]Dist)
+	sink := NewBatchSink(32, func(batch []NodeDistance) error {
+		sizes = append(sizes, len(batch))
+		for _, r := range batch {
+			got[r.Node] = r.Dist
+		}
+		return nil
+	})
+	if err := SolveTo(sink, g, sources(0), INF); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sizes) != 4 || sizes[0] != 32 || sizes[3] != 4 {
+		t.Errorf("expected batches of 32, 32, 32, 4, got %v", sizes)
+	}
+	for v, d := range want {
+		if got[v] != d {
+			t.Errorf("node %d: expected %v, got %v", v, d, got[v])
+		}
+	}
+}
+
+func TestSolveTo_Error(t *testing.T) {
+	g := generateGridGraph(10, 10)
+	errFull := errors.New("disk full")
+
+	calls := 0
+	sink := NewBatchSink(10, func([]NodeDistance) error {
+		calls++
+		return errFull
+	})
+	if err := SolveTo(sink, g, sources(0), INF); !errors.Is(err, errFull) {
+		t.Errorf("expected the sink error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the query to stop at the first failed batch, got %d calls", calls)
+	}
+}
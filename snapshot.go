@@ -0,0 +1,63 @@
+package bmssp
+
+import (
+	"maps"
+	"slices"
+)
+
+// Snapshot returns a copy of g that later changes to g don't affect, in
+// constant time: the copy shares the adjacency lists with g, and whichever
+// graph changes first copies the node map and then each edge list it
+// modifies. A service applying live weight updates can thus hand every
+// query the snapshot current when it starts, without copying the graph per
+// update.
+//
+// The snapshot is mutable, like a Clone; freeze it before sharing it between
+// goroutines. Snapshots are not safe to take concurrently with changes to g.
+func (g *Graph) Snapshot() *Graph {
+	s := *g
+	s.frozen, s.topo = false, nil
+	s.shared, s.owned, s.ownedIn = true, nil, nil
+	g.shared = true
+	return &s
+}
+
+// unshare gives g its own node maps if it shares them with a snapshot, so
+// that they can be written. The edge lists stay shared until written; see
+// ownOut and ownIn.
+func (g *Graph) unshare() {
+	if !g.shared {
+		return
+	}
+	g.adj = maps.Clone(g.adj)
+	if g.radj != nil {
+		g.radj = maps.Clone(g.radj)
+	}
+	if g.data != nil {
+		g.data = maps.Clone(g.data)
+	}
+	g.owned = make(map[NodeID]bool)
+	g.ownedIn = make(map[NodeID]bool)
+	g.shared = false
+}
+
+// ownOut returns the outgoing edges of u after copying them if they may be
+// shared with a snapshot, so that they can be written in place.
+func (g *Graph) ownOut(u NodeID) []Edge {
+	g.unshare()
+	if g.owned != nil && !g.owned[u] {
+		g.adj[u] = slices.Clone(g.adj[u])
+		g.owned[u] = true
+	}
+	return g.adj[u]
+}
+
+// ownIn is ownOut for the reverse index, which must exist.
+func (g *Graph) ownIn(v NodeID) []Edge {
+	g.unshare()
+	if g.ownedIn != nil && !g.ownedIn[v] {
+		g.radj[v] = slices.Clone(g.radj[v])
+		g.ownedIn[v] = true
+	}
+	return g.radj[v]
+}
@@ -0,0 +1,62 @@
+package bmssp
+
+import (
+	"maps"
+	"sync"
+	"testing"
+)
+
+func TestGraph_Snapshot(t *testing.T) {
+	g := generateGridGraph(6, 6)
+	g.InEdges(0) // share the reverse index too
+	g.SetEdgeData(EdgeID{From: 0, To: 1}, "a")
+	want := Dijkstra(g, 0)
+	in := len(g.InEdges(7))
+
+	s := g.Snapshot()
+	g.UpdateEdgeWeight(0, 1, 100)
+	g.AddEdge(0, 35, 1)
+	g.RemoveEdge(1, 2)
+	g.RemoveNode(7)
+	g.SetEdgeData(EdgeID{From: 0, To: 1}, "b")
+
+	if got := Dijkstra(s, 0); !maps.Equal(got, want) {
+		t.Error("snapshot distances changed with the graph")
+	}
+	if s.NumEdges() == g.NumEdges() || len(s.InEdges(7)) != in {
+		t.Error("snapshot edges changed with the graph")
+	}
+	if d, _ := s.EdgeData(EdgeID{From: 0, To: 1}); d != "a" {
+		t.Errorf("snapshot edge data = %v, want a", d)
+	}
+	if Dijkstra(g, 0)[35] != 1 {
+		t.Error("expected the graph to see its own changes")
+	}
+
+	// Changing the snapshot leaves the graph alone as well
+	s.UpdateEdgeWeight(0, 1, 7)
+	for _, e := range g.OutEdges(0) {
+		if e.To == 1 && e.Weight != 100 {
+			t.Errorf("graph weight 0->1 = %v after changing the snapshot, want 100", e.Weight)
+		}
+	}
+}
+
+func TestGraph_SnapshotConcurrentUpdates(t *testing.T) {
+	g := generateGridGraph(10, 10)
+	var wg sync.WaitGroup
+	for i := range 20 {
+		s := g.Snapshot()
+		s.Freeze()
+		want := Dijkstra(s, 0)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := Solve(s, sources(0), INF); !maps.Equal(got.Dist, want) {
+				t.Error("query on a snapshot saw a later update")
+			}
+		}()
+		g.UpdateEdgeWeight(NodeID(i), NodeID(i+1), Dist(i+2))
+	}
+	wg.Wait()
+}
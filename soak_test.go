@@ -0,0 +1,129 @@
+package bmssp
+
+import (
+	"flag"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// The soak test is skipped unless a duration is given. Run it under the race
+// detector before each release:
+//
+//	go test -race -run TestSoak -soak=5m
+//	go test -race -run TestSoak -soak=5m -soak.updates
+var (
+	soakDuration = flag.Duration("soak", 0, "run the concurrent soak test for this long")
+	soakWorkers  = flag.Int("soak.workers", runtime.GOMAXPROCS(0), "concurrent query goroutines in the soak test")
+	soakUpdates  = flag.Bool("soak.updates", false, "apply concurrent graph updates in the soak test")
+)
+
+func TestSoak(t *testing.T) {
+	if *soakDuration <= 0 {
+		t.Skip("set -soak to run the soak test")
+	}
+
+	g := generateGridGraph(40, 40)
+	n := len(g.adj)
+	g.InEdges(0) // build the reverse index before sharing the graph
+
+	// Without updates every answer can be checked against a fixed oracle
+	var oracle []map[NodeID]Dist
+	if !*soakUpdates {
+		oracle = make([]map[NodeID]Dist, n)
+		for u := range oracle {
+			oracle[u] = Dijkstra(g, NodeID(u))
+		}
+	}
+
+	p, err := NewPlanner(g, PlannerOptions{Workers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func(from, to NodeID, got Dist) {
+		if oracle == nil {
+			if got < 0 || math.IsNaN(float64(got)) {
+				t.Errorf("%d->%d: invalid distance %v", from, to, got)
+			}
+			return
+		}
+		if want := oracle[from][to]; math.Abs(float64(got-want)) > 1e-9 {
+			t.Errorf("%d->%d: expected %v, got %v", from, to, want, got)
+		}
+	}
+
+	deadline := time.Now().Add(*soakDuration)
+	var queries atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < *soakWorkers; w++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(seed))
+			for time.Now().Before(deadline) && !t.Failed() {
+				a, b := NodeID(r.Intn(n)), NodeID(r.Intn(n))
+				switch r.Intn(5) {
+				case 0:
+					route, err := p.Route(a, b)
+					if err != nil {
+						t.Error(err)
+					}
+					check(a, b, route.Cost)
+				case 1:
+					m, err := p.Matrix([]NodeID{a}, []NodeID{b, a})
+					if err != nil {
+						t.Error(err)
+					}
+					check(a, b, m[0][0])
+				case 2:
+					iso, err := p.Isochrone(sources(a), 5)
+					if err != nil {
+						t.Error(err)
+					}
+					for v, d := range iso.Dist {
+						check(a, v, d)
+					}
+				case 3:
+					if oracle != nil {
+						check(a, b, BMSSPSingleSource(g, a, INF)[b])
+						check(b, a, BMSSPSingleTarget(g, a, INF)[b])
+					}
+				default:
+					if oracle != nil {
+						for v, d := range Settled(g, sources(a), 3) {
+							check(a, v, d)
+						}
+					}
+				}
+				queries.Add(1)
+			}
+		}(int64(w))
+	}
+
+	if *soakUpdates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(-1))
+			for time.Now().Before(deadline) && !t.Failed() {
+				u := NodeID(r.Intn(n))
+				edges := p.g.OutEdges(u) // read without the lock: only this goroutine mutates
+				if len(edges) == 0 {
+					continue
+				}
+				e := edges[r.Intn(len(edges))]
+				err := p.Update([]EdgeUpdate{{From: u, To: e.To, Weight: Dist(1 + r.Intn(5))}})
+				if err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	t.Logf("%d queries in %v", queries.Load(), *soakDuration)
+}
@@ -0,0 +1,431 @@
+package bmssp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A GraphStore directory holds a base graph in the binary format of
+// WriteTo, base-<gen>.bin, the log of transactions committed since,
+// log-<gen>, and the writer's lock file, LOCK. Every log record is a little-endian uint32 payload length, the
+// CRC-32 of the payload and the payload: a sequence of operations, each an
+// opcode byte followed by varint node IDs and, for weights, a float64. A
+// torn record at the end of the log, from a crash while committing, is
+// ignored. Compaction writes the next generation's base before switching
+// to its empty log, so a crash at any point leaves one consistent
+// generation.
+const (
+	storeOpAddEdge byte = iota + 1
+	storeOpRemoveEdge
+	storeOpUpdateWeight
+	storeOpRemoveNode
+	storeOpAddNode
+)
+
+var (
+	// ErrStoreClosed is returned by GraphStore methods after Close.
+	ErrStoreClosed = errors.New("bmssp: graph store closed")
+
+	// ErrStoreLocked is returned by OpenGraphStore while another GraphStore
+	// has the directory open for writing.
+	ErrStoreLocked = errors.New("bmssp: graph store locked")
+)
+
+// storeLoadAttempts bounds how often loadStore starts over because a
+// compaction switched generations while it was reading.
+const storeLoadAttempts = 16
+
+// GraphStore is a graph persisted in a directory, surviving restarts. Changes
+// are committed in transactions, each appended to a log and synced to disk
+// before Update returns; Compact folds the log into a new base file. One
+// process owns the store for writing, enforced by a lock file, while others
+// load consistent snapshots of the committed state with LoadGraphStore, e.g.
+// to serve queries.
+//
+// A GraphStore is safe for concurrent use.
+type GraphStore struct {
+	dir string
+
+	mu     sync.Mutex
+	gen    int
+	g      *Graph
+	log    *os.File
+	unlock func() error // releases the writer lock
+}
+
+// StoreTx collects the changes of a GraphStore transaction.
+type StoreTx struct {
+	ops []byte
+	err error
+}
+
+// AddEdge adds an edge from from to to, as Graph.AddEdge.
+func (tx *StoreTx) AddEdge(from, to NodeID, weight Dist) {
+	tx.weighted(storeOpAddEdge, from, to, weight)
+}
+
+// RemoveEdge removes the edges from from to to, as Graph.RemoveEdge.
+func (tx *StoreTx) RemoveEdge(from, to NodeID) {
+	tx.ops = append(tx.ops, storeOpRemoveEdge)
+	tx.ops = binary.AppendVarint(tx.ops, int64(from))
+	tx.ops = binary.AppendVarint(tx.ops, int64(to))
+}
+
+// UpdateEdgeWeight changes the weight of the edges from from to to, as
+// Graph.UpdateEdgeWeight.
+func (tx *StoreTx) UpdateEdgeWeight(from, to NodeID, weight Dist) {
+	tx.weighted(storeOpUpdateWeight, from, to, weight)
+}
+
+// AddNode adds v without edges, as Graph.AddNode, e.g. for a node whose
+// edges come later.
+func (tx *StoreTx) AddNode(v NodeID) {
+	tx.ops = append(tx.ops, storeOpAddNode)
+	tx.ops = binary.AppendVarint(tx.ops, int64(v))
+}
+
+// RemoveNode removes v and its edges, as Graph.RemoveNode.
+func (tx *StoreTx) RemoveNode(v NodeID) {
+	tx.ops = append(tx.ops, storeOpRemoveNode)
+	tx.ops = binary.AppendVarint(tx.ops, int64(v))
+}
+
+// weighted appends an operation on an edge with a weight.
+func (tx *StoreTx) weighted(op byte, from, to NodeID, weight Dist) {
+	if !validWeight(weight) && tx.err == nil {
+		tx.err = fmt.Errorf("%w: edge %d->%d has weight %v", ErrInvalidWeight, from, to, weight)
+	}
+	tx.ops = append(tx.ops, op)
+	tx.ops = binary.AppendVarint(tx.ops, int64(from))
+	tx.ops = binary.AppendVarint(tx.ops, int64(to))
+	tx.ops = binary.LittleEndian.AppendUint64(tx.ops, math.Float64bits(float64(weight)))
+}
+
+// OpenGraphStore opens the store in dir for writing, creating it if needed,
+// and loads the committed graph.
+//
+// Returns:
+//   - the store; Close releases it
+//   - ErrStoreLocked if another GraphStore has dir open
+//   - ErrCorruptGraph for an unreadable base file, or the file system error
+func OpenGraphStore(dir string) (*GraphStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	unlock, err := lockFile(filepath.Join(dir, "LOCK"))
+	if err != nil {
+		return nil, err
+	}
+	s, err := openLockedStore(dir)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	s.unlock = unlock
+	return s, nil
+}
+
+// openLockedStore opens the store in dir once its lock is held.
+func openLockedStore(dir string) (*GraphStore, error) {
+	gen, g, valid, err := loadStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	log, err := os.OpenFile(storeLogPath(dir, gen), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	// Drop a torn record so that new ones follow the last complete one
+	if err := log.Truncate(valid); err != nil {
+		log.Close()
+		return nil, err
+	}
+	if _, err := log.Seek(valid, io.SeekStart); err != nil {
+		log.Close()
+		return nil, err
+	}
+	removeStaleGenerations(dir, gen)
+	return &GraphStore{dir: dir, gen: gen, g: g, log: log}, nil
+}
+
+// LoadGraphStore reads the graph last committed to the store in dir,
+// without opening it for writing. It may run while another process
+// commits.
+//
+// Returns:
+//   - the graph
+//   - an error wrapping fs.ErrNotExist if dir does not exist
+//   - ErrCorruptGraph for an unreadable base file, or the file system error
+func LoadGraphStore(dir string) (*Graph, error) {
+	// An empty store has no files yet, but a missing one has no directory
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	_, g, _, err := loadStore(dir)
+	return g, err
+}
+
+// loadStore reads the newest generation of the store in dir and replays its
+// log. It returns the generation, the graph and the length of the log's
+// complete records.
+//
+// A compaction by the writer may remove the generation's files while they
+// are read, so the read starts over whenever the newest generation changed
+// meanwhile.
+func loadStore(dir string) (int, *Graph, int64, error) {
+	for attempt := 1; ; attempt++ {
+		gen := latestGeneration(dir)
+		g, valid, err := loadGeneration(dir, gen)
+		if latestGeneration(dir) == gen {
+			return gen, g, valid, err
+		}
+		if attempt == storeLoadAttempts {
+			return 0, nil, 0, fmt.Errorf("bmssp: graph store %s compacted during every load attempt", dir)
+		}
+	}
+}
+
+// loadGeneration reads the base file of generation gen and replays its log.
+func loadGeneration(dir string, gen int) (*Graph, int64, error) {
+	g := NewGraph()
+	if gen > 0 {
+		f, err := os.Open(storeBasePath(dir, gen))
+		if err != nil {
+			return nil, 0, err
+		}
+		g, err = ReadGraph(f)
+		f.Close()
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	data, err := os.ReadFile(storeLogPath(dir, gen))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, 0, err
+	}
+	var valid int64
+	for len(data) >= 8 {
+		size := binary.LittleEndian.Uint32(data)
+		if uint64(len(data)-8) < uint64(size) {
+			break
+		}
+		payload := data[8 : 8+size]
+		if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(data[4:]) || applyStoreOps(g, payload) != nil {
+			break
+		}
+		valid += 8 + int64(size)
+		data = data[8+size:]
+	}
+	return g, valid, nil
+}
+
+// applyStoreOps applies the operations of a log record to g.
+func applyStoreOps(g *Graph, ops []byte) error {
+	r := bytes.NewReader(ops)
+	node := func() NodeID {
+		v, _ := binary.ReadVarint(r)
+		return NodeID(v)
+	}
+	weight := func() Dist {
+		var b [8]byte
+		io.ReadFull(r, b[:])
+		return Dist(math.Float64frombits(binary.LittleEndian.Uint64(b[:])))
+	}
+	for r.Len() > 0 {
+		op, _ := r.ReadByte()
+		switch op {
+		case storeOpAddEdge:
+			from, to := node(), node()
+			g.AddEdge(from, to, weight())
+		case storeOpRemoveEdge:
+			g.RemoveEdge(node(), node())
+		case storeOpUpdateWeight:
+			from, to := node(), node()
+			g.UpdateEdgeWeight(from, to, weight())
+		case storeOpRemoveNode:
+			g.RemoveNode(node())
+		case storeOpAddNode:
+			g.AddNode(node())
+		default:
+			return fmt.Errorf("%w: unknown store operation %d", ErrCorruptGraph, op)
+		}
+	}
+	return nil
+}
+
+// Update runs fn in a transaction. If fn returns nil, its changes are
+// appended to the log, synced to disk and applied to the graph; otherwise
+// they are discarded.
+//
+// Returns:
+//   - the error of fn, ErrInvalidWeight for a negative or NaN weight, or the
+//     error writing the log; nothing is committed then
+//   - ErrStoreClosed after Close
+func (s *GraphStore) Update(fn func(tx *StoreTx) error) error {
+	tx := &StoreTx{}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if tx.err != nil {
+		return tx.err
+	}
+	record := binary.LittleEndian.AppendUint32(nil, uint32(len(tx.ops)))
+	record = binary.LittleEndian.AppendUint32(record, crc32.ChecksumIEEE(tx.ops))
+	record = append(record, tx.ops...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.log == nil {
+		return ErrStoreClosed
+	}
+	if len(tx.ops) == 0 {
+		return nil
+	}
+	end, err := s.log.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err = s.log.Write(record); err == nil {
+		err = s.log.Sync()
+	}
+	if err != nil {
+		// Cut off the partial record so later commits stay readable
+		s.log.Truncate(end)
+		s.log.Seek(end, io.SeekStart)
+		return err
+	}
+	return applyStoreOps(s.g, tx.ops)
+}
+
+// Snapshot returns a copy of the committed graph, e.g. to serve queries
+// from while transactions continue.
+func (s *GraphStore) Snapshot() *Graph {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.g.Snapshot()
+}
+
+// Compact writes the committed graph as a new base file and starts an
+// empty log, so that opening the store no longer replays the transactions.
+//
+// Returns:
+//   - the file system error; the store is left on its previous generation,
+//     unless only syncing the directory after the switch failed
+//   - ErrStoreClosed after Close
+func (s *GraphStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.log == nil {
+		return ErrStoreClosed
+	}
+	next := s.gen + 1
+	tmp := storeBasePath(s.dir, next) + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := s.g.WriteTo(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	f.Close()
+	log, err := os.OpenFile(storeLogPath(s.dir, next), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	// The rename switches generations atomically
+	if err := os.Rename(tmp, storeBasePath(s.dir, next)); err != nil {
+		log.Close()
+		os.Remove(tmp)
+		return err
+	}
+	s.log.Close()
+	s.gen, s.log = next, log
+	// The previous generation is kept until the rename is durable
+	if err := syncDir(s.dir); err != nil {
+		return err
+	}
+	removeStaleGenerations(s.dir, next)
+	return nil
+}
+
+// Close releases the store's log and its lock. Committed changes are
+// already on disk.
+func (s *GraphStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.log == nil {
+		return ErrStoreClosed
+	}
+	err := s.log.Close()
+	s.log = nil
+	return errors.Join(err, s.unlock())
+}
+
+func storeBasePath(dir string, gen int) string {
+	return filepath.Join(dir, "base-"+strconv.Itoa(gen)+".bin")
+}
+
+func storeLogPath(dir string, gen int) string {
+	return filepath.Join(dir, "log-"+strconv.Itoa(gen))
+}
+
+// latestGeneration returns the newest generation with a base file in dir, 0
+// for a store that was never compacted.
+func latestGeneration(dir string) int {
+	entries, _ := os.ReadDir(dir)
+	gen := 0
+	for _, e := range entries {
+		if n, ok := storeGeneration(e.Name(), "base-", ".bin"); ok {
+			gen = max(gen, n)
+		}
+	}
+	return gen
+}
+
+// removeStaleGenerations deletes the files of generations before gen, left
+// by compaction. Failures are harmless and ignored.
+func removeStaleGenerations(dir string, gen int) {
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		n, ok := storeGeneration(e.Name(), "base-", ".bin")
+		if !ok {
+			n, ok = storeGeneration(e.Name(), "log-", "")
+		}
+		if ok && n < gen {
+			os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
+
+// storeGeneration parses the generation from a file name of the form
+// prefix<gen>suffix.
+func storeGeneration(name, prefix, suffix string) (int, bool) {
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return 0, false
+	}
+	rest, ok = strings.CutSuffix(rest, suffix)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(rest)
+	return n, err == nil
+}
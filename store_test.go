@@ -0,0 +1,143 @@
+package bmssp
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGraphStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "store")
+	s, err := OpenGraphStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Update(func(tx *StoreTx) error {
+		tx.AddEdge(0, 1, 2)
+		tx.AddEdge(1, 2, 3)
+		tx.AddEdge(0, 2, 10)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update(func(tx *StoreTx) error {
+		tx.AddEdge(2, 3, 1)
+		return errors.New("rolled back")
+	}); err == nil || err.Error() != "rolled back" {
+		t.Errorf("expected the transaction's error, got %v", err)
+	}
+	if err := s.Update(func(tx *StoreTx) error {
+		tx.AddEdge(2, 3, -1)
+		return nil
+	}); !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("expected ErrInvalidWeight, got %v", err)
+	}
+	s.Update(func(tx *StoreTx) error {
+		tx.UpdateEdgeWeight(1, 2, 1)
+		tx.AddNode(5)
+		tx.AddNode(6)
+		tx.RemoveNode(6)
+		return nil
+	})
+
+	// A concurrent reader sees the committed state
+	g, err := LoadGraphStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, d := ShortestPath(g, 0, 2); d != 3 || len(g.adj) != 4 || !g.HasNode(5) {
+		t.Errorf("expected distance 3 over 4 nodes with the isolated node 5, got %v over %d", d, len(g.adj))
+	}
+	if snap := s.Snapshot(); snap.numEdges != 3 {
+		t.Errorf("expected 3 edges in the snapshot, got %d", snap.numEdges)
+	}
+	s.Close()
+	if err := s.Update(func(tx *StoreTx) error { return nil }); !errors.Is(err, ErrStoreClosed) {
+		t.Errorf("expected ErrStoreClosed, got %v", err)
+	}
+
+	// A torn record from a crash is dropped on reopening
+	log, _ := os.OpenFile(storeLogPath(dir, 0), os.O_WRONLY|os.O_APPEND, 0)
+	log.Write([]byte{20, 0, 0, 0, 1, 2})
+	log.Close()
+	s, err = OpenGraphStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Update(func(tx *StoreTx) error {
+		tx.RemoveEdge(0, 2)
+		return nil
+	})
+	if err := s.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	s.Update(func(tx *StoreTx) error {
+		tx.AddEdge(2, 3, 4)
+		return nil
+	})
+	s.Close()
+
+	s, err = OpenGraphStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := OpenGraphStore(dir); !errors.Is(err, ErrStoreLocked) {
+		t.Errorf("expected a second writer to get ErrStoreLocked, got %v", err)
+	}
+	g = s.Snapshot()
+	if g.numEdges != 3 || g.InEdges(3) == nil || g.OutEdges(0)[0].To != 1 || !g.HasNode(5) {
+		t.Errorf("expected the compacted and logged changes after reopening, got %v", g.adj)
+	}
+	if _, err := os.Stat(storeLogPath(dir, 0)); !os.IsNotExist(err) {
+		t.Errorf("expected the previous generation removed, got %v", err)
+	}
+
+	if _, err := LoadGraphStore(filepath.Join(dir, "missing")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist for a missing store, got %v", err)
+	}
+}
+
+func TestGraphStore_LoadDuringCompaction(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenGraphStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.Update(func(tx *StoreTx) error {
+		for i := range NodeID(100) {
+			tx.AddEdge(i, i+1, 1)
+		}
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range NodeID(50) {
+			s.Compact()
+			s.Update(func(tx *StoreTx) error {
+				tx.AddEdge(1000+i, 0, 1)
+				return nil
+			})
+		}
+	}()
+	for loads := 0; ; loads++ {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		g, err := LoadGraphStore(dir)
+		if err != nil {
+			t.Fatalf("load %d: %v", loads, err)
+		}
+		if g.NumEdges() < 100 {
+			t.Fatalf("load %d: expected at least the 100 initial edges, got %d", loads, g.NumEdges())
+		}
+	}
+}
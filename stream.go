@@ -0,0 +1,162 @@
+package bmssp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// streamChunkSize is the number of records written between flushes.
+const streamChunkSize = 4096
+
+// streamBuffer is the number of settlements DijkstraStream may run ahead of
+// its reader.
+const streamBuffer = 256
+
+// StreamDistances reports the nodes reachable from sources within bound B
+// one at a time, in settled (non-decreasing distance) order, instead of
+// materializing a result map. Every reported distance is final. Returning
+// false from fn stops the query.
+//
+// Parameters:
+//   - g: input graph
+//   - sources: set of source nodes, all at distance 0
+//   - B: distance bound
+//   - fn: receives each node and its shortest distance
+//   - opts: optional query settings
+//
+// Returns:
+//   - why the query ended; StopCanceled if fn returned false
+func StreamDistances(g *Graph, sources NodeSet, B Dist, fn func(v NodeID, d Dist) bool, opts ...Option) StopReason {
+	s := newSolver(g, opts)
+	s.emit = fn
+	for v := range sources {
+		s.dhat[v] = 0
+	}
+	s.dijkstra(sources, B)
+	return s.stopReason()
+}
+
+// Settlement is a node whose shortest distance has been finalized.
+type Settlement struct {
+	Node NodeID
+	Dist Dist
+}
+
+// DijkstraStream runs a one-to-all query in the background and sends each
+// node to the returned channel as it is settled, in non-decreasing distance
+// order, e.g. for a consumer that only needs the k nearest nodes. Like
+// StreamDistances it runs a Dijkstra search, not the BMSSP recursion, whose
+// settlement order is only non-decreasing between recursion levels. The
+// channel is closed when the query ends. Canceling ctx stops the query; a
+// consumer that stops reading early must cancel ctx, or the search blocks
+// forever.
+//
+// Parameters:
+//   - ctx: stops the query when done
+//   - g: input graph, not mutated while the query runs
+//   - sources: set of source nodes, all at distance 0
+//   - B: distance bound
+//   - opts: optional query settings
+func DijkstraStream(ctx context.Context, g *Graph, sources NodeSet, B Dist, opts ...Option) <-chan Settlement {
+	ch := make(chan Settlement, streamBuffer)
+	go func() {
+		defer close(ch)
+		StreamDistances(g, sources, B, func(v NodeID, d Dist) bool {
+			if ctx.Err() != nil {
+				return false
+			}
+			select {
+			case ch <- Settlement{Node: v, Dist: d}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}, opts...)
+	}()
+	return ch
+}
+
+// KNearest returns the k nodes closest to source that satisfy match, e.g.
+// the nearest restaurants, nearest first. The search stops as soon as the
+// k-th match is settled, so only the region within its distance is
+// explored. The source counts if it matches.
+//
+// Parameters:
+//   - g: input graph
+//   - source: source node
+//   - k: number of nodes wanted
+//   - match: nodes eligible for the result; nil for every node
+//   - opts: optional query settings
+//
+// Returns:
+//   - up to k nodes with their distances, by increasing distance; fewer
+//     if fewer matching nodes are reachable
+func KNearest(g *Graph, source NodeID, k int, match func(NodeID) bool, opts ...Option) []Settlement {
+	if k <= 0 {
+		return nil
+	}
+	S := NewNodeSet()
+	S.Add(source)
+	var out []Settlement
+	StreamDistances(g, S, INF, func(v NodeID, d Dist) bool {
+		if match == nil || match(v) {
+			out = append(out, Settlement{Node: v, Dist: d})
+		}
+		return len(out) < k
+	}, opts...)
+	return out
+}
+
+// flusher is implemented by writers that buffer output downstream, such as
+// http.ResponseWriter.
+type flusher interface {
+	Flush()
+}
+
+// jsonSink writes results as newline-delimited JSON, flushing w after every
+// streamChunkSize records.
+type jsonSink struct {
+	w   io.Writer
+	bw  *bufio.Writer
+	enc *json.Encoder
+	n   int
+}
+
+func newJSONSink(w io.Writer) *jsonSink {
+	bw := bufio.NewWriter(w)
+	return &jsonSink{w: w, bw: bw, enc: json.NewEncoder(bw)}
+}
+
+func (s *jsonSink) Write(v NodeID, d Dist) error {
+	if err := s.enc.Encode(NodeDistance{Node: v, Dist: d}); err != nil {
+		return err
+	}
+	if s.n++; s.n%streamChunkSize == 0 {
+		return s.Flush()
+	}
+	return nil
+}
+
+func (s *jsonSink) Flush() error {
+	if err := s.bw.Flush(); err != nil {
+		return err
+	}
+	if f, ok := s.w.(flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// WriteDistances streams the result of a one-to-all query to w as
+// newline-delimited JSON objects {"node":…,"dist":…} in settled order. Output
+// is written in chunks; if w has a Flush method (like an HTTP response
+// writer) it is called after every chunk, so clients receive a chunked
+// response while the query is still running.
+//
+// Returns:
+//   - the first error returned by w, which also stops the query
+func WriteDistances(w io.Writer, g *Graph, sources NodeSet, B Dist, opts ...Option) error {
+	return SolveTo(newJSONSink(w), g, sources, B, opts...)
+}
@@ -0,0 +1,141 @@
+package bmssp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestStreamDistances(t *testing.T) {
+	g := generateRandomGraph(300, 1500, 10, 5)
+	want := Dijkstra(g, 0)
+
+	last := Dist(0)
+	seen := 0
+	reason := StreamDistances(g, sources(0), INF, func(v NodeID, d Dist) bool {
+		if d != want[v] {
+			t.Errorf("node %d: expected %v, got %v", v, want[v], d)
+		}
+		if d < last {
+			t.Errorf("node %d: distance %v streamed after %v", v, d, last)
+		}
+		last = d
+		seen++
+		return true
+	})
+
+	reachable := 0
+	for _, d := range want {
+		if d < INF {
+			reachable++
+		}
+	}
+	if reason != StopComplete || seen != reachable {
+		t.Errorf("expected all %d reachable nodes, got %d (%v)", reachable, seen, reason)
+	}
+
+	seen = 0
+	reason = StreamDistances(g, sources(0), INF, func(NodeID, Dist) bool {
+		seen++
+		return seen < 5
+	})
+	if reason != StopCanceled || seen != 5 {
+		t.Errorf("expected the caller to stop the stream after 5 nodes, got %d (%v)", seen, reason)
+	}
+}
+
+func TestWriteDistances(t *testing.T) {
+	g := generateGridGraph(10, 10)
+	var buf bytes.Buffer
+	if err := WriteDistances(&buf, g, sources(0), 3); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nodes within 3 steps of a grid corner: 1 + 2 + 3 + 4
+	lines := 0
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var rec NodeDistance
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatalf("line %d: %v", lines, err)
+		}
+		if rec.Dist > 3 {
+			t.Errorf("node %d streamed beyond the bound at %v", rec.Node, rec.Dist)
+		}
+		lines++
+	}
+	if lines != 10 {
+		t.Errorf("expected 10 records, got %d", lines)
+	}
+}
+
+func TestDijkstraStream(t *testing.T) {
+	g := generateGridGraph(30, 30)
+	want := Dijkstra(g, 0)
+
+	n, last := 0, Dist(0)
+	for st := range DijkstraStream(context.Background(), g, sources(0), INF) {
+		if st.Dist != want[st.Node] || st.Dist < last {
+			t.Fatalf("node %d: got %v after %v, expected %v", st.Node, st.Dist, last, want[st.Node])
+		}
+		n, last = n+1, st.Dist
+	}
+	if n != 900 {
+		t.Errorf("expected 900 settlements, got %d", n)
+	}
+
+	// Reading the 10 nearest and canceling stops the search
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := DijkstraStream(ctx, g, sources(0), INF)
+	for range 10 {
+		<-ch
+	}
+	cancel()
+	drained := 0
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				if drained > streamBuffer+1 {
+					t.Errorf("expected the search to stop, %d more settlements", drained)
+				}
+				return
+			}
+			drained++
+		case <-timeout:
+			t.Fatal("channel not closed after cancel")
+		}
+	}
+}
+
+func TestKNearest(t *testing.T) {
+	g := generateGridGraph(30, 30)
+	want := Dijkstra(g, 0)
+
+	near := KNearest(g, 0, 6, nil)
+	if len(near) != 6 || near[0] != (Settlement{Node: 0}) || near[5].Dist != 2 {
+		t.Errorf("unexpected nearest nodes %v", near)
+	}
+
+	// Restaurants on every node of column 7
+	restaurant := func(v NodeID) bool { return v%30 == 7 }
+	near = KNearest(g, 0, 3, restaurant)
+	if len(near) != 3 {
+		t.Fatalf("expected 3 restaurants, got %v", near)
+	}
+	for i, st := range near {
+		if !restaurant(st.Node) || st.Dist != want[st.Node] || st.Dist != Dist(7+i) {
+			t.Errorf("unexpected restaurant %v", st)
+		}
+	}
+	if all := KNearest(g, 0, 100, restaurant); len(all) != 30 {
+		t.Errorf("expected all 30 restaurants, got %d", len(all))
+	}
+	if none := KNearest(g, 0, 0, nil); none != nil {
+		t.Errorf("expected nothing for k=0, got %v", none)
+	}
+}
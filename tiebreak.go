@@ -0,0 +1,46 @@
+package bmssp
+
+import "slices"
+
+// TieBreak selects which of several equally short paths a query reports.
+type TieBreak int
+
+const (
+	// TieBreakNone keeps whichever path the search finds first, which may
+	// depend on the algorithm and its parameters.
+	TieBreakNone TieBreak = iota
+	// TieBreakFewestHops prefers the path with the fewest edges.
+	TieBreakFewestHops
+	// TieBreakLexicographic prefers the path whose node sequence, read from
+	// the source, is lexicographically smallest.
+	TieBreakLexicographic
+)
+
+// WithTieBreak ranks paths of exactly equal length by tb, so that distances
+// are unchanged but predecessors and paths are stable across algorithms,
+// parameters and runs. Lexicographic ranking compares whole paths on every
+// tie and suits graphs where ties are rare or paths short.
+func WithTieBreak(tb TieBreak) Option {
+	return func(c *config) { c.tieBreak = tb }
+}
+
+// improves reports whether reaching v at distance d through u beats v's
+// current path: d is shorter, or equally short and preferred by the
+// configured tie-break.
+func (s *solver) improves(u, v NodeID, d Dist) bool {
+	cur := s.dist(v)
+	if d != cur || cur == INF {
+		return d < cur
+	}
+	switch s.cfg.tieBreak {
+	case TieBreakFewestHops:
+		return s.hops[u]+1 < s.hops[v]
+	case TieBreakLexicographic:
+		// Sources have no predecessor and keep their empty path
+		if p, ok := s.pred[v]; !ok || p == u {
+			return false
+		}
+		return slices.Compare(append(pathTo(s.pred, u), v), pathTo(s.pred, v)) < 0
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package bmssp
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestWithTieBreak(t *testing.T) {
+	g := NewGraph()
+	// Three routes of length 4 from 0 to 9
+	g.AddEdge(0, 5, 2)
+	g.AddEdge(5, 9, 2)
+	g.AddEdge(0, 2, 1)
+	g.AddEdge(2, 7, 1)
+	g.AddEdge(7, 9, 2)
+	g.AddEdge(0, 3, 1)
+	g.AddEdge(3, 4, 1)
+	g.AddEdge(4, 6, 1)
+	g.AddEdge(6, 9, 1)
+
+	solvers := map[string]func(...Option) *Result{
+		"bmssp":    func(opts ...Option) *Result { return Solve(g, sources(0), INF, append(opts, WithoutFastPaths())...) },
+		"dag":      func(opts ...Option) *Result { return Solve(g, sources(0), INF, append(opts, WithDAG())...) },
+		"dijkstra": func(opts ...Option) *Result { return SolveDijkstra(g, sources(0), opts...) },
+	}
+	for name, solve := range solvers {
+		if path := solve(WithTieBreak(TieBreakFewestHops)).PathTo(9); !slices.Equal(path, []NodeID{0, 5, 9}) {
+			t.Errorf("%s: expected the two-hop path, got %v", name, path)
+		}
+		if path := solve(WithTieBreak(TieBreakLexicographic)).PathTo(9); !slices.Equal(path, []NodeID{0, 2, 7, 9}) {
+			t.Errorf("%s: expected the lexicographically smallest path, got %v", name, path)
+		}
+		if d := solve(WithTieBreak(TieBreakLexicographic)).Dist[9]; d != 4 {
+			t.Errorf("%s: tie-breaking changed the distance to %v", name, d)
+		}
+	}
+}
+
+func TestWithTieBreak_StableAcrossAlgorithms(t *testing.T) {
+	// A grid has many equally short paths between most pairs
+	g := generateGridGraph(12, 12)
+	for _, tb := range []TieBreak{TieBreakFewestHops, TieBreakLexicographic} {
+		want := SolveDijkstra(g, sources(0, 77), WithTieBreak(tb)).Pred
+		for name, res := range map[string]*Result{
+			"bmssp": Solve(g, sources(0, 77), INF, WithTieBreak(tb), WithoutFastPaths()),
+			"bfs":   Solve(g, sources(0, 77), INF, WithTieBreak(tb)),
+			"delta": Solve(g, sources(0, 77), INF, WithTieBreak(tb), WithoutFastPaths(), WithDelta(3)),
+		} {
+			if tb == TieBreakLexicographic && !maps.Equal(res.Pred, want) {
+				t.Errorf("%s: predecessors differ from Dijkstra's", name)
+			}
+			for v := range g.adj {
+				if got, exp := len(res.PathTo(v)), len(pathTo(want, v)); got != exp {
+					t.Fatalf("%s, tie-break %d: path to %d has %d nodes, want %d", name, tb, v, got, exp)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package bmssp
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ErrInvalidProfile is returned for malformed travel-time profiles.
+var ErrInvalidProfile = errors.New("bmssp: invalid travel-time profile")
+
+// ErrNotFIFO is returned for travel-time profiles that violate the FIFO
+// property (departing later must never mean arriving earlier).
+var ErrNotFIFO = errors.New("bmssp: profile violates the FIFO property")
+
+// Profile gives the travel time of an edge as a function of the departure time.
+// Profiles must satisfy the FIFO property: depart+TravelTime(depart) must be
+// non-decreasing in depart.
+type Profile interface {
+	TravelTime(depart Dist) Dist
+}
+
+// invertibleProfile is implemented by profiles that can compute the latest
+// departure arriving by a given time exactly, without a numeric search.
+type invertibleProfile interface {
+	LatestDeparture(arriveBy Dist) Dist
+}
+
+// ConstantProfile is a travel time that does not depend on the departure time.
+type ConstantProfile Dist
+
+// TravelTime returns the constant travel time.
+func (c ConstantProfile) TravelTime(Dist) Dist { return Dist(c) }
+
+// LatestDeparture returns arriveBy minus the constant travel time.
+func (c ConstantProfile) LatestDeparture(arriveBy Dist) Dist { return arriveBy - Dist(c) }
+
+// PiecewiseLinear is a travel-time profile interpolated linearly between
+// breakpoints and held constant before the first and after the last one.
+type PiecewiseLinear struct {
+	times  []Dist // breakpoint departure times, strictly ascending
+	travel []Dist // travel time at each breakpoint
+}
+
+// NewPiecewiseLinear creates a profile from breakpoint departure times and the
+// travel times at those breakpoints.
+//
+// Returns:
+//   - the profile
+//   - ErrInvalidProfile if the breakpoints are malformed, or ErrNotFIFO if
+//     any segment lets a later departure arrive earlier
+func NewPiecewiseLinear(times, travel []Dist) (*PiecewiseLinear, error) {
+	if len(times) == 0 || len(times) != len(travel) {
+		return nil, fmt.Errorf("%w: need matching, non-empty breakpoint slices", ErrInvalidProfile)
+	}
+	for i := range times {
+		if math.IsNaN(float64(times[i])) || math.IsInf(float64(times[i]), 0) ||
+			math.IsNaN(float64(travel[i])) || math.IsInf(float64(travel[i]), 0) {
+			return nil, fmt.Errorf("%w: breakpoints must be finite", ErrInvalidProfile)
+		}
+		if travel[i] < 0 {
+			return nil, fmt.Errorf("%w: negative travel time", ErrInvalidProfile)
+		}
+		if i == 0 {
+			continue
+		}
+		if times[i] <= times[i-1] {
+			return nil, fmt.Errorf("%w: breakpoints must be strictly ascending", ErrInvalidProfile)
+		}
+		if times[i]+travel[i] < times[i-1]+travel[i-1] {
+			return nil, ErrNotFIFO
+		}
+	}
+	return &PiecewiseLinear{
+		times:  append([]Dist(nil), times...),
+		travel: append([]Dist(nil), travel...),
+	}, nil
+}
+
+// TravelTime returns the interpolated travel time when departing at depart.
+func (p *PiecewiseLinear) TravelTime(depart Dist) Dist {
+	n := len(p.times)
+	if depart <= p.times[0] {
+		return p.travel[0]
+	}
+	if depart >= p.times[n-1] {
+		return p.travel[n-1]
+	}
+	i := sort.Search(n, func(i int) bool { return p.times[i] > depart }) - 1
+	frac := (depart - p.times[i]) / (p.times[i+1] - p.times[i])
+	return p.travel[i] + frac*(p.travel[i+1]-p.travel[i])
+}
+
+// LatestDeparture returns the latest departure time that arrives by arriveBy,
+// inverting the (piecewise linear, non-decreasing) arrival function exactly.
+func (p *PiecewiseLinear) LatestDeparture(arriveBy Dist) Dist {
+	n := len(p.times)
+	arrival := func(i int) Dist { return p.times[i] + p.travel[i] }
+
+	if arriveBy < arrival(0) {
+		return arriveBy - p.travel[0]
+	}
+	if arriveBy >= arrival(n-1) {
+		return arriveBy - p.travel[n-1]
+	}
+	// Last breakpoint arriving no later than arriveBy; the next one arrives
+	// strictly later, so the segment has positive slope.
+	i := sort.Search(n, func(i int) bool { return arrival(i) > arriveBy }) - 1
+	frac := (arriveBy - arrival(i)) / (arrival(i+1) - arrival(i))
+	return p.times[i] + frac*(p.times[i+1]-p.times[i])
+}
+
+// TimeEdge is a directed edge whose travel time depends on the departure time.
+type TimeEdge struct {
+	To      NodeID  // destination vertex
+	Profile Profile // travel time as a function of departure time
+}
+
+// TimeGraph is a directed graph with time-dependent edge weights, queried
+// forwards with EarliestArrival. Incoming edges are indexed as well, for
+// reverse (latest-departure) queries.
+type TimeGraph struct {
+	adj  map[NodeID][]TimeEdge
+	radj map[NodeID][]timeInEdge
+}
+
+// timeInEdge is an incoming edge in the reverse index of a TimeGraph.
+type timeInEdge struct {
+	from    NodeID
+	profile Profile
+}
+
+// NewTimeGraph creates and returns a new empty time-dependent graph.
+func NewTimeGraph() *TimeGraph {
+	return &TimeGraph{
+		adj:  make(map[NodeID][]TimeEdge),
+		radj: make(map[NodeID][]timeInEdge),
+	}
+}
+
+// AddTimeDependentEdge adds a directed edge from 'from' to 'to' whose travel
+// time is given by profile.
+func (g *TimeGraph) AddTimeDependentEdge(from, to NodeID, profile Profile) {
+	g.adj[from] = append(g.adj[from], TimeEdge{To: to, Profile: profile})
+	g.radj[to] = append(g.radj[to], timeInEdge{from: from, profile: profile})
+	if _, ok := g.adj[to]; !ok {
+		g.adj[to] = nil
+	}
+}
+
+// OutEdges returns all outgoing edges from node u.
+func (g *TimeGraph) OutEdges(u NodeID) []TimeEdge {
+	return g.adj[u]
+}
+
+// latestDepartureDoublings bounds the bracketing search of latestDeparture:
+// 2^48 time units reach back far enough for timestamps in milliseconds,
+// while departure plus travel time still rounds well below the 2^53 where
+// float64 arithmetic loses whole units.
+const latestDepartureDoublings = 48
+
+// latestDeparture returns the latest departure over an edge with profile p
+// that arrives by arriveBy, -Inf if there is none.
+func latestDeparture(p Profile, arriveBy Dist) Dist {
+	if inv, ok := p.(invertibleProfile); ok {
+		return inv.LatestDeparture(arriveBy)
+	}
+
+	// Generic profiles: bracket the answer, then bisect on the monotone
+	// arrival function. Travel times are non-negative, so arriveBy is an
+	// upper bound. Arrival times may be bounded below, e.g. by a scheduled
+	// departure, so the bracket grows a bounded number of times before
+	// giving up.
+	arrives := func(t Dist) bool { return t+p.TravelTime(t) <= arriveBy }
+	negInf := Dist(math.Inf(-1))
+	hi := arriveBy
+	step := p.TravelTime(hi)
+	if !(step >= 1 && step < INF) {
+		step = 1
+	}
+	lo := hi - step
+	for i := 0; !arrives(lo); i++ {
+		if i == latestDepartureDoublings || math.IsInf(float64(lo), 0) {
+			return negInf
+		}
+		hi = lo
+		step *= 2
+		lo -= step
+	}
+	for i := 0; i < 64 && float64(hi-lo) > 1e-12*math.Max(1, math.Abs(float64(hi))); i++ {
+		mid := lo + (hi-lo)/2
+		if arrives(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// departureHeap is a max-heap of tentative latest departure times.
+type departureHeap []departureItem
+
+type departureItem struct {
+	node NodeID
+	time Dist
+}
+
+func (h departureHeap) Len() int           { return len(h) }
+func (h departureHeap) Less(i, j int) bool { return h[i].time > h[j].time }
+func (h departureHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *departureHeap) Push(x any)        { *h = append(*h, x.(departureItem)) }
+func (h *departureHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// LatestDeparture answers the reverse of an earliest-arrival query: for every
+// node, the latest time one can depart from it and still reach target by
+// arriveBy. It runs a single label-setting search backwards over incoming
+// edges, so one call produces the whole one-to-many board for a target.
+//
+// Returns:
+//   - map from every node to its latest departure time, -Inf when target
+//     cannot be reached from the node
+func LatestDeparture(g *TimeGraph, target NodeID, arriveBy Dist) map[NodeID]Dist {
+	negInf := Dist(math.Inf(-1))
+	latest := make(map[NodeID]Dist, len(g.adj))
+	for u := range g.adj {
+		latest[u] = negInf
+	}
+	if _, ok := g.adj[target]; !ok {
+		return latest
+	}
+	latest[target] = arriveBy
+
+	done := NewNodeSet()
+	pq := &departureHeap{{node: target, time: arriveBy}}
+	for pq.Len() > 0 {
+		item := heap.Pop(pq).(departureItem)
+		v := item.node
+		if done.Has(v) || item.time < latest[v] {
+			continue
+		}
+		done.Add(v)
+
+		for _, in := range g.radj[v] {
+			if done.Has(in.from) {
+				continue
+			}
+			if t := latestDeparture(in.profile, latest[v]); t > latest[in.from] {
+				latest[in.from] = t
+				heap.Push(pq, departureItem{node: in.from, time: t})
+			}
+		}
+	}
+	return latest
+}
+
+// LatestDepartures runs LatestDeparture for several arrival deadlines at the
+// same target in parallel on up to GOMAXPROCS workers, e.g. to fill an
+// arrival-time board.
+//
+// Returns:
+//   - one latest-departure map per entry of arriveBy, in the same order
+func LatestDepartures(g *TimeGraph, target NodeID, arriveBy []Dist) []map[NodeID]Dist {
+	out := make([]map[NodeID]Dist, len(arriveBy))
+	parallelFor(len(arriveBy), 0, func(i int) {
+		out[i] = LatestDeparture(g, target, arriveBy[i])
+	})
+	return out
+}
+
+// EarliestArrival computes, for every node, the earliest time it can be
+// reached when leaving source at depart, e.g. for traffic-aware routing
+// with rush-hour profiles. Each edge is traversed at the time the search
+// reaches its tail, and thanks to the FIFO property a Dijkstra-style label
+// setting search finds the earliest arrivals exactly.
+//
+// Parameters:
+//   - g: time-dependent graph
+//   - source: start node
+//   - depart: departure time at source
+//
+// Returns:
+//   - map from every node to its earliest arrival time, INF when the node
+//     cannot be reached from source
+func EarliestArrival(g *TimeGraph, source NodeID, depart Dist) map[NodeID]Dist {
+	arrival := make(map[NodeID]Dist, len(g.adj))
+	for u := range g.adj {
+		arrival[u] = INF
+	}
+	if _, ok := g.adj[source]; !ok {
+		return arrival
+	}
+	arrival[source] = depart
+
+	done := NewNodeSet()
+	pq := BinaryHeap()
+	pq.Push(source, depart)
+	for pq.Len() > 0 {
+		u, t, _ := pq.Pop()
+		if done.Has(u) || t > arrival[u] {
+			continue
+		}
+		done.Add(u)
+
+		for _, e := range g.adj[u] {
+			if done.Has(e.To) {
+				continue
+			}
+			if at := t + e.Profile.TravelTime(t); at < arrival[e.To] {
+				arrival[e.To] = at
+				pq.Push(e.To, at)
+			}
+		}
+	}
+	return arrival
+}
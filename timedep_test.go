@@ -0,0 +1,153 @@
+package bmssp
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPiecewiseLinear(t *testing.T) {
+	// Rush hour: 10 minutes before t=100, 30 minutes at t=120, 10 again from t=160
+	p, err := NewPiecewiseLinear([]Dist{100, 120, 160}, []Dist{10, 30, 10})
+	if err != nil {
+		t.Fatalf("NewPiecewiseLinear: %v", err)
+	}
+
+	cases := []struct{ depart, travel Dist }{
+		{0, 10}, {100, 10}, {110, 20}, {120, 30}, {140, 20}, {200, 10},
+	}
+	for _, c := range cases {
+		if got := p.TravelTime(c.depart); got != c.travel {
+			t.Errorf("TravelTime(%v): expected %v, got %v", c.depart, c.travel, got)
+		}
+		arrive := c.depart + c.travel
+		if got := p.LatestDeparture(arrive); math.Abs(float64(got+p.TravelTime(got)-arrive)) > 1e-9 || got < c.depart-1e-9 {
+			t.Errorf("LatestDeparture(%v): got %v, expected at least %v", arrive, got, c.depart)
+		}
+	}
+
+	if _, err := NewPiecewiseLinear([]Dist{0, 10}, []Dist{30, 5}); !errors.Is(err, ErrNotFIFO) {
+		t.Errorf("expected ErrNotFIFO, got %v", err)
+	}
+	if _, err := NewPiecewiseLinear([]Dist{10, 0}, []Dist{1, 1}); !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("expected ErrInvalidProfile, got %v", err)
+	}
+	nan, inf := Dist(math.NaN()), Dist(math.Inf(1))
+	for _, bp := range [][2][]Dist{
+		{{nan, 10}, {1, 1}},
+		{{0, inf}, {1, 1}},
+		{{-inf}, {1}},
+		{{0, 10}, {1, nan}},
+		{{0}, {inf}},
+	} {
+		if _, err := NewPiecewiseLinear(bp[0], bp[1]); !errors.Is(err, ErrInvalidProfile) {
+			t.Errorf("breakpoints %v: expected ErrInvalidProfile, got %v", bp, err)
+		}
+	}
+}
+
+// stepless is a Profile without an exact inverse, exercising the numeric search.
+type stepless struct{ base Dist }
+
+func (s stepless) TravelTime(depart Dist) Dist { return s.base + depart/10 }
+
+func TestLatestDeparture(t *testing.T) {
+	peak, _ := NewPiecewiseLinear([]Dist{0, 50}, []Dist{5, 25})
+
+	g := NewTimeGraph()
+	g.AddTimeDependentEdge(0, 1, ConstantProfile(10))
+	g.AddTimeDependentEdge(1, 3, peak)
+	g.AddTimeDependentEdge(0, 2, ConstantProfile(3))
+	g.AddTimeDependentEdge(2, 3, stepless{base: 1})
+	g.AddTimeDependentEdge(3, 4, ConstantProfile(1))
+
+	latest := LatestDeparture(g, 3, 100)
+
+	// 2->3 with travel 1 + t/10: t + 1 + t/10 = 100 => t = 90
+	if math.Abs(float64(latest[2]-90)) > 1e-6 {
+		t.Errorf("node 2: expected 90, got %v", latest[2])
+	}
+	// 1->3 is at its 25-minute plateau: 75
+	if latest[1] != 75 {
+		t.Errorf("node 1: expected 75, got %v", latest[1])
+	}
+	// Best from 0 is via 2: 87
+	if math.Abs(float64(latest[0]-87)) > 1e-6 {
+		t.Errorf("node 0: expected 87, got %v", latest[0])
+	}
+	if !math.IsInf(float64(latest[4]), -1) {
+		t.Errorf("node 4 cannot reach the target: expected -Inf, got %v", latest[4])
+	}
+
+	board := LatestDepartures(g, 3, []Dist{100, 200})
+	if board[0][1] != 75 || board[1][1] != 175 {
+		t.Errorf("board: expected node 1 departures 75 and 175, got %v and %v", board[0][1], board[1][1])
+	}
+}
+
+// scheduled is a Profile with one departure at time at, arriving at arrive:
+// earlier departures wait for it and later ones miss it.
+type scheduled struct{ at, arrive Dist }
+
+func (s scheduled) TravelTime(depart Dist) Dist {
+	if depart > s.at {
+		return INF
+	}
+	return s.arrive - depart
+}
+
+func TestLatestDeparture_Schedule(t *testing.T) {
+	p := scheduled{at: 10, arrive: 15}
+	if got := latestDeparture(p, 20); math.Abs(float64(got-10)) > 1e-6 {
+		t.Errorf("arriving by 20: expected to leave at 10, got %v", got)
+	}
+	if got := latestDeparture(p, 12); !math.IsInf(float64(got), -1) {
+		t.Errorf("arriving by 12: expected -Inf, got %v", got)
+	}
+
+	g := NewTimeGraph()
+	g.AddTimeDependentEdge(0, 1, p)
+	if latest := LatestDeparture(g, 1, 12); !math.IsInf(float64(latest[0]), -1) {
+		t.Errorf("node 0: expected -Inf, got %v", latest[0])
+	}
+}
+
+func TestEarliestArrival(t *testing.T) {
+	// Highway 0->1->3 is fast off-peak but jammed at rush hour; the side
+	// road 0->2->3 takes 40 at any time.
+	jam, err := NewPiecewiseLinear([]Dist{100, 120, 200}, []Dist{10, 60, 10})
+	if err != nil {
+		t.Fatalf("NewPiecewiseLinear: %v", err)
+	}
+	g := NewTimeGraph()
+	g.AddTimeDependentEdge(0, 1, ConstantProfile(10))
+	g.AddTimeDependentEdge(1, 3, jam)
+	g.AddTimeDependentEdge(0, 2, ConstantProfile(20))
+	g.AddTimeDependentEdge(2, 3, ConstantProfile(20))
+	g.AddTimeDependentEdge(3, 4, ConstantProfile(1))
+	g.AddTimeDependentEdge(5, 0, ConstantProfile(1))
+
+	for _, c := range []struct{ depart, want Dist }{
+		{0, 20},    // off-peak: highway, 10+10
+		{110, 150}, // rush hour at 120: highway would arrive 180
+		{300, 320}, // off-peak again
+	} {
+		arr := EarliestArrival(g, 0, c.depart)
+		if arr[3] != c.want {
+			t.Errorf("depart %v: expected arrival %v at node 3, got %v", c.depart, c.want, arr[3])
+		}
+		if arr[4] != c.want+1 || arr[0] != c.depart || arr[5] != INF {
+			t.Errorf("depart %v: unexpected arrivals %v", c.depart, arr)
+		}
+	}
+
+	// Consistent with LatestDeparture: leaving at the latest departure for
+	// an arrival deadline arrives by that deadline.
+	latest := LatestDeparture(g, 4, 200)
+	if arr := EarliestArrival(g, 0, latest[0]); arr[4] > 200+1e-9 {
+		t.Errorf("leaving at %v arrives at %v, after the deadline", latest[0], arr[4])
+	}
+	if arr := EarliestArrival(g, 42, 0); arr[0] != INF {
+		t.Errorf("unknown source should reach nothing, got %v", arr)
+	}
+}
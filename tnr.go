@@ -0,0 +1,186 @@
+package bmssp
+
+import (
+	"math"
+	"slices"
+)
+
+// accessNode is a transit node through which a node leaves or enters the
+// long-distance network, and the distance to or from it.
+type accessNode struct {
+	slot int // index into the transit table
+	dist Dist
+}
+
+// TransitNodeRouting answers long-distance queries by table lookups. A
+// small set of transit nodes, the highest ranked of a contraction
+// hierarchy, is picked, and the distances between all of them are stored
+// in a table. Every node keeps its access nodes: the transit nodes its
+// upward searches reach first. A query from s to t is then the minimum over
+// pairs of access nodes of s and t of a few additions, independent of the
+// graph size.
+//
+// The lookup is only exact for queries whose shortest path passes a
+// transit node. A locality filter detects the others, whose upward
+// searches meet below the transit nodes, and answers them by a regular
+// search instead. Every node keeps its upward search spaces for the
+// filter, so the index takes a few hundred entries per node.
+//
+// The index reflects the graph when it was built; rebuild it after the
+// graph changes. A TransitNodeRouting is safe for concurrent use.
+type TransitNodeRouting struct {
+	g     *Graph
+	h     *Hierarchy
+	slot  map[int]int // transit table slot of every transit node index
+	table []Dist      // distances between transit nodes, row-major
+	fwd   [][]accessNode
+	bwd   [][]accessNode
+	// Sorted non-transit nodes of the upward searches, for the locality
+	// filter
+	fwdSpace, bwdSpace [][]int
+}
+
+// NewTransitNodeRouting builds a contraction hierarchy of g, picks the
+// transit nodes and computes the transit table and the access nodes.
+//
+// Parameters:
+//   - g: input graph (not modified); weights must be non-negative
+//   - transitNodes: number of transit nodes; 0 for three times the square
+//     root of the number of nodes. More transit nodes make more queries
+//     global at the cost of a quadratically larger table.
+//
+// Returns:
+//   - the transit node routing index of g
+func NewTransitNodeRouting(g *Graph, transitNodes int) *TransitNodeRouting {
+	h := NewHierarchy(g)
+	n := len(h.ids)
+	if transitNodes <= 0 {
+		transitNodes = int(3 * math.Sqrt(float64(n)))
+	}
+	transitNodes = min(max(transitNodes, 1), n)
+
+	tnr := &TransitNodeRouting{g: g, h: h, slot: make(map[int]int, transitNodes)}
+	transit := make([]NodeID, transitNodes)
+	for x, r := range h.rank {
+		if s := r - (n - transitNodes); s >= 0 {
+			tnr.slot[x] = s
+			transit[s] = h.ids[x]
+		}
+	}
+	for _, row := range h.Matrix(transit, transit) {
+		tnr.table = append(tnr.table, row...)
+	}
+
+	tnr.fwd, tnr.bwd = make([][]accessNode, n), make([][]accessNode, n)
+	tnr.fwdSpace, tnr.bwdSpace = make([][]int, n), make([][]int, n)
+	parallelFor(n, 0, func(x int) {
+		tnr.fwd[x], tnr.fwdSpace[x] = tnr.access(x, h.up, true)
+		tnr.bwd[x], tnr.bwdSpace[x] = tnr.access(x, h.down, false)
+	})
+	return tnr
+}
+
+// access runs the upward search from node index x, stopping at transit
+// nodes, and returns the transit nodes reached that no other one already
+// covers, and the non-transit nodes of the search in increasing order.
+func (tnr *TransitNodeRouting) access(x int, arcs [][]chArc, forward bool) ([]accessNode, []int) {
+	isTransit := func(v int) bool {
+		_, ok := tnr.slot[v]
+		return ok
+	}
+	var found []accessNode
+	var space []int
+	for v, d := range tnr.h.upward(x, arcs, isTransit) {
+		if s, ok := tnr.slot[v]; ok {
+			found = append(found, accessNode{s, d})
+		} else {
+			space = append(space, v)
+		}
+	}
+	slices.Sort(space)
+
+	// An access node reached no faster than through another one is
+	// redundant
+	k := len(tnr.slot)
+	var nodes []accessNode
+	for _, a := range found {
+		redundant := false
+		for _, b := range found {
+			if a.slot == b.slot {
+				continue
+			}
+			via := tnr.table[b.slot*k+a.slot]
+			if !forward {
+				via = tnr.table[a.slot*k+b.slot]
+			}
+			if b.dist+via < a.dist {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			nodes = append(nodes, a)
+		}
+	}
+	return nodes, space
+}
+
+// Local reports whether the query from s to t fails the locality filter
+// and is answered by a regular search rather than the transit table.
+// Unknown nodes are local.
+func (tnr *TransitNodeRouting) Local(s, t NodeID) bool {
+	i, ok := tnr.h.index[s]
+	j, ok2 := tnr.h.index[t]
+	if !ok || !ok2 {
+		return true
+	}
+	return i == j || sortedIntersect(tnr.fwdSpace[i], tnr.bwdSpace[j])
+}
+
+// sortedIntersect reports whether two increasing slices share an element.
+func sortedIntersect(a, b []int) bool {
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] == b[0]:
+			return true
+		case a[0] < b[0]:
+			a = a[1:]
+		default:
+			b = b[1:]
+		}
+	}
+	return false
+}
+
+// Distance returns the shortest distance from s to t: by the transit table
+// for long-distance queries, and by a BMSSP search stopping at t for
+// queries failing the locality filter.
+//
+// Returns:
+//   - the distance; INF if t is unreachable or either node is not in the
+//     graph
+func (tnr *TransitNodeRouting) Distance(s, t NodeID) Dist {
+	i, ok := tnr.h.index[s]
+	j, ok2 := tnr.h.index[t]
+	if !ok || !ok2 {
+		return INF
+	}
+	if tnr.Local(s, t) {
+		S := NewNodeSet()
+		S.Add(s)
+		T := NewNodeSet()
+		T.Add(t)
+		if d, ok := Solve(tnr.g, S, INF, WithTargets(T)).Dist[t]; ok {
+			return d
+		}
+		return INF
+	}
+	k := len(tnr.slot)
+	best := INF
+	for _, a := range tnr.fwd[i] {
+		for _, b := range tnr.bwd[j] {
+			best = min(best, a.dist+tnr.table[a.slot*k+b.slot]+b.dist)
+		}
+	}
+	return best
+}
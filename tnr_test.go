@@ -0,0 +1,63 @@
+package bmssp
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// roadGrid creates a w×h grid with random weights between 1 and 10, the
+// same in both directions, which has unique shortest paths like a road
+// network rather than the many ties of generateGridGraph.
+func roadGrid(w, h int, seed int64) *Graph {
+	rng := rand.New(rand.NewSource(seed))
+	g := NewGraph()
+	link := func(u, v NodeID) {
+		d := Dist(1 + 9*rng.Float64())
+		g.AddEdge(u, v, d)
+		g.AddEdge(v, u, d)
+	}
+	for y := range h {
+		for x := range w {
+			v := NodeID(y*w + x)
+			if x+1 < w {
+				link(v, v+1)
+			}
+			if y+1 < h {
+				link(v, v+NodeID(w))
+			}
+		}
+	}
+	return g
+}
+
+func TestTransitNodeRouting(t *testing.T) {
+	for name, g := range map[string]*Graph{
+		"grid":   roadGrid(40, 40, 4),
+		"random": generateRandomGraph(300, 1200, 10, 9),
+	} {
+		tnr := NewTransitNodeRouting(g, 0)
+		rng := rand.New(rand.NewSource(2))
+		global := 0
+		for range 200 {
+			s, target := NodeID(rng.Intn(len(g.adj))), NodeID(rng.Intn(len(g.adj)))
+			want := SolveDijkstra(g, sources(s)).Dist[target]
+			if got := tnr.Distance(s, target); got != want && !approxEqual(got, want, 1e-9) {
+				t.Errorf("%s: %d -> %d (local %v): expected %v, got %v", name, s, target, tnr.Local(s, target), want, got)
+			}
+			if !tnr.Local(s, target) {
+				global++
+			}
+		}
+		if name == "grid" && global < 100 {
+			t.Errorf("%s: expected most random queries to use the table, got %d of 200", name, global)
+		}
+	}
+
+	g := NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(2, 3, 1)
+	tnr := NewTransitNodeRouting(g, 1)
+	if tnr.Distance(0, 1) != 1 || tnr.Distance(0, 3) != INF || tnr.Distance(0, 9) != INF || tnr.Distance(2, 2) != 0 {
+		t.Errorf("unexpected distances on a disconnected graph")
+	}
+}
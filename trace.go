@@ -0,0 +1,52 @@
+package bmssp
+
+// Attr is a key-value attribute of a tracing span. Value is an int, a
+// float64 or a string.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// Span is a timed operation reported to a Tracer.
+type Span interface {
+	// SetAttributes adds attributes known only when the operation ends.
+	SetAttributes(attrs ...Attr)
+	// End finishes the span.
+	End()
+}
+
+// Tracer receives spans for the phases of a query, so that slow queries
+// can be broken down. It mirrors the OpenTelemetry API closely enough for a
+// thin adapter: StartSpan maps to trace.Tracer.Start with the context of the
+// parent span, and Attr to attribute.KeyValue.
+//
+// A query produces a "bmssp.query" span holding a "bmssp.level" span for
+// every level of the recursion, each of which holds the
+// "bmssp.delta_stepping" spans of its Δ-stepping passes. Dijkstra queries
+// produce a single "bmssp.dijkstra" span.
+type Tracer interface {
+	// StartSpan starts a span as a child of parent, which is nil for the
+	// root span of a query.
+	StartSpan(parent Span, name string, attrs ...Attr) Span
+}
+
+// WithTracer reports the phases of the query to t as spans.
+func WithTracer(t Tracer) Option {
+	return func(c *config) { c.tracer = t }
+}
+
+// beginSpan starts a child of the current span and makes it current. The
+// returned function ends it with the given attributes and restores the
+// parent. The caller must check that a tracer is configured.
+func (s *solver) beginSpan(name string, attrs ...Attr) func(attrs ...Attr) {
+	parent := s.span
+	sp := s.cfg.tracer.StartSpan(parent, name, attrs...)
+	s.span = sp
+	return func(attrs ...Attr) {
+		if len(attrs) > 0 {
+			sp.SetAttributes(attrs...)
+		}
+		sp.End()
+		s.span = parent
+	}
+}
@@ -0,0 +1,80 @@
+package bmssp
+
+import (
+	"strings"
+	"testing"
+)
+
+// recordingSpan records its name, depth in the span tree and attributes.
+type recordingSpan struct {
+	name  string
+	level int
+	attrs map[string]any
+	ended bool
+}
+
+func (s *recordingSpan) SetAttributes(attrs ...Attr) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordingSpan) End() { s.ended = true }
+
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) StartSpan(parent Span, name string, attrs ...Attr) Span {
+	sp := &recordingSpan{name: name, attrs: map[string]any{}}
+	if p, ok := parent.(*recordingSpan); ok {
+		sp.level = p.level + 1
+	}
+	sp.SetAttributes(attrs...)
+	t.spans = append(t.spans, sp)
+	return sp
+}
+
+// tree renders the span names indented by level.
+func (t *recordingTracer) tree() string {
+	var b strings.Builder
+	for _, sp := range t.spans {
+		b.WriteString(strings.Repeat("  ", sp.level) + sp.name + "\n")
+	}
+	return b.String()
+}
+
+func TestWithTracer(t *testing.T) {
+	g := generateGridGraph(10, 10)
+
+	tr := &recordingTracer{}
+	res := Solve(g, sources(0, 55), 12, WithTracer(tr), WithoutFastPaths())
+	if len(tr.spans) < 3 || tr.spans[0].name != "bmssp.query" || tr.spans[1].name != "bmssp.level" {
+		t.Fatalf("expected a query span holding level spans, got:\n%s", tr.tree())
+	}
+	root := tr.spans[0]
+	if root.attrs["sources"] != 2 || root.attrs["nodes_settled"] != res.Stats.NodesSettled || root.attrs["stopped"] != "complete" {
+		t.Errorf("unexpected root attributes %v", root.attrs)
+	}
+	settled := 0
+	for _, sp := range tr.spans {
+		if !sp.ended {
+			t.Errorf("span %s was not ended", sp.name)
+		}
+		if sp.name == "bmssp.delta_stepping" {
+			if sp.level < 2 {
+				t.Errorf("expected Δ-stepping spans below a level span, got:\n%s", tr.tree())
+			}
+			settled += sp.attrs["nodes_settled"].(int)
+		}
+	}
+	if settled != res.Stats.NodesSettled {
+		t.Errorf("expected Δ-stepping spans to account for %d settled nodes, got %d", res.Stats.NodesSettled, settled)
+	}
+
+	tr = &recordingTracer{}
+	SolveDijkstra(g, sources(0), WithTracer(tr))
+	if tr.tree() != "bmssp.dijkstra\n" {
+		t.Errorf("expected a single Dijkstra span, got:\n%s", tr.tree())
+	}
+}
@@ -0,0 +1,121 @@
+package bmssp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// Tree is the shortest-path tree of a query: every reached node with the
+// node it is reached from. Queries from several sources give a forest with
+// one root per source.
+type Tree struct {
+	Roots    []NodeID            // sources, ascending
+	Parent   map[NodeID]NodeID   // parent of every non-root node
+	Children map[NodeID][]NodeID // children of every node, ascending
+	Dist     map[NodeID]Dist     // distance of every node from its root
+	Depth    map[NodeID]int      // number of edges from the root
+	Size     map[NodeID]int      // number of nodes in the subtree, the node included
+}
+
+// Tree builds the shortest-path tree of r from its predecessors, e.g. for
+// analyses of the routes through each node. Only reached nodes are part of
+// it.
+func (r *Result) Tree() *Tree {
+	t := &Tree{
+		Parent:   make(map[NodeID]NodeID, len(r.Pred)),
+		Children: make(map[NodeID][]NodeID),
+		Dist:     make(map[NodeID]Dist, len(r.Dist)),
+		Depth:    make(map[NodeID]int, len(r.Dist)),
+		Size:     make(map[NodeID]int, len(r.Dist)),
+	}
+	for v, d := range r.Dist {
+		if d == INF {
+			continue
+		}
+		t.Dist[v] = d
+		if u, ok := r.Pred[v]; ok {
+			t.Parent[v] = u
+			t.Children[u] = append(t.Children[u], v)
+		} else {
+			t.Roots = append(t.Roots, v)
+		}
+	}
+	slices.Sort(t.Roots)
+	for _, c := range t.Children {
+		slices.Sort(c)
+	}
+
+	// Depths top-down in breadth-first order, sizes bottom-up in reverse
+	order := slices.Clone(t.Roots)
+	for _, v := range t.Roots {
+		t.Depth[v] = 0
+	}
+	for i := 0; i < len(order); i++ {
+		for _, c := range t.Children[order[i]] {
+			t.Depth[c] = t.Depth[order[i]] + 1
+			order = append(order, c)
+		}
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		v := order[i]
+		t.Size[v]++
+		if u, ok := t.Parent[v]; ok {
+			t.Size[u] += t.Size[v]
+		}
+	}
+	return t
+}
+
+// PathTo returns the tree path from the root of v to v, or nil if v is not
+// in the tree.
+func (t *Tree) PathTo(v NodeID) []NodeID {
+	if _, ok := t.Dist[v]; !ok {
+		return nil
+	}
+	return pathTo(t.Parent, v)
+}
+
+// Subtree returns v and its descendants in depth-first preorder, children
+// in ascending order, or nil if v is not in the tree.
+func (t *Tree) Subtree(v NodeID) []NodeID {
+	if _, ok := t.Dist[v]; !ok {
+		return nil
+	}
+	out := make([]NodeID, 0, t.Size[v])
+	stack := []NodeID{v}
+	for len(stack) > 0 {
+		u := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		out = append(out, u)
+		children := t.Children[u]
+		for i := len(children) - 1; i >= 0; i-- {
+			stack = append(stack, children[i])
+		}
+	}
+	return out
+}
+
+// WriteDOT writes t in the Graphviz DOT language, each node labeled with its
+// distance. Nodes and edges are written in preorder from the roots, so the
+// output is stable across runs. See WriteDOT for whole graphs.
+//
+// Returns:
+//   - the first write error
+func (t *Tree) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph \"tree\" {")
+	for _, root := range t.Roots {
+		for _, v := range t.Subtree(root) {
+			label := fmt.Sprintf("%d\n%g", v, float64(t.Dist[v]))
+			fmt.Fprintf(bw, "  %d [label=%s];\n", v, strconv.Quote(label))
+			if u, ok := t.Parent[v]; ok {
+				fmt.Fprintf(bw, "  %d -> %d;\n", u, v)
+			}
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
@@ -0,0 +1,53 @@
+package bmssp
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestResultTree(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(0, 2, 4)
+	g.AddEdge(1, 2, 1)
+	g.AddEdge(1, 3, 5)
+	g.AddEdge(2, 3, 1)
+	g.AddEdge(5, 6, 1)
+	g.AddEdge(7, 0, 1)
+	tree := Solve(g, sources(0, 5), INF).Tree()
+
+	if !slices.Equal(tree.Roots, []NodeID{0, 5}) {
+		t.Errorf("expected roots 0 and 5, got %v", tree.Roots)
+	}
+	if !slices.Equal(tree.PathTo(3), []NodeID{0, 1, 2, 3}) || tree.Dist[3] != 3 || tree.Depth[3] != 3 {
+		t.Errorf("unexpected path %v to 3 at %v", tree.PathTo(3), tree.Dist[3])
+	}
+	if tree.Size[0] != 4 || tree.Size[1] != 3 || tree.Size[5] != 2 || tree.Size[3] != 1 {
+		t.Errorf("unexpected sizes %v", tree.Size)
+	}
+	if !slices.Equal(tree.Subtree(1), []NodeID{1, 2, 3}) || tree.Subtree(7) != nil || tree.PathTo(7) != nil {
+		t.Errorf("unexpected subtree %v", tree.Subtree(1))
+	}
+
+	var sb strings.Builder
+	if err := tree.WriteDOT(&sb); err != nil {
+		t.Fatal(err)
+	}
+	want := "digraph \"tree\" {\n" +
+		"  0 [label=\"0\\n0\"];\n" +
+		"  1 [label=\"1\\n1\"];\n  0 -> 1;\n" +
+		"  2 [label=\"2\\n2\"];\n  1 -> 2;\n" +
+		"  3 [label=\"3\\n3\"];\n  2 -> 3;\n" +
+		"  5 [label=\"5\\n0\"];\n" +
+		"  6 [label=\"6\\n1\"];\n  5 -> 6;\n}\n"
+	if sb.String() != want {
+		t.Errorf("unexpected DOT output:\n%s", sb.String())
+	}
+
+	// Sizes add up on larger trees
+	big := Solve(generateGridGraph(12, 12), sources(0), INF).Tree()
+	if big.Size[0] != 144 || len(big.Subtree(0)) != 144 {
+		t.Errorf("expected the tree to span 144 nodes, got %d", big.Size[0])
+	}
+}
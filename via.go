@@ -0,0 +1,72 @@
+package bmssp
+
+import "fmt"
+
+// ViaRoute is a route through a sequence of waypoints.
+type ViaRoute struct {
+	Path []NodeID // node sequence from the first to the last waypoint, nil if a leg is unreachable
+	Cost Dist     // total cost, INF if a leg is unreachable
+	Legs []Dist   // cost of each leg, from waypoint i to waypoint i+1
+}
+
+// RouteVia computes a route visiting waypoints in the given order, e.g. a
+// delivery round, by chaining point-to-point searches. Legs leaving the same
+// waypoint share one search, which stops once all their destinations are
+// settled, so a round returning to its depot costs no extra search per
+// visit.
+//
+// Parameters:
+//   - g: input graph
+//   - waypoints: nodes to visit in order; at least one
+//   - opts: optional query settings applied to every leg
+//
+// Returns:
+//   - the concatenated route and the cost of every leg; unreachable legs
+//     have cost INF
+//   - ErrNodeNotFound if a waypoint is not in the graph
+func RouteVia(g *Graph, waypoints []NodeID, opts ...Option) (ViaRoute, error) {
+	for _, v := range waypoints {
+		if _, ok := g.adj[v]; !ok {
+			return ViaRoute{}, fmt.Errorf("%w: %d", ErrNodeNotFound, v)
+		}
+	}
+	if len(waypoints) == 0 {
+		return ViaRoute{}, nil
+	}
+
+	// Group the legs by origin
+	targets := make(map[NodeID]NodeSet)
+	var origins []NodeID
+	for i := 1; i < len(waypoints); i++ {
+		from := waypoints[i-1]
+		if targets[from] == nil {
+			targets[from] = NewNodeSet()
+			origins = append(origins, from)
+		}
+		targets[from].Add(waypoints[i])
+	}
+	trees := make(map[NodeID]*Result, len(origins))
+	for _, from := range origins {
+		S := NewNodeSet()
+		S.Add(from)
+		trees[from] = Solve(g, S, INF, append(opts[:len(opts):len(opts)], WithTargets(targets[from]))...)
+	}
+
+	r := ViaRoute{Path: []NodeID{waypoints[0]}, Legs: make([]Dist, len(waypoints)-1)}
+	for i := 1; i < len(waypoints); i++ {
+		tree := trees[waypoints[i-1]]
+		d, ok := tree.Dist[waypoints[i]]
+		if !ok || d == INF {
+			r.Legs[i-1], r.Path = INF, nil
+			continue
+		}
+		r.Legs[i-1] = d
+		if r.Path != nil {
+			r.Path = append(r.Path, tree.PathTo(waypoints[i])[1:]...)
+		}
+	}
+	for _, d := range r.Legs {
+		r.Cost += d
+	}
+	return r, nil
+}
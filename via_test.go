@@ -0,0 +1,39 @@
+package bmssp
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestRouteVia(t *testing.T) {
+	g := generateGridGraph(5, 5)
+	r, err := RouteVia(g, []NodeID{0, 4, 24, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(r.Legs, []Dist{4, 4, 8}) || r.Cost != 16 {
+		t.Errorf("unexpected legs %v and cost %v", r.Legs, r.Cost)
+	}
+	if len(r.Path) != 17 || r.Path[0] != 0 || r.Path[4] != 4 || r.Path[8] != 24 || r.Path[16] != 0 {
+		t.Errorf("unexpected path %v", r.Path)
+	}
+	if cost, ok := PathCost(g, r.Path); !ok || cost != r.Cost {
+		t.Errorf("path costs %v, reported %v", cost, r.Cost)
+	}
+
+	// Repeated waypoints add empty legs
+	r, _ = RouteVia(g, []NodeID{3, 3, 8})
+	if !slices.Equal(r.Path, []NodeID{3, 8}) || !slices.Equal(r.Legs, []Dist{0, 1}) {
+		t.Errorf("unexpected route %+v", r)
+	}
+
+	g.AddEdge(25, 0, 1)
+	r, _ = RouteVia(g, []NodeID{0, 25, 1})
+	if r.Path != nil || r.Cost != INF || r.Legs[0] != INF || r.Legs[1] != 2 {
+		t.Errorf("expected an unreachable first leg, got %+v", r)
+	}
+	if _, err := RouteVia(g, []NodeID{0, 99}); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("expected ErrNodeNotFound, got %v", err)
+	}
+}
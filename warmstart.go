@@ -0,0 +1,203 @@
+package bmssp
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"time"
+)
+
+// ErrNotResumable is returned by ResumeBMSSP for results that cannot be
+// extended.
+var ErrNotResumable = errors.New("bmssp: result cannot be resumed")
+
+// warmStartTolerance is the relative tolerance used when checking that an
+// edge is tight (dhat[u]+w == dhat[v]) during warm-start certification.
+const warmStartTolerance = DefaultEpsilon
+
+// WarmStart computes shortest distances from source like BMSSPSingleSource,
+// but seeds tentative distances from prev, a previous result computed from a
+// nearby source (for example an earlier position of a vehicle along its route).
+//
+// Seeds are derived as prev[v] - prev[source], which is exact for every node
+// whose previous shortest path passed through the new source. The seeds are
+// then made safe in three steps:
+//  1. only seeds realized by a chain of tight edges from source are kept,
+//     so every remaining value is the length of an actual path;
+//  2. a bounded BMSSP pass is started from the heads of all edges violating
+//     the shortest-path condition, lowering every seed that is too high;
+//  3. a verification pass checks the shortest-path condition on every edge
+//     within the bound, falling back to a full recomputation on failure.
+//
+// Parameters:
+//   - g: input graph
+//   - source: new source node
+//   - B: distance bound
+//   - prev: distance map of a previous query on the same graph
+//
+// Returns:
+//   - map of shortest distances from source
+func WarmStart(g *Graph, source NodeID, B Dist, prev map[NodeID]Dist) map[NodeID]Dist {
+	offset, ok := prev[source]
+	if !ok || offset == INF {
+		return BMSSPSingleSource(g, source, B)
+	}
+
+	dhat := newDistanceMap(g)
+	for v, d := range prev {
+		if _, known := dhat[v]; known && d < INF && d >= offset {
+			dhat[v] = d - offset
+		}
+	}
+	dhat[source] = 0
+
+	certified := tightClosure(g, source, dhat)
+	for v := range dhat {
+		if !certified.Has(v) {
+			dhat[v] = INF
+		}
+	}
+
+	// Relax every edge violating the shortest-path condition and restart
+	// the search from its head.
+	S := NewNodeSet()
+	S.Add(source)
+	for u, edges := range g.adj {
+		for _, e := range edges {
+			if dhat[u]+e.Weight < dhat[e.To] {
+				dhat[e.To] = dhat[u] + e.Weight
+				S.Add(e.To)
+			}
+		}
+	}
+	s := &solver{g: g, dhat: dhat}
+	s.run(B, S)
+
+	if !feasible(g, dhat, B) {
+		return BMSSPSingleSource(g, source, B)
+	}
+	return dhat
+}
+
+// isTight reports whether du+w equals dv up to warmStartTolerance.
+func isTight(du, w, dv Dist) bool {
+	return math.Abs(float64(du+w-dv)) <= float64(warmStartTolerance)*math.Max(1, math.Abs(float64(dv)))
+}
+
+// tightClosure returns the nodes reachable from source through tight edges.
+// Their distances are realized by actual paths.
+func tightClosure(g *Graph, source NodeID, dhat map[NodeID]Dist) NodeSet {
+	closure := NewNodeSet()
+	closure.Add(source)
+	stack := []NodeID{source}
+	for len(stack) > 0 {
+		u := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, e := range g.adj[u] {
+			if !closure.Has(e.To) && isTight(dhat[u], e.Weight, dhat[e.To]) {
+				closure.Add(e.To)
+				stack = append(stack, e.To)
+			}
+		}
+	}
+	return closure
+}
+
+// feasible reports whether no edge leaving a node within bound B can still
+// be relaxed.
+func feasible(g *Graph, dhat map[NodeID]Dist, B Dist) bool {
+	for u, edges := range g.adj {
+		if dhat[u] > B {
+			continue
+		}
+		for _, e := range edges {
+			if dhat[u]+e.Weight < dhat[e.To] && !isTight(dhat[u], e.Weight, dhat[e.To]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// resumable records the query of s in r for ResumeBMSSP.
+func (s *solver) resumable(r *Result) *Result {
+	r.g, r.version, r.cfg, r.bound = s.g, s.g.version, s.cfg, s.limit
+	return r
+}
+
+// ResumeBMSSP extends a bounded query to a bound larger by additionalBound,
+// e.g. to grow an isochrone step by step. Distances within the old bound are
+// final and kept as they are: only the edges leaving that region are
+// scanned again, and the search continues from the nodes they reach.
+//
+// The query runs on the graph and with the options of prev; opts are
+// applied on top, e.g. to set a new deadline. prev is not modified.
+//
+// Parameters:
+//   - prev: a complete result of Solve or SolveFrom
+//   - additionalBound: how far beyond the old bound to search
+//   - opts: optional query settings overriding those of prev
+//
+// Returns:
+//   - the result within the new bound; its Stats count the new work only
+//   - ErrNotResumable if prev did not come from Solve or SolveFrom, stopped
+//     early, used a hop limit or hop tie-break, or its graph has changed
+//   - ErrInvalidWeight for a negative or NaN additionalBound
+func ResumeBMSSP(prev *Result, additionalBound Dist, opts ...Option) (*Result, error) {
+	switch {
+	case prev.g == nil:
+		return nil, fmt.Errorf("%w: not a result of Solve or SolveFrom", ErrNotResumable)
+	case prev.Stopped != StopComplete:
+		return nil, fmt.Errorf("%w: query stopped early (%v)", ErrNotResumable, prev.Stopped)
+	case prev.cfg.maxHops > 0 || prev.cfg.tieBreak == TieBreakFewestHops:
+		return nil, fmt.Errorf("%w: hop counts are not kept", ErrNotResumable)
+	case prev.g.version != prev.version:
+		return nil, fmt.Errorf("%w: graph changed since the query", ErrNotResumable)
+	case !validWeight(additionalBound):
+		return nil, fmt.Errorf("%w: bound %v", ErrInvalidWeight, additionalBound)
+	}
+	start := time.Now()
+
+	cfg := prev.cfg
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	s := &solver{g: prev.g, dhat: maps.Clone(prev.Dist), pred: maps.Clone(prev.Pred), cfg: cfg}
+	s.prepare()
+
+	// Distances within the old bound are final: continue beyond it
+	old := prev.bound
+	s.limit = old + additionalBound
+	S := s.reopen(old)
+	s.run(s.limit, S)
+	return s.resumable(s.result(start)), nil
+}
+
+// reopen prepares s to continue a search whose distances are in s.dhat,
+// all lengths of actual paths: the edges leaving nodes within limit are
+// relaxed wherever they still improve a distance, and the nodes to continue
+// from are returned, the improved heads and every node beyond limit.
+// Nodes left beyond s.limit, the bound of the continued search, mark the
+// result as truncated: the search will not settle them.
+func (s *solver) reopen(limit Dist) NodeSet {
+	S := NewNodeSet()
+	for u, du := range s.dhat {
+		if du > limit {
+			if du < INF {
+				s.cut = s.cut || du > s.limit
+				S.Add(u)
+			}
+			continue
+		}
+		for _, e := range s.outEdges(u) {
+			s.stats.EdgesScanned++
+			if d := du + s.weight(u, e); s.improves(u, e.To, d) {
+				s.cut = s.cut || d > s.limit
+				s.relax(u, e.To, d)
+				S.Add(e.To)
+			}
+		}
+	}
+	return S
+}
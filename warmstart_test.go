@@ -0,0 +1,99 @@
+package bmssp
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestWarmStart_MatchesColdRun(t *testing.T) {
+	g := generateGridGraph(15, 15)
+	prev := BMSSPSingleSource(g, 0, 1000)
+
+	// The "vehicle" moves along its route from node 0 to node 1, then 16
+	for _, source := range []NodeID{1, 16} {
+		got := WarmStart(g, source, 1000, prev)
+		want := Dijkstra(g, source)
+		for v, d := range want {
+			if math.Abs(float64(got[v]-d)) > 1e-9 {
+				t.Fatalf("source %d, node %d: expected %v, got %v", source, v, d, got[v])
+			}
+		}
+		prev = got
+	}
+}
+
+func TestWarmStart_RepairsUnderestimates(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(0, 2, 1)
+	g.AddEdge(1, 2, 5)
+	g.AddEdge(2, 3, 1)
+
+	// Seeding from source 0 would place nodes 2 and 3 at distances 0 and 1
+	// from node 1, both far below their true distances
+	prev := BMSSPSingleSource(g, 0, 1000)
+	got := WarmStart(g, 1, 1000, prev)
+	if got[0] != INF || got[1] != 0 || got[2] != 5 || got[3] != 6 {
+		t.Errorf("expected {0:+Inf 1:0 2:5 3:6}, got %v", got)
+	}
+}
+
+func TestResumeBMSSP(t *testing.T) {
+	g := generateRandomGraph(500, 3000, 10, 3)
+	want := Solve(g, sources(0), 30)
+	for _, opts := range [][]Option{nil, {WithoutFastPaths()}} {
+		res := Solve(g, sources(0), 10, opts...)
+		for _, step := range []Dist{5, 0, 15} {
+			next, err := ResumeBMSSP(res, step)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next.Stats.NodesSettled > len(g.adj)/2 && step == 0 {
+				t.Errorf("resuming with no extra bound settled %d nodes", next.Stats.NodesSettled)
+			}
+			res = next
+		}
+		for v, d := range want.Dist {
+			if d <= 30 && !approxEqual(res.Dist[v], d, DefaultEpsilon) {
+				t.Errorf("node %d: expected %v, got %v", v, d, res.Dist[v])
+			}
+			if d <= 30 && d > 0 && res.Pred[v] == v {
+				t.Errorf("node %d: bad predecessor", v)
+			}
+		}
+		if res.Truncated != want.Truncated {
+			t.Errorf("expected truncated=%v, got %v", want.Truncated, res.Truncated)
+		}
+	}
+
+	// Results that cannot be resumed
+	if _, err := ResumeBMSSP(Solve(g, sources(0), 10, WithNodeBudget(5)), 10); !errors.Is(err, ErrNotResumable) {
+		t.Errorf("expected ErrNotResumable for a stopped query, got %v", err)
+	}
+	if _, err := ResumeBMSSP(SolveDijkstra(g, sources(0)), 10); !errors.Is(err, ErrNotResumable) {
+		t.Errorf("expected ErrNotResumable for a Dijkstra result, got %v", err)
+	}
+	res := Solve(g, sources(0), 10)
+	g.AddEdge(0, 499, 1)
+	if _, err := ResumeBMSSP(res, 10); !errors.Is(err, ErrNotResumable) {
+		t.Errorf("expected ErrNotResumable after a change, got %v", err)
+	}
+}
+
+func TestResumeBMSSP_CarriedFrontier(t *testing.T) {
+	// Node 1 is reached at 4, beyond both the first bound and the resumed one
+	g := NewGraph()
+	g.AddEdge(0, 1, 4)
+	g.AddEdge(1, 2, 3)
+	res, err := ResumeBMSSP(Solve(g, sources(0), 1), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Truncated {
+		t.Errorf("expected a truncated result with nodes 1 and 2 unsettled, got %v", res.Dist)
+	}
+	if res, _ = ResumeBMSSP(res, 5); res.Truncated || res.Dist[2] != 7 {
+		t.Errorf("expected node 2 settled at 7 within bound 7, got %v (truncated=%v)", res.Dist[2], res.Truncated)
+	}
+}
@@ -0,0 +1,120 @@
+package bmssp
+
+// EdgeUpdate is a hypothetical change to the edges from From to To. If no
+// such edge exists it is added with the given weight.
+type EdgeUpdate struct {
+	From   NodeID
+	To     NodeID
+	Weight Dist // new weight (ignored when Remove is set)
+	Remove bool // remove the edge instead of updating it
+}
+
+// Query is a point-to-point shortest-path request.
+type Query struct {
+	Source NodeID
+	Target NodeID
+}
+
+// QueryResult is the answer to a Query.
+type QueryResult struct {
+	Query
+	Path []NodeID // nil if Target is unreachable
+	Dist Dist     // INF if Target is unreachable
+}
+
+// scenario is a copy-on-write overlay of hypothetical edge updates on top of
+// a graph. Only the changed edges are stored; everything else is read from
+// the graph.
+type scenario struct {
+	weights map[EdgeID]Dist   // updated weights, INF for removed edges
+	added   map[NodeID][]Edge // edges that do not exist in the graph
+}
+
+func newScenario(g *Graph, changes []EdgeUpdate) *scenario {
+	sc := &scenario{
+		weights: make(map[EdgeID]Dist),
+		added:   make(map[NodeID][]Edge),
+	}
+	for _, c := range changes {
+		id := EdgeID{From: c.From, To: c.To}
+		w := c.Weight
+		if c.Remove {
+			w = INF
+		}
+		if hasEdge(g, c.From, c.To) {
+			sc.weights[id] = w
+			continue
+		}
+
+		// Changes to an added edge replace it
+		kept := sc.added[c.From][:0]
+		for _, e := range sc.added[c.From] {
+			if e.To != c.To {
+				kept = append(kept, e)
+			}
+		}
+		if !c.Remove {
+			kept = append(kept, Edge{To: c.To, Weight: w})
+		}
+		sc.added[c.From] = kept
+	}
+	return sc
+}
+
+// hasEdge reports whether g contains an edge from u to v.
+func hasEdge(g *Graph, u, v NodeID) bool {
+	for _, e := range g.adj[u] {
+		if e.To == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Weight returns the scenario weight of edge e leaving from.
+func (sc *scenario) Weight(from NodeID, e Edge) Dist {
+	if w, ok := sc.weights[EdgeID{From: from, To: e.To}]; ok {
+		return w
+	}
+	return e.Weight
+}
+
+// ExtraEdges returns the edges added by the scenario.
+func (sc *scenario) ExtraEdges(from NodeID) []Edge {
+	return sc.added[from]
+}
+
+// Simulate answers queries as if changes had been applied to g, without
+// touching g: the changes live in a copy-on-write overlay that is discarded
+// afterwards. This supports capacity-planning studies such as "what if we
+// add this link" against a live graph.
+//
+// Parameters:
+//   - g: input graph (not modified)
+//   - changes: hypothetical edge additions, weight changes and removals,
+//     applied in order
+//   - queries: point-to-point queries to answer under the changes
+//
+// Returns:
+//   - one result per query, in the same order
+func Simulate(g *Graph, changes []EdgeUpdate, queries []Query) []QueryResult {
+	sc := newScenario(g, changes)
+
+	out := make([]QueryResult, len(queries))
+	for i, q := range queries {
+		s := newSolver(g, []Option{WithOverlay(sc)})
+		s.pred = make(map[NodeID]NodeID)
+		s.dhat[q.Source] = 0
+
+		S := NewNodeSet()
+		S.Add(q.Source)
+		s.run(INF, S)
+
+		out[i] = QueryResult{Query: q, Dist: INF}
+		if d, ok := s.dhat[q.Target]; ok && d < INF {
+			out[i].Dist = d
+			out[i].Path = pathTo(s.pred, q.Target)
+		}
+	}
+	return out
+}
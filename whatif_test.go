@@ -0,0 +1,38 @@
+package bmssp
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSimulate(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(0, 1, 4)
+	g.AddEdge(1, 2, 4)
+	g.AddEdge(0, 3, 1)
+	g.AddEdge(3, 2, 10)
+
+	queries := []Query{{Source: 0, Target: 2}, {Source: 0, Target: 9}}
+	results := Simulate(g, []EdgeUpdate{
+		{From: 3, To: 2, Weight: 2},    // cheaper existing link
+		{From: 0, To: 1, Remove: true}, // failed link
+		{From: 2, To: 9, Weight: 1},    // new link to a new node
+		{From: 0, To: 9, Weight: 99},   // added, then removed again
+		{From: 0, To: 9, Remove: true},
+	}, queries)
+
+	if r := results[0]; r.Dist != 3 || !slices.Equal(r.Path, []NodeID{0, 3, 2}) {
+		t.Errorf("query 0->2: expected [0 3 2] of cost 3, got %v of cost %v", r.Path, r.Dist)
+	}
+	if r := results[1]; r.Dist != 4 || !slices.Equal(r.Path, []NodeID{0, 3, 2, 9}) {
+		t.Errorf("query 0->9: expected [0 3 2 9] of cost 4, got %v of cost %v", r.Path, r.Dist)
+	}
+
+	// The live graph is untouched
+	if path, d := ShortestPath(g, 0, 2); d != 8 || !slices.Equal(path, []NodeID{0, 1, 2}) {
+		t.Errorf("live graph: expected [0 1 2] of cost 8, got %v of cost %v", path, d)
+	}
+	if _, d := ShortestPath(g, 0, 9); d != INF {
+		t.Errorf("live graph: node 9 should not exist, got dist %v", d)
+	}
+}
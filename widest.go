@@ -0,0 +1,88 @@
+package bmssp
+
+// WidestResult holds the widest (maximum-bottleneck) paths from a source.
+type WidestResult struct {
+	Width map[NodeID]Dist   // bottleneck of the widest path; 0 if unreachable, INF at the source
+	Pred  map[NodeID]NodeID // predecessors on the widest paths; the source has none
+}
+
+// PathTo returns a widest path from the source to v, or nil if v is
+// unreachable.
+func (r *WidestResult) PathTo(v NodeID) []NodeID {
+	if r.Width[v] == 0 {
+		return nil
+	}
+	return pathTo(r.Pred, v)
+}
+
+// WidestPaths computes, for every node, the path from source that maximizes
+// its smallest edge weight, e.g. the route with the most bandwidth when
+// weights are link capacities. It is Dijkstra's algorithm with the min-plus
+// relaxation replaced by max-min: nodes are settled by decreasing width, and
+// an edge extends a path with the smaller of the path's width and its own.
+// Edges with weight INF are closed, as elsewhere, and never used.
+//
+// Parameters:
+//   - g: input graph with capacities as weights
+//   - source: source node
+//   - opts: optional query settings; WithQueue selects the priority queue
+//
+// Returns:
+//   - the widths of and paths to every node
+func WidestPaths(g *Graph, source NodeID, opts ...Option) *WidestResult {
+	return widest(g, source, nil, opts)
+}
+
+// WidestPath returns a maximum-bottleneck path from source to target and
+// its width, stopping once target is settled. See WidestPaths.
+//
+// Returns:
+//   - the node sequence from source to target (nil if target is unreachable)
+//   - the smallest edge weight on the path (0 if target is unreachable)
+func WidestPath(g *Graph, source, target NodeID, opts ...Option) ([]NodeID, Dist) {
+	r := widest(g, source, &target, opts)
+	return r.PathTo(target), r.Width[target]
+}
+
+// widest runs the max-min search from source, stopping at target if it is
+// not nil.
+func widest(g *Graph, source NodeID, target *NodeID, opts []Option) *WidestResult {
+	r := &WidestResult{Width: make(map[NodeID]Dist, len(g.adj)), Pred: make(map[NodeID]NodeID)}
+	for u := range g.adj {
+		r.Width[u] = 0
+	}
+	if _, ok := g.adj[source]; !ok {
+		return r
+	}
+	r.Width[source] = INF
+
+	newQueue := newConfig(opts).newQueue
+	if newQueue == nil {
+		newQueue = BinaryHeap
+	}
+	// The queues are min-queues, so widths are stored negated
+	pq := newQueue()
+	pq.Push(source, -INF)
+	settled := NewNodeSet()
+	for pq.Len() > 0 {
+		u, negW, _ := pq.Pop()
+		if settled.Has(u) || -negW < r.Width[u] {
+			continue
+		}
+		settled.Add(u)
+		if target != nil && u == *target {
+			break
+		}
+		for _, e := range g.adj[u] {
+			if e.Weight == INF {
+				continue
+			}
+			if w := min(r.Width[u], e.Weight); w > r.Width[e.To] {
+				r.Width[e.To] = w
+				r.Pred[e.To] = u
+				pq.Push(e.To, -w)
+			}
+		}
+	}
+	return r
+}
@@ -0,0 +1,84 @@
+package bmssp
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestWidestPath(t *testing.T) {
+	g := NewGraph()
+	// Short route through a thin link, long route through thick links
+	g.AddEdge(0, 1, 100)
+	g.AddEdge(1, 3, 5)
+	g.AddEdge(0, 2, 50)
+	g.AddEdge(2, 4, 40)
+	g.AddEdge(4, 3, 60)
+	g.AddEdge(3, 5, 1000)
+	g.AddEdge(0, 5, INF) // closed
+	g.AddEdge(6, 0, 1)
+
+	path, w := WidestPath(g, 0, 5)
+	if w != 40 || !slices.Equal(path, []NodeID{0, 2, 4, 3, 5}) {
+		t.Errorf("expected width 40 via 2 and 4, got %v along %v", w, path)
+	}
+
+	r := WidestPaths(g, 0)
+	if r.Width[0] != INF || r.Width[1] != 100 || r.Width[3] != 40 {
+		t.Errorf("unexpected widths %v", r.Width)
+	}
+	if r.Width[6] != 0 || r.PathTo(6) != nil {
+		t.Errorf("node 6 should be unreachable, got %v", r.Width[6])
+	}
+	if path, w := WidestPath(g, 0, 42); path != nil || w != 0 {
+		t.Errorf("unknown target: got %v, %v", path, w)
+	}
+}
+
+// bruteWidest computes bottlenecks by repeated relaxation until stable.
+func bruteWidest(g *Graph, source NodeID) map[NodeID]Dist {
+	width := make(map[NodeID]Dist)
+	width[source] = INF
+	for changed := true; changed; {
+		changed = false
+		for u, edges := range g.adj {
+			for _, e := range edges {
+				if w := min(width[u], e.Weight); w > width[e.To] {
+					width[e.To] = w
+					changed = true
+				}
+			}
+		}
+	}
+	return width
+}
+
+func TestWidestPaths_MatchesBruteForce(t *testing.T) {
+	g := generateRandomGraph(200, 800, 100, 12)
+	rng := rand.New(rand.NewSource(1))
+	for _, src := range []NodeID{0, NodeID(rng.Intn(200))} {
+		want := bruteWidest(g, src)
+		got := WidestPaths(g, src, WithQueue(BinaryHeap))
+		for v := range g.adj {
+			if got.Width[v] != want[v] {
+				t.Fatalf("source %d, node %d: expected %v, got %v", src, v, want[v], got.Width[v])
+			}
+			// The reported path must realize the width
+			if path := got.PathTo(v); v != src && path != nil {
+				bottleneck := INF
+				for i := 1; i < len(path); i++ {
+					best := Dist(0)
+					for _, e := range g.adj[path[i-1]] {
+						if e.To == path[i] {
+							best = max(best, e.Weight)
+						}
+					}
+					bottleneck = min(bottleneck, best)
+				}
+				if bottleneck != want[v] {
+					t.Errorf("path to %d has bottleneck %v, want %v", v, bottleneck, want[v])
+				}
+			}
+		}
+	}
+}